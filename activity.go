@@ -0,0 +1,139 @@
+package togglplanapi
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// The API has no endpoint for the activity or audit log of a workspace, so
+// an activity log is built from the changes detected by a TaskFeed instead.
+// It only records what polls can see: who made a change isn't reported, and
+// several edits between two polls show up as one.
+
+// Activity is an entry of a task activity log.
+type Activity struct {
+	// Time is when the change was made, as far as it is known: the update
+	// time of the task, or the time of the poll that noticed a deletion.
+	Time   time.Time  `json:"time"`
+	Action ChangeType `json:"action"`
+	TaskId ID         `json:"task_id"`
+	// Fields are the JSON names of the fields that changed, for updates.
+	Fields []string `json:"fields,omitempty"`
+	// Task is the task after the change, or before it for deletions.
+	Task Task `json:"task"`
+}
+
+// ActivityFromChanges turns the changes of a TaskFeed poll into activity log
+// entries, in the same order.
+// Arguments:
+//
+//	changes: Changes returned by TaskFeed.Poll
+//	polledAt: Time of the poll, used for deletions
+func ActivityFromChanges(changes []TaskChange, polledAt time.Time) []Activity {
+	activities := make([]Activity, 0, len(changes))
+	for _, change := range changes {
+		activity := Activity{Time: polledAt, Action: change.Type, TaskId: change.Task.Id, Task: change.Task}
+
+		switch change.Type {
+		case ChangeCreated:
+			if !change.Task.CreatedAt.IsZero() {
+				activity.Time = change.Task.CreatedAt.Time
+			}
+		case ChangeUpdated:
+			if !change.Task.UpdatedAt.IsZero() {
+				activity.Time = change.Task.UpdatedAt.Time
+			}
+			if change.Previous != nil {
+				activity.Fields = changedFields(*change.Previous, change.Task)
+			}
+		}
+
+		activities = append(activities, activity)
+	}
+	return activities
+}
+
+// changedFields returns the sorted JSON names of the fields that differ
+// between two versions of a task.
+func changedFields(previous Task, current Task) []string {
+	update, changed := DiffTask(previous, current)
+	if !changed {
+		return nil
+	}
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		return nil
+	}
+	var set map[string]json.RawMessage
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil
+	}
+
+	fields := make([]string, 0, len(set))
+	for field := range set {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// ActivityFilter narrows down an activity log. Zero fields don't filter.
+type ActivityFilter struct {
+	// Since and Until bound the time of the entries, inclusively.
+	Since time.Time
+	Until time.Time
+	// Actions keeps entries of these types.
+	Actions []ChangeType
+	// MemberIds keeps entries of tasks assigned to any of these members.
+	MemberIds []ID
+	// ProjectIds keeps entries of tasks in any of these projects.
+	ProjectIds []ID
+}
+
+// Matches reports whether an activity log entry passes the filter.
+func (filter ActivityFilter) Matches(activity Activity) bool {
+	if !filter.Since.IsZero() && activity.Time.Before(filter.Since) {
+		return false
+	}
+	if !filter.Until.IsZero() && activity.Time.After(filter.Until) {
+		return false
+	}
+	if len(filter.Actions) > 0 && !contains(filter.Actions, activity.Action) {
+		return false
+	}
+	if len(filter.ProjectIds) > 0 && !contains(filter.ProjectIds, activity.Task.ProjectId) {
+		return false
+	}
+	if len(filter.MemberIds) > 0 {
+		for _, assignee := range activity.Task.Assignees {
+			if contains(filter.MemberIds, assignee) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// FilterActivity returns the entries of an activity log that pass filter.
+func FilterActivity(activities []Activity, filter ActivityFilter) []Activity {
+	var filtered []Activity
+	for _, activity := range activities {
+		if filter.Matches(activity) {
+			filtered = append(filtered, activity)
+		}
+	}
+	return filtered
+}
+
+// contains reports whether values contains value.
+func contains[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package togglplanapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestActivityFromChanges(t *testing.T) {
+	polledAt := time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC)
+	updatedAt := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+
+	previous := Task{Id: 1, Name: "Draft", Done: false}
+	current := Task{Id: 1, Name: "Review", Done: true, ProjectId: 7, UpdatedAt: DateTime{updatedAt}}
+	deleted := Task{Id: 2, ProjectId: 8}
+
+	activities := ActivityFromChanges([]TaskChange{
+		{Type: ChangeUpdated, Task: current, Previous: &previous},
+		{Type: ChangeDeleted, Task: deleted, Previous: &deleted},
+	}, polledAt)
+
+	if len(activities) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", activities)
+	}
+	if !activities[0].Time.Equal(updatedAt) || !reflect.DeepEqual(activities[0].Fields, []string{"done", "name", "project_id"}) {
+		t.Fatalf("unexpected update entry %+v", activities[0])
+	}
+	if !activities[1].Time.Equal(polledAt) || activities[1].Action != ChangeDeleted {
+		t.Fatalf("unexpected deletion entry %+v", activities[1])
+	}
+
+	filtered := FilterActivity(activities, ActivityFilter{Since: polledAt.Add(-time.Hour), ProjectIds: []ID{8}})
+	if len(filtered) != 1 || filtered[0].TaskId != 2 {
+		t.Fatalf("unexpected filtered entries %+v", filtered)
+	}
+}
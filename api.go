@@ -0,0 +1,168 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// apiURL builds the full URL of an API endpoint, in the API version of pa.
+// Arguments:
+//
+//	pa: togglPlanApi instance
+//	path: Endpoint path relative to /api/<version>, starting with a slash
+//	query: Query string parameters, if any (may be nil)
+func apiURL(pa *togglPlanApi, path string, query url.Values) string {
+	fullURL := APIURL(pa, path)
+	if len(query) > 0 {
+		fullURL += "?" + query.Encode()
+	}
+	return fullURL
+}
+
+// workspacePath builds an endpoint path scoped to a workspace.
+func workspacePath(workspaceId ID, format string, args ...interface{}) string {
+	return fmt.Sprintf("/%d", workspaceId) + fmt.Sprintf(format, args...)
+}
+
+// getJSON sends an authenticated GET request and decodes the JSON response into out.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	path: Endpoint path relative to /api/<version>
+//	query: Query string parameters, if any (may be nil)
+//	out: Pointer to the value the response is decoded into
+func getJSON(ctx context.Context, pa *togglPlanApi, path string, query url.Values, out interface{}) error {
+	return sendJSON(ctx, pa, "GET", path, query, nil, out)
+}
+
+// sendJSON sends an authenticated request with an optional JSON body, and
+// decodes the JSON response into out unless out is nil.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	method: HTTP method (GET, POST, etc.)
+//	path: Endpoint path relative to /api/<version>
+//	query: Query string parameters, if any (may be nil)
+//	in: Value to encode as the request body (nil for no body)
+//	out: Pointer to the value the response is decoded into (nil to discard it)
+func sendJSON(ctx context.Context, pa *togglPlanApi, method string, path string, query url.Values, in interface{}, out interface{}) error {
+	return sendJSONWithHeaders(ctx, pa, method, path, query, map[string]string{}, in, out)
+}
+
+// sendJSONWithHeaders works like sendJSON, adding headers to the request.
+func sendJSONWithHeaders(ctx context.Context, pa *togglPlanApi, method string, path string, query url.Values, headers map[string]string, in interface{}, out interface{}) error {
+	if err := checkTypedVersion(pa); err != nil {
+		return err
+	}
+	if err := validateInput(pa, in); err != nil {
+		return err
+	}
+
+	body := []byte{}
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = encoded
+	}
+
+	if pa.cache != nil && method == http.MethodGet && out != nil {
+		return cachedGet(ctx, pa, path, apiURL(pa, path, query), headers, out)
+	}
+
+	resp, _, err := authenticatedRequest(ctx, pa, apiURL(pa, path, query), method, body, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	recordResponse(ctx, resp)
+
+	// A write makes the cached copies of the resource, and of the lists
+	// of the workspace, stale
+	if pa.cache != nil && method != http.MethodGet {
+		invalidateScope(ctx, pa, path)
+	}
+
+	// A 204 No Content response leaves out untouched
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+
+	err = decodeResponse(pa, resp, out)
+	if errors.Is(err, io.EOF) && resp.StatusCode == http.StatusCreated {
+		// The created resource wasn't sent back, but may be fetched from its Location
+		location, locationErr := resp.Location()
+		if locationErr != nil {
+			return fmt.Errorf("created resource has neither a body nor a Location header: %w", err)
+		}
+		return fetchJSON(ctx, pa, location.String(), out)
+	}
+	return err
+}
+
+// ErrForeignLocation is matched, using errors.Is, by errors reporting that
+// the API pointed to a URL outside of itself, in a Location header, which
+// isn't followed so that the token of the client isn't sent there.
+var ErrForeignLocation = errors.New("location is outside of the API")
+
+// checkLocation returns an error matching ErrForeignLocation unless location,
+// a URL sent by the API, has the scheme and host of pa and is under its path.
+func checkLocation(pa *togglPlanApi, location string) error {
+	base, err := url.Parse(pa.baseURL)
+	if err != nil {
+		return err
+	}
+	target, err := url.Parse(location)
+	if err != nil {
+		return err
+	}
+
+	basePath := strings.TrimSuffix(base.Path, "/")
+	if target.Scheme != base.Scheme || target.Host != base.Host || target.User != nil ||
+		(basePath != "" && target.Path != basePath && !strings.HasPrefix(target.Path, basePath+"/")) {
+		return fmt.Errorf("%w: %s", ErrForeignLocation, location)
+	}
+	return nil
+}
+
+// fetchJSON sends an authenticated GET request to a full URL of the API, and
+// decodes the JSON response into out.
+func fetchJSON(ctx context.Context, pa *togglPlanApi, url string, out interface{}) error {
+	if err := checkLocation(pa, url); err != nil {
+		return err
+	}
+	resp, _, err := authenticatedRequest(ctx, pa, url, http.MethodGet, nil, map[string]string{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	recordResponse(ctx, resp)
+
+	return decodeResponse(pa, resp, out)
+}
+
+// decodeResponse decodes the body of a successful response into out, after
+// checking that it is JSON.
+func decodeResponse(pa *togglPlanApi, resp *http.Response, out interface{}) error {
+	if err := checkJSONBody(resp); err != nil {
+		return err
+	}
+	return decodeJSON(pa, resp.Body, out)
+}
+
+// decodeJSON decodes a response body into out, with the decoder configured on pa.
+func decodeJSON(pa *togglPlanApi, r io.Reader, out interface{}) error {
+	if pa.decodeJSON != nil {
+		return pa.decodeJSON(r, out)
+	}
+	return json.NewDecoder(r).Decode(out)
+}
@@ -0,0 +1,37 @@
+package togglplanapi
+
+import "context"
+
+// authKey is the context key of the bearer token set with WithAuth.
+type authKey struct{}
+
+// WithAuth returns a context that makes the calls it is passed to
+// authenticate with token rather than the credentials of the client. A
+// server acting for many Toggl Plan users can then share one client, with
+// its connections, cache and settings, and pick the user of each call:
+//
+//	ctx := togglplanapi.WithAuth(r.Context(), tokens[user])
+//	tasks, err := togglplanapi.GetTasks(ctx, pa, workspaceId, filter)
+//
+// The client doesn't fetch or refresh the token, and cached responses are
+// kept apart by token, so that users never see each other's data. Raw calls
+// without a context, such as Get, keep using the credentials of the client;
+// Do uses the context of its request.
+func WithAuth(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, authKey{}, token)
+}
+
+// contextToken returns the bearer token set on ctx with WithAuth, if any.
+func contextToken(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(authKey{}).(string)
+	return token, ok
+}
+
+// bearerToken returns the bearer token of a request made with ctx: the one
+// set with WithAuth, or else the token of pa, fetched first if necessary.
+func bearerToken(ctx context.Context, pa *togglPlanApi) (string, error) {
+	if token, ok := contextToken(ctx); ok {
+		return token, nil
+	}
+	return ensureToken(ctx, pa)
+}
@@ -0,0 +1,51 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithAuth(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		user := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		fmt.Fprintf(w, `{"id":7,"name":"Task of %s"}`, user)
+	})
+	WithCache(NewMemoryCache(), CacheOptions{TTL: time.Minute})(pa)
+
+	ctx := context.Background()
+	for _, user := range []string{"ada", "bob", ""} {
+		callCtx := ctx
+		want := "Task of token"
+		if user != "" {
+			callCtx = WithAuth(ctx, user)
+			want = "Task of " + user
+		}
+		// The second call comes from the cache of the user
+		for i := 0; i < 2; i++ {
+			task, err := GetTask(callCtx, pa, 1, 7)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if task.Name != want {
+				t.Errorf("expected %q, got %q", want, task.Name)
+			}
+		}
+	}
+	if stats := Stats(pa); stats.Requests != 3 {
+		t.Errorf("expected a request per user, got %d", stats.Requests)
+	}
+
+	req, _ := http.NewRequestWithContext(WithAuth(ctx, "cy"), http.MethodGet, pa.baseURL+"/api/v5/1/tasks/7", nil)
+	resp, err := Do(pa, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if body, _ := readBody(resp); !strings.Contains(body, "Task of cy") {
+		t.Errorf("unexpected body %s", body)
+	}
+}
@@ -0,0 +1,143 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ArchiveVersion is the version of the archive format written by Backup.
+// It is increased whenever the format changes in a way older readers can't handle.
+const ArchiveVersion = 1
+
+// Archive is a snapshot of a workspace, as written by Backup.
+type Archive struct {
+	Version     int         `json:"version"`
+	CreatedAt   time.Time   `json:"created_at"`
+	WorkspaceId ID          `json:"workspace_id"`
+	Projects    []Project   `json:"projects"`
+	Tasks       []Task      `json:"tasks"`
+	Milestones  []Milestone `json:"milestones"`
+	Members     []Member    `json:"members"`
+	Groups      []Group     `json:"groups"`
+	Tags        []Tag       `json:"tags"`
+	Comments    []Comment   `json:"comments"`
+}
+
+// BackupOptions configures Backup.
+type BackupOptions struct {
+	// Since and Until set the range of tasks included in the archive, since
+	// tasks can only be listed by date. Default to two years before and after
+	// the current day.
+	Since Date
+	Until Date
+
+	// SkipComments leaves out task comments, which take one request per task to fetch.
+	SkipComments bool
+
+	// Progress, if set, receives the progress of the backup, in phases named
+	// after the resources fetched: "projects", "tasks", "milestones",
+	// "members", "groups", "tags" and "comments".
+	Progress ProgressFunc
+}
+
+// Backup walks all projects, tasks, milestones, members, groups, tags and
+// comments of a workspace, and writes them to w as a versioned JSON archive.
+// The archive can be read back with ReadArchive.
+//
+// With options.Progress set, phases fetched in a single request only report
+// that they started, while tasks and comments report each request.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	w: Destination of the archive
+//	options: Backup settings (use `togglplanapi.BackupOptions{}` for the defaults)
+func Backup(ctx context.Context, pa *togglPlanApi, workspaceId ID, w io.Writer, options BackupOptions) error {
+	now := time.Now()
+	if options.Since.IsZero() {
+		options.Since = DateOf(now).AddDate(-2, 0, 0)
+	}
+	if options.Until.IsZero() {
+		options.Until = DateOf(now).AddDate(2, 0, 0)
+	}
+
+	archive := Archive{
+		Version:     ArchiveVersion,
+		CreatedAt:   now.UTC(),
+		WorkspaceId: workspaceId,
+	}
+
+	var err error
+
+	options.Progress.Report("projects", 0, 0)
+	if archive.Projects, err = GetProjects(ctx, pa, workspaceId); err != nil {
+		return fmt.Errorf("backing up projects: %w", err)
+	}
+
+	filter := TaskFilter{Since: options.Since, Until: options.Until, Progress: options.Progress}
+	if archive.Tasks, err = ListAllTasks(ctx, pa, workspaceId, filter); err != nil {
+		return fmt.Errorf("backing up tasks: %w", err)
+	}
+
+	options.Progress.Report("milestones", 0, 0)
+	if archive.Milestones, err = GetMilestones(ctx, pa, workspaceId); err != nil {
+		return fmt.Errorf("backing up milestones: %w", err)
+	}
+
+	options.Progress.Report("members", 0, 0)
+	if archive.Members, err = GetMembers(ctx, pa, workspaceId); err != nil {
+		return fmt.Errorf("backing up members: %w", err)
+	}
+
+	options.Progress.Report("groups", 0, 0)
+	if archive.Groups, err = GetGroups(ctx, pa, workspaceId); err != nil {
+		return fmt.Errorf("backing up groups: %w", err)
+	}
+
+	options.Progress.Report("tags", 0, 0)
+	if archive.Tags, err = GetTags(ctx, pa, workspaceId); err != nil {
+		return fmt.Errorf("backing up tags: %w", err)
+	}
+
+	if !options.SkipComments {
+		progress := options.Progress.Counter("comments", len(archive.Tasks))
+		for _, task := range archive.Tasks {
+			comments, err := GetComments(ctx, pa, workspaceId, task.Id)
+			if err != nil {
+				return fmt.Errorf("backing up comments of task %d: %w", task.Id, err)
+			}
+
+			for _, comment := range comments {
+				comment.TaskId = task.Id
+				archive.Comments = append(archive.Comments, comment)
+			}
+			progress.Add()
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(archive)
+}
+
+// ReadArchive reads an archive written by Backup.
+// It fails if the archive was written by a newer, incompatible version of this package.
+// Arguments:
+//
+//	r: Source of the archive
+func ReadArchive(r io.Reader) (*Archive, error) {
+	var archive Archive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, err
+	}
+
+	if archive.Version < 1 || archive.Version > ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", archive.Version)
+	}
+
+	return &archive, nil
+}
@@ -0,0 +1,71 @@
+package togglplanapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBackup(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v5/42/projects":
+			fmt.Fprint(w, `[{"id":1,"name":"Website"}]`)
+		case "/api/v5/42/tasks":
+			fmt.Fprint(w, `[{"id":10,"name":"Design","project_id":1}]`)
+		case "/api/v5/42/tasks/10/comments":
+			fmt.Fprint(w, `[{"id":100,"body":"Looks good"}]`)
+		case "/api/v5/42/milestones", "/api/v5/42/groups", "/api/v5/42/tags":
+			fmt.Fprint(w, `[]`)
+		case "/api/v5/42/members":
+			fmt.Fprint(w, `[{"id":3,"name":"Ada","email":"ada@example.com"}]`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	var phases []string
+	options := BackupOptions{
+		Since: NewDate(2024, 1, 1),
+		Until: NewDate(2024, 1, 31),
+		Progress: func(update ProgressUpdate) {
+			phases = append(phases, fmt.Sprintf("%s %d/%d", update.Phase, update.Done, update.Total))
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Backup(context.Background(), pa, 42, &buf, options); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := ReadArchive(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if archive.Version != ArchiveVersion || archive.WorkspaceId != 42 {
+		t.Fatalf("unexpected archive header %+v", archive)
+	}
+	if len(archive.Projects) != 1 || len(archive.Tasks) != 1 || len(archive.Members) != 1 {
+		t.Fatalf("unexpected archive contents %+v", archive)
+	}
+	if len(archive.Comments) != 1 || archive.Comments[0].TaskId != 10 {
+		t.Fatalf("comments not linked to their task: %+v", archive.Comments)
+	}
+
+	want := "projects 0/0,tasks 0/1,tasks 1/1,milestones 0/0,members 0/0,groups 0/0,tags 0/0,comments 0/1,comments 1/1"
+	if got := strings.Join(phases, ","); got != want {
+		t.Fatalf("unexpected progress %s", got)
+	}
+}
+
+func TestReadArchiveRejectsNewerVersions(t *testing.T) {
+	_, err := ReadArchive(strings.NewReader(`{"version":99}`))
+	if err == nil {
+		t.Fatal("expected an error for an unsupported version")
+	}
+}
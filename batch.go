@@ -0,0 +1,53 @@
+package togglplanapi
+
+import (
+	"context"
+	"sync"
+)
+
+// RunBatch runs jobs, with at most concurrency of them running at the same
+// time, and returns their errors in the same order as jobs.
+//
+// Jobs that haven't started when ctx is cancelled are skipped, and their
+// error is set to the context's error. A job that panics gets a *PanicError.
+// Arguments:
+//
+//	ctx: Context passed to every job
+//	concurrency: Maximum number of jobs running at the same time (values below 1 mean 1)
+//	jobs: Functions to run, typically each sending one or more requests
+func RunBatch(ctx context.Context, concurrency int, jobs []func(ctx context.Context) error) []error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	errs := make([]error, len(jobs))
+	slots := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(jobs); j++ {
+				errs[j] = ctx.Err()
+			}
+			wg.Wait()
+			return errs
+		}
+
+		wg.Add(1)
+		go func(i int, job func(ctx context.Context) error) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = callHook("job", func() error { return job(ctx) })
+		}(i, job)
+	}
+
+	wg.Wait()
+	return errs
+}
@@ -0,0 +1,60 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBatch(t *testing.T) {
+	var running, peak int32
+	failure := errors.New("failed")
+
+	jobs := make([]func(ctx context.Context) error, 10)
+	for i := range jobs {
+		i := i
+		jobs[i] = func(ctx context.Context) error {
+			now := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if now <= old || atomic.CompareAndSwapInt32(&peak, old, now) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+
+			if i == 3 {
+				return failure
+			}
+			return nil
+		}
+	}
+
+	errs := RunBatch(context.Background(), 3, jobs)
+
+	if peak > 3 {
+		t.Fatalf("%d jobs ran at the same time, expected at most 3", peak)
+	}
+	for i, err := range errs {
+		if (i == 3) != (err == failure) {
+			t.Fatalf("unexpected error for job %d: %v", i, err)
+		}
+	}
+}
+
+func TestRunBatchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ran := false
+	errs := RunBatch(ctx, 1, []func(ctx context.Context) error{
+		func(ctx context.Context) error { ran = true; return nil },
+	})
+
+	if ran || errs[0] != context.Canceled {
+		t.Fatalf("cancelled job ran (%v) or returned %v", ran, errs[0])
+	}
+}
@@ -0,0 +1,125 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// roundTripFunc answers requests without a network, so that benchmarks
+// measure the client alone.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// benchmarkTasks returns the JSON of n tasks, shaped like those of the API.
+func benchmarkTasks(n int) []byte {
+	tasks := make([]Task, n)
+	for i := range tasks {
+		tasks[i] = Task{
+			Id:               ID(i + 1),
+			Name:             fmt.Sprintf("Task %d", i+1),
+			Notes:            "<p>Notes of the task, with <strong>markup</strong>.</p>",
+			ProjectId:        42,
+			Assignees:        []ID{3, 5},
+			Tags:             []string{"backend", "review"},
+			StartDate:        NewDate(2024, 3, 1),
+			EndDate:          NewDate(2024, 3, 8),
+			EstimatedMinutes: 240,
+			Checklist:        []ChecklistItem{{Name: "Write tests"}, {Name: "Deploy", Done: true}},
+		}
+	}
+	data, err := json.Marshal(tasks)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+func BenchmarkRequest(b *testing.B) {
+	pa := New(username, password, clientId, clientSecret, "token", WithDefaultHeader("Accept-Language", "en"))
+	pa.httpClient.Transport = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"id":1,"name":"Design"}`)),
+			Request:    req,
+		}, nil
+	})
+	url := APIURL(pa, "/1/tasks/1")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Get(pa, url); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeTasks(b *testing.B) {
+	data := benchmarkTasks(1000)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var tasks []Task
+		if err := json.Unmarshal(data, &tasks); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetTasks(b *testing.B) {
+	data := benchmarkTasks(1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	pa := New(username, password, clientId, clientSecret, "token")
+	pa.baseURL = server.URL
+	ctx := context.Background()
+	filter := TaskFilter{Since: NewDate(2024, 3, 1), Until: NewDate(2024, 3, 31)}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tasks, err := GetTasks(ctx, pa, 1, filter)
+		if err != nil || len(tasks) != 1000 {
+			b.Fatalf("got %d tasks, %v", len(tasks), err)
+		}
+	}
+}
+
+func BenchmarkListAllTasks(b *testing.B) {
+	data := benchmarkTasks(100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer server.Close()
+
+	pa := New(username, password, clientId, clientSecret, "token")
+	pa.baseURL = server.URL
+	ctx := context.Background()
+	// A year is fetched in 12 windows, which all answer the same tasks
+	filter := TaskFilter{Since: NewDate(2024, 1, 1), Until: NewDate(2024, 12, 31)}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tasks, err := ListAllTasks(ctx, pa, 1, filter)
+		if err != nil || len(tasks) != 100 {
+			b.Fatalf("got %d tasks, %v", len(tasks), err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+)
+
+// TaskBuilder assembles the fields of a new task step by step, and checks
+// them before anything is sent to the API:
+//
+//	task, err := togglplanapi.NewTask(workspaceId).
+//		Title("Write release notes").
+//		Between(start, end).
+//		AssignTo(memberId).
+//		InProject(projectId).
+//		Create(ctx, pa)
+//
+// The methods modify and return the same builder.
+type TaskBuilder struct {
+	workspaceId ID
+	params      TaskParams
+}
+
+// NewTask starts building a task in a workspace.
+func NewTask(workspaceId ID) *TaskBuilder {
+	return &TaskBuilder{workspaceId: workspaceId}
+}
+
+// Title sets the name of the task.
+func (b *TaskBuilder) Title(name string) *TaskBuilder {
+	b.params.Name = name
+	return b
+}
+
+// Notes sets the notes of the task.
+func (b *TaskBuilder) Notes(notes string) *TaskBuilder {
+	b.params.Notes = notes
+	return b
+}
+
+// On schedules the task for a single day.
+func (b *TaskBuilder) On(date Date) *TaskBuilder {
+	return b.Between(date, date)
+}
+
+// Between schedules the task from start to end, both inclusive.
+func (b *TaskBuilder) Between(start Date, end Date) *TaskBuilder {
+	b.params.StartDate = start
+	b.params.EndDate = end
+	return b
+}
+
+// From sets the HH:MM times at which the task starts and ends.
+// Without times, the task takes whole days.
+func (b *TaskBuilder) From(startTime string, endTime string) *TaskBuilder {
+	b.params.StartTime = startTime
+	b.params.EndTime = endTime
+	return b
+}
+
+// AssignTo adds workspace members to the assignees of the task.
+func (b *TaskBuilder) AssignTo(memberIds ...ID) *TaskBuilder {
+	b.params.Assignees = append(b.params.Assignees, memberIds...)
+	return b
+}
+
+// InProject puts the task in a project.
+func (b *TaskBuilder) InProject(projectId ID) *TaskBuilder {
+	b.params.ProjectId = projectId
+	return b
+}
+
+// InMilestone puts the task in a milestone.
+func (b *TaskBuilder) InMilestone(milestoneId ID) *TaskBuilder {
+	b.params.MilestoneId = milestoneId
+	return b
+}
+
+// WithStatus sets the plan status of the task.
+func (b *TaskBuilder) WithStatus(planStatusId ID) *TaskBuilder {
+	b.params.PlanStatusId = planStatusId
+	return b
+}
+
+// Tagged adds tags to the task.
+func (b *TaskBuilder) Tagged(tags ...string) *TaskBuilder {
+	b.params.Tags = append(b.params.Tags, tags...)
+	return b
+}
+
+// Color sets the color of the task.
+func (b *TaskBuilder) Color(color Color) *TaskBuilder {
+	b.params.Color = color
+	return b
+}
+
+// Estimate sets the planned effort of the task.
+func (b *TaskBuilder) Estimate(estimate Estimate) *TaskBuilder {
+	b.params.EstimatedMinutes = estimate
+	return b
+}
+
+// Checklist adds items to the checklist of the task.
+func (b *TaskBuilder) Checklist(names ...string) *TaskBuilder {
+	for _, name := range names {
+		b.params.Checklist = append(b.params.Checklist, ChecklistItem{Name: name})
+	}
+	return b
+}
+
+// Build checks the fields of the task and returns them.
+// All problems found are reported together, as a *ValidationError.
+func (b *TaskBuilder) Build() (TaskParams, error) {
+	err := b.params.Validate()
+	if b.workspaceId == 0 {
+		invalid, _ := err.(*ValidationError)
+		if invalid == nil {
+			invalid = &ValidationError{Kind: "task"}
+		}
+		invalid.Problems = append([]error{errors.New("workspace is required")}, invalid.Problems...)
+		err = invalid
+	}
+
+	return b.params, err
+}
+
+// Create checks the fields of the task and creates it.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+func (b *TaskBuilder) Create(ctx context.Context, pa *togglPlanApi) (*Task, error) {
+	params, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return CreateTask(ctx, pa, b.workspaceId, params)
+}
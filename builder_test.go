@@ -0,0 +1,75 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTaskBuilderCreate(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var params TaskParams
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			// The handler runs on the goroutine of the server, which mustn't
+			// call t.Fatal
+			t.Errorf("decoding the params: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if params.Name != "Release notes" || params.EndDate != NewDate(2024, 3, 5) || params.Assignees[1] != 4 || params.ProjectId != 9 {
+			t.Errorf("unexpected params %+v", params)
+		}
+		json.NewEncoder(w).Encode(Task{Id: 1, Name: params.Name})
+	})
+
+	task, err := NewTask(42).
+		Title("Release notes").
+		Between(NewDate(2024, 3, 4), NewDate(2024, 3, 5)).
+		AssignTo(3, 4).
+		InProject(9).
+		Create(context.Background(), pa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Id != 1 {
+		t.Fatalf("unexpected task %+v", task)
+	}
+}
+
+func TestTaskBuilderBuild(t *testing.T) {
+	tests := []struct {
+		builder *TaskBuilder
+		want    []string
+	}{
+		{NewTask(42).Title("Standup").On(NewDate(2024, 3, 4)).From("09:00", "09:15"), nil},
+		{NewTask(42).Title("Backlog item"), nil},
+		{NewTask(0).Title(" "), []string{"workspace is required", "title is required"}},
+		{NewTask(42).Title("Late").Between(NewDate(2024, 3, 5), NewDate(2024, 3, 4)), []string{"before start date"}},
+		{NewTask(42).Title("Half").Between(NewDate(2024, 3, 5), Date{}), []string{"dates must be set together"}},
+		{NewTask(42).Title("Backwards").On(NewDate(2024, 3, 4)).From("10:00", "09:00"), []string{"not after start time"}},
+		{NewTask(42).Title("Floating").From("09:00", "10:00"), []string{"need start and end dates"}},
+		{NewTask(42).Title("Clock").On(NewDate(2024, 3, 4)).From("9am", "10:00"), []string{`invalid start time "9am"`}},
+	}
+
+	for _, test := range tests {
+		_, err := test.builder.Build()
+		if len(test.want) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", test.builder.params.Name, err)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Errorf("%s: expected an error", test.builder.params.Name)
+			continue
+		}
+		for _, want := range test.want {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: error %q doesn't mention %q", test.builder.params.Name, err, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,427 @@
+package togglplanapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache stores the responses of typed GET calls, so that they can be
+// revalidated with conditional requests or, within their TTL, used without a
+// request at all. Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the entry stored under key, if any.
+	Get(key string) (CachedResponse, bool)
+	// Set stores entry under key, replacing any previous entry.
+	Set(key string, entry CachedResponse)
+	// Delete removes the entry stored under key, if any.
+	Delete(key string)
+}
+
+// CachedResponse is a response stored in a Cache.
+type CachedResponse struct {
+	Body []byte `json:"body"`
+	// ETag and LastModified are the validators of the response, sent back in
+	// If-None-Match and If-Modified-Since headers to revalidate it.
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	// StoredAt is the time the response was received or last revalidated.
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// CacheOptions configures the response cache of a client.
+type CacheOptions struct {
+	// TTL is how long a cached response is used without asking the API.
+	// Older responses are revalidated, which costs a request but no body if
+	// they haven't changed. Defaults to 0: every call is revalidated, so that
+	// writes made elsewhere are always seen.
+	TTL time.Duration
+
+	// StaleWhileRevalidate is how long after its TTL a cached response is
+	// still returned at once, while it is revalidated in the background.
+	// Defaults to 0: stale responses are revalidated before returning.
+	StaleWhileRevalidate time.Duration
+
+	// OnRefresh, if set, is called after each background revalidation, from
+	// the goroutine that ran it. Calling the same typed call again returns
+	// the refreshed response. Panics are recovered and dropped.
+	OnRefresh func(RefreshEvent)
+}
+
+// RefreshEvent reports the outcome of a background revalidation.
+type RefreshEvent struct {
+	// URL is the URL of the revalidated response.
+	URL string
+	// Changed reports whether the API sent a new response, rather than
+	// confirming the cached one.
+	Changed bool
+	// Err is the error of the request. The stale response stays cached.
+	Err error
+}
+
+// WithCache caches the responses of typed GET calls in cache. Responses are
+// keyed by URL and by the account of the client, so that a cache can be
+// shared by clients of several accounts.
+//
+// Calls answered from the cache without a request don't fill in the
+// Response of WithResponse.
+func WithCache(cache Cache, options CacheOptions) Option {
+	return func(pa *togglPlanApi) {
+		pa.cache = &responseCache{store: cache, options: options, refreshing: map[string]bool{}}
+	}
+}
+
+// responseCache is the cache of a client, with its settings and the
+// responses being revalidated in the background.
+type responseCache struct {
+	store   Cache
+	options CacheOptions
+
+	mu         sync.Mutex
+	refreshing map[string]bool
+}
+
+// cacheKey returns the key of the response to url in the cache of pa, for a
+// call made with ctx.
+func cacheKey(ctx context.Context, pa *togglPlanApi, url string) string {
+	// Calls made with WithAuth, and clients built with a token only, are
+	// told apart by their token
+	token, ok := contextToken(ctx)
+	if !ok {
+		if pa.username != "" {
+			return pa.username + " " + url
+		}
+		token, _ = currentToken(pa)
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8]) + " " + url
+}
+
+// cacheScope returns the scope of path whose cached responses a write to
+// path makes stale: its workspace, e.g. "/42" for "/42/tasks/7", or its
+// first segment for paths outside of workspaces.
+func cacheScope(path string) string {
+	scope := strings.TrimPrefix(path, "/")
+	if i := strings.IndexAny(scope, "/?"); i >= 0 {
+		scope = scope[:i]
+	}
+	return "/" + scope
+}
+
+// generationKey returns the key of the generation of the scope of path in
+// the cache of pa.
+func generationKey(ctx context.Context, pa *togglPlanApi, path string) string {
+	return cacheKey(ctx, pa, "generation "+APIURL(pa, cacheScope(path)))
+}
+
+// scopedCacheKey returns the key of the response to url, fetched from path,
+// in the cache of pa. The key holds the generation of the scope of path, so
+// that the responses cached before a write in the scope are no longer used,
+// lists and all.
+func scopedCacheKey(ctx context.Context, pa *togglPlanApi, path string, url string) string {
+	generation, _ := pa.cache.store.Get(generationKey(ctx, pa, path))
+	return cacheKey(ctx, pa, url) + " " + string(generation.Body)
+}
+
+// invalidateScope makes the responses cached in the scope of path stale, by
+// deleting the response of path and starting a new generation of the scope.
+// The generation is kept in the cache, so that processes sharing a FileCache
+// see it too.
+func invalidateScope(ctx context.Context, pa *togglPlanApi, path string) {
+	pa.cache.store.Delete(scopedCacheKey(ctx, pa, path, APIURL(pa, path)))
+
+	generation := make([]byte, 8)
+	rand.Read(generation)
+	pa.cache.store.Set(generationKey(ctx, pa, path), CachedResponse{
+		Body:     []byte(hex.EncodeToString(generation)),
+		StoredAt: time.Now(),
+	})
+}
+
+// cachedGet works like getJSON for the full url of path, going through the
+// cache of pa.
+func cachedGet(ctx context.Context, pa *togglPlanApi, path string, url string, headers map[string]string, out interface{}) error {
+	key := scopedCacheKey(ctx, pa, path, url)
+	entry, cached := pa.cache.store.Get(key)
+	if cached {
+		age := time.Since(entry.StoredAt)
+		if age < pa.cache.options.TTL {
+			pa.metrics.recordCache(cacheHit)
+			return decodeJSON(pa, bytes.NewReader(entry.Body), out)
+		}
+		if age < pa.cache.options.TTL+pa.cache.options.StaleWhileRevalidate {
+			pa.metrics.recordCache(cacheHit)
+			if err := decodeJSON(pa, bytes.NewReader(entry.Body), out); err != nil {
+				return err
+			}
+			refreshInBackground(ctx, pa, key, url, headers, entry)
+			return nil
+		}
+	}
+
+	entry, changed, err := revalidate(ctx, pa, key, url, headers, entry, cached)
+	if err != nil {
+		return err
+	}
+	if changed {
+		pa.metrics.recordCache(cacheMiss)
+	} else {
+		pa.metrics.recordCache(cacheRevalidated)
+	}
+	return decodeJSON(pa, bytes.NewReader(entry.Body), out)
+}
+
+// revalidate fetches url, conditionally if the entry is cached, and stores
+// the response in the cache of pa. It returns the entry stored, and whether
+// the API sent a new response.
+func revalidate(ctx context.Context, pa *togglPlanApi, key string, url string, headers map[string]string, entry CachedResponse, cached bool) (CachedResponse, bool, error) {
+	if cached {
+		headers = mergeMaps(headers, nil)
+		if entry.ETag != "" {
+			headers["If-None-Match"] = entry.ETag
+		}
+		if entry.LastModified != "" {
+			headers["If-Modified-Since"] = entry.LastModified
+		}
+	}
+
+	resp, _, err := authenticatedRequest(ctx, pa, url, http.MethodGet, nil, headers)
+	var apiErr *APIError
+	if cached && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotModified {
+		entry.StoredAt = time.Now()
+		pa.cache.store.Set(key, entry)
+		return entry, false, nil
+	}
+	if err != nil {
+		return CachedResponse{}, false, err
+	}
+	defer resp.Body.Close()
+	recordResponse(ctx, resp)
+
+	if err := checkJSONBody(resp); err != nil {
+		return CachedResponse{}, false, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return CachedResponse{}, false, err
+	}
+	// Responses that don't decode aren't worth keeping
+	if !json.Valid(body) {
+		return CachedResponse{}, false, decodeJSON(pa, bytes.NewReader(body), &json.RawMessage{})
+	}
+
+	entry = CachedResponse{
+		Body:         body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		StoredAt:     time.Now(),
+	}
+	pa.cache.store.Set(key, entry)
+	return entry, true, nil
+}
+
+// refreshInBackground revalidates a stale entry in a goroutine, unless it is
+// already being revalidated, and reports the outcome to OnRefresh. It keeps
+// the token set on ctx with WithAuth, if any.
+func refreshInBackground(ctx context.Context, pa *togglPlanApi, key string, url string, headers map[string]string, entry CachedResponse) {
+	c := pa.cache
+	c.mu.Lock()
+	if c.refreshing[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.refreshing[key] = true
+	c.mu.Unlock()
+
+	// The call that found the entry stale will have returned, so its
+	// context may be canceled already
+	background := context.Background()
+	if token, ok := contextToken(ctx); ok {
+		background = WithAuth(background, token)
+	}
+
+	go func() {
+		_, changed, err := revalidate(background, pa, key, url, headers, entry, true)
+
+		c.mu.Lock()
+		delete(c.refreshing, key)
+		c.mu.Unlock()
+
+		if c.options.OnRefresh != nil {
+			// Nothing is waiting for the refresh, so a panic is dropped
+			callHook("OnRefresh", func() error {
+				c.options.OnRefresh(RefreshEvent{URL: url, Changed: changed, Err: err})
+				return nil
+			})
+		}
+	}()
+}
+
+// errNoCache is returned by Warmup for clients without a cache.
+var errNoCache = errors.New("client has no cache, see WithCache")
+
+// Warmup fetches the metadata of a workspace at the same time, so that
+// it is in the cache of pa when interactive commands need it: the members
+// and groups, projects, milestones, tags, board columns, and the settings of
+// the workspace. Within the TTL of the cache, the calls fetching them are
+// then answered without a request.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance, with a cache (see WithCache)
+//	workspaceId: ID of the workspace
+func Warmup(ctx context.Context, pa *togglPlanApi, workspaceId ID) error {
+	if pa.cache == nil {
+		return errNoCache
+	}
+
+	return errors.Join(RunBatch(ctx, 4, []func(ctx context.Context) error{
+		func(ctx context.Context) error {
+			_, err := GetMembers(ctx, pa, workspaceId)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := GetGroups(ctx, pa, workspaceId)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := GetProjects(ctx, pa, workspaceId)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := GetMilestones(ctx, pa, workspaceId)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := GetTags(ctx, pa, workspaceId)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := GetPlanStatuses(ctx, pa, workspaceId)
+			return err
+		},
+		func(ctx context.Context) error {
+			_, err := GetWorkspaces(ctx, pa)
+			return err
+		},
+	})...)
+}
+
+// MemoryCache is a Cache held in memory, for long-running processes.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]CachedResponse
+}
+
+// NewMemoryCache returns an empty MemoryCache.
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: map[string]CachedResponse{}}
+}
+
+func (c *MemoryCache) Get(key string) (CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	return entry, ok
+}
+
+func (c *MemoryCache) Set(key string, entry CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = entry
+}
+
+func (c *MemoryCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// FileCache is a Cache stored in a directory, one file per entry, so that
+// short-lived processes on the same machine, such as the invocations of a
+// CLI, share their cached responses and validators.
+//
+// Entries are written to a temporary file and renamed into place, so that
+// processes reading the cache never see a partial entry, and concurrent
+// writers of an entry leave one of their versions. Entries that can't be
+// read, e.g. after a crash, are treated as missing.
+type FileCache struct {
+	dir string
+}
+
+// NewFileCache returns a FileCache storing its entries in dir, which is
+// created if needed. DefaultCacheDir returns a directory suited for it.
+func NewFileCache(dir string) (*FileCache, error) {
+	// Responses hold workspace data, so only the user may read them
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &FileCache{dir: dir}, nil
+}
+
+// DefaultCacheDir returns the directory of the package in the user's cache
+// directory, such as ~/.cache/togglplanapi on Linux.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "togglplanapi"), nil
+}
+
+// path returns the file of the entry stored under key.
+func (c *FileCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *FileCache) Get(key string) (CachedResponse, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return CachedResponse{}, false
+	}
+	var entry CachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return CachedResponse{}, false
+	}
+	return entry, true
+}
+
+// Set stores entry under key. Failures to write are ignored, since the
+// entry can be fetched again.
+func (c *FileCache) Set(key string, entry CachedResponse) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	file, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+}
+
+func (c *FileCache) Delete(key string) {
+	os.Remove(c.path(key))
+}
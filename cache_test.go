@@ -0,0 +1,225 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileCacheSharedByClients(t *testing.T) {
+	var requests, bodies int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		bodies++
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `[{"id":1,"name":"Website"}]`)
+	}
+
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Two clients stand for two invocations of a CLI
+	server := newTestClient(t, handler)
+	for i := 0; i < 2; i++ {
+		pa := New(username, password, clientId, clientSecret, "token", WithCache(cache, CacheOptions{}))
+		pa.baseURL = server.baseURL
+
+		projects, err := GetProjects(context.Background(), pa, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(projects) != 1 || projects[0].Name != "Website" {
+			t.Fatalf("unexpected projects %+v", projects)
+		}
+	}
+
+	if requests != 2 || bodies != 1 {
+		t.Errorf("expected the second call to be revalidated, got %d requests and %d bodies", requests, bodies)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	requests := 0
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method == http.MethodGet {
+			fmt.Fprintf(w, `{"id":7,"name":"Design %d"}`, requests)
+		} else {
+			fmt.Fprint(w, `{"id":7,"name":"Renamed"}`)
+		}
+	})
+	WithCache(NewMemoryCache(), CacheOptions{TTL: time.Minute})(pa)
+
+	ctx := context.Background()
+	first, err := GetTask(ctx, pa, 1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := GetTask(ctx, pa, 1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 || second.Name != first.Name {
+		t.Fatalf("expected a cached task, got %q after %d requests", second.Name, requests)
+	}
+
+	// Updating the task drops its cached copy
+	name := "Renamed"
+	if _, err := UpdateTask(ctx, pa, 1, 7, TaskUpdate{Name: &name}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetTask(ctx, pa, 1, 7); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Errorf("expected the task to be fetched again, got %d requests", requests)
+	}
+}
+
+func TestCacheWriteInvalidatesLists(t *testing.T) {
+	requests := map[string]int{}
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests[r.Method+" "+r.URL.Path]++
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v5/1/tasks":
+			fmt.Fprint(w, `{"id":8,"name":"New"}`)
+		default:
+			fmt.Fprintf(w, `[{"id":7,"name":"Listed %d"}]`, requests[r.Method+" "+r.URL.Path])
+		}
+	})
+	WithCache(NewMemoryCache(), CacheOptions{TTL: time.Minute})(pa)
+
+	ctx := context.Background()
+	list := func() {
+		for _, workspaceId := range []ID{1, 2} {
+			if _, err := GetProjects(ctx, pa, workspaceId); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	list()
+	list()
+	if requests["GET /api/v5/1/projects"] != 1 || requests["GET /api/v5/2/projects"] != 1 {
+		t.Fatalf("expected the lists to be cached, got %v", requests)
+	}
+
+	// A task created in workspace 1 makes its lists stale, and only its
+	if _, err := CreateTask(ctx, pa, 1, TaskParams{Name: "New"}); err != nil {
+		t.Fatal(err)
+	}
+	list()
+	if requests["GET /api/v5/1/projects"] != 2 || requests["GET /api/v5/2/projects"] != 1 {
+		t.Errorf("expected the lists of workspace 1 to be fetched again, got %v", requests)
+	}
+}
+
+func TestFileCacheIgnoresBrokenEntries(t *testing.T) {
+	cache, err := NewFileCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Set("key", CachedResponse{Body: []byte("[]"), ETag: `"a"`})
+	if entry, ok := cache.Get("key"); !ok || entry.ETag != `"a"` || string(entry.Body) != "[]" {
+		t.Fatalf("unexpected entry %+v", entry)
+	}
+
+	if err := os.WriteFile(cache.path("key"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("key"); ok {
+		t.Error("expected a broken entry to be missing")
+	}
+
+	cache.Delete("key")
+	if _, err := os.Stat(cache.path("key")); !os.IsNotExist(err) {
+		t.Errorf("expected the entry to be deleted, got %v", err)
+	}
+}
+
+func TestCacheStaleWhileRevalidate(t *testing.T) {
+	requests := 0
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprintf(w, `{"id":7,"name":"Design %d"}`, requests)
+	})
+
+	refreshed := make(chan RefreshEvent, 1)
+	cache := NewMemoryCache()
+	WithCache(cache, CacheOptions{
+		StaleWhileRevalidate: time.Hour,
+		OnRefresh:            func(event RefreshEvent) { refreshed <- event },
+	})(pa)
+
+	ctx := context.Background()
+	if _, err := GetTask(ctx, pa, 1, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	// The stale task is returned at once, and refreshed in the background
+	task, err := GetTask(ctx, pa, 1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Name != "Design 1" {
+		t.Errorf("expected the stale task, got %q", task.Name)
+	}
+
+	select {
+	case event := <-refreshed:
+		if !event.Changed || event.Err != nil || event.URL != pa.baseURL+"/api/v5/1/tasks/7" {
+			t.Errorf("unexpected refresh %+v", event)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the task wasn't refreshed")
+	}
+
+	task, err = GetTask(ctx, pa, 1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Name != "Design 2" {
+		t.Errorf("expected the refreshed task, got %q", task.Name)
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	var mu sync.Mutex
+	paths := map[string]int{}
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths[r.URL.Path]++
+		mu.Unlock()
+		fmt.Fprint(w, `[]`)
+	})
+
+	if err := Warmup(context.Background(), pa, 1); err == nil {
+		t.Fatal("expected an error without a cache")
+	}
+
+	WithCache(NewMemoryCache(), CacheOptions{TTL: time.Minute})(pa)
+	if err := Warmup(context.Background(), pa, 1); err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 7 {
+		t.Fatalf("unexpected requests %v", paths)
+	}
+
+	if _, err := GetProjects(context.Background(), pa, 1); err != nil {
+		t.Fatal(err)
+	}
+	if paths["/api/v5/1/projects"] != 1 {
+		t.Errorf("expected projects to come from the cache, got %d requests", paths["/api/v5/1/projects"])
+	}
+}
@@ -0,0 +1,150 @@
+package togglplanapi
+
+import (
+	"sync"
+	"time"
+)
+
+// HolidayProvider supplies the public holidays of a year, for a Calendar.
+type HolidayProvider interface {
+	Holidays(year int) []Date
+}
+
+// HolidayList is a fixed list of holidays.
+type HolidayList []Date
+
+// Holidays returns the dates of the list falling in year.
+func (list HolidayList) Holidays(year int) []Date {
+	var holidays []Date
+	for _, date := range list {
+		if date.Year == year {
+			holidays = append(holidays, date)
+		}
+	}
+	return holidays
+}
+
+// HolidayFunc adapts a function to a HolidayProvider, for holidays computed
+// from rules such as "the fourth Thursday of November".
+type HolidayFunc func(year int) []Date
+
+// Holidays calls f.
+func (f HolidayFunc) Holidays(year int) []Date {
+	return f(year)
+}
+
+// Annual returns a provider of holidays falling on the same day every year,
+// such as January 1st.
+func Annual(month time.Month, day int) HolidayProvider {
+	return HolidayFunc(func(year int) []Date {
+		return []Date{NewDate(year, month, day)}
+	})
+}
+
+// Calendar tells which days are worked, for date math in working days.
+// A nil *Calendar works Monday to Friday, without holidays.
+//
+// Holidays are asked for once per year and provider, and remembered; a
+// Calendar is safe for concurrent use.
+type Calendar struct {
+	workdays  [7]bool
+	providers []HolidayProvider
+
+	mu       sync.Mutex
+	holidays map[int]map[Date]bool
+}
+
+// NewCalendar returns a calendar working on the given weekdays, or Monday to
+// Friday if none are given, with holidays from providers.
+func NewCalendar(workdays []time.Weekday, providers ...HolidayProvider) *Calendar {
+	if len(workdays) == 0 {
+		workdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	}
+
+	c := &Calendar{providers: providers, holidays: map[int]map[Date]bool{}}
+	for _, weekday := range workdays {
+		c.workdays[weekday] = true
+	}
+	return c
+}
+
+// defaultCalendar is used by methods called on a nil *Calendar.
+var defaultCalendar = NewCalendar(nil)
+
+// IsWorkday reports whether date is worked: a working weekday that isn't a holiday.
+func (c *Calendar) IsWorkday(date Date) bool {
+	if c == nil {
+		c = defaultCalendar
+	}
+	return c.workdays[date.Weekday()] && !c.IsHoliday(date)
+}
+
+// IsHoliday reports whether date is a holiday.
+func (c *Calendar) IsHoliday(date Date) bool {
+	if c == nil || len(c.providers) == 0 {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	holidays, ok := c.holidays[date.Year]
+	if !ok {
+		holidays = map[Date]bool{}
+		for _, provider := range c.providers {
+			for _, holiday := range provider.Holidays(date.Year) {
+				holidays[holiday] = true
+			}
+		}
+		c.holidays[date.Year] = holidays
+	}
+	return holidays[date]
+}
+
+// NextWorkday returns date if it is worked, or the first workday after it.
+func (c *Calendar) NextWorkday(date Date) Date {
+	// Give up after a year, for calendars without any workday
+	for i := 0; i < 366 && !c.IsWorkday(date); i++ {
+		date = date.AddDays(1)
+	}
+	return date
+}
+
+// AddWorkdays returns the date n workdays after date, or before it if n is
+// negative. A date that isn't worked first moves forward to the next workday,
+// so AddWorkdays(date, 0) is NextWorkday(date).
+func (c *Calendar) AddWorkdays(date Date, n int) Date {
+	date = c.NextWorkday(date)
+	if !c.IsWorkday(date) {
+		return date
+	}
+
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for n > 0 {
+		date = date.AddDays(step)
+		if c.IsWorkday(date) {
+			n--
+		}
+	}
+	return date
+}
+
+// WorkdaysBetween returns the number of workdays from start to end, counting
+// end but not start. It is negative if end is before start.
+func (c *Calendar) WorkdaysBetween(start Date, end Date) int {
+	sign := 1
+	if end.Before(start) {
+		start, end, sign = end, start, -1
+	}
+
+	days := 0
+	for date := start.AddDays(1); !date.After(end); date = date.AddDays(1) {
+		if c.IsWorkday(date) {
+			days++
+		}
+	}
+	return sign * days
+}
@@ -0,0 +1,45 @@
+package togglplanapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendar(t *testing.T) {
+	friday := NewDate(2024, 3, 8)
+	cal := NewCalendar(nil, HolidayList{NewDate(2024, 3, 11)}, Annual(time.December, 25))
+
+	if got := cal.AddWorkdays(friday, 1); got != NewDate(2024, 3, 12) {
+		t.Fatalf("expected the weekend and the holiday to be skipped, got %s", got)
+	}
+	if got := cal.AddWorkdays(NewDate(2024, 3, 12), -1); got != friday {
+		t.Fatalf("expected to go back to friday, got %s", got)
+	}
+	if got := cal.AddWorkdays(NewDate(2024, 3, 9), 0); got != NewDate(2024, 3, 12) {
+		t.Fatalf("expected saturday to move to the next workday, got %s", got)
+	}
+	if got := cal.WorkdaysBetween(friday, NewDate(2024, 3, 13)); got != 2 {
+		t.Fatalf("expected 2 workdays, got %d", got)
+	}
+	if got := cal.WorkdaysBetween(NewDate(2024, 3, 13), friday); got != -2 {
+		t.Fatalf("expected -2 workdays, got %d", got)
+	}
+	if cal.IsWorkday(NewDate(2025, 12, 25)) {
+		t.Fatal("expected christmas to be a holiday every year")
+	}
+}
+
+func TestCalendarWorkdays(t *testing.T) {
+	// Sunday to Thursday
+	cal := NewCalendar([]time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday})
+
+	thursday := NewDate(2024, 3, 7)
+	if got := cal.AddWorkdays(thursday, 1); got != NewDate(2024, 3, 10) {
+		t.Fatalf("expected sunday, got %s", got)
+	}
+
+	var defaults *Calendar
+	if defaults.IsWorkday(NewDate(2024, 3, 9)) || !defaults.IsWorkday(thursday) {
+		t.Fatal("expected a nil calendar to work monday to friday")
+	}
+}
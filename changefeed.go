@@ -0,0 +1,180 @@
+package togglplanapi
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ChangeType describes how a task changed between two polls of a TaskFeed.
+type ChangeType string
+
+const (
+	ChangeCreated ChangeType = "created"
+	ChangeUpdated ChangeType = "updated"
+	ChangeDeleted ChangeType = "deleted"
+)
+
+// TaskChange is a change to a task detected by a TaskFeed.
+type TaskChange struct {
+	Type ChangeType
+
+	// Task is the current version of the task, or its last known version if it was deleted.
+	Task Task
+
+	// Previous is the version of the task seen by the previous poll, or nil if it was created.
+	Previous *Task
+}
+
+// TaskFeedOptions configures a TaskFeed.
+type TaskFeedOptions struct {
+	// DaysBack and DaysAhead set the rolling window of tasks watched, relative
+	// to the day of each poll. Default to 30 and 180 days.
+	DaysBack  int
+	DaysAhead int
+
+	// ProjectIds and MemberIds restrict the tasks watched, like in TaskFilter.
+	ProjectIds []ID
+	MemberIds  []ID
+}
+
+// TaskFeed detects changes to the tasks of a workspace by polling them and
+// comparing each result with the previous one. It is the building block for
+// mirroring tasks into other systems.
+//
+// The first poll reports every task as created. Tasks that disappear from the
+// watched window (because they were deleted, or moved out of the window) are
+// reported as deleted.
+//
+// A TaskFeed is safe for concurrent use, but polls are serialized.
+type TaskFeed struct {
+	pa          *togglPlanApi
+	workspaceId ID
+	options     TaskFeedOptions
+
+	mu    sync.Mutex
+	known map[ID]Task
+	now   func() time.Time
+}
+
+// NewTaskFeed returns a TaskFeed watching the tasks of a workspace.
+// Arguments:
+//
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	options: Feed settings (use `togglplanapi.TaskFeedOptions{}` for the defaults)
+func NewTaskFeed(pa *togglPlanApi, workspaceId ID, options TaskFeedOptions) *TaskFeed {
+	if options.DaysBack <= 0 {
+		options.DaysBack = 30
+	}
+	if options.DaysAhead <= 0 {
+		options.DaysAhead = 180
+	}
+
+	return &TaskFeed{
+		pa:          pa,
+		workspaceId: workspaceId,
+		options:     options,
+		known:       map[ID]Task{},
+		now:         time.Now,
+	}
+}
+
+// Poll fetches the watched tasks and returns how they changed since the previous poll.
+// If fetching fails, the feed is left unchanged so that the next poll reports
+// the changes again.
+func (feed *TaskFeed) Poll(ctx context.Context) ([]TaskChange, error) {
+	feed.mu.Lock()
+	defer feed.mu.Unlock()
+
+	today := DateOf(feed.now())
+	filter := TaskFilter{
+		Since:      today.AddDays(-feed.options.DaysBack),
+		Until:      today.AddDays(feed.options.DaysAhead),
+		ProjectIds: feed.options.ProjectIds,
+		MemberIds:  feed.options.MemberIds,
+	}
+
+	tasks, err := ListAllTasks(ctx, feed.pa, feed.workspaceId, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffTasks(feed.known, tasks)
+
+	feed.known = make(map[ID]Task, len(tasks))
+	for _, task := range tasks {
+		feed.known[task.Id] = task
+	}
+
+	return changes, nil
+}
+
+// Watch polls the feed every interval until ctx is cancelled, and calls fn
+// with the changes of every poll that found some. Errors of individual polls
+// are passed to fn too, and don't stop the watch.
+// Arguments:
+//
+//	ctx: Context stopping the watch when cancelled
+//	interval: Time between polls
+//	fn: Function receiving the changes or the error of each poll
+func (feed *TaskFeed) Watch(ctx context.Context, interval time.Duration, fn func(changes []TaskChange, err error)) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		changes, err := feed.Poll(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil || len(changes) > 0 {
+			fn(changes, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// diffTasks compares a previous snapshot of tasks with the current list.
+// Created and updated tasks are reported in the order of current, followed
+// by deleted tasks in no particular order.
+func diffTasks(previous map[ID]Task, current []Task) []TaskChange {
+	var changes []TaskChange
+	seen := make(map[ID]bool, len(current))
+
+	for _, task := range current {
+		seen[task.Id] = true
+
+		old, ok := previous[task.Id]
+		switch {
+		case !ok:
+			changes = append(changes, TaskChange{Type: ChangeCreated, Task: task})
+		case taskChanged(old, task):
+			old := old
+			changes = append(changes, TaskChange{Type: ChangeUpdated, Task: task, Previous: &old})
+		}
+	}
+
+	for id, task := range previous {
+		if !seen[id] {
+			task := task
+			changes = append(changes, TaskChange{Type: ChangeDeleted, Task: task, Previous: &task})
+		}
+	}
+
+	return changes
+}
+
+// taskChanged reports whether two versions of a task differ, using their
+// update times when available.
+func taskChanged(old Task, current Task) bool {
+	if !old.UpdatedAt.IsZero() && !current.UpdatedAt.IsZero() {
+		return !old.UpdatedAt.Equal(current.UpdatedAt.Time)
+	}
+	return !reflect.DeepEqual(old, current)
+}
@@ -0,0 +1,46 @@
+package togglplanapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestTaskFeed(t *testing.T) {
+	responses := []string{
+		`[{"id":1,"updated_at":"2024-03-01T10:00:00Z"},{"id":2,"updated_at":"2024-03-01T10:00:00Z"}]`,
+		`[{"id":1,"updated_at":"2024-03-02T10:00:00Z"},{"id":3,"updated_at":"2024-03-02T10:00:00Z"}]`,
+		`[{"id":1,"updated_at":"2024-03-02T10:00:00Z"},{"id":3,"updated_at":"2024-03-02T10:00:00Z"}]`,
+	}
+
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(responses[0]))
+	})
+
+	// A short window keeps each poll to a single request
+	feed := NewTaskFeed(pa, 42, TaskFeedOptions{DaysBack: 1, DaysAhead: 1})
+
+	expected := [][]string{
+		{"created 1", "created 2"},
+		{"updated 1", "created 3", "deleted 2"},
+		{},
+	}
+
+	for i := range expected {
+		changes, err := feed.Poll(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(changes) != len(expected[i]) {
+			t.Fatalf("poll %d: expected %v, got %+v", i, expected[i], changes)
+		}
+		for j, change := range changes {
+			if got := string(change.Type) + " " + change.Task.Id.String(); got != expected[i][j] {
+				t.Fatalf("poll %d: expected %v, got %s at %d", i, expected[i], got, j)
+			}
+		}
+
+		responses = responses[1:]
+	}
+}
@@ -0,0 +1,192 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CloneOptions configures CloneProject.
+type CloneOptions struct {
+	// Name is the name of the copy. Defaults to the name of the project
+	// followed by " (copy)".
+	Name string
+
+	// Offset moves the dates of the copy by a number of days, or back if it
+	// is negative. Dates are counted in workdays of Calendar if it is set,
+	// with tasks keeping their length in workdays.
+	Offset   int
+	Calendar *Calendar
+
+	// MemberIds replaces assignees of the tasks, e.g. to hand a project
+	// over to another team. Members missing from the map keep their tasks,
+	// and members mapped to 0 are unassigned.
+	MemberIds map[ID]ID
+
+	// ResetDone creates the tasks and their checklist items as not done.
+	ResetDone bool
+
+	// Since and Until set the range of tasks copied, since tasks can only be
+	// listed by date. Default to two years before and after the current day.
+	Since Date
+	Until Date
+}
+
+// CloneResult reports what CloneProject created.
+// The ID maps go from IDs in the original project to IDs in the copy.
+type CloneResult struct {
+	Project    *Project
+	Milestones map[ID]ID
+	Tasks      map[ID]ID
+}
+
+// CloneProject copies a project with its milestones and tasks, including
+// their tags and checklists, into a new project of the same workspace.
+// Tasks without dates are not copied, since tasks can only be listed by date.
+//
+// If an error occurs part way, the result reports what was created so far.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	projectId: ID of the project to copy
+//	options: Name, date offset and assignees of the copy (use `togglplanapi.CloneOptions{}` for the defaults)
+func CloneProject(ctx context.Context, pa *togglPlanApi, workspaceId ID, projectId ID, options CloneOptions) (*CloneResult, error) {
+	now := time.Now()
+	if options.Since.IsZero() {
+		options.Since = DateOf(now).AddDate(-2, 0, 0)
+	}
+	if options.Until.IsZero() {
+		options.Until = DateOf(now).AddDate(2, 0, 0)
+	}
+
+	projects, err := GetProjects(ctx, pa, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("reading projects: %w", err)
+	}
+
+	var original *Project
+	for i := range projects {
+		if projects[i].Id == projectId {
+			original = &projects[i]
+			break
+		}
+	}
+	if original == nil {
+		return nil, fmt.Errorf("project %d not found", projectId)
+	}
+
+	milestones, err := GetMilestones(ctx, pa, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("reading milestones: %w", err)
+	}
+
+	tasks, err := ListAllTasks(ctx, pa, workspaceId, TaskFilter{Since: options.Since, Until: options.Until, ProjectIds: []ID{projectId}})
+	if err != nil {
+		return nil, fmt.Errorf("reading tasks: %w", err)
+	}
+
+	result := &CloneResult{Milestones: map[ID]ID{}, Tasks: map[ID]ID{}}
+
+	name := options.Name
+	if name == "" {
+		name = original.Name + " (copy)"
+	}
+
+	params := ProjectParams{Name: name, Notes: original.Notes, Color: original.Color}
+	params.StartDate, params.EndDate = options.shift(original.StartDate, original.EndDate)
+
+	result.Project, err = CreateProject(ctx, pa, workspaceId, params)
+	if err != nil {
+		return result, fmt.Errorf("creating project: %w", err)
+	}
+
+	for _, milestone := range milestones {
+		if milestone.ProjectId != projectId {
+			continue
+		}
+
+		date, _ := options.shift(milestone.Date, milestone.Date)
+		created, err := CreateMilestone(ctx, pa, workspaceId, MilestoneParams{
+			Name:      milestone.Name,
+			Date:      date,
+			ProjectId: result.Project.Id,
+		})
+		if err != nil {
+			return result, fmt.Errorf("copying milestone %d: %w", milestone.Id, err)
+		}
+		result.Milestones[milestone.Id] = created.Id
+	}
+
+	for _, task := range tasks {
+		if task.ProjectId != projectId {
+			continue
+		}
+
+		var assignees []ID
+		for _, id := range task.Assignees {
+			if mapped, ok := options.MemberIds[id]; ok {
+				id = mapped
+			}
+			if id != 0 {
+				assignees = append(assignees, id)
+			}
+		}
+
+		checklist := append([]ChecklistItem(nil), task.Checklist...)
+		done := task.Done
+		if options.ResetDone {
+			done = false
+			for i := range checklist {
+				checklist[i].Done = false
+			}
+		}
+
+		params := TaskParams{
+			Name:             task.Name,
+			Notes:            task.Notes,
+			StartTime:        task.StartTime,
+			EndTime:          task.EndTime,
+			Color:            task.Color,
+			EstimatedMinutes: task.EstimatedMinutes,
+			Done:             done,
+			ProjectId:        result.Project.Id,
+			MilestoneId:      result.Milestones[task.MilestoneId],
+			PlanStatusId:     task.PlanStatusId,
+			Assignees:        assignees,
+			Tags:             task.Tags,
+			Checklist:        checklist,
+		}
+		params.StartDate, params.EndDate = options.shift(task.StartDate, task.EndDate)
+
+		created, err := CreateTask(ctx, pa, workspaceId, params)
+		if err != nil {
+			return result, fmt.Errorf("copying task %d: %w", task.Id, err)
+		}
+		result.Tasks[task.Id] = created.Id
+	}
+
+	return result, nil
+}
+
+// shift moves a date range by options.Offset, keeping its length. Unset dates stay unset.
+func (options CloneOptions) shift(start Date, end Date) (Date, Date) {
+	move := func(date Date) Date {
+		if date.IsZero() {
+			return date
+		}
+		if options.Calendar == nil {
+			return date.AddDays(options.Offset)
+		}
+		return options.Calendar.AddWorkdays(date, options.Offset)
+	}
+
+	if options.Calendar == nil || start.IsZero() || end.IsZero() {
+		return move(start), move(end)
+	}
+
+	length := options.Calendar.WorkdaysBetween(start, end)
+	start = move(start)
+	return start, options.Calendar.AddWorkdays(start, length)
+}
@@ -0,0 +1,82 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestCloneProject(t *testing.T) {
+	var project ProjectParams
+	var milestone MilestoneParams
+	var task TaskParams
+
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			switch r.URL.Path {
+			case "/api/v5/2/projects":
+				fmt.Fprint(w, `[{"id":9,"name":"Other"},{"id":10,"name":"Sprint","color":3,"start_date":"2024-03-04","end_date":"2024-03-15"}]`)
+			case "/api/v5/2/milestones":
+				fmt.Fprint(w, `[{"id":20,"name":"Demo","date":"2024-03-15","project_id":10},{"id":21,"name":"Other","project_id":9}]`)
+			case "/api/v5/2/tasks":
+				if r.URL.Query().Get("project_ids") != "10" {
+					t.Errorf("unexpected task filter %s", r.URL.RawQuery)
+				}
+				if r.URL.Query().Get("since") != "2024-03-01" {
+					fmt.Fprint(w, `[]`)
+					return
+				}
+				fmt.Fprint(w, `[{"id":50,"name":"Build","start_date":"2024-03-08","end_date":"2024-03-11","done":true,"project_id":10,"milestone_id":20,"workspace_members":[40,41,42],"tags":["Backend"],"checklist":[{"name":"API","done":true}]}]`)
+			}
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v5/2/projects":
+			json.NewDecoder(r.Body).Decode(&project)
+			fmt.Fprint(w, `{"id":100}`)
+		case "/api/v5/2/milestones":
+			json.NewDecoder(r.Body).Decode(&milestone)
+			fmt.Fprint(w, `{"id":200}`)
+		case "/api/v5/2/tasks":
+			json.NewDecoder(r.Body).Decode(&task)
+			fmt.Fprint(w, `{"id":500}`)
+		}
+	})
+
+	result, err := CloneProject(context.Background(), pa, 2, 10, CloneOptions{
+		Offset:    2,
+		Calendar:  NewCalendar(nil),
+		MemberIds: map[ID]ID{40: 400, 41: 0},
+		ResetDone: true,
+		Since:     NewDate(2024, 3, 1),
+		Until:     NewDate(2024, 3, 31),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Project.Id != 100 || result.Milestones[20] != 200 || len(result.Milestones) != 1 || result.Tasks[50] != 500 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	if project.Name != "Sprint (copy)" || project.Color != ColorYellow || project.StartDate != NewDate(2024, 3, 6) || project.EndDate != NewDate(2024, 3, 19) {
+		t.Fatalf("unexpected project %+v", project)
+	}
+	if milestone.ProjectId != 100 || milestone.Date != NewDate(2024, 3, 19) {
+		t.Fatalf("unexpected milestone %+v", milestone)
+	}
+
+	// Friday to Monday, moved by 2 workdays
+	if task.StartDate != NewDate(2024, 3, 12) || task.EndDate != NewDate(2024, 3, 13) {
+		t.Fatalf("unexpected task dates %s to %s", task.StartDate, task.EndDate)
+	}
+	if task.ProjectId != 100 || task.MilestoneId != 200 || fmt.Sprint(task.Assignees) != "[400 42]" {
+		t.Fatalf("task references not remapped: %+v", task)
+	}
+	if task.Done || task.Checklist[0].Done || task.Tags[0] != "Backend" {
+		t.Fatalf("unexpected task %+v", task)
+	}
+}
@@ -0,0 +1,94 @@
+package togglplanapi
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Hex values of the colors of the Toggl Plan palette.
+const (
+	ColorRedHex    = "#e53935"
+	ColorOrangeHex = "#fb8c00"
+	ColorYellowHex = "#fdd835"
+	ColorGreenHex  = "#43a047"
+	ColorTealHex   = "#00897b"
+	ColorBlueHex   = "#1e88e5"
+	ColorIndigoHex = "#3949ab"
+	ColorPurpleHex = "#8e24aa"
+	ColorPinkHex   = "#d81b60"
+	ColorBrownHex  = "#6d4c41"
+	ColorGreyHex   = "#757575"
+)
+
+var colorHexes = []string{"", ColorRedHex, ColorOrangeHex, ColorYellowHex, ColorGreenHex, ColorTealHex, ColorBlueHex, ColorIndigoHex, ColorPurpleHex, ColorPinkHex, ColorBrownHex, ColorGreyHex}
+
+// Hex returns the hex value of the color, such as "#1e88e5", or "" for
+// ColorNone and invalid colors.
+func (c Color) Hex() string {
+	if !c.Valid() {
+		return ""
+	}
+	return colorHexes[c]
+}
+
+// RGBA implements image/color.Color, so that colors of the palette can be
+// drawn. ColorNone and invalid colors are transparent.
+func (c Color) RGBA() (r, g, b, a uint32) {
+	red, green, blue, err := parseHex(c.Hex())
+	if err != nil {
+		return 0, 0, 0, 0
+	}
+	return uint32(red) * 0x101, uint32(green) * 0x101, uint32(blue) * 0x101, 0xffff
+}
+
+// NearestColor returns the color of the palette closest to a hex value, such
+// as "#2f80ed", "2f80ed" or "#28e", e.g. to map the colors of another tool.
+func NearestColor(hex string) (Color, error) {
+	r, g, b, err := parseHex(hex)
+	if err != nil {
+		return ColorNone, err
+	}
+
+	nearest, best := ColorNone, -1
+	for c := ColorRed; c.Valid(); c++ {
+		pr, pg, pb, _ := parseHex(c.Hex())
+		// Weighted distance, closer to perceived differences than a plain
+		// Euclidean one
+		mean := (int(r) + int(pr)) / 2
+		dr, dg, db := int(r)-int(pr), int(g)-int(pg), int(b)-int(pb)
+		distance := (512+mean)*dr*dr>>8 + 4*dg*dg + (767-mean)*db*db>>8
+		if best < 0 || distance < best {
+			nearest, best = c, distance
+		}
+	}
+	return nearest, nil
+}
+
+// ColorFor returns a color of the palette for a name, such as the name of a
+// new project or tag. The same name always gets the same color, regardless
+// of case and surrounding spaces, so that colors stay stable across runs.
+func ColorFor(name string) Color {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(name))))
+	return ColorRed + Color(h.Sum32()%uint32(len(colorHexes)-1))
+}
+
+// parseHex returns the components of a hex color with 3 or 6 digits, with or
+// without a leading "#".
+func parseHex(hex string) (r, g, b uint8, err error) {
+	digits := strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+	if len(digits) != 6 {
+		return 0, 0, 0, fmt.Errorf("invalid hex color %q", hex)
+	}
+
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hex color %q", hex)
+	}
+	return uint8(value >> 16), uint8(value >> 8), uint8(value), nil
+}
@@ -0,0 +1,51 @@
+package togglplanapi
+
+import (
+	"context"
+)
+
+// Comment represents a comment on a task.
+type Comment struct {
+	Id        ID       `json:"id"`
+	TaskId    ID       `json:"task_id"`
+	AuthorId  ID       `json:"workspace_member_id"`
+	Body      string   `json:"body"`
+	CreatedAt DateTime `json:"created_at"`
+	UpdatedAt DateTime `json:"updated_at"`
+}
+
+// GetComments fetches the comments of a task.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	taskId: ID of the task
+func GetComments(ctx context.Context, pa *togglPlanApi, workspaceId ID, taskId ID) ([]Comment, error) {
+	var comments []Comment
+	err := getJSON(ctx, pa, workspacePath(workspaceId, "/tasks/%d/comments", taskId), nil, &comments)
+	return comments, err
+}
+
+// CommentParams holds the fields of a comment to create.
+type CommentParams struct {
+	// Body is the HTML of the comment. Use FormatMentions to build it from
+	// plain text with @mentions.
+	Body string `json:"body"`
+}
+
+// CreateComment adds a comment to a task, and returns the new comment.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	taskId: ID of the task
+//	params: Body of the comment
+func CreateComment(ctx context.Context, pa *togglPlanApi, workspaceId ID, taskId ID, params CommentParams) (*Comment, error) {
+	var comment Comment
+	if err := sendJSON(ctx, pa, "POST", workspacePath(workspaceId, "/tasks/%d/comments", taskId), nil, params, &comment); err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
@@ -0,0 +1,47 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// GetIfModifiedSince fetches path, relative to the API version of pa (e.g.
+// "/1/tasks?since=2024-03-01"), and decodes the response into out only if it
+// changed after since. It reports whether it did, so that pollers can skip
+// unchanged data cheaply.
+//
+// The request carries an If-Modified-Since header, which the API answers
+// with 304 Not Modified when nothing changed. If it sends the data anyway,
+// a Last-Modified header not after since still counts as unchanged.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	path: Endpoint path relative to /api/<version>, with its query string if any
+//	since: Time of the copy the caller already has
+//	out: Pointer to the value the response is decoded into
+func GetIfModifiedSince(ctx context.Context, pa *togglPlanApi, path string, since time.Time, out interface{}) (bool, error) {
+	headers := map[string]string{"If-Modified-Since": since.UTC().Format(http.TimeFormat)}
+
+	resp, _, err := authenticatedRequest(ctx, pa, apiURL(pa, path, nil), http.MethodGet, nil, headers)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	recordResponse(ctx, resp)
+
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil && !modified.After(since) {
+		return false, nil
+	}
+
+	if err := decodeResponse(pa, resp, out); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,45 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetIfModifiedSince(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	honour := true
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		if err != nil {
+			t.Errorf("missing If-Modified-Since: %v", err)
+		}
+		if honour && !modified.After(since) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		fmt.Fprint(w, `[{"id":1}]`)
+	})
+
+	var tasks []Task
+	changed, err := GetIfModifiedSince(context.Background(), pa, "/1/tasks", modified.Add(-time.Hour), &tasks)
+	if err != nil || !changed || len(tasks) != 1 {
+		t.Fatalf("expected the tasks to be fetched, got %v, %v, %v", changed, tasks, err)
+	}
+
+	tasks = nil
+	changed, err = GetIfModifiedSince(context.Background(), pa, "/1/tasks", modified, &tasks)
+	if err != nil || changed || tasks != nil {
+		t.Fatalf("expected 304 to count as unchanged, got %v, %v, %v", changed, tasks, err)
+	}
+
+	// Servers ignoring the header are caught by Last-Modified
+	honour = false
+	changed, err = GetIfModifiedSince(context.Background(), pa, "/1/tasks", modified, &tasks)
+	if err != nil || changed || tasks != nil {
+		t.Fatalf("expected an old Last-Modified to count as unchanged, got %v, %v, %v", changed, tasks, err)
+	}
+}
@@ -0,0 +1,79 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Created describes a resource created with PostCreated.
+type Created struct {
+	// Id is the ID of the new resource, taken from the end of Location or
+	// from the id field of Body. It is 0 if neither holds one.
+	Id ID
+	// Location is the URL of the new resource, if the API sent one.
+	Location string
+	// Body is the response body, which may be empty.
+	Body string
+}
+
+// PostCreated works like Post, and also reports the ID and URL of the
+// resource it created, so that they don't have to be parsed out of the body.
+func PostCreated(pa *togglPlanApi, url string, body []byte, opts ...RequestOption) (*Created, error) {
+	resp, _, cancel, err := rawRequest(context.Background(), pa, url, http.MethodPost, body, opts)
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	created := &Created{}
+	if location, err := resp.Location(); err == nil {
+		created.Location = location.String()
+	}
+
+	created.Body, err = readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	created.Id = createdId(created.Location, created.Body)
+	return created, nil
+}
+
+// Fetch returns the body of the created resource: Body if the API sent the
+// resource back, or the response to a GET request to Location otherwise.
+// A Location outside of the API isn't fetched, and fails with an error
+// matching ErrForeignLocation.
+func (c *Created) Fetch(pa *togglPlanApi) (string, error) {
+	if strings.TrimSpace(c.Body) != "" {
+		return c.Body, nil
+	}
+	if c.Location == "" {
+		return "", errors.New("created resource has no Location")
+	}
+	if err := checkLocation(pa, c.Location); err != nil {
+		return "", err
+	}
+	return Get(pa, c.Location)
+}
+
+// createdId returns the ID at the end of location, or else the id field of
+// the JSON object in body, or 0.
+func createdId(location string, body string) ID {
+	if location != "" {
+		if id, err := ParseID(path.Base(strings.TrimRight(location, "/"))); err == nil {
+			return id
+		}
+	}
+
+	var resource struct {
+		Id ID `json:"id"`
+	}
+	if json.Unmarshal([]byte(body), &resource) == nil {
+		return resource.Id
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPostCreated(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v5/1/tasks":
+			w.Header().Set("Location", "/api/v5/1/tasks/77")
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == "POST":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":88,"name":"Launch"}`)
+		case r.URL.Path == "/api/v5/1/tasks/77":
+			fmt.Fprint(w, `{"id":77,"name":"Design"}`)
+		}
+	})
+
+	created, err := PostCreated(pa, pa.baseURL+"/api/v5/1/tasks", []byte(`{"name":"Design"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Id != 77 || created.Location != pa.baseURL+"/api/v5/1/tasks/77" || created.Body != "" {
+		t.Fatalf("unexpected result %+v", created)
+	}
+	if body, err := created.Fetch(pa); err != nil || body != `{"id":77,"name":"Design"}` {
+		t.Fatalf("unexpected fetch %q, %v", body, err)
+	}
+
+	created, err = PostCreated(pa, pa.baseURL+"/api/v5/1/milestones", nil)
+	if err != nil || created.Id != 88 {
+		t.Fatalf("expected the ID of the body, got %+v, %v", created, err)
+	}
+
+	// Typed calls fetch the created resource when it isn't sent back
+	task, err := CreateTask(context.Background(), pa, 1, TaskParams{Name: "Design"})
+	if err != nil || task.Id != 77 || task.Name != "Design" {
+		t.Fatalf("unexpected task %+v, %v", task, err)
+	}
+}
+
+func TestForeignLocation(t *testing.T) {
+	foreignAuth := ""
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		foreignAuth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"id":77,"name":"Design"}`)
+	}))
+	defer foreign.Close()
+
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", foreign.URL+"/api/v5/1/tasks/77")
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	if _, err := CreateTask(context.Background(), pa, 1, TaskParams{Name: "Design"}); !errors.Is(err, ErrForeignLocation) {
+		t.Errorf("expected a foreign Location not to be followed, got %v", err)
+	}
+
+	created, err := PostCreated(pa, pa.baseURL+"/api/v5/1/tasks", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := created.Fetch(pa); !errors.Is(err, ErrForeignLocation) {
+		t.Errorf("expected a foreign Location not to be fetched, got %v", err)
+	}
+
+	if foreignAuth != "" {
+		t.Errorf("expected no request with credentials to the foreign host, got %q", foreignAuth)
+	}
+}
@@ -0,0 +1,242 @@
+package togglplanapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Date is a calendar date without a time of day or time zone, as used by
+// Toggl Plan for task, milestone and project dates.
+//
+// Unlike time.Time, a Date marshals to JSON as "YYYY-MM-DD", and doesn't
+// shift to the previous or next day when converted between time zones.
+// The zero value is an unset date, which marshals to null.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// dateLayout is the format of date-only fields in the Toggl Plan API.
+const dateLayout = "2006-01-02"
+
+// NewDate returns the Date for a year, month and day. Out of range values are
+// normalized, so that e.g. NewDate(2024, 1, 32) is February 1, 2024.
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf returns the date of t in t's own time zone.
+// Use DateOf(t.In(loc)) to get the date of t in another time zone.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// Today returns the current date in loc.
+func Today(loc *time.Location) Date {
+	return DateOf(time.Now().In(loc))
+}
+
+// ParseDate parses a date in YYYY-MM-DD format.
+// An empty string parses as the zero Date.
+func ParseDate(value string) (Date, error) {
+	if value == "" {
+		return Date{}, nil
+	}
+
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q", value)
+	}
+	return DateOf(t), nil
+}
+
+// IsZero reports whether the date is unset.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// String formats the date as YYYY-MM-DD, or returns an empty string if it is unset.
+func (d Date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// In returns midnight at the start of the date in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// At returns the date at the given time of day in loc.
+func (d Date) At(hour int, minute int, loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, hour, minute, 0, 0, loc)
+}
+
+// AddDays returns the date n days later (or earlier, if n is negative).
+func (d Date) AddDays(n int) Date {
+	return NewDate(d.Year, d.Month, d.Day+n)
+}
+
+// AddDate returns the date the given number of years, months and days later,
+// normalized like time.Time.AddDate.
+func (d Date) AddDate(years int, months int, days int) Date {
+	return NewDate(d.Year+years, d.Month+time.Month(months), d.Day+days)
+}
+
+// Weekday returns the day of the week of the date.
+func (d Date) Weekday() time.Weekday {
+	return d.In(time.UTC).Weekday()
+}
+
+// DaysUntil returns the number of days from d to other, which is negative if other is earlier.
+func (d Date) DaysUntil(other Date) int {
+	return int(other.In(time.UTC).Sub(d.In(time.UTC)) / (24 * time.Hour))
+}
+
+// Compare returns -1 if d is before other, 0 if they are the same date, and +1 if d is after other.
+func (d Date) Compare(other Date) int {
+	switch {
+	case d.Year != other.Year:
+		return sign(d.Year - other.Year)
+	case d.Month != other.Month:
+		return sign(int(d.Month - other.Month))
+	default:
+		return sign(d.Day - other.Day)
+	}
+}
+
+// Before reports whether d is before other.
+func (d Date) Before(other Date) bool {
+	return d.Compare(other) < 0
+}
+
+// After reports whether d is after other.
+func (d Date) After(other Date) bool {
+	return d.Compare(other) > 0
+}
+
+// Between reports whether d is within the inclusive range from start to end.
+func (d Date) Between(start Date, end Date) bool {
+	return !d.Before(start) && !d.After(end)
+}
+
+// MarshalJSON encodes the date as "YYYY-MM-DD", or null if it is unset.
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON decodes a date from "YYYY-MM-DD", null or an empty string.
+// Timestamps are accepted too, keeping only their date part as written.
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*d = Date{}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid date %s", data)
+	}
+
+	// Keep the date of timestamps as written, without converting between time zones
+	if len(value) > len(dateLayout) && value[len(dateLayout)] == 'T' {
+		value = value[:len(dateLayout)]
+	}
+
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// MarshalText encodes the date as YYYY-MM-DD, e.g. for use as a map key.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a date from YYYY-MM-DD.
+func (d *Date) UnmarshalText(text []byte) error {
+	parsed, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// DateTime is a point in time as returned by the Toggl Plan API, such as the
+// creation time of a resource.
+//
+// It embeds time.Time, and decodes timestamps with or without a time zone
+// offset (those without one are taken as UTC), as well as null and empty
+// strings as the zero time.
+type DateTime struct {
+	time.Time
+}
+
+// dateTimeLayouts are the timestamp formats accepted by DateTime, in order of preference.
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// MarshalJSON encodes the time in RFC 3339 format, or null if it is unset.
+func (dt DateTime) MarshalJSON() ([]byte, error) {
+	if dt.IsZero() {
+		return []byte("null"), nil
+	}
+	return dt.Time.MarshalJSON()
+}
+
+// UnmarshalJSON decodes a timestamp, null or an empty string.
+func (dt *DateTime) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*dt = DateTime{}
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("invalid timestamp %s", data)
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		*dt = DateTime{}
+		return nil
+	}
+
+	for _, layout := range dateTimeLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			*dt = DateTime{parsed}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid timestamp %q", value)
+}
+
+// sign returns -1, 0 or +1 depending on the sign of n.
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
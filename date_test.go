@@ -0,0 +1,143 @@
+package togglplanapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDateJSON(t *testing.T) {
+	var task struct {
+		Start Date  `json:"start"`
+		End   Date  `json:"end"`
+		Due   Date  `json:"due"`
+		Stamp Date  `json:"stamp"`
+		Ptr   *Date `json:"ptr"`
+	}
+
+	data := `{"start":"2024-03-04","end":null,"due":"","stamp":"2024-03-04T23:30:00-05:00","ptr":"2024-12-31"}`
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		t.Fatal(err)
+	}
+
+	if task.Start != NewDate(2024, 3, 4) || !task.End.IsZero() || !task.Due.IsZero() {
+		t.Fatalf("unexpected dates %+v", task)
+	}
+	// The date of a timestamp is kept as written, not shifted to UTC
+	if task.Stamp != NewDate(2024, 3, 4) || *task.Ptr != NewDate(2024, 12, 31) {
+		t.Fatalf("unexpected dates %+v", task)
+	}
+
+	encoded, err := json.Marshal(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"start":"2024-03-04","end":null,"due":null,"stamp":"2024-03-04","ptr":"2024-12-31"}`
+	if string(encoded) != expected {
+		t.Fatalf("Marshal() = %s", encoded)
+	}
+
+	if err := json.Unmarshal([]byte(`{"start":"04/03/2024"}`), &task); err == nil {
+		t.Fatal("expected an error for an invalid date")
+	}
+}
+
+func TestDateArithmetic(t *testing.T) {
+	d := NewDate(2024, 2, 28)
+
+	if d.AddDays(1) != NewDate(2024, 2, 29) || d.AddDays(2) != NewDate(2024, 3, 1) {
+		t.Fatalf("AddDays() doesn't handle leap years")
+	}
+	if d.DaysUntil(NewDate(2024, 3, 31)) != 32 || NewDate(2024, 3, 31).DaysUntil(d) != -32 {
+		t.Fatalf("unexpected DaysUntil()")
+	}
+	if !d.Before(d.AddDays(1)) || d.After(d) || d.Compare(d) != 0 {
+		t.Fatalf("unexpected comparisons")
+	}
+	if !d.Between(d, d) || d.Between(d.AddDays(1), d.AddDays(2)) {
+		t.Fatalf("unexpected Between()")
+	}
+	if d.Weekday() != time.Wednesday {
+		t.Fatalf("Weekday() = %v", d.Weekday())
+	}
+}
+
+func TestDateTimeZones(t *testing.T) {
+	auckland := time.FixedZone("NZDT", 13*60*60)
+
+	// 20:00 UTC on March 3 is already March 4 in Auckland
+	instant := time.Date(2024, 3, 3, 20, 0, 0, 0, time.UTC)
+	if DateOf(instant) != NewDate(2024, 3, 3) || DateOf(instant.In(auckland)) != NewDate(2024, 3, 4) {
+		t.Fatalf("DateOf() doesn't use the time's own zone")
+	}
+
+	if got := NewDate(2024, 3, 4).In(auckland); got.Day() != 4 || got.Hour() != 0 || got.Location() != auckland {
+		t.Fatalf("In() = %v", got)
+	}
+}
+
+func TestDateTimeJSON(t *testing.T) {
+	var stamps []DateTime
+	data := `["2024-03-04T10:00:00.123Z","2024-03-04T10:00:00","2024-03-04T12:00:00+02:00",null,""]`
+	if err := json.Unmarshal([]byte(data), &stamps); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	if !stamps[1].Equal(expected) || !stamps[2].Equal(expected) || !stamps[3].IsZero() || !stamps[4].IsZero() {
+		t.Fatalf("unexpected timestamps %v", stamps)
+	}
+}
+
+// mustParseDate parses a YYYY-MM-DD date, panicking if it is invalid.
+func mustParseDate(value string) Date {
+	d, err := ParseDate(value)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func FuzzDateUnmarshalJSON(f *testing.F) {
+	for _, seed := range []string{`"2024-03-01"`, `"2024-03-01T23:30:00+02:00"`, `null`, `""`, `"2024-02-30"`, `20240301`} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var date Date
+		if err := json.Unmarshal(data, &date); err != nil {
+			return
+		}
+
+		encoded, err := json.Marshal(date)
+		if err != nil {
+			t.Fatalf("encoding %v: %v", date, err)
+		}
+		var decoded Date
+		if err := json.Unmarshal(encoded, &decoded); err != nil || decoded != date {
+			t.Fatalf("%s decoded as %v, then %s as %v (%v)", data, date, encoded, decoded, err)
+		}
+	})
+}
+
+func FuzzDateTimeUnmarshalJSON(f *testing.F) {
+	for _, seed := range []string{`"2024-03-01T09:30:00Z"`, `"2024-03-01 09:30:00"`, `"2024-03-01T09:30:00.123+05:30"`, `null`, `" "`, `1709285400`} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var dt DateTime
+		if err := json.Unmarshal(data, &dt); err != nil {
+			return
+		}
+
+		// Times that can't be written in RFC 3339, such as years past 9999,
+		// can't be encoded back
+		encoded, err := json.Marshal(dt)
+		if err != nil {
+			return
+		}
+		var decoded DateTime
+		if err := json.Unmarshal(encoded, &decoded); err != nil || !decoded.Equal(dt.Time) {
+			t.Fatalf("%s decoded as %v, then %s as %v (%v)", data, dt, encoded, decoded, err)
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SyncStore persists the marks of a SyncState between runs.
+type SyncStore interface {
+	Load() (map[string]SyncMark, error)
+	Save(marks map[string]SyncMark) error
+}
+
+// SyncMark is how far a collection was synced: the update time of the
+// newest record passed on, and the IDs of the records updated at exactly
+// that time. Records updated at the same time but not yet seen (e.g. saved
+// just after the sync) are still passed on by the next sync.
+type SyncMark struct {
+	Since time.Time `json:"since"`
+	Seen  []ID      `json:"seen,omitempty"`
+}
+
+// SyncState remembers, for each resource collection (e.g. the tasks of a
+// workspace), the update time of the newest record seen by the last
+// successful sync, so that the next sync only passes on records updated
+// since. Timestamps come from the API, so the clock of the machine running
+// the sync doesn't matter.
+//
+// The API can't filter records by update time, so collections are still
+// fetched in full; only the records passed on are filtered. Deleted records
+// aren't reported: use a TaskFeed, which compares snapshots, to notice them.
+//
+// A SyncState is safe for concurrent use.
+type SyncState struct {
+	store SyncStore
+
+	mu    sync.Mutex
+	marks map[string]SyncMark
+}
+
+// NewSyncState returns a SyncState persisted in store. The state is loaded
+// on first use.
+func NewSyncState(store SyncStore) *SyncState {
+	return &SyncState{store: store}
+}
+
+// Since returns the update time of the newest record synced in collection,
+// or the zero time if it was never synced.
+func (state *SyncState) Since(collection string) (time.Time, error) {
+	mark, err := state.mark(collection)
+	return mark.Since, err
+}
+
+// mark returns the mark of collection.
+func (state *SyncState) mark(collection string) (SyncMark, error) {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if err := state.load(); err != nil {
+		return SyncMark{}, err
+	}
+	return state.marks[collection], nil
+}
+
+// Reset forgets collection, so that its next sync passes on every record.
+func (state *SyncState) Reset(collection string) error {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if err := state.load(); err != nil {
+		return err
+	}
+	delete(state.marks, collection)
+	return state.store.Save(state.marks)
+}
+
+// load reads the marks from the store, unless they were read already.
+func (state *SyncState) load() error {
+	if state.marks != nil {
+		return nil
+	}
+
+	marks, err := state.store.Load()
+	if err != nil {
+		return fmt.Errorf("loading sync state: %w", err)
+	}
+	if marks == nil {
+		marks = map[string]SyncMark{}
+	}
+	state.marks = marks
+	return nil
+}
+
+// advance records that collection was synced up to mark, unless a
+// concurrent sync got further, and saves the state.
+func (state *SyncState) advance(collection string, mark SyncMark) error {
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if mark.Since.Before(state.marks[collection].Since) {
+		return nil
+	}
+	state.marks[collection] = mark
+	return state.store.Save(state.marks)
+}
+
+// SyncCollection fetches a collection of records and passes those updated
+// since its last successful sync to apply. The state only moves forward once
+// apply succeeds, so that records are passed on again if it fails. Records
+// updated at the same time as the newest one of the last sync are passed on
+// unless that sync already saw their ID.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	state: State remembering the last sync of each collection
+//	collection: Key of the collection in state, e.g. "42/tasks"
+//	fetch: Function fetching every record of the collection
+//	id: Function returning the ID of a record
+//	updatedAt: Function returning the update time of a record
+//	apply: Function receiving the changed records (not called if there are none)
+func SyncCollection[T any](ctx context.Context, state *SyncState, collection string, fetch func(ctx context.Context) ([]T, error), id func(T) ID, updatedAt func(T) time.Time, apply func([]T) error) error {
+	mark, err := state.mark(collection)
+	if err != nil {
+		return err
+	}
+
+	records, err := fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[ID]bool, len(mark.Seen))
+	for _, recordId := range mark.Seen {
+		seen[recordId] = true
+	}
+
+	var changed []T
+	next := SyncMark{Since: mark.Since, Seen: append([]ID(nil), mark.Seen...)}
+	for _, record := range records {
+		updated := updatedAt(record)
+		if updated.Before(mark.Since) || updated.Equal(mark.Since) && seen[id(record)] {
+			continue
+		}
+		changed = append(changed, record)
+
+		switch {
+		case updated.After(next.Since):
+			next = SyncMark{Since: updated, Seen: []ID{id(record)}}
+		case updated.Equal(next.Since):
+			next.Seen = append(next.Seen, id(record))
+		}
+	}
+
+	if len(changed) == 0 {
+		return nil
+	}
+	if err := apply(changed); err != nil {
+		return err
+	}
+	return state.advance(collection, next)
+}
+
+// SyncTasks passes the tasks matching filter that were updated since the
+// last sync of the workspace's tasks to apply. See SyncCollection.
+// Keep filter the same between runs, or tasks that newly match it but
+// haven't changed are missed.
+func SyncTasks(ctx context.Context, pa *togglPlanApi, state *SyncState, workspaceId ID, filter TaskFilter, apply func([]Task) error) error {
+	fetch := func(ctx context.Context) ([]Task, error) {
+		return ListAllTasks(ctx, pa, workspaceId, filter)
+	}
+	id := func(task Task) ID { return task.Id }
+	updatedAt := func(task Task) time.Time { return task.UpdatedAt.Time }
+	return SyncCollection(ctx, state, fmt.Sprintf("%d/tasks", workspaceId), fetch, id, updatedAt, apply)
+}
+
+// SyncProjects passes the projects updated since the last sync of the
+// workspace's projects to apply. See SyncCollection.
+func SyncProjects(ctx context.Context, pa *togglPlanApi, state *SyncState, workspaceId ID, apply func([]Project) error) error {
+	fetch := func(ctx context.Context) ([]Project, error) {
+		return GetProjects(ctx, pa, workspaceId)
+	}
+	id := func(project Project) ID { return project.Id }
+	updatedAt := func(project Project) time.Time { return project.UpdatedAt.Time }
+	return SyncCollection(ctx, state, fmt.Sprintf("%d/projects", workspaceId), fetch, id, updatedAt, apply)
+}
+
+// SyncMilestones passes the milestones updated since the last sync of the
+// workspace's milestones to apply. See SyncCollection.
+func SyncMilestones(ctx context.Context, pa *togglPlanApi, state *SyncState, workspaceId ID, apply func([]Milestone) error) error {
+	fetch := func(ctx context.Context) ([]Milestone, error) {
+		return GetMilestones(ctx, pa, workspaceId)
+	}
+	id := func(milestone Milestone) ID { return milestone.Id }
+	updatedAt := func(milestone Milestone) time.Time { return milestone.UpdatedAt.Time }
+	return SyncCollection(ctx, state, fmt.Sprintf("%d/milestones", workspaceId), fetch, id, updatedAt, apply)
+}
+
+// MemorySyncStore keeps sync marks in memory, for syncs running in a
+// single long-lived process.
+type MemorySyncStore struct {
+	mu    sync.Mutex
+	marks map[string]SyncMark
+}
+
+// Load returns a copy of the saved marks.
+func (store *MemorySyncStore) Load() (map[string]SyncMark, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return copyMarks(store.marks), nil
+}
+
+// Save replaces the saved marks.
+func (store *MemorySyncStore) Save(marks map[string]SyncMark) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.marks = copyMarks(marks)
+	return nil
+}
+
+// copyMarks returns a copy of marks.
+func copyMarks(marks map[string]SyncMark) map[string]SyncMark {
+	copied := make(map[string]SyncMark, len(marks))
+	for collection, mark := range marks {
+		mark.Seen = append([]ID(nil), mark.Seen...)
+		copied[collection] = mark
+	}
+	return copied
+}
+
+// FileSyncStore keeps sync marks in a JSON file, for syncs run
+// periodically (e.g. from cron). A missing file is treated as no marks.
+type FileSyncStore struct {
+	Path string
+}
+
+// Load reads the marks from the file.
+func (store FileSyncStore) Load() (map[string]SyncMark, error) {
+	data, err := os.ReadFile(store.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]SyncMark{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var marks map[string]SyncMark
+	err = json.Unmarshal(data, &marks)
+	return marks, err
+}
+
+// Save writes the marks to the file, replacing it atomically so that a
+// crash never leaves a truncated file behind.
+func (store FileSyncStore) Save(marks map[string]SyncMark) error {
+	data, err := json.MarshalIndent(marks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(store.Path), filepath.Base(store.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), store.Path)
+}
@@ -0,0 +1,91 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSyncProjects(t *testing.T) {
+	projects := `[{"id":1,"updated_at":"2024-03-01T10:00:00Z"},{"id":2,"updated_at":"2024-03-02T10:00:00Z"}]`
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, projects)
+	})
+
+	store := FileSyncStore{Path: filepath.Join(t.TempDir(), "sync.json")}
+	state := NewSyncState(store)
+
+	var synced []ID
+	apply := func(changed []Project) error {
+		for _, project := range changed {
+			synced = append(synced, project.Id)
+		}
+		return nil
+	}
+
+	if err := SyncProjects(context.Background(), pa, state, 42, apply); err != nil || len(synced) != 2 {
+		t.Fatalf("expected every project on the first sync, got %v, %v", synced, err)
+	}
+
+	// Only project 2 changes, and the state survives a restart
+	projects = `[{"id":1,"updated_at":"2024-03-01T10:00:00Z"},{"id":2,"updated_at":"2024-03-03T10:00:00Z"}]`
+	state = NewSyncState(store)
+	synced = nil
+
+	failing := errors.New("downstream is down")
+	err := SyncProjects(context.Background(), pa, state, 42, func([]Project) error { return failing })
+	if !errors.Is(err, failing) {
+		t.Fatalf("expected the apply error, got %v", err)
+	}
+
+	if err := SyncProjects(context.Background(), pa, state, 42, apply); err != nil || len(synced) != 1 || synced[0] != 2 {
+		t.Fatalf("expected project 2 again after the failed apply, got %v, %v", synced, err)
+	}
+
+	since, err := state.Since("42/projects")
+	if err != nil || !since.Equal(time.Date(2024, 3, 3, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected timestamp %v, %v", since, err)
+	}
+
+	synced = nil
+	if err := SyncProjects(context.Background(), pa, state, 42, apply); err != nil || synced != nil {
+		t.Fatalf("expected nothing new, got %v, %v", synced, err)
+	}
+}
+
+func TestSyncProjectsSameTimestamp(t *testing.T) {
+	projects := `[{"id":1,"updated_at":"2024-03-01T10:00:00Z"}]`
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, projects)
+	})
+
+	store := FileSyncStore{Path: filepath.Join(t.TempDir(), "sync.json")}
+
+	var synced []ID
+	apply := func(changed []Project) error {
+		for _, project := range changed {
+			synced = append(synced, project.Id)
+		}
+		return nil
+	}
+
+	if err := SyncProjects(context.Background(), pa, NewSyncState(store), 42, apply); err != nil || len(synced) != 1 || synced[0] != 1 {
+		t.Fatalf("expected the project once, got %v, %v", synced, err)
+	}
+
+	// Project 2 was saved in the same second as project 1, after the sync
+	projects = `[{"id":1,"updated_at":"2024-03-01T10:00:00Z"},{"id":2,"updated_at":"2024-03-01T10:00:00Z"}]`
+	synced = nil
+	if err := SyncProjects(context.Background(), pa, NewSyncState(store), 42, apply); err != nil || len(synced) != 1 || synced[0] != 2 {
+		t.Fatalf("expected only the late project, got %v, %v", synced, err)
+	}
+
+	synced = nil
+	if err := SyncProjects(context.Background(), pa, NewSyncState(store), 42, apply); err != nil || synced != nil {
+		t.Fatalf("expected nothing new, got %v, %v", synced, err)
+	}
+}
@@ -0,0 +1,144 @@
+package togglplanapi
+
+// DiffTask compares a task as it was fetched with a locally modified copy,
+// and returns an update holding only the fields that differ. Sending it with
+// UpdateTask leaves every other field alone, so concurrent edits made to
+// those fields by someone else are not overwritten.
+// The second result is false if nothing changed.
+//
+//	original := *task
+//	task.Name = "Final review"
+//	task.Done = true
+//	update, changed := togglplanapi.DiffTask(original, *task)
+func DiffTask(original Task, modified Task) (TaskUpdate, bool) {
+	var update TaskUpdate
+	changed := false
+
+	if original.Name != modified.Name {
+		update.Name = &modified.Name
+		changed = true
+	}
+	if original.Notes != modified.Notes {
+		update.Notes = &modified.Notes
+		changed = true
+	}
+	if original.StartDate != modified.StartDate {
+		update.StartDate = &modified.StartDate
+		changed = true
+	}
+	if original.EndDate != modified.EndDate {
+		update.EndDate = &modified.EndDate
+		changed = true
+	}
+	if original.StartTime != modified.StartTime {
+		update.StartTime = &modified.StartTime
+		changed = true
+	}
+	if original.EndTime != modified.EndTime {
+		update.EndTime = &modified.EndTime
+		changed = true
+	}
+	if original.Color != modified.Color {
+		update.Color = &modified.Color
+		changed = true
+	}
+	if original.EstimatedMinutes != modified.EstimatedMinutes {
+		update.EstimatedMinutes = &modified.EstimatedMinutes
+		changed = true
+	}
+	if original.Done != modified.Done {
+		update.Done = &modified.Done
+		changed = true
+	}
+	if original.ProjectId != modified.ProjectId {
+		update.ProjectId = OptionalId(modified.ProjectId)
+		changed = true
+	}
+	if original.MilestoneId != modified.MilestoneId {
+		update.MilestoneId = OptionalId(modified.MilestoneId)
+		changed = true
+	}
+	if original.PlanStatusId != modified.PlanStatusId {
+		update.PlanStatusId = OptionalId(modified.PlanStatusId)
+		changed = true
+	}
+	if !equalSlices(original.Assignees, modified.Assignees) {
+		assignees := nonNil(modified.Assignees)
+		update.Assignees = &assignees
+		changed = true
+	}
+	if !equalSlices(original.Tags, modified.Tags) {
+		tags := nonNil(modified.Tags)
+		update.Tags = &tags
+		changed = true
+	}
+	if !equalSlices(original.Checklist, modified.Checklist) {
+		checklist := nonNil(modified.Checklist)
+		update.Checklist = &checklist
+		changed = true
+	}
+	if original.Position != modified.Position {
+		update.Position = &modified.Position
+		changed = true
+	}
+
+	return update, changed
+}
+
+// DiffProject compares a project as it was fetched with a locally modified
+// copy, and returns an update holding only the fields that differ, like DiffTask.
+// The second result is false if nothing changed.
+func DiffProject(original Project, modified Project) (ProjectUpdate, bool) {
+	var update ProjectUpdate
+	changed := false
+
+	if original.Name != modified.Name {
+		update.Name = &modified.Name
+		changed = true
+	}
+	if original.Notes != modified.Notes {
+		update.Notes = &modified.Notes
+		changed = true
+	}
+	if original.Color != modified.Color {
+		update.Color = &modified.Color
+		changed = true
+	}
+	if original.StartDate != modified.StartDate {
+		update.StartDate = &modified.StartDate
+		changed = true
+	}
+	if original.EndDate != modified.EndDate {
+		update.EndDate = &modified.EndDate
+		changed = true
+	}
+	if original.Archived != modified.Archived {
+		update.Archived = &modified.Archived
+		changed = true
+	}
+
+	return update, changed
+}
+
+// equalSlices reports whether a and b hold the same elements in the same order.
+// A nil slice equals an empty one.
+func equalSlices[T comparable](a []T, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// nonNil returns s, or an empty slice if s is nil, so that it is sent as []
+// rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package togglplanapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDiffTask(t *testing.T) {
+	original := Task{
+		Id:        7,
+		Name:      "Design",
+		StartDate: NewDate(2024, 3, 4),
+		EndDate:   NewDate(2024, 3, 5),
+		ProjectId: 5,
+		Assignees: []ID{3},
+		Tags:      []string{"web"},
+	}
+
+	if _, changed := DiffTask(original, original); changed {
+		t.Fatal("expected no changes for an identical task")
+	}
+
+	modified := original
+	modified.EndDate = NewDate(2024, 3, 8)
+	modified.ProjectId = 0
+	modified.Assignees = nil
+	modified.Tags = []string{"web"}
+
+	update, changed := DiffTask(original, modified)
+	if !changed {
+		t.Fatal("expected changes")
+	}
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"end_date":"2024-03-08","project_id":null,"workspace_members":[]}` {
+		t.Fatalf("unexpected payload %s", data)
+	}
+}
+
+func TestDiffProject(t *testing.T) {
+	original := Project{Id: 1, Name: "Web", Color: ColorBlue}
+	modified := original
+	modified.Archived = true
+
+	update, changed := DiffProject(original, modified)
+	data, _ := json.Marshal(update)
+	if !changed || string(data) != `{"archived":true}` {
+		t.Fatalf("unexpected payload %s", data)
+	}
+}
@@ -0,0 +1,294 @@
+/*
+Package digest summarizes the tasks of a Toggl Plan workspace for a user or a
+team: what is due today, what is overdue, what was newly assigned and what
+was completed. Digests are rendered as plain text, Markdown or HTML email,
+or by your own Renderer.
+
+Example Usage:
+
+	import (
+		"context"
+		"os"
+
+		"github.com/ricotheque/togglplanapi/digest"
+	)
+
+	func main() {
+		d, err := digest.Fetch(context.Background(), pa, workspaceId, digest.Options{
+			MemberIds: []togglplanapi.ID{memberId},
+		})
+		if err != nil {
+			panic(err)
+		}
+
+		digest.Markdown.Render(os.Stdout, d)
+	}
+*/
+package digest
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"togglplanapi"
+)
+
+// Period is the span of time a digest looks back on.
+type Period int
+
+const (
+	// Daily digests report on the previous day.
+	Daily Period = iota
+	// Weekly digests report on the previous seven days.
+	Weekly
+)
+
+// Options configures a digest.
+type Options struct {
+	// Title of the digest. Defaults to "Daily digest" or "Weekly digest".
+	Title  string
+	Period Period
+
+	// Today is the day of the digest. Defaults to today in Location.
+	Today togglplanapi.Date
+	// Location is the time zone of the readers, in which timestamps are
+	// turned into days. Defaults to time.Local.
+	Location *time.Location
+
+	// MemberIds restricts the digest to the tasks of a user or a team. By
+	// default it covers the whole workspace.
+	MemberIds []togglplanapi.ID
+
+	// Previous are the tasks the previous digest was built from. If given,
+	// tasks are newly assigned when they gained an assignee of MemberIds
+	// since. Otherwise, tasks created during the period count as newly
+	// assigned.
+	Previous []togglplanapi.Task
+
+	// DaysBack and DaysAhead set the range of tasks fetched by Fetch,
+	// relative to Today, which bounds how old overdue tasks and how far
+	// ahead newly assigned tasks can be. Default to 30 and 60 days.
+	DaysBack  int
+	DaysAhead int
+}
+
+// Item is a task of a digest, with the names of its project and assignees.
+type Item struct {
+	Task      togglplanapi.Task
+	Project   string
+	Assignees []string
+}
+
+// Digest is the summary of the tasks of a period.
+type Digest struct {
+	Title string
+	// Date is the day of the digest, and Since the first day of the period
+	// it looks back on.
+	Date  togglplanapi.Date
+	Since togglplanapi.Date
+
+	// Overdue tasks ended before Date and aren't done.
+	Overdue []Item
+	// DueToday tasks end on Date and aren't done.
+	DueToday []Item
+	// NewlyAssigned tasks were assigned during the period and aren't done.
+	NewlyAssigned []Item
+	// Completed tasks were marked done during the period, as far as their
+	// update time tells.
+	Completed []Item
+}
+
+// Empty reports whether the digest has nothing to report.
+func (d Digest) Empty() bool {
+	return len(d.Overdue) == 0 && len(d.DueToday) == 0 && len(d.NewlyAssigned) == 0 && len(d.Completed) == 0
+}
+
+// Fetch fetches the tasks, projects and members of a workspace, and builds
+// their digest. See Build.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	options: Period, audience and range of the digest (use `digest.Options{}` for the defaults)
+func Fetch(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, options Options) (Digest, error) {
+	options = withDefaults(options)
+
+	var tasks []togglplanapi.Task
+	var projects []togglplanapi.Project
+	var members []togglplanapi.Member
+	err := errors.Join(togglplanapi.RunBatch(ctx, 3, []func(ctx context.Context) error{
+		func(ctx context.Context) (err error) {
+			tasks, err = togglplanapi.ListAllTasks(ctx, pa, workspaceId, togglplanapi.TaskFilter{
+				Since:     options.Today.AddDays(-options.DaysBack),
+				Until:     options.Today.AddDays(options.DaysAhead),
+				MemberIds: options.MemberIds,
+			})
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			projects, err = togglplanapi.GetProjects(ctx, pa, workspaceId)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			members, err = togglplanapi.GetMembers(ctx, pa, workspaceId)
+			return err
+		},
+	})...)
+	if err != nil {
+		return Digest{}, err
+	}
+
+	return Build(tasks, projects, members, options), nil
+}
+
+// Build sorts tasks into the sections of a digest. Tasks in several sections,
+// such as an overdue task that was newly assigned, are listed in each.
+// Within sections, tasks are sorted by end date and name.
+// Arguments:
+//
+//	tasks: Tasks to report on
+//	projects: Projects used to look up project names
+//	members: Members used to look up member names
+//	options: Period and audience of the digest
+func Build(tasks []togglplanapi.Task, projects []togglplanapi.Project, members []togglplanapi.Member, options Options) Digest {
+	options = withDefaults(options)
+
+	projectNames := map[togglplanapi.ID]string{}
+	for _, project := range projects {
+		projectNames[project.Id] = project.Name
+	}
+	memberNames := map[togglplanapi.ID]string{}
+	for _, member := range members {
+		memberNames[member.Id] = member.Name
+	}
+
+	days := 1
+	if options.Period == Weekly {
+		days = 7
+	}
+	d := Digest{Title: options.Title, Date: options.Today, Since: options.Today.AddDays(-days)}
+
+	previous := map[togglplanapi.ID]togglplanapi.Task{}
+	for _, task := range options.Previous {
+		previous[task.Id] = task
+	}
+
+	for _, task := range tasks {
+		if !assignedTo(task, options.MemberIds) {
+			continue
+		}
+
+		item := Item{Task: task, Project: projectNames[task.ProjectId]}
+		for _, assignee := range task.Assignees {
+			item.Assignees = append(item.Assignees, memberNames[assignee])
+		}
+
+		duringPeriod := func(t togglplanapi.DateTime) bool {
+			if t.IsZero() {
+				return false
+			}
+			day := togglplanapi.DateOf(t.In(options.Location))
+			return !day.Before(d.Since) && day.Before(d.Date)
+		}
+
+		if task.Done {
+			if duringPeriod(task.UpdatedAt) {
+				d.Completed = append(d.Completed, item)
+			}
+			continue
+		}
+
+		if !task.EndDate.IsZero() && task.EndDate.Before(d.Date) {
+			d.Overdue = append(d.Overdue, item)
+		}
+		if task.EndDate == d.Date {
+			d.DueToday = append(d.DueToday, item)
+		}
+
+		if options.Previous != nil {
+			if newlyAssigned(previous[task.Id], task, options.MemberIds) {
+				d.NewlyAssigned = append(d.NewlyAssigned, item)
+			}
+		} else if duringPeriod(task.CreatedAt) {
+			d.NewlyAssigned = append(d.NewlyAssigned, item)
+		}
+	}
+
+	for _, section := range [][]Item{d.Overdue, d.DueToday, d.NewlyAssigned, d.Completed} {
+		sortItems(section)
+	}
+	return d
+}
+
+// withDefaults fills in the defaults of options.
+func withDefaults(options Options) Options {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+	if options.Today.IsZero() {
+		options.Today = togglplanapi.Today(options.Location)
+	}
+	if options.Title == "" {
+		options.Title = "Daily digest"
+		if options.Period == Weekly {
+			options.Title = "Weekly digest"
+		}
+	}
+	if options.DaysBack <= 0 {
+		options.DaysBack = 30
+	}
+	if options.DaysAhead <= 0 {
+		options.DaysAhead = 60
+	}
+	return options
+}
+
+// assignedTo reports whether a task is assigned to any of memberIds, or
+// whether memberIds is empty.
+func assignedTo(task togglplanapi.Task, memberIds []togglplanapi.ID) bool {
+	if len(memberIds) == 0 {
+		return true
+	}
+	for _, assignee := range task.Assignees {
+		if contains(memberIds, assignee) {
+			return true
+		}
+	}
+	return false
+}
+
+// newlyAssigned reports whether current gained an assignee of memberIds (or
+// any assignee, if memberIds is empty) since the previous version of the task.
+// A task missing from the previous tasks has a zero previous version.
+func newlyAssigned(previous togglplanapi.Task, current togglplanapi.Task, memberIds []togglplanapi.ID) bool {
+	for _, assignee := range current.Assignees {
+		if (len(memberIds) == 0 || contains(memberIds, assignee)) && !contains(previous.Assignees, assignee) {
+			return true
+		}
+	}
+	return false
+}
+
+// contains reports whether ids contains id.
+func contains(ids []togglplanapi.ID, id togglplanapi.ID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// sortItems sorts items by end date, then by name.
+func sortItems(items []Item) {
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := items[i].Task, items[j].Task
+		if a.EndDate != b.EndDate {
+			return a.EndDate.Before(b.EndDate)
+		}
+		return a.Name < b.Name
+	})
+}
@@ -0,0 +1,131 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"togglplanapi"
+)
+
+func TestBuild(t *testing.T) {
+	today := togglplanapi.NewDate(2024, 3, 6)
+	yesterday := togglplanapi.DateTime{Time: time.Date(2024, 3, 5, 15, 0, 0, 0, time.UTC)}
+	lastWeek := togglplanapi.DateTime{Time: time.Date(2024, 2, 27, 15, 0, 0, 0, time.UTC)}
+
+	tasks := []togglplanapi.Task{
+		{Id: 1, Name: "Late", EndDate: today.AddDays(-2), Assignees: []togglplanapi.ID{7}, CreatedAt: lastWeek},
+		{Id: 2, Name: "Due", EndDate: today, Assignees: []togglplanapi.ID{7}, CreatedAt: yesterday, ProjectId: 3},
+		{Id: 3, Name: "Shipped", Done: true, Assignees: []togglplanapi.ID{7}, UpdatedAt: yesterday},
+		{Id: 4, Name: "Old news", Done: true, Assignees: []togglplanapi.ID{7}, UpdatedAt: lastWeek},
+		{Id: 5, Name: "Someone else's", EndDate: today, Assignees: []togglplanapi.ID{8}},
+	}
+	projects := []togglplanapi.Project{{Id: 3, Name: "Website"}}
+	members := []togglplanapi.Member{{Id: 7, Name: "Ada"}}
+
+	d := Build(tasks, projects, members, Options{Today: today, Location: time.UTC, MemberIds: []togglplanapi.ID{7}})
+
+	got := []int{len(d.Overdue), len(d.DueToday), len(d.NewlyAssigned), len(d.Completed)}
+	if got[0] != 1 || got[1] != 1 || got[2] != 1 || got[3] != 1 || d.NewlyAssigned[0].Task.Id != 2 || d.Completed[0].Task.Id != 3 {
+		t.Fatalf("unexpected sections %v: %+v", got, d)
+	}
+
+	// With the previous tasks, only new assignments count
+	d = Build(tasks, projects, members, Options{
+		Today:     today,
+		Location:  time.UTC,
+		MemberIds: []togglplanapi.ID{7},
+		Previous:  []togglplanapi.Task{{Id: 2, Assignees: []togglplanapi.ID{7}}},
+	})
+	if len(d.NewlyAssigned) != 1 || d.NewlyAssigned[0].Task.Id != 1 {
+		t.Fatalf("unexpected newly assigned tasks %+v", d.NewlyAssigned)
+	}
+}
+
+func TestRender(t *testing.T) {
+	d := Digest{
+		Title:    "Daily digest",
+		Date:     togglplanapi.NewDate(2024, 3, 6),
+		DueToday: []Item{{Task: togglplanapi.Task{Name: "Fix <b> & *stars*", EndDate: togglplanapi.NewDate(2024, 3, 6)}, Project: "Website", Assignees: []string{"Ada"}}},
+	}
+
+	for _, test := range []struct {
+		renderer Renderer
+		expected string
+	}{
+		{Text, "Daily digest — Wed, Mar 6, 2024\n\nDue today (1)\n  - Fix <b> & *stars* (Website · Ada · due Mar 6)\n"},
+		{Markdown, "# Daily digest — Wed, Mar 6, 2024\n\n## Due today (1)\n\n- **Fix \\<b> & \\*stars\\*** Website · Ada · due Mar 6\n"},
+		{HTML, "<li><strong>Fix &lt;b&gt; &amp; *stars*</strong> <span style=\"color: #777;\">Website · Ada · due Mar 6</span></li>"},
+	} {
+		var b strings.Builder
+		if err := test.renderer.Render(&b, d); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(b.String(), test.expected) {
+			t.Errorf("expected %q in:\n%s", test.expected, b.String())
+		}
+	}
+
+	var b strings.Builder
+	Text.Render(&b, Digest{Title: "Daily digest", Date: d.Date})
+	if !strings.Contains(b.String(), nothingToReport) {
+		t.Errorf("empty digest rendered as %q", b.String())
+	}
+}
+
+func TestSend(t *testing.T) {
+	d := Digest{Title: "Weekly digest", Date: togglplanapi.NewDate(2024, 3, 6)}
+
+	var sent Email
+	sender := SenderFunc(func(ctx context.Context, email Email) error {
+		sent = email
+		return nil
+	})
+	if err := Send(context.Background(), sender, d, "plan@example.com", []string{"ada@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := sent.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subject, _ := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if subject != "Weekly digest — Wed, Mar 6, 2024" || message.Header.Get("To") != "ada@example.com" {
+		t.Fatalf("unexpected headers %v", message.Header)
+	}
+
+	_, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part)
+		if !strings.Contains(string(body), nothingToReport) {
+			t.Errorf("unexpected %s part %q", part.Header.Get("Content-Type"), body)
+		}
+		types = append(types, part.Header.Get("Content-Type"))
+	}
+	if strings.Join(types, ", ") != "text/plain; charset=utf-8, text/html; charset=utf-8" {
+		t.Fatalf("unexpected parts %v", types)
+	}
+}
@@ -0,0 +1,204 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Email is an email message with a plain text and an HTML version.
+type Email struct {
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Sender delivers emails, e.g. through SMTP or the API of an email service.
+type Sender interface {
+	Send(ctx context.Context, email Email) error
+}
+
+// SenderFunc adapts a function to the Sender interface.
+type SenderFunc func(ctx context.Context, email Email) error
+
+// Send calls f.
+func (f SenderFunc) Send(ctx context.Context, email Email) error {
+	return f(ctx, email)
+}
+
+// NewEmail renders a digest as an email, with its title and date as subject.
+// Arguments:
+//
+//	d: Digest to send
+//	from: Address of the sender
+//	to: Addresses of the recipients
+func NewEmail(d Digest, from string, to []string) (Email, error) {
+	var text, html strings.Builder
+	if err := Text.Render(&text, d); err != nil {
+		return Email{}, err
+	}
+	if err := HTML.Render(&html, d); err != nil {
+		return Email{}, err
+	}
+	return Email{From: from, To: to, Subject: heading(d), Text: text.String(), HTML: html.String()}, nil
+}
+
+// Send renders a digest as an email and delivers it with sender.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the delivery
+//	sender: Sender delivering the email, such as an SMTPSender
+//	d: Digest to send
+//	from: Address of the sender
+//	to: Addresses of the recipients
+func Send(ctx context.Context, sender Sender, d Digest, from string, to []string) error {
+	email, err := NewEmail(d, from, to)
+	if err != nil {
+		return err
+	}
+	return sender.Send(ctx, email)
+}
+
+// Bytes returns the email as a MIME message, with the plain text and HTML
+// versions as alternatives.
+func (email Email) Bytes() ([]byte, error) {
+	var b bytes.Buffer
+	parts := multipart.NewWriter(&b)
+
+	header := func(key string, value string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+	header("From", email.From)
+	header("To", strings.Join(email.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", email.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	b.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain", email.Text},
+		{"text/html", email.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// SMTPSender delivers emails through an SMTP server. The connection is
+// upgraded with STARTTLS when the server supports it.
+type SMTPSender struct {
+	Host string
+	// Port defaults to 587.
+	Port int
+
+	// Username and Password authenticate with PLAIN authentication, which
+	// is only allowed over TLS or to localhost. Leave them empty to send
+	// without authenticating.
+	Username string
+	Password string
+
+	// TLSConfig configures STARTTLS. Defaults to verifying the certificate
+	// of Host.
+	TLSConfig *tls.Config
+}
+
+// Send delivers an email. ctx bounds connecting and the whole exchange with
+// the server.
+func (s SMTPSender) Send(ctx context.Context, email Email) error {
+	if len(email.To) == 0 {
+		return errors.New("email has no recipients")
+	}
+	message, err := email.Bytes()
+	if err != nil {
+		return err
+	}
+
+	port := s.Port
+	if port == 0 {
+		port = 587
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", net.JoinHostPort(s.Host, strconv.Itoa(port)))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		config := s.TLSConfig
+		if config == nil {
+			config = &tls.Config{ServerName: s.Host}
+		}
+		if err := client.StartTLS(config); err != nil {
+			return err
+		}
+	}
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, s.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(email.From); err != nil {
+		return err
+	}
+	for _, to := range email.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("recipient %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(message); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
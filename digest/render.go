@@ -0,0 +1,157 @@
+package digest
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// Renderer writes a digest in some format.
+type Renderer interface {
+	Render(w io.Writer, d Digest) error
+}
+
+// RendererFunc adapts a function to the Renderer interface.
+type RendererFunc func(w io.Writer, d Digest) error
+
+// Render calls f.
+func (f RendererFunc) Render(w io.Writer, d Digest) error {
+	return f(w, d)
+}
+
+// Renderers of the package.
+var (
+	// Text renders a digest as plain text, e.g. for terminals and text email.
+	Text Renderer = RendererFunc(renderText)
+	// Markdown renders a digest as Markdown, e.g. for chat tools and wikis.
+	Markdown Renderer = RendererFunc(renderMarkdown)
+	// HTML renders a digest as a self-contained HTML document, for email.
+	HTML Renderer = RendererFunc(renderHTML)
+)
+
+// Section is a titled list of items of a digest.
+type Section struct {
+	Title string
+	Items []Item
+}
+
+// Sections returns the sections of a digest that have items, in the order
+// they are rendered: overdue, due today, newly assigned and completed.
+func (d Digest) Sections() []Section {
+	var sections []Section
+	for _, section := range []Section{
+		{"Overdue", d.Overdue},
+		{"Due today", d.DueToday},
+		{"Newly assigned", d.NewlyAssigned},
+		{"Completed", d.Completed},
+	} {
+		if len(section.Items) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// Details describes the project, assignees and end date of an item, e.g.
+// "Website · Ada, Bob · due Mar 4".
+func (item Item) Details() string {
+	var details []string
+	if item.Project != "" {
+		details = append(details, item.Project)
+	}
+	var assignees []string
+	for _, assignee := range item.Assignees {
+		if assignee != "" {
+			assignees = append(assignees, assignee)
+		}
+	}
+	if len(assignees) > 0 {
+		details = append(details, strings.Join(assignees, ", "))
+	}
+	if !item.Task.EndDate.IsZero() {
+		details = append(details, "due "+item.Task.EndDate.In(time.UTC).Format("Jan 2"))
+	}
+	return strings.Join(details, " · ")
+}
+
+// heading returns the title of a digest followed by its date.
+func heading(d Digest) string {
+	return d.Title + " — " + d.Date.In(time.UTC).Format("Mon, Jan 2, 2006")
+}
+
+// nothingToReport is written for digests without items.
+const nothingToReport = "Nothing to report."
+
+func renderText(w io.Writer, d Digest) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, heading(d))
+
+	if d.Empty() {
+		fmt.Fprintln(bw, "\n"+nothingToReport)
+	}
+	for _, section := range d.Sections() {
+		fmt.Fprintf(bw, "\n%s (%d)\n", section.Title, len(section.Items))
+		for _, item := range section.Items {
+			line := "  - " + item.Task.Name
+			if details := item.Details(); details != "" {
+				line += " (" + details + ")"
+			}
+			fmt.Fprintln(bw, line)
+		}
+	}
+	return bw.Flush()
+}
+
+func renderMarkdown(w io.Writer, d Digest) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "# "+markdownEscaper.Replace(heading(d)))
+
+	if d.Empty() {
+		fmt.Fprintln(bw, "\n"+nothingToReport)
+	}
+	for _, section := range d.Sections() {
+		fmt.Fprintf(bw, "\n## %s (%d)\n\n", section.Title, len(section.Items))
+		for _, item := range section.Items {
+			line := "- **" + markdownEscaper.Replace(item.Task.Name) + "**"
+			if details := item.Details(); details != "" {
+				line += " " + markdownEscaper.Replace(details)
+			}
+			fmt.Fprintln(bw, line)
+		}
+	}
+	return bw.Flush()
+}
+
+// markdownEscaper escapes the characters of names that Markdown would treat
+// as formatting.
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`)
+
+var htmlTemplate = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Heading}}</title></head>
+<body style="font-family: sans-serif; color: #222;">
+<h1 style="font-size: 20px;">{{.Heading}}</h1>
+{{- if .Digest.Empty}}
+<p>` + nothingToReport + `</p>
+{{- end}}
+{{- range .Digest.Sections}}
+<h2 style="font-size: 16px; margin-top: 24px;">{{.Title}} ({{len .Items}})</h2>
+<ul>
+{{- range .Items}}
+<li><strong>{{.Task.Name}}</strong>{{with .Details}} <span style="color: #777;">{{.}}</span>{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
+
+func renderHTML(w io.Writer, d Digest) error {
+	return htmlTemplate.Execute(w, struct {
+		Heading string
+		Digest  Digest
+	}{heading(d), d})
+}
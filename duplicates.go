@@ -0,0 +1,204 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+)
+
+// DuplicateOptions configures FindDuplicates. By default, tasks are likely
+// duplicates if they have the same name, ignoring case and spacing, are in
+// the same project, and have overlapping dates.
+type DuplicateOptions struct {
+	// AcrossProjects also matches tasks of different projects.
+	AcrossProjects bool
+
+	// IgnoreDates matches tasks whatever their dates. Otherwise undated tasks
+	// only match other undated tasks.
+	IgnoreDates bool
+}
+
+// FindDuplicates returns the groups of tasks that are likely duplicates of
+// each other, e.g. after an import was run twice. Each group holds at least
+// two tasks, the oldest first, which is the one to keep with MergeTasks.
+// Groups are sorted by the ID of their first task.
+//
+// Dates overlap transitively: a task overlapping two others groups them
+// even if those two don't overlap.
+func FindDuplicates(tasks []Task, options DuplicateOptions) [][]Task {
+	type key struct {
+		name      string
+		projectId ID
+	}
+	buckets := map[key][]Task{}
+	for _, task := range tasks {
+		k := key{name: normalizeName(task.Name)}
+		if !options.AcrossProjects {
+			k.projectId = task.ProjectId
+		}
+		buckets[k] = append(buckets[k], task)
+	}
+
+	var groups [][]Task
+	for _, bucket := range buckets {
+		if len(bucket) < 2 {
+			continue
+		}
+		sort.Slice(bucket, func(i, j int) bool { return olderTask(bucket[i], bucket[j]) })
+
+		if options.IgnoreDates {
+			groups = append(groups, bucket)
+			continue
+		}
+
+		// Join the groups of each pair of overlapping tasks
+		group := make([]int, len(bucket))
+		for i := range group {
+			group[i] = i
+		}
+		var find func(i int) int
+		find = func(i int) int {
+			if group[i] != i {
+				group[i] = find(group[i])
+			}
+			return group[i]
+		}
+		for i := range bucket {
+			for j := i + 1; j < len(bucket); j++ {
+				if datesOverlap(bucket[i], bucket[j]) {
+					group[find(j)] = find(i)
+				}
+			}
+		}
+
+		members := map[int][]Task{}
+		for i, task := range bucket {
+			root := find(i)
+			members[root] = append(members[root], task)
+		}
+		for _, tasks := range members {
+			if len(tasks) > 1 {
+				groups = append(groups, tasks)
+			}
+		}
+	}
+
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0].Id < groups[j][0].Id })
+	return groups
+}
+
+// normalizeName returns a task name in lower case with its spacing collapsed.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// olderTask reports whether a was created before b, by ID if neither has a
+// creation time.
+func olderTask(a Task, b Task) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt.Time) {
+		return a.CreatedAt.Before(b.CreatedAt.Time)
+	}
+	return a.Id < b.Id
+}
+
+// datesOverlap reports whether two tasks share a day, or are both undated.
+func datesOverlap(a Task, b Task) bool {
+	aDated := !a.StartDate.IsZero() && !a.EndDate.IsZero()
+	bDated := !b.StartDate.IsZero() && !b.EndDate.IsZero()
+	if !aDated || !bDated {
+		return !aDated && !bDated
+	}
+	return !a.StartDate.After(b.EndDate) && !b.StartDate.After(a.EndDate)
+}
+
+// MergeTasks consolidates duplicates into keep, and deletes them. keep gets
+// the assignees, tags and checklist items of the duplicates it lacks, and
+// their notes and estimate if it has none. The comments of the duplicates are
+// copied to keep, in the order they were written, under the name of the
+// authenticated user and preceded by a line naming the duplicate.
+//
+// Nothing is deleted until keep holds everything, so a failed merge can be
+// run again, at the cost of copying comments twice.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	keep: Task to keep, e.g. the first of a group returned by FindDuplicates
+//	duplicates: Tasks to merge into keep and delete
+func MergeTasks(ctx context.Context, pa *togglPlanApi, workspaceId ID, keep Task, duplicates []Task) (*Task, error) {
+	for _, duplicate := range duplicates {
+		comments, err := GetComments(ctx, pa, workspaceId, duplicate.Id)
+		if err != nil {
+			return nil, fmt.Errorf("fetching comments of task %d: %w", duplicate.Id, err)
+		}
+		sort.SliceStable(comments, func(i, j int) bool { return comments[i].CreatedAt.Before(comments[j].CreatedAt.Time) })
+
+		for _, comment := range comments {
+			body := fmt.Sprintf("<p><em>From %s (%d):</em></p>%s", html.EscapeString(duplicate.Name), duplicate.Id, comment.Body)
+			if _, err := CreateComment(ctx, pa, workspaceId, keep.Id, CommentParams{Body: body}); err != nil {
+				return nil, fmt.Errorf("copying comment %d of task %d: %w", comment.Id, duplicate.Id, err)
+			}
+		}
+	}
+
+	merged := &keep
+	if update, changed := DiffTask(keep, mergeFields(keep, duplicates)); changed {
+		var err error
+		if merged, err = UpdateTask(ctx, pa, workspaceId, keep.Id, update); err != nil {
+			return nil, fmt.Errorf("updating task %d: %w", keep.Id, err)
+		}
+	}
+
+	for _, duplicate := range duplicates {
+		if err := DeleteTask(ctx, pa, workspaceId, duplicate.Id); err != nil {
+			return merged, fmt.Errorf("deleting task %d: %w", duplicate.Id, err)
+		}
+	}
+
+	return merged, nil
+}
+
+// mergeFields returns keep with the fields of duplicates merged in.
+func mergeFields(keep Task, duplicates []Task) Task {
+	merged := keep
+	merged.Assignees = append([]ID(nil), keep.Assignees...)
+	merged.Tags = append([]string(nil), keep.Tags...)
+	merged.Checklist = append([]ChecklistItem(nil), keep.Checklist...)
+
+	for _, duplicate := range duplicates {
+		for _, memberId := range duplicate.Assignees {
+			if !contains(merged.Assignees, memberId) {
+				merged.Assignees = append(merged.Assignees, memberId)
+			}
+		}
+		for _, tag := range duplicate.Tags {
+			if !contains(merged.Tags, tag) {
+				merged.Tags = append(merged.Tags, tag)
+			}
+		}
+		for _, item := range duplicate.Checklist {
+			found := false
+			for i, existing := range merged.Checklist {
+				if normalizeName(existing.Name) == normalizeName(item.Name) {
+					merged.Checklist[i].Done = existing.Done || item.Done
+					found = true
+				}
+			}
+			if !found {
+				merged.Checklist = append(merged.Checklist, item)
+			}
+		}
+
+		if strings.TrimSpace(merged.Notes) == "" {
+			merged.Notes = duplicate.Notes
+		}
+		if merged.EstimatedMinutes == 0 {
+			merged.EstimatedMinutes = duplicate.EstimatedMinutes
+		}
+	}
+
+	return merged
+}
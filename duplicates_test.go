@@ -0,0 +1,88 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	monday := NewDate(2024, 3, 4)
+	tasks := []Task{
+		{Id: 1, Name: "Write  spec", ProjectId: 7, StartDate: monday, EndDate: monday.AddDays(2)},
+		{Id: 2, Name: "write spec", ProjectId: 7, StartDate: monday.AddDays(2), EndDate: monday.AddDays(3)},
+		// Overlaps task 2 only, but joins the group through it
+		{Id: 3, Name: "Write spec", ProjectId: 7, StartDate: monday.AddDays(3), EndDate: monday.AddDays(5)},
+		// Another project
+		{Id: 4, Name: "Write spec", ProjectId: 8, StartDate: monday, EndDate: monday},
+		// Later dates
+		{Id: 5, Name: "Write spec", ProjectId: 7, StartDate: monday.AddDays(14), EndDate: monday.AddDays(14)},
+		{Id: 6, Name: "Backlog"},
+		{Id: 7, Name: "backlog"},
+	}
+
+	groups := FindDuplicates(tasks, DuplicateOptions{})
+	if len(groups) != 2 || len(groups[0]) != 3 || groups[0][0].Id != 1 || len(groups[1]) != 2 || groups[1][0].Id != 6 {
+		t.Fatalf("unexpected groups %+v", groups)
+	}
+
+	groups = FindDuplicates(tasks, DuplicateOptions{AcrossProjects: true, IgnoreDates: true})
+	if len(groups) != 2 || len(groups[0]) != 5 {
+		t.Fatalf("unexpected groups across projects %+v", groups)
+	}
+}
+
+func TestMergeTasks(t *testing.T) {
+	var mu sync.Mutex
+	var comments []string
+	var update map[string]any
+	deleted := map[string]bool{}
+
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v5/1/tasks/2/comments":
+			json.NewEncoder(w).Encode([]Comment{{Id: 9, Body: "<p>Second</p>"}})
+		case r.Method == "POST" && r.URL.Path == "/api/v5/1/tasks/1/comments":
+			var params CommentParams
+			json.NewDecoder(r.Body).Decode(&params)
+			comments = append(comments, params.Body)
+			json.NewEncoder(w).Encode(Comment{Id: 10, Body: params.Body})
+		case r.Method == "PUT" && r.URL.Path == "/api/v5/1/tasks/1":
+			json.NewDecoder(r.Body).Decode(&update)
+			json.NewEncoder(w).Encode(Task{Id: 1, Assignees: []ID{3, 4}})
+		case r.Method == "DELETE":
+			deleted[r.URL.Path] = true
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	keep := Task{Id: 1, Name: "Write spec", Assignees: []ID{3}, Checklist: []ChecklistItem{{Name: "Draft"}}}
+	duplicate := Task{Id: 2, Name: "Write spec", Assignees: []ID{3, 4}, Notes: "<p>Notes</p>", Checklist: []ChecklistItem{{Name: "draft", Done: true}, {Name: "Review"}}}
+
+	merged, err := MergeTasks(context.Background(), pa, 1, keep, []Task{duplicate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.Id != 1 || len(merged.Assignees) != 2 {
+		t.Fatalf("unexpected merged task %+v", merged)
+	}
+
+	if len(comments) != 1 || !strings.HasPrefix(comments[0], "<p><em>From Write spec (2):</em></p>") || !strings.HasSuffix(comments[0], "<p>Second</p>") {
+		t.Fatalf("unexpected copied comments %q", comments)
+	}
+	checklist, _ := update["checklist"].([]any)
+	if len(update) != 3 || update["notes"] != "<p>Notes</p>" || len(checklist) != 2 || !checklist[0].(map[string]any)["done"].(bool) {
+		t.Fatalf("unexpected update %v", update)
+	}
+	if !deleted["/api/v5/1/tasks/2"] || len(deleted) != 1 {
+		t.Fatalf("expected the duplicate to be deleted, got %v", deleted)
+	}
+}
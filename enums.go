@@ -0,0 +1,143 @@
+package togglplanapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Color is the index of a color in the Toggl Plan palette, used by tasks,
+// projects and tags. The zero Color leaves the choice to Toggl Plan.
+type Color int
+
+// Colors of the Toggl Plan palette, in the order of the color picker.
+const (
+	ColorNone Color = iota
+	ColorRed
+	ColorOrange
+	ColorYellow
+	ColorGreen
+	ColorTeal
+	ColorBlue
+	ColorIndigo
+	ColorPurple
+	ColorPink
+	ColorBrown
+	ColorGrey
+)
+
+var colorNames = []string{"none", "red", "orange", "yellow", "green", "teal", "blue", "indigo", "purple", "pink", "brown", "grey"}
+
+// ParseColor returns the color with the given name, such as "blue".
+// Names are not case-sensitive, and "gray" is accepted for ColorGrey.
+func ParseColor(name string) (Color, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "gray" {
+		return ColorGrey, nil
+	}
+
+	for i, colorName := range colorNames {
+		if name == colorName {
+			return Color(i), nil
+		}
+	}
+
+	return ColorNone, fmt.Errorf("unknown color %q", name)
+}
+
+// Valid reports whether c is a color of the palette.
+func (c Color) Valid() bool {
+	return c >= ColorNone && int(c) < len(colorNames)
+}
+
+// String returns the name of the color, or its index if it isn't valid.
+func (c Color) String() string {
+	if !c.Valid() {
+		return "Color(" + strconv.Itoa(int(c)) + ")"
+	}
+	return colorNames[c]
+}
+
+// Estimate is the planned effort of a task, in minutes.
+type Estimate int
+
+// Common estimates, matching the presets of the Toggl Plan task editor.
+// A working day is 8 hours.
+const (
+	EstimateNone        Estimate = 0
+	EstimateQuarterHour Estimate = 15
+	EstimateHalfHour    Estimate = 30
+	EstimateHour        Estimate = 60
+	EstimateHalfDay     Estimate = 4 * EstimateHour
+	EstimateDay         Estimate = 8 * EstimateHour
+	EstimateWeek        Estimate = 5 * EstimateDay
+)
+
+// Hours returns an estimate of the given number of hours, rounded to the minute.
+func Hours(hours float64) Estimate {
+	return Estimate(hours*60 + 0.5)
+}
+
+// Valid reports whether e is a possible estimate, that is not negative.
+func (e Estimate) Valid() bool {
+	return e >= 0
+}
+
+// Hours returns the estimate in hours.
+func (e Estimate) Hours() float64 {
+	return float64(e) / 60
+}
+
+// String formats the estimate as hours and minutes, such as "1h30m".
+func (e Estimate) String() string {
+	if e == 0 {
+		return "0m"
+	}
+
+	var text string
+	if e < 0 {
+		text = "-"
+		e = -e
+	}
+	if e >= EstimateHour {
+		text += strconv.Itoa(int(e/EstimateHour)) + "h"
+	}
+	if e%EstimateHour != 0 {
+		text += strconv.Itoa(int(e%EstimateHour)) + "m"
+	}
+	return text
+}
+
+// TaskState is where a task stands on the board, derived from its dates and Done flag.
+type TaskState int
+
+const (
+	// StateUnscheduled tasks have no dates yet, and sit in the backlog.
+	StateUnscheduled TaskState = iota
+	// StateScheduled tasks are on the timeline and not done.
+	StateScheduled
+	// StateDone tasks are marked as done.
+	StateDone
+)
+
+var taskStateNames = []string{"unscheduled", "scheduled", "done"}
+
+// String returns the name of the state.
+func (s TaskState) String() string {
+	if s < 0 || int(s) >= len(taskStateNames) {
+		return "TaskState(" + strconv.Itoa(int(s)) + ")"
+	}
+	return taskStateNames[s]
+}
+
+// State returns where task stands on the board.
+func (task Task) State() TaskState {
+	switch {
+	case task.Done:
+		return StateDone
+	case task.StartDate.IsZero():
+		return StateUnscheduled
+	default:
+		return StateScheduled
+	}
+}
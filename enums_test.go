@@ -0,0 +1,94 @@
+package togglplanapi
+
+import "testing"
+
+func TestColor(t *testing.T) {
+	color, err := ParseColor(" Gray ")
+	if err != nil || color != ColorGrey || color.String() != "grey" {
+		t.Fatalf("unexpected color %v, %v", color, err)
+	}
+
+	if _, err := ParseColor("plaid"); err == nil {
+		t.Fatal("expected an error for an unknown color")
+	}
+
+	if Color(99).Valid() || Color(99).String() != "Color(99)" {
+		t.Fatal("expected color 99 to be invalid")
+	}
+}
+
+func TestEstimateString(t *testing.T) {
+	tests := map[Estimate]string{
+		EstimateNone:     "0m",
+		EstimateHalfHour: "30m",
+		EstimateDay:      "8h",
+		Hours(1.5):       "1h30m",
+		-90:              "-1h30m",
+	}
+
+	for estimate, want := range tests {
+		if got := estimate.String(); got != want {
+			t.Errorf("%d: got %s, want %s", int(estimate), got, want)
+		}
+	}
+}
+
+func TestTaskState(t *testing.T) {
+	tests := []struct {
+		task Task
+		want TaskState
+	}{
+		{Task{}, StateUnscheduled},
+		{Task{StartDate: NewDate(2024, 3, 4), EndDate: NewDate(2024, 3, 4)}, StateScheduled},
+		{Task{StartDate: NewDate(2024, 3, 4), Done: true}, StateDone},
+	}
+
+	for _, test := range tests {
+		if got := test.task.State(); got != test.want {
+			t.Errorf("%+v: got %s, want %s", test.task, got, test.want)
+		}
+	}
+}
+
+func TestColorHex(t *testing.T) {
+	if ColorBlue.Hex() != ColorBlueHex || ColorNone.Hex() != "" || Color(99).Hex() != "" {
+		t.Fatal("unexpected hex values")
+	}
+	if r, g, b, a := ColorRed.RGBA(); r != 0xe5e5 || g != 0x3939 || b != 0x3535 || a != 0xffff {
+		t.Fatalf("unexpected RGBA %x %x %x %x", r, g, b, a)
+	}
+
+	tests := map[string]Color{
+		"#2f80ed": ColorBlue,
+		"e53935":  ColorRed,
+		"#fff000": ColorYellow,
+		"#888":    ColorGrey,
+		"#303f9f": ColorIndigo,
+	}
+	for hex, want := range tests {
+		if got, err := NearestColor(hex); err != nil || got != want {
+			t.Errorf("NearestColor(%q) = %v, %v; want %v", hex, got, err, want)
+		}
+	}
+	if _, err := NearestColor("#12345"); err == nil {
+		t.Fatal("expected an error for an invalid hex color")
+	}
+}
+
+func TestColorFor(t *testing.T) {
+	color := ColorFor("Website redesign")
+	if !color.Valid() || color == ColorNone {
+		t.Fatalf("expected a palette color, got %v", color)
+	}
+	if ColorFor("  website REDESIGN ") != color {
+		t.Fatal("expected the color to ignore case and spaces")
+	}
+
+	seen := map[Color]bool{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		seen[ColorFor(name)] = true
+	}
+	if len(seen) < 4 {
+		t.Fatalf("expected names to spread over the palette, got %v", seen)
+	}
+}
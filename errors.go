@@ -0,0 +1,333 @@
+package togglplanapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrConflict is matched, using errors.Is, by errors reporting that a
+// resource was changed by someone else since it was fetched.
+var ErrConflict = errors.New("conflict")
+
+// ErrNotFound is matched, using errors.Is, by errors reporting that a
+// resource doesn't exist.
+var ErrNotFound = errors.New("not found")
+
+// ErrRateLimited is matched, using errors.Is, by errors reporting that the
+// rate limit of the API was still exceeded after retries. The APIError
+// tells when to try again in RetryAfter and RateLimit.
+var ErrRateLimited = errors.New("rate limited")
+
+// maxErrorBody is the most of an error response kept in APIError.Body.
+const maxErrorBody = 64 << 10
+
+// maxErrorSnippet is the most of a page that isn't JSON kept in
+// APIError.Body, in bytes.
+const maxErrorSnippet = 300
+
+// APIError is returned when the API answers with a status code outside of
+// the 2xx range, or when a typed call gets a page that isn't JSON, such as
+// the error page of a proxy or the login page of a captive portal.
+type APIError struct {
+	StatusCode int
+	// Body is the start of the last response body, which usually explains
+	// the error. Pages that aren't JSON, such as HTML, are reduced to the
+	// start of their text.
+	Body string
+	// ContentType is the media type of the last response, such as
+	// "application/json" or "text/html".
+	ContentType string
+	// Attempts is the number of times the request was sent, including
+	// retries.
+	Attempts int
+	// Elapsed is the time from the first attempt to the last response.
+	Elapsed time.Duration
+
+	// RetryAfter is the delay the API asked for in the Retry-After header of
+	// the last response, usually with 429 Too Many Requests or
+	// 503 Service Unavailable, or 0 if it sent none.
+	RetryAfter time.Duration
+	// RateLimit holds the rate limit headers of the last response.
+	RateLimit RateLimit
+}
+
+// RateLimit is the state of the rate limit of the API, as told by the
+// X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers of a
+// response, or their RateLimit-* equivalents. Fields are zero when their
+// header is missing.
+type RateLimit struct {
+	// Limit is the number of requests allowed in the current window, and
+	// Remaining how many of them are left.
+	Limit     int
+	Remaining int
+	// Reset is the time the window ends and Remaining is reset to Limit.
+	Reset time.Time
+}
+
+// parseRateLimit reads the rate limit headers of a response received at now.
+// Reset is read as a Unix time if it is large enough to be one, and as a
+// number of seconds from now otherwise.
+func parseRateLimit(header http.Header, now time.Time) RateLimit {
+	value := func(name string) (int64, bool) {
+		for _, key := range []string{"X-RateLimit-" + name, "RateLimit-" + name} {
+			if n, err := strconv.ParseInt(strings.TrimSpace(header.Get(key)), 10, 64); err == nil && n >= 0 {
+				return n, true
+			}
+		}
+		return 0, false
+	}
+
+	var limit RateLimit
+	if n, ok := value("Limit"); ok {
+		limit.Limit = int(n)
+	}
+	if n, ok := value("Remaining"); ok {
+		limit.Remaining = int(n)
+	}
+	if n, ok := value("Reset"); ok {
+		if n >= 1e9 {
+			limit.Reset = time.Unix(n, 0)
+		} else {
+			limit.Reset = now.Add(time.Duration(n) * time.Second)
+		}
+	}
+	return limit
+}
+
+// newAPIError reads and closes the body of an error response.
+func newAPIError(resp *http.Response) *APIError {
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	apiErr := &APIError{
+		StatusCode:  resp.StatusCode,
+		Body:        string(body),
+		ContentType: contentType,
+		RetryAfter:  retryAfter(resp),
+		RateLimit:   parseRateLimit(resp.Header, time.Now()),
+	}
+	if !json.Valid(body) {
+		apiErr.Body = errorSnippet(apiErr.Body, contentType)
+	}
+	return apiErr
+}
+
+// htmlNoise matches the parts of an HTML page that aren't text: its doctype,
+// head, scripts, styles and comments.
+var htmlNoise = regexp.MustCompile(`(?is)<!doctype[^>]*>|<head\b.*?</head>|<script\b.*?</script>|<style\b.*?</style>|<!--.*?-->`)
+
+// errorSnippet reduces a body that isn't JSON to the start of its text, on
+// a single line.
+func errorSnippet(body string, contentType string) string {
+	if strings.Contains(contentType, "html") || strings.HasPrefix(strings.TrimSpace(body), "<") {
+		body = HTMLToText(htmlNoise.ReplaceAllString(body, " "))
+	}
+	body = strings.Join(strings.Fields(body), " ")
+	if len(body) <= maxErrorSnippet {
+		return body
+	}
+
+	cut := maxErrorSnippet
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return body[:cut] + "…"
+}
+
+// checkJSONBody returns an *APIError if resp, a successful response to a
+// typed call, is a page rather than JSON. A proxy may answer with its own
+// page, which would otherwise fail to decode with a confusing syntax error.
+// Responses are checked by their first byte, since some servers send JSON
+// as text/plain, and otherwise read in full to accept scalars such as true
+// or "ok". Empty bodies pass, and leave it to the caller.
+func checkJSONBody(resp *http.Response) error {
+	contentType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if strings.Contains(contentType, "json") {
+		return nil
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{reader, resp.Body}
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			return nil
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			reader.Discard(1)
+			continue
+		case '{', '[':
+			return nil
+		}
+
+		body, err := io.ReadAll(reader)
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{bytes.NewReader(body), resp.Body}
+		if err == nil && json.Valid(body) {
+			return nil
+		}
+		return newAPIError(resp)
+	}
+}
+
+// Wait returns how long to wait before sending the request again: the
+// delay of the Retry-After header, or else the time until the rate limit is
+// reset if no requests are left. It returns 0 if the API didn't say.
+func (e *APIError) Wait() time.Duration {
+	if e.RetryAfter > 0 {
+		return e.RetryAfter
+	}
+	if !e.RateLimit.Reset.IsZero() && e.RateLimit.Remaining == 0 {
+		if wait := time.Until(e.RateLimit.Reset); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// Error returns the status text of the response, or "401" for 401 Unauthorized.
+// If the request was retried, it also tells how many times and for how long.
+// For a successful response that isn't JSON, it returns its media type and
+// the start of its text.
+func (e *APIError) Error() string {
+	if e.StatusCode < 300 {
+		return fmt.Sprintf("unexpected %s response: %s", e.ContentType, e.Body)
+	}
+	if e.StatusCode == http.StatusUnauthorized {
+		return "401"
+	}
+	return http.StatusText(e.StatusCode) + retrySuffix(e.Attempts, e.Elapsed)
+}
+
+// RetryError is returned when a request that was retried failed without a
+// response, e.g. because the network was down. It wraps the error of the
+// last attempt.
+type RetryError struct {
+	Attempts int
+	Elapsed  time.Duration
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return e.Err.Error() + retrySuffix(e.Attempts, e.Elapsed)
+}
+
+// Unwrap returns the error of the last attempt.
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// retrySuffix describes the attempts of a request that was retried, or
+// returns an empty string if it was sent once.
+func retrySuffix(attempts int, elapsed time.Duration) string {
+	if attempts <= 1 {
+		return ""
+	}
+	return fmt.Sprintf(" (after %d attempts in %s)", attempts, elapsed.Round(time.Millisecond))
+}
+
+// Is reports whether a 409 Conflict or 412 Precondition Failed response is
+// matched by ErrConflict, a 404 Not Found response by ErrNotFound, and a
+// 429 Too Many Requests response by ErrRateLimited.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict || e.StatusCode == http.StatusPreconditionFailed
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// Temporary reports whether the request may succeed if sent again: on
+// 429 Too Many Requests, and on server errors other than 501 Not Implemented.
+// These are the responses the client retries on its own.
+func (e *APIError) Temporary() bool {
+	return retryableStatus(e.StatusCode)
+}
+
+// retryableStatus reports whether a response with status is retried.
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || (status >= 500 && status != http.StatusNotImplemented)
+}
+
+// retryableNetworkError reports whether a request that failed without a
+// response is retried. Certificates that fail verification won't pass on
+// another attempt, nor will requests redirected too many times or whose
+// middleware panicked.
+func retryableNetworkError(err error) bool {
+	var certificateErr *tls.CertificateVerificationError
+	var panicErr *PanicError
+	return !errors.As(err, &certificateErr) && !errors.Is(err, errPinMismatch) && !errors.Is(err, ErrTooManyRedirects) &&
+		!errors.As(err, &panicErr)
+}
+
+// IsRetryable reports whether a request that failed with err is worth
+// sending again later, following the policy the client applies to its own
+// retries: temporary API errors (see APIError.Temporary) and network errors,
+// including timeouts, are; canceled requests, invalid inputs and other
+// responses are not. Since the client has already retried, callers should
+// wait before trying again.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return retryableNetworkError(err)
+	}
+	return false
+}
+
+// IsTemporary is IsRetryable, under the name used by frameworks that follow
+// net.Error.
+func IsTemporary(err error) bool {
+	return IsRetryable(err)
+}
+
+// ConflictError reports that a resource was changed since the version an
+// update was based on.
+type ConflictError struct {
+	// Resource is what was changed, such as "task 7".
+	Resource string
+	Expected DateTime
+	Actual   DateTime
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s was updated at %s, expected %s", e.Resource, e.Actual.Format(time.RFC3339), e.Expected.Format(time.RFC3339))
+}
+
+// Is makes ConflictError match ErrConflict.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
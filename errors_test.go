@@ -0,0 +1,158 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	cases := []struct {
+		err       error
+		retryable bool
+	}{
+		{nil, false},
+		{&APIError{StatusCode: 429}, true},
+		{&APIError{StatusCode: 503}, true},
+		{fmt.Errorf("reading tasks: %w", &APIError{StatusCode: 502}), true},
+		{&APIError{StatusCode: 501}, false},
+		{&APIError{StatusCode: 404}, false},
+		{&APIError{StatusCode: 401}, false},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{context.DeadlineExceeded, true},
+		{context.Canceled, false},
+		{fmt.Errorf("handshake: %w", errPinMismatch), false},
+		{&ValidationError{}, false},
+	}
+
+	for _, c := range cases {
+		if IsRetryable(c.err) != c.retryable || IsTemporary(c.err) != c.retryable {
+			t.Errorf("IsRetryable(%v): expected %v", c.err, c.retryable)
+		}
+	}
+}
+
+func TestAPIErrorAttempts(t *testing.T) {
+	calls := 0
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "bad date")
+	})
+
+	_, err := Get(pa, pa.baseURL)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+	if apiErr.Attempts != 2 || apiErr.Elapsed <= 0 || apiErr.Body != "bad date" {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+	if !strings.HasPrefix(err.Error(), "Bad Request (after 2 attempts in ") {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestAPIErrorRateLimit(t *testing.T) {
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header: http.Header{
+			"Retry-After":           {"30"},
+			"X-Ratelimit-Limit":     {"100"},
+			"X-Ratelimit-Remaining": {"0"},
+			"X-Ratelimit-Reset":     {"1700000000"},
+		},
+		Body: io.NopCloser(strings.NewReader("slow down")),
+	}
+
+	err := fmt.Errorf("listing tasks: %w", newAPIError(resp))
+	if !errors.Is(err, ErrRateLimited) || errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected a rate limit error, got %v", err)
+	}
+
+	var apiErr *APIError
+	errors.As(err, &apiErr)
+	if apiErr.RetryAfter != 30*time.Second || apiErr.Wait() != 30*time.Second {
+		t.Errorf("unexpected retry after %s", apiErr.RetryAfter)
+	}
+	if apiErr.RateLimit.Limit != 100 || apiErr.RateLimit.Remaining != 0 || !apiErr.RateLimit.Reset.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("unexpected rate limit %+v", apiErr.RateLimit)
+	}
+}
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	limit := parseRateLimit(http.Header{"Ratelimit-Remaining": {"3"}, "Ratelimit-Reset": {"20"}}, now)
+	if limit.Limit != 0 || limit.Remaining != 3 || !limit.Reset.Equal(now.Add(20*time.Second)) {
+		t.Errorf("unexpected rate limit %+v", limit)
+	}
+
+	if limit := parseRateLimit(http.Header{"X-Ratelimit-Limit": {"many"}}, now); limit != (RateLimit{}) {
+		t.Errorf("expected no rate limit, got %+v", limit)
+	}
+
+	wait := (&APIError{RateLimit: RateLimit{Reset: time.Now().Add(time.Minute)}}).Wait()
+	if wait <= 50*time.Second || wait > time.Minute {
+		t.Errorf("expected to wait until the reset, got %s", wait)
+	}
+}
+
+func TestHTMLErrorPages(t *testing.T) {
+	page := `<!DOCTYPE html><html><head><title>502</title><style>body{color:red}</style></head>
+<body><h1>502 Bad Gateway</h1><script>track()</script><p>nginx</p></body></html>`
+
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if r.URL.Path == "/api/v5/1/tasks/1" {
+			// A captive portal answering in place of the API
+			fmt.Fprint(w, `<html><body>Please sign in to the network</body></html>`)
+			return
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, page)
+	})
+
+	var apiErr *APIError
+	if _, err := GetProjects(context.Background(), pa, 1); !errors.As(err, &apiErr) {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+	if apiErr.ContentType != "text/html" || apiErr.Body != "502 Bad Gateway nginx" {
+		t.Errorf("unexpected error %+v", apiErr)
+	}
+
+	_, err := GetTask(context.Background(), pa, 1, 1)
+	if !errors.As(err, &apiErr) || err.Error() != "unexpected text/html response: Please sign in to the network" {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// Scalars are valid JSON, whatever the media type
+	for body, valid := range map[string]bool{"true": true, ` "ok"`: true, "42\n": true, "null": true, "ok": false, "": true} {
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"text/plain"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}
+		if err := checkJSONBody(resp); (err == nil) != valid {
+			t.Errorf("%q: unexpected error %v", body, err)
+			continue
+		}
+		if rest, _ := io.ReadAll(resp.Body); valid && strings.TrimSpace(string(rest)) != strings.TrimSpace(body) {
+			t.Errorf("%q: expected the body to be kept, got %q", body, rest)
+		}
+	}
+
+	if snippet := errorSnippet(strings.Repeat("é", 200), "text/plain"); len(snippet) != 300+len("…") {
+		t.Errorf("unexpected snippet length %d", len(snippet))
+	}
+}
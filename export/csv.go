@@ -0,0 +1,144 @@
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"togglplanapi"
+)
+
+// taskColumns is the header row written by WriteTasksCSV.
+var taskColumns = []string{"id", "title", "project", "assignees", "start", "end", "done", "tags"}
+
+// projectColumns is the header row written by WriteProjectsCSV.
+var projectColumns = []string{"id", "name", "start", "end", "archived"}
+
+// ExportTasksCSV fetches the tasks of a workspace that match filter, and writes them to w as CSV.
+// Long date ranges are fetched in windows using togglplanapi.ListAllTasks.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	filter: Date range and optional project/member restrictions
+//	w: Destination of the CSV
+func ExportTasksCSV(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, filter togglplanapi.TaskFilter, w io.Writer) error {
+	tasks, err := togglplanapi.ListAllTasks(ctx, pa, workspaceId, filter)
+	if err != nil {
+		return err
+	}
+
+	projects, err := togglplanapi.GetProjects(ctx, pa, workspaceId)
+	if err != nil {
+		return err
+	}
+
+	return WriteTasksCSV(w, tasks, projects)
+}
+
+// TasksCSV is ExportTasksCSV under its former name.
+//
+// Deprecated: Use ExportTasksCSV.
+func TasksCSV(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, filter togglplanapi.TaskFilter, w io.Writer) error {
+	return ExportTasksCSV(ctx, pa, workspaceId, filter, w)
+}
+
+// WriteTasksCSV writes tasks to w as CSV, starting with a header row.
+//
+// The columns are id, title, project, assignees, start, end, done and tags.
+// The project column holds the project's name, or is empty if the task isn't
+// part of a known project. Assignees (workspace member IDs) and tags are
+// separated by semicolons. Start and end are dates in YYYY-MM-DD format,
+// followed by the time of day for timed tasks.
+// Arguments:
+//
+//	w: Destination of the CSV
+//	tasks: Tasks to write
+//	projects: Projects used to look up project names (may be nil)
+func WriteTasksCSV(w io.Writer, tasks []togglplanapi.Task, projects []togglplanapi.Project) error {
+	projectNames := map[togglplanapi.ID]string{}
+	for _, project := range projects {
+		projectNames[project.Id] = project.Name
+	}
+
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(taskColumns); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		assignees := make([]string, len(task.Assignees))
+		for i, id := range task.Assignees {
+			assignees[i] = id.String()
+		}
+
+		record := []string{
+			task.Id.String(),
+			task.Name,
+			projectNames[task.ProjectId],
+			strings.Join(assignees, ";"),
+			strings.TrimSpace(task.StartDate.String() + " " + task.StartTime),
+			strings.TrimSpace(task.EndDate.String() + " " + task.EndTime),
+			strconv.FormatBool(task.Done),
+			strings.Join(task.Tags, ";"),
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ProjectsCSV fetches the projects of a workspace and writes them to w as CSV.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	w: Destination of the CSV
+func ProjectsCSV(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, w io.Writer) error {
+	projects, err := togglplanapi.GetProjects(ctx, pa, workspaceId)
+	if err != nil {
+		return err
+	}
+
+	return WriteProjectsCSV(w, projects)
+}
+
+// WriteProjectsCSV writes projects to w as CSV, starting with a header row.
+// The columns are id, name, start, end and archived.
+// Arguments:
+//
+//	w: Destination of the CSV
+//	projects: Projects to write
+func WriteProjectsCSV(w io.Writer, projects []togglplanapi.Project) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(projectColumns); err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		record := []string{
+			project.Id.String(),
+			project.Name,
+			project.StartDate.String(),
+			project.EndDate.String(),
+			strconv.FormatBool(project.Archived),
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,29 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"togglplanapi"
+)
+
+func TestWriteTasksCSV(t *testing.T) {
+	tasks := []togglplanapi.Task{
+		{Id: 1, Name: "Plan, then build", ProjectId: 5, Assignees: []togglplanapi.ID{3, 4}, StartDate: togglplanapi.NewDate(2024, 3, 4), EndDate: togglplanapi.NewDate(2024, 3, 5), Tags: []string{"a", "b"}},
+		{Id: 2, Name: "Standup", StartDate: togglplanapi.NewDate(2024, 3, 4), EndDate: togglplanapi.NewDate(2024, 3, 4), StartTime: "09:00", EndTime: "09:15", Done: true},
+	}
+	projects := []togglplanapi.Project{{Id: 5, Name: "Website"}}
+
+	var buf bytes.Buffer
+	if err := WriteTasksCSV(&buf, tasks, projects); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "id,title,project,assignees,start,end,done,tags\n" +
+		"1,\"Plan, then build\",Website,3;4,2024-03-04,2024-03-05,false,a;b\n" +
+		"2,Standup,,,2024-03-04 09:00,2024-03-04 09:15,true,\n"
+
+	if buf.String() != expected {
+		t.Fatalf("unexpected CSV:\n%s", buf.String())
+	}
+}
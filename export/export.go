@@ -0,0 +1,30 @@
+/*
+Package export converts Toggl Plan data into formats understood by other
+tools, such as calendar applications.
+
+Example Usage:
+
+	import (
+		"context"
+		"os"
+		"time"
+
+		"github.com/ricotheque/togglplanapi"
+		"github.com/ricotheque/togglplanapi/export"
+	)
+
+	func main() {
+		pa := togglplanapi.New(username, password, clientId, clientSecret, "")
+
+		today := togglplanapi.Today(time.Local)
+
+		filter := togglplanapi.TaskFilter{
+			Since: today,
+			Until: today.AddDate(0, 1, 0),
+		}
+
+		// Write next month's tasks and milestones as an .ics file
+		err := export.ICS(context.Background(), pa, workspaceId, filter, time.Local, os.Stdout)
+	}
+*/
+package export
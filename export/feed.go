@@ -0,0 +1,199 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"togglplanapi"
+)
+
+// FeedOptions configures a FeedHandler.
+type FeedOptions struct {
+	// Location is the time zone that task start and end times are expressed in.
+	// Defaults to UTC.
+	Location *time.Location
+
+	// CacheFor is how long a generated feed is served before it is fetched again.
+	// Defaults to 15 minutes.
+	CacheFor time.Duration
+
+	// DaysBack and DaysAhead set the window of tasks included in a feed,
+	// relative to the current day. Default to 30 and 180 days.
+	DaysBack  int
+	DaysAhead int
+
+	// IncludeMilestones adds the workspace's milestones to every feed.
+	IncludeMilestones bool
+}
+
+// FeedHandler is an http.Handler serving a live iCalendar feed per workspace
+// member, so that they can subscribe to their schedule in a calendar app.
+//
+// Each member is reachable under a secret URL ending in /<secret>.ics.
+// Anyone who knows the secret can read the member's schedule, so secrets
+// should be long and random (see NewFeedSecret).
+type FeedHandler struct {
+	workspaceId togglplanapi.ID
+	secrets     map[string]togglplanapi.ID
+	options     FeedOptions
+
+	// fetch builds the calendar of a member, and is replaced in tests.
+	fetch func(ctx context.Context, memberId togglplanapi.ID) ([]byte, error)
+	now   func() time.Time
+
+	mu    sync.Mutex
+	cache map[togglplanapi.ID]cachedFeed
+}
+
+// cachedFeed is a generated calendar and the time it was generated.
+type cachedFeed struct {
+	body      []byte
+	fetchedAt time.Time
+}
+
+// NewFeedHandler returns a FeedHandler serving the schedules of workspace members.
+// Arguments:
+//
+//	pa: togglPlanApi instance used to fetch tasks
+//	workspaceId: ID of the workspace
+//	secrets: Map of URL secrets to the workspace member ID whose tasks they serve
+//	options: Feed settings (use `export.FeedOptions{}` for the defaults)
+func NewFeedHandler(pa *togglplanapi.Client, workspaceId togglplanapi.ID, secrets map[string]togglplanapi.ID, options FeedOptions) *FeedHandler {
+	if options.Location == nil {
+		options.Location = time.UTC
+	}
+	if options.CacheFor <= 0 {
+		options.CacheFor = 15 * time.Minute
+	}
+	if options.DaysBack <= 0 {
+		options.DaysBack = 30
+	}
+	if options.DaysAhead <= 0 {
+		options.DaysAhead = 180
+	}
+
+	fh := &FeedHandler{
+		workspaceId: workspaceId,
+		secrets:     secrets,
+		options:     options,
+		now:         time.Now,
+		cache:       map[togglplanapi.ID]cachedFeed{},
+	}
+
+	fh.fetch = func(ctx context.Context, memberId togglplanapi.ID) ([]byte, error) {
+		today := togglplanapi.DateOf(fh.now().In(options.Location))
+
+		filter := togglplanapi.TaskFilter{
+			Since:     today.AddDays(-options.DaysBack),
+			Until:     today.AddDays(options.DaysAhead),
+			MemberIds: []togglplanapi.ID{memberId},
+		}
+
+		tasks, err := togglplanapi.GetTasks(ctx, pa, workspaceId, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		var milestones []togglplanapi.Milestone
+		if options.IncludeMilestones {
+			all, err := togglplanapi.GetMilestones(ctx, pa, workspaceId)
+			if err != nil {
+				return nil, err
+			}
+			milestones = filterMilestones(all, filter)
+		}
+
+		var buf bytes.Buffer
+		if err := WriteICS(&buf, tasks, milestones, options.Location); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	return fh
+}
+
+// ServeHTTP serves the feed whose secret matches the last segment of the request path.
+func (fh *FeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	memberId, ok := fh.lookup(strings.TrimSuffix(path.Base(r.URL.Path), ".ics"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := fh.feed(r.Context(), memberId)
+	if err != nil {
+		http.Error(w, "Couldn't load the calendar", http.StatusBadGateway)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Cache-Control", "private, max-age="+strconv.FormatInt(int64(fh.options.CacheFor/time.Second), 10))
+	w.Write(body)
+}
+
+// lookup returns the member ID for secret.
+// Secrets are compared in constant time so that they can't be guessed from response timings.
+func (fh *FeedHandler) lookup(secret string) (togglplanapi.ID, bool) {
+	var memberId togglplanapi.ID
+	found := false
+
+	for candidate, id := range fh.secrets {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(secret)) == 1 {
+			memberId = id
+			found = true
+		}
+	}
+
+	return memberId, found && secret != ""
+}
+
+// feed returns the calendar of a member, from the cache if it is fresh enough.
+func (fh *FeedHandler) feed(ctx context.Context, memberId togglplanapi.ID) ([]byte, error) {
+	fh.mu.Lock()
+	cached, ok := fh.cache[memberId]
+	fh.mu.Unlock()
+
+	if ok && fh.now().Sub(cached.fetchedAt) < fh.options.CacheFor {
+		return cached.body, nil
+	}
+
+	body, err := fh.fetch(ctx, memberId)
+	if err != nil {
+		// A stale calendar is more useful to subscribers than an error
+		if ok {
+			return cached.body, nil
+		}
+		return nil, err
+	}
+
+	fh.mu.Lock()
+	fh.cache[memberId] = cachedFeed{body: body, fetchedAt: fh.now()}
+	fh.mu.Unlock()
+
+	return body, nil
+}
+
+// NewFeedSecret returns a random secret suitable for a feed URL.
+func NewFeedSecret() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
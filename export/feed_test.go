@@ -0,0 +1,81 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"togglplanapi"
+	"togglplanapi/internal/testclient"
+)
+
+func TestFeedHandler(t *testing.T) {
+	fh := NewFeedHandler(nil, 1, map[string]togglplanapi.ID{"s3cret": 7}, FeedOptions{CacheFor: time.Minute})
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	fh.now = func() time.Time { return now }
+
+	fetches := 0
+	failing := false
+	fh.fetch = func(ctx context.Context, memberId togglplanapi.ID) ([]byte, error) {
+		if memberId != 7 {
+			t.Errorf("fetched member %d", memberId)
+		}
+		if failing {
+			return nil, errors.New("unreachable")
+		}
+		fetches++
+		return []byte("BEGIN:VCALENDAR\r\n"), nil
+	}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		fh.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+		return rec
+	}
+
+	if rec := get("/calendars/s3cret.ics"); rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/calendar; charset=utf-8" {
+		t.Fatalf("unexpected response %d %v", rec.Code, rec.Header())
+	}
+
+	if rec := get("/calendars/guess.ics"); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown secret returned %d", rec.Code)
+	}
+
+	get("/calendars/s3cret.ics")
+	if fetches != 1 {
+		t.Fatalf("expected the cached feed to be served, fetched %d times", fetches)
+	}
+
+	// Once expired, a failed refresh falls back to the stale feed
+	now = now.Add(2 * time.Minute)
+	failing = true
+	if rec := get("/calendars/s3cret.ics"); rec.Code != http.StatusOK {
+		t.Fatalf("stale feed not served, got %d", rec.Code)
+	}
+}
+
+func TestFeedHandlerUndatedTask(t *testing.T) {
+	pa := testclient.New(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/1/tasks" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `[{"id":1,"name":"Someday"},{"id":2,"name":"Review","start_date":"2024-03-04","end_date":"2024-03-04"}]`)
+	})
+
+	fh := NewFeedHandler(pa, 1, map[string]togglplanapi.ID{"s3cret": 7}, FeedOptions{})
+
+	rec := httptest.NewRecorder()
+	fh.ServeHTTP(rec, httptest.NewRequest("GET", "/calendars/s3cret.ics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d", rec.Code)
+	}
+	if body := rec.Body.String(); strings.Contains(body, "UID:task-1@") || !strings.Contains(body, "UID:task-2@") {
+		t.Fatalf("expected the dated task only\n%s", body)
+	}
+}
@@ -0,0 +1,247 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"togglplanapi"
+)
+
+// ICS fetches the tasks and milestones of a workspace that match filter, and
+// writes them to w as an RFC 5545 iCalendar document.
+// Milestones are restricted to the filter's date range and projects.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	filter: Date range and optional project/member restrictions
+//	loc: Time zone that task start and end times are expressed in
+//	w: Destination of the calendar
+func ICS(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, filter togglplanapi.TaskFilter, loc *time.Location, w io.Writer) error {
+	tasks, err := togglplanapi.GetTasks(ctx, pa, workspaceId, filter)
+	if err != nil {
+		return err
+	}
+
+	milestones, err := togglplanapi.GetMilestones(ctx, pa, workspaceId)
+	if err != nil {
+		return err
+	}
+
+	return WriteICS(w, tasks, filterMilestones(milestones, filter), loc)
+}
+
+// WriteICS writes tasks and milestones to w as an RFC 5545 iCalendar document.
+//
+// Tasks without start and end times, as well as milestones, become all-day
+// events. Tasks without dates, such as those of the backlog, are left out,
+// as a calendar has nowhere to show them. Tasks may include occurrences of recurring tasks expanded with
+// togglplanapi.ExpandRecurring. Timed tasks are interpreted in loc and written in UTC, so that
+// calendar applications show them at the right time regardless of their own
+// time zone settings.
+// Arguments:
+//
+//	w: Destination of the calendar
+//	tasks: Tasks to include
+//	milestones: Milestones to include
+//	loc: Time zone that task start and end times are expressed in
+func WriteICS(w io.Writer, tasks []togglplanapi.Task, milestones []togglplanapi.Milestone, loc *time.Location) error {
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	cw := &calendarWriter{w: bufio.NewWriter(w)}
+
+	cw.line("BEGIN:VCALENDAR")
+	cw.line("VERSION:2.0")
+	cw.line("PRODID:-//ricotheque//togglplanapi//EN")
+	cw.line("CALSCALE:GREGORIAN")
+
+	// Occurrences of recurring tasks share the ID of their task
+	occurrences := map[togglplanapi.ID]int{}
+	for _, task := range tasks {
+		occurrences[task.Id]++
+	}
+
+	for _, task := range tasks {
+		if task.StartDate.IsZero() || task.EndDate.IsZero() {
+			continue
+		}
+		if err := writeTaskEvent(cw, task, occurrences[task.Id] > 1, loc); err != nil {
+			return err
+		}
+	}
+
+	for _, milestone := range milestones {
+		if err := writeMilestoneEvent(cw, milestone); err != nil {
+			return err
+		}
+	}
+
+	cw.line("END:VCALENDAR")
+
+	if cw.err != nil {
+		return cw.err
+	}
+	return cw.w.Flush()
+}
+
+// writeTaskEvent writes a single task as a VEVENT. The UID of an occurrence
+// of a recurring task includes its start date, to tell occurrences apart.
+func writeTaskEvent(cw *calendarWriter, task togglplanapi.Task, occurrence bool, loc *time.Location) error {
+	cw.line("BEGIN:VEVENT")
+	if occurrence {
+		cw.line(fmt.Sprintf("UID:task-%d-%s@plan.toggl.com", task.Id, formatDate(task.StartDate)))
+	} else {
+		cw.line(fmt.Sprintf("UID:task-%d@plan.toggl.com", task.Id))
+	}
+	cw.line("DTSTAMP:" + formatUTC(stamp(task.UpdatedAt.Time)))
+
+	if task.StartTime == "" || task.EndTime == "" {
+		// DTEND is exclusive for all-day events, while Toggl Plan end dates are inclusive
+		cw.line("DTSTART;VALUE=DATE:" + formatDate(task.StartDate))
+		cw.line("DTEND;VALUE=DATE:" + formatDate(task.EndDate.AddDays(1)))
+	} else {
+		startAt, err := togglplanapi.AtClock(task.StartDate, task.StartTime, loc)
+		if err != nil {
+			return fmt.Errorf("task %d: invalid start time %q", task.Id, task.StartTime)
+		}
+
+		endAt, err := togglplanapi.AtClock(task.EndDate, task.EndTime, loc)
+		if err != nil {
+			return fmt.Errorf("task %d: invalid end time %q", task.Id, task.EndTime)
+		}
+
+		cw.line("DTSTART:" + formatUTC(startAt))
+		cw.line("DTEND:" + formatUTC(endAt))
+	}
+
+	cw.line("SUMMARY:" + escapeText(task.Name))
+	if task.Notes != "" {
+		cw.line("DESCRIPTION:" + escapeText(task.Notes))
+	}
+	if len(task.Tags) > 0 {
+		escaped := make([]string, len(task.Tags))
+		for i, tag := range task.Tags {
+			escaped[i] = escapeText(tag)
+		}
+		cw.line("CATEGORIES:" + strings.Join(escaped, ","))
+	}
+	cw.line("END:VEVENT")
+
+	return nil
+}
+
+// writeMilestoneEvent writes a single milestone as an all-day VEVENT.
+func writeMilestoneEvent(cw *calendarWriter, milestone togglplanapi.Milestone) error {
+	if milestone.Date.IsZero() {
+		return fmt.Errorf("milestone %d: has no date", milestone.Id)
+	}
+
+	cw.line("BEGIN:VEVENT")
+	cw.line(fmt.Sprintf("UID:milestone-%d@plan.toggl.com", milestone.Id))
+	cw.line("DTSTAMP:" + formatUTC(stamp(milestone.UpdatedAt.Time)))
+	cw.line("DTSTART;VALUE=DATE:" + formatDate(milestone.Date))
+	cw.line("DTEND;VALUE=DATE:" + formatDate(milestone.Date.AddDays(1)))
+	cw.line("SUMMARY:" + escapeText(milestone.Name))
+	cw.line("CATEGORIES:Milestone")
+	cw.line("END:VEVENT")
+
+	return nil
+}
+
+// filterMilestones keeps the milestones that fall within the filter's date
+// range and, if the filter names projects, belong to one of them.
+func filterMilestones(milestones []togglplanapi.Milestone, filter togglplanapi.TaskFilter) []togglplanapi.Milestone {
+	projects := map[togglplanapi.ID]bool{}
+	for _, id := range filter.ProjectIds {
+		projects[id] = true
+	}
+
+	var result []togglplanapi.Milestone
+	for _, milestone := range milestones {
+		if !filter.Since.IsZero() && milestone.Date.Before(filter.Since) {
+			continue
+		}
+		if !filter.Until.IsZero() && milestone.Date.After(filter.Until) {
+			continue
+		}
+		if len(projects) > 0 && !projects[milestone.ProjectId] {
+			continue
+		}
+		result = append(result, milestone)
+	}
+
+	return result
+}
+
+// formatDate formats date as an iCalendar DATE.
+func formatDate(date togglplanapi.Date) string {
+	return date.In(time.UTC).Format("20060102")
+}
+
+// stamp returns t, or the current time if t is not set.
+func stamp(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
+
+// formatUTC formats t as an iCalendar UTC date-time.
+func formatUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// escapeText escapes a value of the iCalendar TEXT type.
+func escapeText(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(text)
+}
+
+// calendarWriter writes iCalendar content lines, folding them at 75 octets
+// and terminating them with CRLF as required by RFC 5545.
+// The first write error is kept in err and later writes are skipped.
+type calendarWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a single content line.
+func (cw *calendarWriter) line(content string) {
+	if cw.err != nil {
+		return
+	}
+
+	limit := 75
+	for len(content) > limit {
+		// Never split a multi-byte character across lines
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(content[cut]) {
+			cut--
+		}
+
+		_, cw.err = cw.w.WriteString(content[:cut] + "\r\n ")
+		if cw.err != nil {
+			return
+		}
+		content = content[cut:]
+
+		// Continuation lines start with a space, which counts towards the limit
+		limit = 74
+	}
+
+	_, cw.err = cw.w.WriteString(content + "\r\n")
+}
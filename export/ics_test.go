@@ -0,0 +1,92 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"togglplanapi"
+)
+
+func TestWriteICS(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+
+	updated := togglplanapi.DateTime{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	tasks := []togglplanapi.Task{
+		{Id: 1, Name: "Write report, draft", StartDate: togglplanapi.NewDate(2024, 3, 4), EndDate: togglplanapi.NewDate(2024, 3, 5), UpdatedAt: updated},
+		{Id: 2, Name: "Standup", StartDate: togglplanapi.NewDate(2024, 7, 1), EndDate: togglplanapi.NewDate(2024, 7, 1), StartTime: "09:00", EndTime: "09:15", UpdatedAt: updated},
+		{Id: 3, Name: strings.Repeat("long ", 20), StartDate: togglplanapi.NewDate(2024, 3, 4), EndDate: togglplanapi.NewDate(2024, 3, 4), UpdatedAt: updated},
+	}
+
+	milestones := []togglplanapi.Milestone{
+		{Id: 9, Name: "Launch", Date: togglplanapi.NewDate(2024, 3, 31), UpdatedAt: updated},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, tasks, milestones, berlin); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	expected := []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:task-1@plan.toggl.com\r\n",
+		"DTSTART;VALUE=DATE:20240304\r\nDTEND;VALUE=DATE:20240306\r\n",
+		"SUMMARY:Write report\\, draft\r\n",
+		// 09:00 in Berlin during summer time is 07:00 UTC
+		"DTSTART:20240701T070000Z\r\nDTEND:20240701T071500Z\r\n",
+		"UID:milestone-9@plan.toggl.com\r\n",
+		"DTSTART;VALUE=DATE:20240331\r\nDTEND;VALUE=DATE:20240401\r\n",
+		"END:VCALENDAR\r\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(out, e) {
+			t.Errorf("output is missing %q\n%s", e, out)
+		}
+	}
+
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > 75 {
+			t.Errorf("line is not folded: %q", line)
+		}
+	}
+}
+
+func TestWriteICSMissingDate(t *testing.T) {
+	tasks := []togglplanapi.Task{
+		{Id: 1, EndDate: togglplanapi.NewDate(2024, 1, 1)},
+		{Id: 2, StartDate: togglplanapi.NewDate(2024, 1, 1), EndDate: togglplanapi.NewDate(2024, 1, 1)},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, tasks, nil, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); strings.Contains(out, "UID:task-1@") || !strings.Contains(out, "UID:task-2@") {
+		t.Fatalf("expected the undated task to be left out\n%s", out)
+	}
+}
+
+func TestWriteICSOccurrences(t *testing.T) {
+	recurring := togglplanapi.RecurringTask{
+		Task:       togglplanapi.Task{Id: 1, Name: "Review", StartDate: togglplanapi.NewDate(2024, 3, 4), EndDate: togglplanapi.NewDate(2024, 3, 4)},
+		Recurrence: togglplanapi.Recurrence{Frequency: togglplanapi.EveryWeek},
+	}
+	tasks := recurring.Expand(togglplanapi.NewDate(2024, 3, 1), togglplanapi.NewDate(2024, 3, 17))
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, tasks, nil, time.UTC); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, uid := range []string{"UID:task-1-20240304@", "UID:task-1-20240311@"} {
+		if !strings.Contains(buf.String(), uid) {
+			t.Errorf("output is missing %q\n%s", uid, buf.String())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package togglplanapi
+
+import (
+	"strings"
+)
+
+// externalIdLabel starts the line of a task's notes holding its external ID.
+const externalIdLabel = "external-id: "
+
+// ExternalId returns the external ID stored in a task's notes by
+// SetExternalId, or an empty string if there is none.
+//
+// Toggl Plan has no custom fields, so tools mirroring other systems (e.g.
+// issue trackers) keep the ID of the mirrored item on a line of the notes,
+// where it survives edits made in the Toggl Plan apps.
+// Arguments:
+//
+//	notes: Notes of the task
+func ExternalId(notes string) string {
+	for _, line := range strings.Split(notes, "\n") {
+		if strings.HasPrefix(line, externalIdLabel) {
+			return strings.TrimSpace(strings.TrimPrefix(line, externalIdLabel))
+		}
+	}
+	return ""
+}
+
+// SetExternalId returns notes with its external ID line set to id, replacing
+// any existing one. If id is empty, the line is removed.
+// Arguments:
+//
+//	notes: Notes of the task
+//	id: External ID to store
+func SetExternalId(notes string, id string) string {
+	var lines []string
+	for _, line := range strings.Split(notes, "\n") {
+		if !strings.HasPrefix(line, externalIdLabel) {
+			lines = append(lines, line)
+		}
+	}
+
+	result := strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	if id == "" {
+		return result
+	}
+	if result != "" {
+		result += "\n\n"
+	}
+	return result + externalIdLabel + id
+}
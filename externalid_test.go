@@ -0,0 +1,29 @@
+package togglplanapi
+
+import (
+	"testing"
+)
+
+func TestExternalId(t *testing.T) {
+	notes := SetExternalId("Fix the login form.", "github:acme/web#12")
+	if notes != "Fix the login form.\n\nexternal-id: github:acme/web#12" {
+		t.Fatalf("unexpected notes %q", notes)
+	}
+
+	if id := ExternalId(notes); id != "github:acme/web#12" {
+		t.Fatalf("ExternalId() = %q", id)
+	}
+
+	notes = SetExternalId(notes, "github:acme/web#13")
+	if id := ExternalId(notes); id != "github:acme/web#13" {
+		t.Fatalf("ExternalId() after replacing = %q", id)
+	}
+
+	if notes := SetExternalId(notes, ""); notes != "Fix the login form." {
+		t.Fatalf("unexpected notes after removing the ID %q", notes)
+	}
+
+	if id := ExternalId("No ID here"); id != "" {
+		t.Fatalf("ExternalId() without an ID = %q", id)
+	}
+}
@@ -0,0 +1,160 @@
+package togglplanapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// jsonFields maps the JSON names of the fields of a struct type to their index.
+func jsonFields(t reflect.Type) map[string]int {
+	fields := map[string]int{}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields[name] = i
+		}
+	}
+	return fields
+}
+
+// checkFields reports fields that are not JSON fields of T, and a sort key
+// that isn't one either, or whose values can't be compared. An empty sort
+// key is valid.
+func checkFields[T any](fields []string, sortKey string) error {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	known := jsonFields(t)
+	for _, field := range fields {
+		if _, ok := known[field]; !ok {
+			return fmt.Errorf("unknown field %q", field)
+		}
+	}
+
+	if key := strings.TrimPrefix(sortKey, "-"); key != "" {
+		index, ok := known[key]
+		if !ok {
+			return fmt.Errorf("unknown sort field %q", key)
+		}
+		if !sortable(t.Field(index).Type) {
+			return fmt.Errorf("can't sort by field %q", key)
+		}
+	}
+	return nil
+}
+
+// selectFields zeroes the fields of items that are not listed by their JSON
+// name, keeping the ID. Nothing is changed if fields is empty.
+func selectFields[T any](items []T, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+
+	known := jsonFields(reflect.TypeOf((*T)(nil)).Elem())
+	keep := map[int]bool{known["id"]: true}
+	for _, field := range fields {
+		if i, ok := known[field]; ok {
+			keep[i] = true
+		}
+	}
+
+	for i := range items {
+		value := reflect.ValueOf(&items[i]).Elem()
+		for j := 0; j < value.NumField(); j++ {
+			if !keep[j] {
+				value.Field(j).Set(reflect.Zero(value.Field(j).Type()))
+			}
+		}
+	}
+}
+
+// sortByField sorts items by the field with the JSON name key, or in
+// descending order if key starts with "-". Items that compare equal keep
+// their order. Nothing is changed if key is empty or unknown.
+func sortByField[T any](items []T, key string) {
+	descending := strings.HasPrefix(key, "-")
+	index, ok := jsonFields(reflect.TypeOf((*T)(nil)).Elem())[strings.TrimPrefix(key, "-")]
+	if !ok {
+		return
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		a := reflect.ValueOf(items[i]).Field(index)
+		b := reflect.ValueOf(items[j]).Field(index)
+		if descending {
+			return compareValues(b, a) < 0
+		}
+		return compareValues(a, b) < 0
+	})
+}
+
+// sortable reports whether compareValues compares values of type t.
+func sortable(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeOf(Date{}), reflect.TypeOf(DateTime{}), reflect.TypeOf(time.Time{}):
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// compareValues compares two values of a sortable field, returning -1, 0 or +1.
+// Strings are compared without case, and unset dates sort first. It panics
+// for values that aren't sortable, which checkFields rejects.
+func compareValues(a reflect.Value, b reflect.Value) int {
+	switch a := a.Interface().(type) {
+	case Date:
+		return a.Compare(b.Interface().(Date))
+	case DateTime:
+		return a.Compare(b.Interface().(DateTime).Time)
+	case time.Time:
+		return a.Compare(b.Interface().(time.Time))
+	}
+
+	switch a.Kind() {
+	case reflect.String:
+		return strings.Compare(strings.ToLower(a.String()), strings.ToLower(b.String()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch {
+		case a.Int() < b.Int():
+			return -1
+		case a.Int() > b.Int():
+			return 1
+		}
+		return 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch {
+		case a.Uint() < b.Uint():
+			return -1
+		case a.Uint() > b.Uint():
+			return 1
+		}
+		return 0
+	case reflect.Float32, reflect.Float64:
+		switch {
+		case a.Float() < b.Float():
+			return -1
+		case a.Float() > b.Float():
+			return 1
+		}
+		return 0
+	case reflect.Bool:
+		switch {
+		case a.Bool() == b.Bool():
+			return 0
+		case b.Bool():
+			return -1
+		default:
+			return 1
+		}
+	}
+	panic(fmt.Sprintf("togglplanapi: can't compare values of type %s", a.Type()))
+}
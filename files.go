@@ -0,0 +1,120 @@
+package togglplanapi
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+)
+
+// Attachment describes a file attached to a task.
+type Attachment struct {
+	Id         ID       `json:"id"`
+	TaskId     ID       `json:"task_id"`
+	Name       string   `json:"name"`
+	Size       int64    `json:"size"`
+	MimeType   string   `json:"mime_type"`
+	UploaderId ID       `json:"workspace_member_id"`
+	URL        string   `json:"url"`
+	CreatedAt  DateTime `json:"created_at"`
+}
+
+// GetAttachments fetches the metadata of the files attached to a task.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	taskId: ID of the task
+func GetAttachments(ctx context.Context, pa *togglPlanApi, workspaceId ID, taskId ID) ([]Attachment, error) {
+	var attachments []Attachment
+	err := getJSON(ctx, pa, workspacePath(workspaceId, "/tasks/%d/attachments", taskId), nil, &attachments)
+	return attachments, err
+}
+
+// GetAttachment fetches the metadata of a single attached file. If it
+// doesn't exist, the error matches ErrNotFound, or the attachment is nil,
+// see WithNotFoundAsNil.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	attachmentId: ID of the attachment
+func GetAttachment(ctx context.Context, pa *togglPlanApi, workspaceId ID, attachmentId ID) (*Attachment, error) {
+	return getOne[Attachment](ctx, pa, workspacePath(workspaceId, "/attachments/%d", attachmentId))
+}
+
+// UploadAvatar replaces the avatar of the user the client is authenticated
+// as, and returns the updated user. The image is read into memory so that the
+// upload can be retried.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	filename: Name of the image file, whose extension gives its type (e.g. "me.png")
+//	image: Content of the image
+func UploadAvatar(ctx context.Context, pa *togglPlanApi, filename string, image io.Reader) (*Me, error) {
+	var me Me
+	if err := uploadFile(ctx, pa, "/me/avatar", "avatar", filename, image, &me); err != nil {
+		return nil, err
+	}
+	return &me, nil
+}
+
+// DeleteAvatar removes the avatar of the user the client is authenticated as.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+func DeleteAvatar(ctx context.Context, pa *togglPlanApi) error {
+	return sendJSON(ctx, pa, "DELETE", "/me/avatar", nil, nil, nil)
+}
+
+// uploadFile sends a file as a multipart form field, and decodes the JSON
+// response into out.
+func uploadFile(ctx context.Context, pa *togglPlanApi, path string, field string, filename string, content io.Reader, out interface{}) error {
+	if err := checkTypedVersion(pa); err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, field, filepath.Base(filename)))
+	header.Set("Content-Type", contentType(filename))
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, content); err != nil {
+		return fmt.Errorf("reading %s: %w", filename, err)
+	}
+	if err := form.Close(); err != nil {
+		return err
+	}
+
+	headers := map[string]string{"Content-Type": form.FormDataContentType()}
+	resp, _, err := authenticatedRequest(ctx, pa, apiURL(pa, path, nil), http.MethodPost, body.Bytes(), headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	recordResponse(ctx, resp)
+
+	return decodeResponse(pa, resp, out)
+}
+
+// contentType guesses the MIME type of a file from its extension.
+func contentType(filename string) string {
+	if mimeType := mime.TypeByExtension(filepath.Ext(filename)); mimeType != "" {
+		return mimeType
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,36 @@
+package togglplanapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUploadAvatar(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/v5/me/avatar" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		file, header, err := r.FormFile("avatar")
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		content, _ := io.ReadAll(file)
+		if header.Filename != "me.png" || header.Header.Get("Content-Type") != "image/png" || string(content) != "PNG" {
+			t.Errorf("unexpected upload %s %v %q", header.Filename, header.Header, content)
+		}
+		w.Write([]byte(`{"id":5,"avatar_url":"https://example.com/5.png"}`))
+	})
+
+	me, err := UploadAvatar(context.Background(), pa, "/tmp/me.png", strings.NewReader("PNG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if me.AvatarURL != "https://example.com/5.png" {
+		t.Fatalf("unexpected user %+v", me)
+	}
+}
@@ -0,0 +1,167 @@
+/*
+Package gantt draws timelines built by the schedule package as Gantt charts,
+in SVG for wikis and web pages, or in PNG for tools that only take images.
+
+Example Usage:
+
+	import (
+		"os"
+
+		"github.com/ricotheque/togglplanapi/gantt"
+		"github.com/ricotheque/togglplanapi/schedule"
+	)
+
+	func main() {
+		timeline, err := schedule.ProjectTimeline(tasks, milestones, schedule.TimelineOptions{})
+		if err != nil {
+			panic(err)
+		}
+
+		f, _ := os.Create("project.svg")
+		defer f.Close()
+
+		gantt.SVG(f, timeline, gantt.Options{Title: "Website relaunch"})
+	}
+*/
+package gantt
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"togglplanapi"
+	"togglplanapi/schedule"
+)
+
+// Options configures the drawing of a chart. Zero fields keep the defaults.
+type Options struct {
+	// Title is drawn above the chart by SVG.
+	Title string
+
+	// DayWidth is the width of a day, in pixels. Defaults to 24.
+	DayWidth int
+	// RowHeight is the height of a row, in pixels. Defaults to 28.
+	RowHeight int
+	// LabelWidth is the width of the column of row labels, in pixels.
+	// Defaults to 160 for SVG, and to none for PNG, which draws no text.
+	LabelWidth int
+
+	// Today is marked with a vertical line if it is in the timeline.
+	Today togglplanapi.Date
+}
+
+// Sizes of the parts of a chart, in pixels.
+const (
+	titleHeight = 32
+	axisHeight  = 36
+	barPadding  = 5
+)
+
+// Colors of a chart.
+var (
+	background  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	weekend     = color.RGBA{0xf3, 0xf4, 0xf6, 0xff}
+	gridLine    = color.RGBA{0xe5, 0xe7, 0xeb, 0xff}
+	textColor   = color.RGBA{0x37, 0x41, 0x51, 0xff}
+	critical    = color.RGBA{0xdc, 0x26, 0x26, 0xff}
+	linkColor   = color.RGBA{0x6b, 0x72, 0x80, 0xff}
+	todayColor  = color.RGBA{0x25, 0x63, 0xeb, 0xff}
+	milestoneFg = color.RGBA{0x11, 0x18, 0x27, 0xff}
+)
+
+// defaultColor fills the bars of tasks without a color.
+var defaultColor = color.RGBA{0x4a, 0x90, 0xd9, 0xff}
+
+// barColor returns the fill of a bar.
+func barColor(bar schedule.Bar) color.RGBA {
+	c := defaultColor
+	if bar.Color != togglplanapi.ColorNone && bar.Color.Valid() {
+		c = color.RGBAModel.Convert(bar.Color).(color.RGBA)
+	}
+	if bar.Done {
+		// Done tasks are faded towards white
+		c = color.RGBA{c.R/2 + 0x80, c.G/2 + 0x80, c.B/2 + 0x80, 0xff}
+	}
+	return c
+}
+
+// layout places the parts of a timeline on a chart.
+type layout struct {
+	timeline schedule.Timeline
+	options  Options
+	top      int
+	days     int
+	width    int
+	height   int
+	// bars holds the rectangle of the first bar of each task, which links
+	// are drawn between.
+	bars map[togglplanapi.ID]image.Rectangle
+}
+
+// newLayout places a timeline, with room for a title if withTitle is set.
+func newLayout(timeline schedule.Timeline, options Options, withTitle bool) *layout {
+	if options.DayWidth <= 0 {
+		options.DayWidth = 24
+	}
+	if options.RowHeight <= 0 {
+		options.RowHeight = 28
+	}
+
+	l := &layout{timeline: timeline, options: options, top: axisHeight, bars: map[togglplanapi.ID]image.Rectangle{}}
+	if withTitle && options.Title != "" {
+		l.top += titleHeight
+	}
+	if !timeline.Since.IsZero() && !timeline.Until.Before(timeline.Since) {
+		l.days = timeline.Since.DaysUntil(timeline.Until) + 1
+	}
+	l.width = options.LabelWidth + l.days*options.DayWidth
+	l.height = l.top + len(timeline.Rows)*options.RowHeight + options.RowHeight/2
+
+	for i, row := range timeline.Rows {
+		for _, bar := range row.Bars {
+			if _, ok := l.bars[bar.TaskId]; !ok {
+				l.bars[bar.TaskId] = l.barRect(i, bar)
+			}
+		}
+	}
+	return l
+}
+
+// x returns the left edge of a day, clamped to the chart.
+func (l *layout) x(date togglplanapi.Date) int {
+	days := l.timeline.Since.DaysUntil(date)
+	if days < 0 {
+		days = 0
+	}
+	if days > l.days {
+		days = l.days
+	}
+	return l.options.LabelWidth + days*l.options.DayWidth
+}
+
+// rowY returns the top edge of a row.
+func (l *layout) rowY(row int) int {
+	return l.top + row*l.options.RowHeight
+}
+
+// barRect returns the rectangle of a bar in a row, clipped to the chart.
+func (l *layout) barRect(row int, bar schedule.Bar) image.Rectangle {
+	y := l.rowY(row)
+	return image.Rect(l.x(bar.Start), y+barPadding, l.x(bar.End.AddDays(1)), y+l.options.RowHeight-barPadding)
+}
+
+// inRange reports whether a day is in the timeline.
+func (l *layout) inRange(date togglplanapi.Date) bool {
+	return l.days > 0 && date.Between(l.timeline.Since, l.timeline.Until)
+}
+
+// day returns the nth day of the timeline.
+func (l *layout) day(n int) togglplanapi.Date {
+	return l.timeline.Since.AddDays(n)
+}
+
+// isWeekend reports whether a day falls on a weekend.
+func isWeekend(date togglplanapi.Date) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
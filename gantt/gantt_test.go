@@ -0,0 +1,84 @@
+package gantt
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"togglplanapi"
+	"togglplanapi/schedule"
+)
+
+func testTimeline(t *testing.T) schedule.Timeline {
+	day := togglplanapi.NewDate(2024, 2, 27)
+	tasks := []togglplanapi.Task{
+		{Id: 1, Name: "Design & copy", StartDate: day, EndDate: day.AddDays(2), Color: togglplanapi.ColorGreen},
+		{Id: 2, Name: "Build", StartDate: day.AddDays(3), EndDate: day.AddDays(9), Done: true},
+	}
+	milestones := []togglplanapi.Milestone{{Id: 9, Name: "Launch", Date: day.AddDays(10)}}
+
+	timeline, err := schedule.ProjectTimeline(tasks, milestones, schedule.TimelineOptions{
+		Dependencies: []schedule.Dependency{{From: 1, To: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return timeline
+}
+
+func TestSVG(t *testing.T) {
+	var b bytes.Buffer
+	if err := SVG(&b, testTimeline(t), Options{Title: "Relaunch <Q1>", Today: togglplanapi.NewDate(2024, 3, 1)}); err != nil {
+		t.Fatal(err)
+	}
+	svg := b.String()
+
+	// The output must be well-formed XML
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid SVG: %v\n%s", err, svg)
+		}
+	}
+
+	for _, expected := range []string{
+		`width="424" height="138"`,
+		"Relaunch &lt;Q1&gt;",
+		"Design &amp; copy (2024-02-27 – 2024-02-29)",
+		"Feb 2024", "Mar 2024",
+		`fill="#43a047" stroke="#dc2626"`,
+		`marker-end="url(#arrow)"`,
+		"Launch (2024-03-08)",
+	} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("expected %q in:\n%s", expected, svg)
+		}
+	}
+}
+
+func TestPNG(t *testing.T) {
+	var b bytes.Buffer
+	if err := PNG(&b, testTimeline(t), Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := png.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 11*24 || size.Y != 36+2*28+14 {
+		t.Fatalf("unexpected size %v", size)
+	}
+
+	// The middle of the first bar has the color of the task
+	if r, g, b, _ := img.At(30, 36+14).RGBA(); r>>8 != 0x43 || g>>8 != 0xa0 || b>>8 != 0x47 {
+		t.Fatalf("unexpected bar color %x %x %x", r>>8, g>>8, b>>8)
+	}
+}
@@ -0,0 +1,118 @@
+package gantt
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+
+	"togglplanapi/schedule"
+)
+
+// PNG draws a timeline as a PNG image, like SVG but without any text, as the
+// standard library has no font rendering: rows, weekends, bars, milestones,
+// links and the critical path are drawn, and titles and labels are left out.
+// Use SVG, or convert its output, for labeled charts.
+func PNG(w io.Writer, timeline schedule.Timeline, options Options) error {
+	return png.Encode(w, drawImage(timeline, options))
+}
+
+// drawImage draws a timeline on an image.
+func drawImage(timeline schedule.Timeline, options Options) *image.RGBA {
+	l := newLayout(timeline, options, false)
+	dw, rh := l.options.DayWidth, l.options.RowHeight
+
+	img := image.NewRGBA(image.Rect(0, 0, l.width, l.height))
+	fill(img, img.Bounds(), background)
+
+	for n := 0; n < l.days; n++ {
+		x := l.options.LabelWidth + n*dw
+		if isWeekend(l.day(n)) {
+			fill(img, image.Rect(x, l.top, x+dw, l.height), weekend)
+		}
+		// Ticks on the axis, longer at the start of months
+		tick := l.top - 4
+		if n == 0 || l.day(n).Day == 1 {
+			tick = l.top - 16
+		}
+		fill(img, image.Rect(x, tick, x+1, l.top), gridLine)
+	}
+	fill(img, image.Rect(0, l.top-1, l.width, l.top), gridLine)
+
+	for i, row := range timeline.Rows {
+		y := l.rowY(i) + rh
+		fill(img, image.Rect(0, y, l.width, y+1), gridLine)
+
+		for _, bar := range row.Bars {
+			r := l.barRect(i, bar)
+			if r.Dx() <= 0 {
+				continue
+			}
+			if bar.Critical {
+				fill(img, r.Inset(-2), critical)
+			}
+			fill(img, r, barColor(bar))
+		}
+	}
+
+	for _, link := range timeline.Links {
+		from, okFrom := l.bars[link.From]
+		to, okTo := l.bars[link.To]
+		if !okFrom || !okTo {
+			continue
+		}
+		c := linkColor
+		if link.Critical {
+			c = critical
+		}
+		y1, y2 := (from.Min.Y+from.Max.Y)/2, (to.Min.Y+to.Max.Y)/2
+		x := from.Max.X + 4
+		hline(img, from.Max.X, x, y1, c)
+		vline(img, x, y1, y2, c)
+		hline(img, x, to.Min.X, y2, c)
+	}
+
+	for _, marker := range timeline.Milestones {
+		if !l.inRange(marker.Date) {
+			continue
+		}
+		x := l.x(marker.Date) + dw/2
+		for y := l.top; y < l.height; y += 7 {
+			vline(img, x, y, y+4, milestoneFg)
+		}
+		// Diamond on the axis
+		for d := 0; d <= 5; d++ {
+			fill(img, image.Rect(x-d, l.top-10+d, x+d+1, l.top-10+d+1), milestoneFg)
+			fill(img, image.Rect(x-d, l.top-d, x+d+1, l.top-d+1), milestoneFg)
+		}
+	}
+
+	if l.inRange(options.Today) {
+		x := l.x(options.Today) + dw/2
+		fill(img, image.Rect(x-1, l.top, x+1, l.height), todayColor)
+	}
+
+	return img
+}
+
+// fill paints a rectangle of img.
+func fill(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	draw.Draw(img, r, &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
+
+// hline draws a horizontal line from x1 to x2.
+func hline(img *image.RGBA, x1 int, x2 int, y int, c color.RGBA) {
+	if x2 < x1 {
+		x1, x2 = x2, x1
+	}
+	fill(img, image.Rect(x1, y, x2+1, y+1), c)
+}
+
+// vline draws a vertical line from y1 to y2.
+func vline(img *image.RGBA, x int, y1 int, y2 int, c color.RGBA) {
+	if y2 < y1 {
+		y1, y2 = y2, y1
+	}
+	fill(img, image.Rect(x, y1, x+1, y2+1), c)
+}
@@ -0,0 +1,129 @@
+package gantt
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+	"time"
+
+	"togglplanapi/schedule"
+)
+
+// SVG draws a timeline as an SVG image: a row label column, a day axis with
+// shaded weekends, bars colored like their tasks, milestones, links, and
+// the critical path outlined in red. Hovering a bar or milestone shows its
+// name and dates.
+func SVG(w io.Writer, timeline schedule.Timeline, options Options) error {
+	if options.LabelWidth <= 0 {
+		options.LabelWidth = 160
+	}
+	l := newLayout(timeline, options, true)
+	dw, rh := l.options.DayWidth, l.options.RowHeight
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n",
+		l.width, l.height, l.width, l.height)
+	fmt.Fprint(bw, `<defs><marker id="arrow" viewBox="0 0 6 6" refX="6" refY="3" markerWidth="6" markerHeight="6" orient="auto"><path d="M0,0 L6,3 L0,6 z" fill="`+hex(linkColor)+`"/></marker></defs>`+"\n")
+	fmt.Fprintf(bw, `<rect width="%d" height="%d" fill="%s"/>`+"\n", l.width, l.height, hex(background))
+
+	if options.Title != "" {
+		fmt.Fprintf(bw, `<text x="8" y="22" font-size="16" font-weight="bold" fill="%s">%s</text>`+"\n", hex(textColor), escape(options.Title))
+	}
+
+	// Day axis, with weekends shaded
+	for n := 0; n < l.days; n++ {
+		date := l.day(n)
+		x := l.options.LabelWidth + n*dw
+		if isWeekend(date) {
+			fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n", x, l.top, dw, l.height-l.top, hex(weekend))
+		}
+		if n == 0 || date.Day == 1 {
+			fmt.Fprintf(bw, `<text x="%d" y="%d" fill="%s" font-weight="bold">%s</text>`+"\n", x+2, l.top-22, hex(textColor), date.In(time.UTC).Format("Jan 2006"))
+		}
+		if dw >= 16 {
+			fmt.Fprintf(bw, `<text x="%d" y="%d" fill="%s" text-anchor="middle" font-size="10">%d</text>`+"\n", x+dw/2, l.top-6, hex(textColor), date.Day)
+		}
+	}
+
+	// Rows, with their labels and bars
+	for i, row := range timeline.Rows {
+		y := l.rowY(i)
+		fmt.Fprintf(bw, `<line x1="0" y1="%d" x2="%d" y2="%d" stroke="%s"/>`+"\n", y+rh, l.width, y+rh, hex(gridLine))
+		fmt.Fprintf(bw, `<text x="8" y="%d" fill="%s">%s</text>`+"\n", y+rh/2+4, hex(textColor), escape(truncate(row.Label, l.options.LabelWidth/7)))
+
+		for _, bar := range row.Bars {
+			r := l.barRect(i, bar)
+			if r.Dx() <= 0 {
+				continue
+			}
+			stroke := ""
+			if bar.Critical {
+				stroke = ` stroke="` + hex(critical) + `" stroke-width="2"`
+			}
+			fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" rx="3" fill="%s"%s><title>%s</title></rect>`+"\n",
+				r.Min.X, r.Min.Y, r.Dx(), r.Dy(), hex(barColor(bar)), stroke, escape(bar.Label+" ("+bar.Start.String()+" – "+bar.End.String()+")"))
+			if len(bar.Label)*7 < r.Dx()-8 {
+				fmt.Fprintf(bw, `<text x="%d" y="%d" fill="#fff" font-size="11" pointer-events="none">%s</text>`+"\n", r.Min.X+4, r.Min.Y+r.Dy()/2+4, escape(bar.Label))
+			}
+		}
+	}
+
+	// Links go from the end of a bar to the start of the next
+	for _, link := range timeline.Links {
+		from, okFrom := l.bars[link.From]
+		to, okTo := l.bars[link.To]
+		if !okFrom || !okTo {
+			continue
+		}
+		c := linkColor
+		if link.Critical {
+			c = critical
+		}
+		y1, y2 := (from.Min.Y+from.Max.Y)/2, (to.Min.Y+to.Max.Y)/2
+		fmt.Fprintf(bw, `<path d="M%d,%d H%d V%d H%d" fill="none" stroke="%s" marker-end="url(#arrow)"/>`+"\n",
+			from.Max.X, y1, from.Max.X+4, y2, to.Min.X, hex(c))
+	}
+
+	// Milestones are drawn as a diamond on the axis and a dashed line
+	for _, marker := range timeline.Milestones {
+		if !l.inRange(marker.Date) {
+			continue
+		}
+		x := l.x(marker.Date) + dw/2
+		fmt.Fprintf(bw, `<g><title>%s</title><line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-dasharray="4 3"/><path d="M%d,%d l5,5 l-5,5 l-5,-5 z" fill="%s"/></g>`+"\n",
+			escape(marker.Label+" ("+marker.Date.String()+")"), x, l.top, x, l.height, hex(milestoneFg), x, l.top-5, hex(milestoneFg))
+	}
+
+	if l.inRange(options.Today) {
+		x := l.x(options.Today) + dw/2
+		fmt.Fprintf(bw, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="2"/>`+"\n", x, l.top, x, l.height, hex(todayColor))
+	}
+
+	fmt.Fprintln(bw, "</svg>")
+	return bw.Flush()
+}
+
+// hex returns a color in #rrggbb notation.
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// escape escapes text for XML.
+func escape(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// truncate shortens text to at most n characters, ending with an ellipsis
+// if it was cut.
+func truncate(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n || n < 1 {
+		return text
+	}
+	return string(runes[:n-1]) + "…"
+}
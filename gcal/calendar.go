@@ -0,0 +1,144 @@
+package gcal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Event is a Google Calendar event, reduced to the fields that are synced.
+type Event struct {
+	Id                 string              `json:"id,omitempty"`
+	Status             string              `json:"status,omitempty"`
+	Summary            string              `json:"summary"`
+	Description        string              `json:"description"`
+	Start              EventTime           `json:"start"`
+	End                EventTime           `json:"end"`
+	Updated            time.Time           `json:"updated,omitempty"`
+	ExtendedProperties *extendedProperties `json:"extendedProperties,omitempty"`
+}
+
+// EventTime is the start or end of an event. All-day events set Date
+// (YYYY-MM-DD, with an exclusive end), timed events set DateTime.
+type EventTime struct {
+	Date     string `json:"date,omitempty"`
+	DateTime string `json:"dateTime,omitempty"`
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+// extendedProperties holds private key/value pairs stored on an event.
+type extendedProperties struct {
+	Private map[string]string `json:"private,omitempty"`
+}
+
+// taskIdProperty is the private event property holding the ID of the task the event was created from.
+const taskIdProperty = "togglPlanTaskId"
+
+// Cancelled reports whether the event was deleted.
+func (event Event) Cancelled() bool {
+	return event.Status == "cancelled"
+}
+
+// taskId returns the ID of the task the event was created from, if any.
+func (event Event) taskId() string {
+	if event.ExtendedProperties == nil {
+		return ""
+	}
+	return event.ExtendedProperties.Private[taskIdProperty]
+}
+
+// calendarRequest sends a request to the Google Calendar API and decodes the
+// response into out, unless out is nil.
+func (s *Syncer) calendarRequest(ctx context.Context, method string, path string, query url.Values, in interface{}, out interface{}) error {
+	fullURL := s.options.baseURL() + "/calendars/" + url.PathEscape(s.options.CalendarId) + path
+	if len(query) > 0 {
+		fullURL += "?" + query.Encode()
+	}
+
+	var body bytes.Buffer
+	if in != nil {
+		if err := json.NewEncoder(&body).Encode(in); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, &body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.options.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Deleting an event that is already gone is not an error
+	if method == "DELETE" && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone) {
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("google calendar: %s %s: %s", method, path, http.StatusText(resp.StatusCode))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// listEvents fetches the events of the calendar updated since updatedMin,
+// including deleted ones. If updatedMin is zero, all events are fetched.
+func (s *Syncer) listEvents(ctx context.Context, updatedMin time.Time) ([]Event, error) {
+	var events []Event
+
+	query := url.Values{}
+	query.Set("showDeleted", "true")
+	query.Set("maxResults", "250")
+	if !updatedMin.IsZero() {
+		query.Set("updatedMin", updatedMin.UTC().Format(time.RFC3339))
+	}
+
+	for {
+		var page struct {
+			Items         []Event `json:"items"`
+			NextPageToken string  `json:"nextPageToken"`
+		}
+
+		if err := s.calendarRequest(ctx, "GET", "/events", query, nil, &page); err != nil {
+			return nil, err
+		}
+		events = append(events, page.Items...)
+
+		if page.NextPageToken == "" {
+			return events, nil
+		}
+		query.Set("pageToken", page.NextPageToken)
+	}
+}
+
+// insertEvent creates an event and returns it.
+func (s *Syncer) insertEvent(ctx context.Context, event Event) (*Event, error) {
+	var created Event
+	err := s.calendarRequest(ctx, "POST", "/events", nil, event, &created)
+	return &created, err
+}
+
+// updateEvent replaces an event and returns it.
+func (s *Syncer) updateEvent(ctx context.Context, eventId string, event Event) (*Event, error) {
+	var updated Event
+	err := s.calendarRequest(ctx, "PUT", "/events/"+url.PathEscape(eventId), nil, event, &updated)
+	return &updated, err
+}
+
+// deleteEvent deletes an event.
+func (s *Syncer) deleteEvent(ctx context.Context, eventId string) error {
+	return s.calendarRequest(ctx, "DELETE", "/events/"+url.PathEscape(eventId), nil, nil, nil)
+}
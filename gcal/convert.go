@@ -0,0 +1,99 @@
+package gcal
+
+import (
+	"fmt"
+	"time"
+
+	"togglplanapi"
+)
+
+// taskToEvent converts a task into an event of the calendar.
+// Timed tasks are expressed in loc.
+func taskToEvent(task togglplanapi.Task, loc *time.Location) (Event, error) {
+	event := Event{
+		Summary:     task.Name,
+		Description: task.Notes,
+		ExtendedProperties: &extendedProperties{
+			Private: map[string]string{taskIdProperty: task.Id.String()},
+		},
+	}
+
+	if task.StartDate.IsZero() || task.EndDate.IsZero() {
+		return event, fmt.Errorf("task %d: has no dates", task.Id)
+	}
+
+	if task.StartTime == "" || task.EndTime == "" {
+		// Event end dates are exclusive, while task end dates are inclusive
+		event.Start = EventTime{Date: task.StartDate.String()}
+		event.End = EventTime{Date: task.EndDate.AddDays(1).String()}
+		return event, nil
+	}
+
+	startAt, err := togglplanapi.AtClock(task.StartDate, task.StartTime, loc)
+	if err != nil {
+		return event, fmt.Errorf("task %d: invalid start time %q", task.Id, task.StartTime)
+	}
+	endAt, err := togglplanapi.AtClock(task.EndDate, task.EndTime, loc)
+	if err != nil {
+		return event, fmt.Errorf("task %d: invalid end time %q", task.Id, task.EndTime)
+	}
+
+	event.Start = EventTime{DateTime: startAt.Format(time.RFC3339), TimeZone: loc.String()}
+	event.End = EventTime{DateTime: endAt.Format(time.RFC3339), TimeZone: loc.String()}
+	return event, nil
+}
+
+// eventToTask converts the scheduling fields of an event into task fields,
+// expressing times in loc.
+func eventToTask(event Event, loc *time.Location) (togglplanapi.TaskParams, error) {
+	params := togglplanapi.TaskParams{
+		Name:  event.Summary,
+		Notes: event.Description,
+	}
+
+	if event.Start.Date != "" {
+		start, err := togglplanapi.ParseDate(event.Start.Date)
+		if err != nil {
+			return params, fmt.Errorf("event %s: invalid start date %q", event.Id, event.Start.Date)
+		}
+		end, err := togglplanapi.ParseDate(event.End.Date)
+		if err != nil {
+			return params, fmt.Errorf("event %s: invalid end date %q", event.Id, event.End.Date)
+		}
+
+		params.StartDate = start
+		params.EndDate = end.AddDays(-1)
+		if params.EndDate.Before(params.StartDate) {
+			params.EndDate = params.StartDate
+		}
+		return params, nil
+	}
+
+	start, err := time.Parse(time.RFC3339, event.Start.DateTime)
+	if err != nil {
+		return params, fmt.Errorf("event %s: invalid start %q", event.Id, event.Start.DateTime)
+	}
+	end, err := time.Parse(time.RFC3339, event.End.DateTime)
+	if err != nil {
+		return params, fmt.Errorf("event %s: invalid end %q", event.Id, event.End.DateTime)
+	}
+
+	start, end = start.In(loc), end.In(loc)
+	params.StartDate = togglplanapi.DateOf(start)
+	params.StartTime = start.Format("15:04")
+	params.EndDate = togglplanapi.DateOf(end)
+	params.EndTime = end.Format("15:04")
+	return params, nil
+}
+
+// taskUpdate converts task fields into an update of all scheduling fields.
+func taskUpdate(params togglplanapi.TaskParams) togglplanapi.TaskUpdate {
+	return togglplanapi.TaskUpdate{
+		Name:      &params.Name,
+		Notes:     &params.Notes,
+		StartDate: &params.StartDate,
+		EndDate:   &params.EndDate,
+		StartTime: &params.StartTime,
+		EndTime:   &params.EndTime,
+	}
+}
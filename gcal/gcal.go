@@ -0,0 +1,437 @@
+/*
+Package gcal keeps a Google Calendar and the tasks of a Toggl Plan workspace
+in sync, in both directions.
+
+Tasks are pushed to the calendar as events, and changes made to those events
+in the calendar are pulled back into the tasks. Optionally, events created
+directly in the calendar become new tasks. Which task each event mirrors is
+kept in a Store between runs. Changes to tasks are detected with a
+togglplanapi.TaskFeed.
+
+The package doesn't handle Google's OAuth flow: pass an *http.Client that
+authorizes its requests for the calendar scope, such as one returned by
+golang.org/x/oauth2.
+
+Example Usage:
+
+	import (
+		"context"
+		"time"
+
+		"github.com/ricotheque/togglplanapi"
+		"github.com/ricotheque/togglplanapi/gcal"
+	)
+
+	func main() {
+		pa := togglplanapi.New(username, password, clientId, clientSecret, "")
+
+		syncer := gcal.NewSyncer(pa, workspaceId, gcal.Options{
+			CalendarId: "primary",
+			HTTPClient: oauthClient,
+			Location:   time.Local,
+			Store:      gcal.FileStore{Path: "gcal-sync.json"},
+			Feed:       togglplanapi.TaskFeedOptions{MemberIds: []togglplanapi.ID{memberId}},
+		})
+
+		result, err := syncer.Sync(context.Background())
+	}
+*/
+package gcal
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"togglplanapi"
+)
+
+// ConflictPolicy decides which side wins when a task and its event were both
+// changed since the last sync.
+type ConflictPolicy int
+
+const (
+	// NewestWins keeps whichever was changed last.
+	NewestWins ConflictPolicy = iota
+	// PlanWins always keeps the task.
+	PlanWins
+	// CalendarWins always keeps the event.
+	CalendarWins
+)
+
+// Options configures a Syncer.
+type Options struct {
+	// CalendarId is the calendar to sync with, e.g. "primary".
+	CalendarId string
+
+	// HTTPClient sends the Google Calendar requests, and must be authorized for the calendar scope.
+	HTTPClient *http.Client
+
+	// BaseURL is the Google Calendar API URL. Defaults to https://www.googleapis.com/calendar/v3.
+	BaseURL string
+
+	// Location is the time zone of timed tasks. Defaults to UTC.
+	Location *time.Location
+
+	// Feed selects the tasks pushed to the calendar.
+	Feed togglplanapi.TaskFeedOptions
+
+	// Store keeps the links between tasks and events between runs. Defaults to a MemoryStore.
+	Store Store
+
+	// Conflicts decides which side wins when both were changed. Defaults to NewestWins.
+	Conflicts ConflictPolicy
+
+	// PullNewEvents creates a task for every event created directly in the calendar.
+	PullNewEvents bool
+
+	// ProjectId and Assignees are set on tasks created from events.
+	ProjectId togglplanapi.ID
+	Assignees []togglplanapi.ID
+
+	// DeleteTasks deletes a task when its event is deleted from the calendar.
+	// Otherwise, the task is only unlinked from the event.
+	DeleteTasks bool
+}
+
+// baseURL returns the Google Calendar API URL.
+func (options Options) baseURL() string {
+	if options.BaseURL == "" {
+		return "https://www.googleapis.com/calendar/v3"
+	}
+	return strings.TrimRight(options.BaseURL, "/")
+}
+
+// Result reports what a sync changed.
+type Result struct {
+	EventsCreated int
+	EventsUpdated int
+	EventsDeleted int
+	TasksCreated  int
+	TasksUpdated  int
+	TasksDeleted  int
+
+	// Conflicts counts the tasks and events that were both changed since the last sync.
+	Conflicts int
+
+	// Errors lists the tasks and events that couldn't be synced.
+	// They are retried on the next sync: the sync time isn't saved, so
+	// events are listed again, and the task changes are kept for later.
+	Errors []error
+}
+
+// Syncer syncs the tasks of a workspace with a Google Calendar.
+// A Syncer is safe for concurrent use, but syncs are serialized.
+type Syncer struct {
+	pa          *togglplanapi.Client
+	workspaceId togglplanapi.ID
+	options     Options
+	feed        *togglplanapi.TaskFeed
+
+	mu  sync.Mutex
+	now func() time.Time
+	// retry holds the task changes that failed to sync, which the feed
+	// won't report again.
+	retry []togglplanapi.TaskChange
+}
+
+// NewSyncer returns a Syncer for the tasks of a workspace.
+// Arguments:
+//
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	options: Calendar and sync settings
+func NewSyncer(pa *togglplanapi.Client, workspaceId togglplanapi.ID, options Options) *Syncer {
+	if options.Location == nil {
+		options.Location = time.UTC
+	}
+	if options.Store == nil {
+		options.Store = &MemoryStore{}
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = http.DefaultClient
+	}
+
+	return &Syncer{
+		pa:          pa,
+		workspaceId: workspaceId,
+		options:     options,
+		feed:        togglplanapi.NewTaskFeed(pa, workspaceId, options.Feed),
+		now:         time.Now,
+	}
+}
+
+// Sync pushes the tasks changed since the last sync to the calendar, and pulls
+// the events changed since then back into their tasks.
+// An error is returned if the sync couldn't run at all; errors syncing single
+// tasks or events are reported in the result instead.
+func (s *Syncer) Sync(ctx context.Context) (*Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.options.Store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	started := s.now()
+
+	changes, err := s.feed.Poll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	changes = mergeChanges(s.retry, changes)
+
+	events, err := s.listEvents(ctx, state.LastSync)
+	if err != nil {
+		return nil, err
+	}
+
+	run := &syncRun{
+		Syncer:  s,
+		result:  &Result{},
+		byTask:  map[togglplanapi.ID]*Mapping{},
+		byEvent: map[string]*Mapping{},
+		handled: map[string]bool{},
+	}
+	for i := range state.Mappings {
+		run.link(state.Mappings[i])
+	}
+
+	run.changedEvents = map[string]Event{}
+	for _, event := range events {
+		if mapping, ok := run.byEvent[event.Id]; !ok || event.Updated.After(mapping.EventUpdated) {
+			run.changedEvents[event.Id] = event
+		}
+	}
+
+	s.retry = nil
+	for _, change := range changes {
+		failed := len(run.result.Errors)
+		run.pushTask(ctx, change)
+		if len(run.result.Errors) > failed {
+			s.retry = append(s.retry, change)
+		}
+	}
+
+	for _, event := range events {
+		if _, changed := run.changedEvents[event.Id]; changed && !run.handled[event.Id] {
+			run.pullEvent(ctx, event)
+		}
+	}
+
+	// Events that failed to sync are listed again by the next sync, while
+	// those that were synced are skipped by their mapping
+	if len(run.result.Errors) == 0 {
+		state.LastSync = started
+	}
+	state.Mappings = state.Mappings[:0]
+	for _, mapping := range run.byTask {
+		state.Mappings = append(state.Mappings, *mapping)
+	}
+	sort.Slice(state.Mappings, func(i, j int) bool { return state.Mappings[i].TaskId < state.Mappings[j].TaskId })
+
+	if err := s.options.Store.Save(state); err != nil {
+		return run.result, err
+	}
+
+	return run.result, nil
+}
+
+// mergeChanges returns the changes of a poll, preceded by the earlier changes
+// to retry of tasks that the poll didn't report again.
+func mergeChanges(retry []togglplanapi.TaskChange, changes []togglplanapi.TaskChange) []togglplanapi.TaskChange {
+	if len(retry) == 0 {
+		return changes
+	}
+
+	polled := make(map[togglplanapi.ID]bool, len(changes))
+	for _, change := range changes {
+		polled[change.Task.Id] = true
+	}
+
+	var merged []togglplanapi.TaskChange
+	for _, change := range retry {
+		if !polled[change.Task.Id] {
+			merged = append(merged, change)
+		}
+	}
+	return append(merged, changes...)
+}
+
+// syncRun holds the working state of a single sync.
+type syncRun struct {
+	*Syncer
+
+	result        *Result
+	byTask        map[togglplanapi.ID]*Mapping
+	byEvent       map[string]*Mapping
+	changedEvents map[string]Event
+
+	// handled marks changed events already resolved while pushing tasks.
+	handled map[string]bool
+}
+
+// link records a mapping.
+func (run *syncRun) link(mapping Mapping) {
+	run.byTask[mapping.TaskId] = &mapping
+	run.byEvent[mapping.EventId] = &mapping
+}
+
+// unlink forgets a mapping.
+func (run *syncRun) unlink(mapping *Mapping) {
+	delete(run.byTask, mapping.TaskId)
+	delete(run.byEvent, mapping.EventId)
+}
+
+// fail records an error syncing a single task or event.
+func (run *syncRun) fail(err error) {
+	run.result.Errors = append(run.result.Errors, err)
+}
+
+// pushTask mirrors a task change to the calendar.
+func (run *syncRun) pushTask(ctx context.Context, change togglplanapi.TaskChange) {
+	task := change.Task
+	mapping := run.byTask[task.Id]
+
+	if change.Type == togglplanapi.ChangeDeleted {
+		if mapping == nil {
+			return
+		}
+
+		// The feed also reports tasks that moved out of its window, whose
+		// events are kept
+		current, err := togglplanapi.GetTask(togglplanapi.NotFoundAsNil(ctx, true), run.pa, run.workspaceId, task.Id)
+		if err != nil {
+			run.fail(fmt.Errorf("checking deletion of task %d: %w", task.Id, err))
+			return
+		}
+		if current != nil {
+			return
+		}
+
+		if err := run.deleteEvent(ctx, mapping.EventId); err != nil {
+			run.fail(fmt.Errorf("deleting event of task %d: %w", task.Id, err))
+			return
+		}
+		run.handled[mapping.EventId] = true
+		run.unlink(mapping)
+		run.result.EventsDeleted++
+		return
+	}
+
+	// Tasks not changed since they were last synced include those updated by the sync itself
+	if mapping != nil && !task.UpdatedAt.After(mapping.TaskUpdated) {
+		return
+	}
+
+	event, err := taskToEvent(task, run.options.Location)
+	if err != nil {
+		run.fail(err)
+		return
+	}
+
+	if mapping == nil {
+		created, err := run.insertEvent(ctx, event)
+		if err != nil {
+			run.fail(fmt.Errorf("creating event of task %d: %w", task.Id, err))
+			return
+		}
+		run.link(Mapping{TaskId: task.Id, EventId: created.Id, TaskUpdated: task.UpdatedAt.Time, EventUpdated: created.Updated})
+		run.handled[created.Id] = true
+		run.result.EventsCreated++
+		return
+	}
+
+	if changedEvent, ok := run.changedEvents[mapping.EventId]; ok {
+		run.result.Conflicts++
+		if !run.taskWins(task, changedEvent) {
+			// The event is pulled into the task instead
+			return
+		}
+	}
+
+	updated, err := run.updateEvent(ctx, mapping.EventId, event)
+	if err != nil {
+		run.fail(fmt.Errorf("updating event of task %d: %w", task.Id, err))
+		return
+	}
+	mapping.TaskUpdated = task.UpdatedAt.Time
+	mapping.EventUpdated = updated.Updated
+	run.handled[mapping.EventId] = true
+	run.result.EventsUpdated++
+}
+
+// taskWins resolves a conflict between a task and its event.
+func (run *syncRun) taskWins(task togglplanapi.Task, event Event) bool {
+	switch run.options.Conflicts {
+	case PlanWins:
+		return true
+	case CalendarWins:
+		return false
+	default:
+		return !task.UpdatedAt.Before(event.Updated)
+	}
+}
+
+// pullEvent mirrors an event change to its task.
+func (run *syncRun) pullEvent(ctx context.Context, event Event) {
+	mapping := run.byEvent[event.Id]
+
+	if event.Cancelled() {
+		if mapping == nil {
+			return
+		}
+		if run.options.DeleteTasks {
+			if err := togglplanapi.DeleteTask(ctx, run.pa, run.workspaceId, mapping.TaskId); err != nil {
+				run.fail(fmt.Errorf("deleting task %d: %w", mapping.TaskId, err))
+				return
+			}
+			run.result.TasksDeleted++
+		}
+		run.unlink(mapping)
+		return
+	}
+
+	params, err := eventToTask(event, run.options.Location)
+	if err != nil {
+		run.fail(err)
+		return
+	}
+
+	if mapping == nil {
+		// Events created by an earlier sync whose mapping was lost are linked again
+		if id, err := togglplanapi.ParseID(event.taskId()); err == nil {
+			mapping = &Mapping{TaskId: id, EventId: event.Id}
+			run.link(*mapping)
+			mapping = run.byEvent[event.Id]
+		} else if !run.options.PullNewEvents {
+			return
+		} else {
+			params.ProjectId = run.options.ProjectId
+			params.Assignees = run.options.Assignees
+
+			task, err := togglplanapi.CreateTask(ctx, run.pa, run.workspaceId, params)
+			if err != nil {
+				run.fail(fmt.Errorf("creating task from event %s: %w", event.Id, err))
+				return
+			}
+			run.link(Mapping{TaskId: task.Id, EventId: event.Id, TaskUpdated: task.UpdatedAt.Time, EventUpdated: event.Updated})
+			run.result.TasksCreated++
+			return
+		}
+	}
+
+	task, err := togglplanapi.UpdateTask(ctx, run.pa, run.workspaceId, mapping.TaskId, taskUpdate(params))
+	if err != nil {
+		run.fail(fmt.Errorf("updating task %d from event %s: %w", mapping.TaskId, event.Id, err))
+		return
+	}
+	mapping.TaskUpdated = task.UpdatedAt.Time
+	mapping.EventUpdated = event.Updated
+	run.result.TasksUpdated++
+}
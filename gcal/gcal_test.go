@@ -0,0 +1,369 @@
+package gcal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"togglplanapi"
+	"togglplanapi/internal/testclient"
+)
+
+func TestTaskEventRoundTrip(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+
+	tasks := []togglplanapi.Task{
+		{Id: 1, Name: "Offsite", StartDate: togglplanapi.NewDate(2024, 3, 4), EndDate: togglplanapi.NewDate(2024, 3, 6)},
+		{Id: 2, Name: "Review", StartDate: togglplanapi.NewDate(2024, 3, 4), EndDate: togglplanapi.NewDate(2024, 3, 4), StartTime: "09:00", EndTime: "10:30"},
+	}
+
+	for _, task := range tasks {
+		event, err := taskToEvent(task, tokyo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event.taskId() == "" {
+			t.Fatalf("event of task %d doesn't reference it", task.Id)
+		}
+
+		params, err := eventToTask(event, tokyo)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if params.StartDate != task.StartDate || params.EndDate != task.EndDate || params.StartTime != task.StartTime || params.EndTime != task.EndTime {
+			t.Fatalf("task %d came back as %+v", task.Id, params)
+		}
+	}
+
+	event, _ := taskToEvent(tasks[0], tokyo)
+	if event.End.Date != "2024-03-07" {
+		t.Fatalf("all-day event should end the day after the task, got %s", event.End.Date)
+	}
+
+	event, _ = taskToEvent(tasks[1], tokyo)
+	if event.Start.DateTime != "2024-03-04T09:00:00+09:00" {
+		t.Fatalf("unexpected event start %s", event.Start.DateTime)
+	}
+}
+
+func TestFileStore(t *testing.T) {
+	store := FileStore{Path: filepath.Join(t.TempDir(), "state.json")}
+
+	state, err := store.Load()
+	if err != nil || len(state.Mappings) != 0 {
+		t.Fatalf("missing file should load as an empty state, got %+v, %v", state, err)
+	}
+
+	saved := State{
+		LastSync: time.Date(2024, 3, 4, 12, 0, 0, 0, time.UTC),
+		Mappings: []Mapping{{TaskId: 1, EventId: "abc"}},
+	}
+	if err := store.Save(saved); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err = store.Load()
+	if err != nil || !state.LastSync.Equal(saved.LastSync) || state.Mappings[0].EventId != "abc" {
+		t.Fatalf("unexpected state %+v, %v", state, err)
+	}
+}
+
+// fakeClock returns times a minute apart, so that every change is newer
+// than the one before.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (clock *fakeClock) tick() time.Time {
+	clock.mu.Lock()
+	defer clock.mu.Unlock()
+	clock.now = clock.now.Add(time.Minute)
+	return clock.now
+}
+
+// fakeWorld serves the tasks of workspace 1 and the events of the primary
+// calendar, and records the writes made to either.
+type fakeWorld struct {
+	t     *testing.T
+	clock *fakeClock
+
+	mu     sync.Mutex
+	tasks  map[togglplanapi.ID]togglplanapi.Task
+	hidden map[togglplanapi.ID]bool // Tasks out of the window of the feed
+	events map[string]Event
+	writes []string
+	fail   map[string]bool // Writes answered with a 500
+}
+
+func newFakeWorld(t *testing.T) *fakeWorld {
+	return &fakeWorld{
+		t:      t,
+		clock:  &fakeClock{now: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)},
+		tasks:  map[togglplanapi.ID]togglplanapi.Task{},
+		hidden: map[togglplanapi.ID]bool{},
+		events: map[string]Event{},
+		fail:   map[string]bool{},
+	}
+}
+
+// syncer returns a Syncer of workspace 1 against the fake servers.
+func (world *fakeWorld) syncer(options Options) *Syncer {
+	pa := testclient.New(world.t, world.servePlan)
+	calendar := httptest.NewServer(http.HandlerFunc(world.serveCalendar))
+	world.t.Cleanup(calendar.Close)
+
+	options.CalendarId = "primary"
+	options.BaseURL = calendar.URL
+	options.HTTPClient = calendar.Client()
+	syncer := NewSyncer(pa, 1, options)
+	syncer.now = world.clock.tick
+	return syncer
+}
+
+// putTask adds or changes a task, as if edited in Toggl Plan.
+func (world *fakeWorld) putTask(task togglplanapi.Task) {
+	world.mu.Lock()
+	defer world.mu.Unlock()
+	task.StartDate = togglplanapi.NewDate(2024, 3, 4)
+	task.EndDate = togglplanapi.NewDate(2024, 3, 5)
+	task.UpdatedAt = togglplanapi.DateTime{Time: world.clock.tick()}
+	world.tasks[task.Id] = task
+}
+
+// putEvent changes an event, as if edited in the calendar.
+func (world *fakeWorld) putEvent(event Event) {
+	world.mu.Lock()
+	defer world.mu.Unlock()
+	event.Updated = world.clock.tick()
+	world.events[event.Id] = event
+}
+
+// takeWrites returns the writes recorded since the last call, sorted.
+func (world *fakeWorld) takeWrites() []string {
+	world.mu.Lock()
+	defer world.mu.Unlock()
+	writes := world.writes
+	world.writes = nil
+	sort.Strings(writes)
+	return writes
+}
+
+// write records a write, and reports whether it should fail.
+func (world *fakeWorld) write(r *http.Request, summary string) bool {
+	call := r.Method + " " + r.URL.Path
+	if summary != "" {
+		call += " " + summary
+	}
+	world.writes = append(world.writes, call)
+	return world.fail[r.Method+" "+r.URL.Path]
+}
+
+func (world *fakeWorld) servePlan(w http.ResponseWriter, r *http.Request) {
+	world.mu.Lock()
+	defer world.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v5/1/tasks")
+	if path == r.URL.Path {
+		world.t.Errorf("unexpected Plan request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+
+	if path == "" && r.Method == http.MethodGet {
+		var tasks []togglplanapi.Task
+		for _, task := range world.tasks {
+			if !world.hidden[task.Id] {
+				tasks = append(tasks, task)
+			}
+		}
+		json.NewEncoder(w).Encode(tasks)
+		return
+	}
+
+	id, err := togglplanapi.ParseID(strings.TrimPrefix(path, "/"))
+	task, ok := world.tasks[id]
+	if err != nil || !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		json.NewEncoder(w).Encode(task)
+	case http.MethodPut:
+		var update togglplanapi.TaskUpdate
+		json.NewDecoder(r.Body).Decode(&update)
+		if world.write(r, *update.Name) {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		task.Name = *update.Name
+		task.UpdatedAt = togglplanapi.DateTime{Time: world.clock.tick()}
+		world.tasks[id] = task
+		json.NewEncoder(w).Encode(task)
+	default:
+		world.t.Errorf("unexpected Plan request %s %s", r.Method, r.URL.Path)
+	}
+}
+
+func (world *fakeWorld) serveCalendar(w http.ResponseWriter, r *http.Request) {
+	world.mu.Lock()
+	defer world.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/calendars/primary/events")
+	if r.Method == http.MethodGet && path == "" {
+		var page struct {
+			Items []Event `json:"items"`
+		}
+		for _, event := range world.events {
+			page.Items = append(page.Items, event)
+		}
+		json.NewEncoder(w).Encode(page)
+		return
+	}
+
+	var event Event
+	json.NewDecoder(r.Body).Decode(&event)
+	if world.write(r, event.Summary) {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		event.Id = fmt.Sprintf("ev%d", len(world.events)+1)
+	case http.MethodPut:
+		event.Id = strings.TrimPrefix(path, "/")
+	case http.MethodDelete:
+		event = world.events[strings.TrimPrefix(path, "/")]
+		event.Status = "cancelled"
+	}
+	event.Updated = world.clock.tick()
+	world.events[event.Id] = event
+	json.NewEncoder(w).Encode(event)
+}
+
+// checkSync runs a sync, and checks its result and the writes it made.
+func checkSync(t *testing.T, world *fakeWorld, syncer *Syncer, want Result, writes ...string) {
+	t.Helper()
+
+	result, err := syncer.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(*result) != fmt.Sprint(want) {
+		t.Errorf("expected %+v, got %+v", want, *result)
+	}
+	if got := world.takeWrites(); strings.Join(got, "\n") != strings.Join(writes, "\n") {
+		t.Errorf("expected the writes %q, got %q", writes, got)
+	}
+}
+
+func TestSync(t *testing.T) {
+	world := newFakeWorld(t)
+	syncer := world.syncer(Options{})
+
+	world.putTask(togglplanapi.Task{Id: 1, Name: "Offsite"})
+	world.putTask(togglplanapi.Task{Id: 2, Name: "Review"})
+	checkSync(t, world, syncer, Result{EventsCreated: 2},
+		"POST /calendars/primary/events Offsite",
+		"POST /calendars/primary/events Review")
+
+	// Nothing changed
+	checkSync(t, world, syncer, Result{})
+
+	world.putTask(togglplanapi.Task{Id: 1, Name: "Team offsite"})
+	checkSync(t, world, syncer, Result{EventsUpdated: 1}, "PUT /calendars/primary/events/ev1 Team offsite")
+
+	event := world.events["ev2"]
+	event.Summary = "Design review"
+	world.putEvent(event)
+	checkSync(t, world, syncer, Result{TasksUpdated: 1}, "PUT /api/v5/1/tasks/2 Design review")
+
+	// A task leaving the window of the feed keeps its event
+	world.hidden[1] = true
+	checkSync(t, world, syncer, Result{})
+
+	delete(world.tasks, 2)
+	checkSync(t, world, syncer, Result{EventsDeleted: 1}, "DELETE /calendars/primary/events/ev2")
+
+	state, _ := syncer.options.Store.Load()
+	if len(state.Mappings) != 1 || state.Mappings[0].TaskId != 1 || state.Mappings[0].EventId != "ev1" {
+		t.Fatalf("expected only task 1 to stay linked, got %+v", state.Mappings)
+	}
+}
+
+func TestSyncConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    ConflictPolicy
+		taskFirst bool
+		write     string
+	}{
+		{"newest is the event", NewestWins, true, "PUT /api/v5/1/tasks/1 Calendar"},
+		{"newest is the task", NewestWins, false, "PUT /calendars/primary/events/ev1 Plan"},
+		{"plan wins", PlanWins, true, "PUT /calendars/primary/events/ev1 Plan"},
+		{"calendar wins", CalendarWins, false, "PUT /api/v5/1/tasks/1 Calendar"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			world := newFakeWorld(t)
+			syncer := world.syncer(Options{Conflicts: test.policy})
+
+			world.putTask(togglplanapi.Task{Id: 1, Name: "Offsite"})
+			checkSync(t, world, syncer, Result{EventsCreated: 1}, "POST /calendars/primary/events Offsite")
+
+			// Both sides change before the next sync
+			event := world.events["ev1"]
+			event.Summary = "Calendar"
+			if test.taskFirst {
+				world.putTask(togglplanapi.Task{Id: 1, Name: "Plan"})
+				world.putEvent(event)
+			} else {
+				world.putEvent(event)
+				world.putTask(togglplanapi.Task{Id: 1, Name: "Plan"})
+			}
+
+			want := Result{Conflicts: 1, EventsUpdated: 1}
+			if strings.Contains(test.write, "/tasks/") {
+				want = Result{Conflicts: 1, TasksUpdated: 1}
+			}
+			checkSync(t, world, syncer, want, test.write)
+		})
+	}
+}
+
+func TestSyncRetriesFailures(t *testing.T) {
+	world := newFakeWorld(t)
+	syncer := world.syncer(Options{})
+
+	world.putTask(togglplanapi.Task{Id: 1, Name: "Offsite"})
+	world.fail["POST /calendars/primary/events"] = true
+
+	result, err := syncer.Sync(context.Background())
+	if err != nil || len(result.Errors) != 1 || result.EventsCreated != 0 {
+		t.Fatalf("expected the event to fail, got %+v, %v", result, err)
+	}
+	world.takeWrites()
+	if state, _ := syncer.options.Store.Load(); !state.LastSync.IsZero() {
+		t.Fatalf("expected the sync time not to be saved after a failure, got %s", state.LastSync)
+	}
+
+	// The feed doesn't report the task again, but the sync retries it
+	world.fail = map[string]bool{}
+	checkSync(t, world, syncer, Result{EventsCreated: 1}, "POST /calendars/primary/events Offsite")
+	if state, _ := syncer.options.Store.Load(); state.LastSync.IsZero() {
+		t.Fatal("expected the sync time to be saved")
+	}
+}
@@ -0,0 +1,103 @@
+package gcal
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"togglplanapi"
+)
+
+// Mapping links a task to the calendar event mirroring it.
+// The update times are those of the last version synced, and are used to
+// detect which side changed since.
+type Mapping struct {
+	TaskId       togglplanapi.ID `json:"task_id"`
+	EventId      string          `json:"event_id"`
+	TaskUpdated  time.Time       `json:"task_updated"`
+	EventUpdated time.Time       `json:"event_updated"`
+}
+
+// State is what a Syncer remembers between runs.
+type State struct {
+	LastSync time.Time `json:"last_sync"`
+	Mappings []Mapping `json:"mappings"`
+}
+
+// Store persists the State of a Syncer.
+type Store interface {
+	Load() (State, error)
+	Save(state State) error
+}
+
+// MemoryStore keeps the state in memory, for syncers running in a single long-lived process.
+type MemoryStore struct {
+	mu    sync.Mutex
+	state State
+}
+
+// Load returns the saved state.
+func (store *MemoryStore) Load() (State, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.state, nil
+}
+
+// Save replaces the saved state.
+func (store *MemoryStore) Save(state State) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.state = state
+	return nil
+}
+
+// FileStore keeps the state in a JSON file, for syncers run periodically
+// (e.g. from cron). A missing file is treated as an empty state.
+type FileStore struct {
+	Path string
+}
+
+// Load reads the state from the file.
+func (store FileStore) Load() (State, error) {
+	var state State
+
+	data, err := os.ReadFile(store.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+// Save writes the state to the file, replacing it atomically so that a crash
+// never leaves a truncated file behind.
+func (store FileStore) Save(state State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(store.Path), filepath.Base(store.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), store.Path)
+}
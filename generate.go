@@ -0,0 +1,5 @@
+package togglplanapi
+
+// Calls described in openapi.json are generated into generated.go. To add an
+// endpoint, describe it there and run go generate.
+//go:generate go run ./internal/apigen -spec openapi.json -out generated.go
@@ -0,0 +1,51 @@
+// Code generated by apigen from openapi.json. DO NOT EDIT.
+
+package togglplanapi
+
+import (
+	"context"
+)
+
+// Me represents the user the client is authenticated as.
+type Me struct {
+	Id        ID       `json:"id"`
+	Name      string   `json:"name"`
+	Email     string   `json:"email"`
+	AvatarURL string   `json:"avatar_url"`
+	CreatedAt DateTime `json:"created_at"`
+	UpdatedAt DateTime `json:"updated_at"`
+}
+
+// Workspace represents a workspace the user is a member of.
+type Workspace struct {
+	Id        ID       `json:"id"`
+	Name      string   `json:"name"`
+	Timezone  string   `json:"timezone"`
+	WeekStart int      `json:"week_start"`
+	CreatedAt DateTime `json:"created_at"`
+	UpdatedAt DateTime `json:"updated_at"`
+}
+
+// GetMe fetches the user the client is authenticated as.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+func GetMe(ctx context.Context, pa *togglPlanApi) (*Me, error) {
+	var result Me
+	if err := getJSON(ctx, pa, "/me", nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// GetWorkspaces fetches the workspaces the user is a member of.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+func GetWorkspaces(ctx context.Context, pa *togglPlanApi) ([]Workspace, error) {
+	var result []Workspace
+	err := getJSON(ctx, pa, "/workspaces", nil, &result)
+	return result, err
+}
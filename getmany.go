@@ -0,0 +1,100 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+)
+
+// GetManyTasks fetches several tasks by ID. The API has no endpoint to fetch
+// tasks by ID in bulk, so they are fetched one by one, with at most
+// concurrency requests at a time.
+//
+// Tasks are returned keyed by ID, and so are the errors of the tasks that
+// couldn't be fetched; errors.Is(err, ErrNotFound) tells deleted tasks apart.
+// Duplicate IDs are fetched once.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	ids: IDs of the tasks
+//	concurrency: Maximum number of requests sent at the same time (values below 1 mean 1)
+func GetManyTasks(ctx context.Context, pa *togglPlanApi, workspaceId ID, ids []ID, concurrency int) (map[ID]Task, map[ID]error) {
+	return getMany(ctx, ids, concurrency, func(ctx context.Context, id ID) (*Task, error) {
+		return GetTask(NotFoundAsNil(ctx, false), pa, workspaceId, id)
+	})
+}
+
+// GetManyProjects fetches several projects by ID. Since all projects of a
+// workspace come in a single response, this takes one request no matter how
+// many IDs are asked for.
+//
+// Projects are returned keyed by ID, and so are errors: IDs missing from the
+// workspace get an error matching ErrNotFound, and all IDs get the error of
+// the request if it fails.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	ids: IDs of the projects
+func GetManyProjects(ctx context.Context, pa *togglPlanApi, workspaceId ID, ids []ID) (map[ID]Project, map[ID]error) {
+	found := map[ID]Project{}
+	errs := map[ID]error{}
+
+	projects, err := GetProjects(ctx, pa, workspaceId)
+	if err != nil {
+		for _, id := range ids {
+			errs[id] = err
+		}
+		return found, errs
+	}
+
+	byId := make(map[ID]Project, len(projects))
+	for _, project := range projects {
+		byId[project.Id] = project
+	}
+
+	for _, id := range ids {
+		if project, ok := byId[id]; ok {
+			found[id] = project
+		} else {
+			errs[id] = fmt.Errorf("project %d: %w", id, ErrNotFound)
+		}
+	}
+	return found, errs
+}
+
+// getMany calls get for each distinct ID, with at most concurrency calls at
+// a time using RunBatch, and collects the results and errors by ID.
+func getMany[T any](ctx context.Context, ids []ID, concurrency int, get func(ctx context.Context, id ID) (*T, error)) (map[ID]T, map[ID]error) {
+	var distinct []ID
+	seen := map[ID]bool{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			distinct = append(distinct, id)
+		}
+	}
+
+	results := make([]*T, len(distinct))
+	jobs := make([]func(ctx context.Context) error, len(distinct))
+	for i, id := range distinct {
+		i, id := i, id
+		jobs[i] = func(ctx context.Context) (err error) {
+			results[i], err = get(ctx, id)
+			return err
+		}
+	}
+
+	found := map[ID]T{}
+	errs := map[ID]error{}
+	for i, err := range RunBatch(ctx, concurrency, jobs) {
+		if err != nil {
+			errs[distinct[i]] = err
+			continue
+		}
+		found[distinct[i]] = *results[i]
+	}
+	return found, errs
+}
@@ -0,0 +1,77 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGetManyTasks(t *testing.T) {
+	var requests int32
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		switch r.URL.Path {
+		case "/api/v5/42/tasks/1":
+			fmt.Fprint(w, `{"id":1,"name":"Design"}`)
+		case "/api/v5/42/tasks/2":
+			fmt.Fprint(w, `{"id":2,"name":"Build"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	tasks, errs := GetManyTasks(context.Background(), pa, 42, []ID{1, 2, 3, 1}, 2)
+
+	if len(tasks) != 2 || tasks[1].Name != "Design" || tasks[2].Name != "Build" {
+		t.Fatalf("unexpected tasks %+v", tasks)
+	}
+	if len(errs) != 1 || !errors.Is(errs[3], ErrNotFound) {
+		t.Fatalf("expected task 3 not to be found, got %v", errs)
+	}
+	if requests != 3 {
+		t.Fatalf("expected duplicate IDs to be fetched once, got %d requests", requests)
+	}
+}
+
+func TestGetManyProjects(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":1,"name":"Website"},{"id":2,"name":"App"}]`)
+	})
+
+	projects, errs := GetManyProjects(context.Background(), pa, 42, []ID{2, 5})
+	if len(projects) != 1 || projects[2].Name != "App" {
+		t.Fatalf("unexpected projects %+v", projects)
+	}
+	if len(errs) != 1 || !errors.Is(errs[5], ErrNotFound) {
+		t.Fatalf("expected project 5 not to be found, got %v", errs)
+	}
+}
+
+// TestGetManyTasksFetchesOneToken is best run with -race.
+func TestGetManyTasksFetchesOneToken(t *testing.T) {
+	var tokens int32
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v5/authenticate/token" {
+			atomic.AddInt32(&tokens, 1)
+			fmt.Fprint(w, `{"access_token":"fresh"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"id":%s}`, r.URL.Path[len("/api/v5/42/tasks/"):])
+	})
+	pa.bearerToken = ""
+
+	tasks, errs := GetManyTasks(context.Background(), pa, 42, []ID{1, 2, 3, 4, 5, 6}, 6)
+	if len(tasks) != 6 || len(errs) != 0 {
+		t.Fatalf("unexpected tasks %+v and errors %v", tasks, errs)
+	}
+	if tokens != 1 {
+		t.Fatalf("expected a single token request, got %d", tokens)
+	}
+}
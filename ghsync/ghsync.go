@@ -0,0 +1,360 @@
+/*
+Package ghsync mirrors the issues of a GitHub repository into Toggl Plan
+tasks, so that engineering work shows up on the team timeline.
+
+Each issue is mirrored into a task of a project, which is found again on the
+next run by the external ID stored in its notes (see
+togglplanapi.ExternalId). Issue milestones become Toggl Plan milestones of
+the same name. Optionally, marking a task as done or not done in Toggl Plan
+closes or reopens its issue.
+
+Example Usage:
+
+	import (
+		"context"
+		"time"
+
+		"github.com/ricotheque/togglplanapi"
+		"github.com/ricotheque/togglplanapi/ghsync"
+	)
+
+	func main() {
+		pa := togglplanapi.New(username, password, clientId, clientSecret, "")
+
+		result, err := ghsync.Sync(context.Background(), pa, workspaceId, ghsync.Options{
+			Owner:     "acme",
+			Repo:      "website",
+			Token:     githubToken,
+			ProjectId: projectId,
+			Since:     time.Now().AddDate(-1, 0, 0),
+		})
+	}
+*/
+package ghsync
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"togglplanapi"
+)
+
+// Options configures Sync.
+type Options struct {
+	// Owner and Repo name the GitHub repository.
+	Owner string
+	Repo  string
+
+	// Token is a GitHub token with access to the repository's issues.
+	Token string
+
+	// BaseURL is the GitHub API URL. Defaults to https://api.github.com, and
+	// can be set for GitHub Enterprise Server.
+	BaseURL string
+
+	// HTTPClient sends the GitHub requests. Defaults to a client with a one minute timeout.
+	HTTPClient *http.Client
+
+	// ProjectId is the Toggl Plan project issues are mirrored into.
+	ProjectId togglplanapi.ID
+
+	// Since is the earliest creation date of mirrored issues. Tasks can only
+	// be listed by date, so this also bounds the search for previously mirrored tasks.
+	Since time.Time
+
+	// Assignees maps GitHub logins to workspace member IDs.
+	Assignees map[string]togglplanapi.ID
+
+	// TwoWay closes or reopens issues when their task is marked as done or not
+	// done in Toggl Plan after the issue was last updated.
+	TwoWay bool
+}
+
+// baseURL returns the GitHub API URL.
+func (options Options) baseURL() string {
+	if options.BaseURL == "" {
+		return "https://api.github.com"
+	}
+	return strings.TrimRight(options.BaseURL, "/")
+}
+
+// httpClient returns the client used for GitHub requests.
+func (options Options) httpClient() *http.Client {
+	if options.HTTPClient == nil {
+		return &http.Client{Timeout: time.Minute}
+	}
+	return options.HTTPClient
+}
+
+// Result reports what Sync changed.
+type Result struct {
+	Created int
+	Updated int
+
+	// Pushed counts the issues closed or reopened from Toggl Plan.
+	Pushed int
+
+	// Errors lists the issues that couldn't be synced, keyed by issue number.
+	Errors map[int]error
+}
+
+// IssueId returns the external ID of an issue, as stored in its task's notes.
+func (options Options) IssueId(number int) string {
+	return fmt.Sprintf("github:%s/%s#%d", options.Owner, options.Repo, number)
+}
+
+// Sync mirrors the issues of a repository created since options.Since into
+// tasks of options.ProjectId, creating, updating and (with TwoWay) pushing
+// state back as needed.
+//
+// Tasks start on the day their issue was created and end on the due date of
+// the issue's milestone, the day it was closed, or the day it was created,
+// whichever applies first.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	options: Repository, target project and sync settings
+func Sync(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, options Options) (*Result, error) {
+	issues, err := ListIssues(ctx, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var recent []Issue
+	for _, issue := range issues {
+		if !issue.CreatedAt.Before(options.Since) {
+			recent = append(recent, issue)
+		}
+	}
+
+	filter := togglplanapi.TaskFilter{
+		Since:      togglplanapi.DateOf(options.Since),
+		Until:      togglplanapi.Today(time.Local).AddDate(1, 0, 0),
+		ProjectIds: []togglplanapi.ID{options.ProjectId},
+	}
+	tasks, err := togglplanapi.ListAllTasks(ctx, pa, workspaceId, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	milestones, err := syncMilestones(ctx, pa, workspaceId, options, recent)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{Errors: map[int]error{}}
+
+	for _, change := range plan(options, recent, tasks, milestones) {
+		var err error
+
+		switch {
+		case change.push:
+			err = setIssueState(ctx, options, change.issue.Number, change.task.Done)
+			if err == nil {
+				result.Pushed++
+			}
+		case change.task == nil:
+			_, err = togglplanapi.CreateTask(ctx, pa, workspaceId, change.params)
+			if err == nil {
+				result.Created++
+			}
+		default:
+			_, err = togglplanapi.UpdateTask(ctx, pa, workspaceId, change.task.Id, change.update)
+			if err == nil {
+				result.Updated++
+			}
+		}
+
+		if err != nil {
+			result.Errors[change.issue.Number] = err
+		}
+	}
+
+	return result, nil
+}
+
+// change is a single action needed to bring an issue and its task in sync.
+type change struct {
+	issue Issue
+
+	// task is the existing task of the issue, or nil if it must be created with params.
+	task   *togglplanapi.Task
+	params togglplanapi.TaskParams
+
+	// update holds the fields to change on task.
+	update togglplanapi.TaskUpdate
+
+	// push means the task's done state must be copied to the issue instead.
+	push bool
+}
+
+// plan works out the changes needed to mirror issues into tasks.
+func plan(options Options, issues []Issue, tasks []togglplanapi.Task, milestones map[int]togglplanapi.ID) []change {
+	byExternalId := map[string]*togglplanapi.Task{}
+	for i := range tasks {
+		if id := togglplanapi.ExternalId(tasks[i].Notes); id != "" {
+			byExternalId[id] = &tasks[i]
+		}
+	}
+
+	var changes []change
+	for _, issue := range issues {
+		params := taskParams(options, issue, milestones)
+		task := byExternalId[options.IssueId(issue.Number)]
+
+		if task == nil {
+			changes = append(changes, change{issue: issue, params: params})
+			continue
+		}
+
+		if options.TwoWay && task.Done != issue.Closed() && task.UpdatedAt.After(issue.UpdatedAt) {
+			changes = append(changes, change{issue: issue, task: task, push: true})
+			continue
+		}
+
+		if update, changed := taskUpdate(*task, params); changed {
+			changes = append(changes, change{issue: issue, task: task, update: update})
+		}
+	}
+
+	return changes
+}
+
+// taskParams returns the task mirroring an issue.
+func taskParams(options Options, issue Issue, milestones map[int]togglplanapi.ID) togglplanapi.TaskParams {
+	start := togglplanapi.DateOf(issue.CreatedAt)
+	end := start
+	switch {
+	case issue.Milestone != nil && issue.Milestone.DueOn != nil:
+		end = togglplanapi.DateOf(*issue.Milestone.DueOn)
+	case issue.ClosedAt != nil:
+		end = togglplanapi.DateOf(*issue.ClosedAt)
+	}
+	if end.Before(start) {
+		end = start
+	}
+
+	notes := strings.TrimSpace(issue.Body)
+	if issue.HTMLURL != "" {
+		notes = strings.TrimSpace(issue.HTMLURL + "\n\n" + notes)
+	}
+
+	params := togglplanapi.TaskParams{
+		Name:      fmt.Sprintf("#%d %s", issue.Number, issue.Title),
+		Notes:     togglplanapi.SetExternalId(notes, options.IssueId(issue.Number)),
+		StartDate: start,
+		EndDate:   end,
+		Done:      issue.Closed(),
+		ProjectId: options.ProjectId,
+	}
+
+	if issue.Milestone != nil {
+		params.MilestoneId = milestones[issue.Milestone.Number]
+	}
+
+	for _, assignee := range issue.Assignees {
+		if id, ok := options.Assignees[assignee.Login]; ok {
+			params.Assignees = append(params.Assignees, id)
+		}
+	}
+
+	for _, label := range issue.Labels {
+		params.Tags = append(params.Tags, label.Name)
+	}
+
+	return params
+}
+
+// taskUpdate returns the fields of task that differ from params.
+// Dates are left alone once the task exists, since planners may have moved it.
+func taskUpdate(task togglplanapi.Task, params togglplanapi.TaskParams) (togglplanapi.TaskUpdate, bool) {
+	var update togglplanapi.TaskUpdate
+	changed := false
+
+	if task.Name != params.Name {
+		update.Name = &params.Name
+		changed = true
+	}
+	if task.Notes != params.Notes {
+		update.Notes = &params.Notes
+		changed = true
+	}
+	if task.Done != params.Done {
+		update.Done = &params.Done
+		changed = true
+	}
+	if task.MilestoneId != params.MilestoneId {
+		update.MilestoneId = togglplanapi.OptionalId(params.MilestoneId)
+		changed = true
+	}
+	if fmt.Sprint(task.Assignees) != fmt.Sprint(params.Assignees) {
+		assignees := params.Assignees
+		update.Assignees = &assignees
+		changed = true
+	}
+	if fmt.Sprint(task.Tags) != fmt.Sprint(params.Tags) {
+		tags := params.Tags
+		update.Tags = &tags
+		changed = true
+	}
+
+	return update, changed
+}
+
+// syncMilestones makes sure a Toggl Plan milestone exists for every GitHub
+// milestone with a due date used by issues, matching them by name within the
+// project. It returns the Toggl Plan milestone IDs keyed by GitHub milestone number.
+func syncMilestones(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, options Options, issues []Issue) (map[int]togglplanapi.ID, error) {
+	existing, err := togglplanapi.GetMilestones(ctx, pa, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]togglplanapi.Milestone{}
+	for _, milestone := range existing {
+		if milestone.ProjectId == options.ProjectId {
+			byName[milestone.Name] = milestone
+		}
+	}
+
+	ids := map[int]togglplanapi.ID{}
+	for _, issue := range issues {
+		if issue.Milestone == nil || issue.Milestone.DueOn == nil {
+			continue
+		}
+		if _, done := ids[issue.Milestone.Number]; done {
+			continue
+		}
+
+		params := togglplanapi.MilestoneParams{
+			Name:      issue.Milestone.Title,
+			Date:      togglplanapi.DateOf(*issue.Milestone.DueOn),
+			ProjectId: options.ProjectId,
+		}
+
+		milestone, ok := byName[params.Name]
+		switch {
+		case !ok:
+			created, err := togglplanapi.CreateMilestone(ctx, pa, workspaceId, params)
+			if err != nil {
+				return nil, err
+			}
+			milestone = *created
+		case milestone.Date != params.Date:
+			updated, err := togglplanapi.UpdateMilestone(ctx, pa, workspaceId, milestone.Id, togglplanapi.MilestoneUpdate{Date: &params.Date})
+			if err != nil {
+				return nil, err
+			}
+			milestone = *updated
+		}
+
+		ids[issue.Milestone.Number] = milestone.Id
+	}
+
+	return ids, nil
+}
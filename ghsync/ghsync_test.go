@@ -0,0 +1,194 @@
+package ghsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"togglplanapi"
+	"togglplanapi/internal/testclient"
+)
+
+func TestListIssues(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("page") == "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s/repos/acme/web/issues?page=2>; rel="next"`, server.URL))
+			fmt.Fprint(w, `[{"number":1,"title":"Bug"},{"number":2,"title":"PR","pull_request":{}}]`)
+			return
+		}
+		fmt.Fprint(w, `[{"number":3,"title":"Feature"}]`)
+	}))
+	defer server.Close()
+
+	options := Options{Owner: "acme", Repo: "web", Token: "gh-token", BaseURL: server.URL}
+
+	issues, err := ListIssues(context.Background(), options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(issues) != 2 || issues[0].Number != 1 || issues[1].Number != 3 {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	options := Options{Owner: "acme", Repo: "web", ProjectId: 5, TwoWay: true}
+	created := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	issues := []Issue{
+		{Number: 1, Title: "New", State: "open", CreatedAt: created},
+		{Number: 2, Title: "Renamed", State: "open", CreatedAt: created, UpdatedAt: created},
+		{Number: 3, Title: "Done in Plan", State: "open", CreatedAt: created, UpdatedAt: created},
+	}
+
+	existing := func(number int, name string, done bool) togglplanapi.Task {
+		params := taskParams(options, Issue{Number: number, Title: name, CreatedAt: created}, nil)
+		return togglplanapi.Task{
+			Id:        togglplanapi.ID(number * 10),
+			Name:      params.Name,
+			Notes:     params.Notes,
+			Done:      done,
+			UpdatedAt: togglplanapi.DateTime{Time: created.Add(time.Hour)},
+		}
+	}
+
+	tasks := []togglplanapi.Task{
+		existing(2, "Old name", false),
+		existing(3, "Done in Plan", true),
+	}
+
+	changes := plan(options, issues, tasks, nil)
+	if len(changes) != 3 {
+		t.Fatalf("expected 3 changes, got %+v", changes)
+	}
+
+	if changes[0].task != nil || changes[0].params.StartDate != togglplanapi.NewDate(2024, 3, 4) || togglplanapi.ExternalId(changes[0].params.Notes) != "github:acme/web#1" {
+		t.Fatalf("expected issue 1 to be created, got %+v", changes[0])
+	}
+
+	if changes[1].update.Name == nil || *changes[1].update.Name != "#2 Renamed" {
+		t.Fatalf("expected issue 2 to be renamed, got %+v", changes[1])
+	}
+
+	if !changes[2].push {
+		t.Fatalf("expected issue 3 to be closed from Toggl Plan, got %+v", changes[2])
+	}
+}
+
+func TestSync(t *testing.T) {
+	options := Options{Owner: "acme", Repo: "web", Token: "gh-token", ProjectId: 5, TwoWay: true,
+		Since: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Assignees: map[string]togglplanapi.ID{"ada": 3}}
+	created := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	var pushed []string
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /repos/acme/web/issues":
+			fmt.Fprint(w, `[
+				{"number":1,"title":"New","state":"open","created_at":"2024-03-04T10:00:00Z","updated_at":"2024-03-04T10:00:00Z",
+				 "milestone":{"number":4,"title":"v1","due_on":"2024-04-01T07:00:00Z"},"assignees":[{"login":"ada"}],"labels":[{"name":"bug"}]},
+				{"number":2,"title":"Renamed","state":"open","created_at":"2024-03-04T10:00:00Z","updated_at":"2024-03-04T10:00:00Z"},
+				{"number":3,"title":"Done in Plan","state":"open","created_at":"2024-03-04T10:00:00Z","updated_at":"2024-03-04T10:00:00Z"},
+				{"number":4,"title":"Ancient","state":"open","created_at":"2023-06-01T10:00:00Z","updated_at":"2023-06-01T10:00:00Z"}
+			]`)
+		case "PATCH /repos/acme/web/issues/3":
+			body, _ := io.ReadAll(r.Body)
+			pushed = append(pushed, string(body))
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected GitHub request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	}))
+	defer github.Close()
+	options.BaseURL = github.URL
+
+	existing := func(number int, name string, done bool) togglplanapi.Task {
+		params := taskParams(options, Issue{Number: number, Title: name, CreatedAt: created}, nil)
+		return togglplanapi.Task{
+			Id:        togglplanapi.ID(number * 10),
+			Name:      params.Name,
+			Notes:     params.Notes,
+			StartDate: params.StartDate,
+			EndDate:   params.EndDate,
+			Done:      done,
+			UpdatedAt: togglplanapi.DateTime{Time: created.Add(time.Hour)},
+		}
+	}
+
+	var milestones []togglplanapi.MilestoneParams
+	var tasks []togglplanapi.TaskParams
+	var updates []map[string]interface{}
+	pa := testclient.New(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v5/1/tasks":
+			if r.URL.Query().Get("project_ids") != "5" {
+				t.Errorf("expected the tasks of the project, got %s", r.URL.RawQuery)
+			}
+			json.NewEncoder(w).Encode([]togglplanapi.Task{existing(2, "Old name", false), existing(3, "Done in Plan", true)})
+		case "GET /api/v5/1/milestones":
+			fmt.Fprint(w, `[{"id":8,"name":"v1","project_id":6,"date":"2024-04-01"}]`)
+		case "POST /api/v5/1/milestones":
+			var params togglplanapi.MilestoneParams
+			json.NewDecoder(r.Body).Decode(&params)
+			milestones = append(milestones, params)
+			fmt.Fprintf(w, `{"id":9,"name":%q}`, params.Name)
+		case "POST /api/v5/1/tasks":
+			var params togglplanapi.TaskParams
+			json.NewDecoder(r.Body).Decode(&params)
+			tasks = append(tasks, params)
+			fmt.Fprintf(w, `{"id":10,"name":%q}`, params.Name)
+		case "PUT /api/v5/1/tasks/20":
+			var update map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&update)
+			updates = append(updates, update)
+			fmt.Fprint(w, `{"id":20}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+
+	result, err := Sync(context.Background(), pa, 1, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 1 || result.Updated != 1 || result.Pushed != 1 || len(result.Errors) != 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	// The milestone of another project with the same name isn't reused
+	if len(milestones) != 1 || milestones[0].Name != "v1" || milestones[0].ProjectId != 5 || milestones[0].Date != togglplanapi.NewDate(2024, 4, 1) {
+		t.Fatalf("unexpected milestones %+v", milestones)
+	}
+
+	if len(tasks) != 1 {
+		t.Fatalf("expected issue 1 only to be created, got %+v", tasks)
+	}
+	task := tasks[0]
+	if task.Name != "#1 New" || togglplanapi.ExternalId(task.Notes) != "github:acme/web#1" || task.ProjectId != 5 || task.MilestoneId != 9 ||
+		task.StartDate != togglplanapi.NewDate(2024, 3, 4) || task.EndDate != togglplanapi.NewDate(2024, 4, 1) ||
+		len(task.Assignees) != 1 || task.Assignees[0] != 3 || len(task.Tags) != 1 || task.Tags[0] != "bug" {
+		t.Errorf("unexpected task %+v", task)
+	}
+
+	// Only the name of issue 2 changed, so only the name is sent
+	if len(updates) != 1 || len(updates[0]) != 1 || updates[0]["name"] != "#2 Renamed" {
+		t.Errorf("unexpected updates %v", updates)
+	}
+
+	if len(pushed) != 1 || pushed[0] != `{"state":"closed"}` {
+		t.Errorf("expected issue 3 to be closed, got %v", pushed)
+	}
+}
@@ -0,0 +1,138 @@
+package ghsync
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Issue is a GitHub issue, reduced to the fields mirrored into Toggl Plan.
+type Issue struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	State     string     `json:"state"`
+	HTMLURL   string     `json:"html_url"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	Milestone *Milestone `json:"milestone"`
+	Assignees []struct {
+		Login string `json:"login"`
+	} `json:"assignees"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// Closed reports whether the issue is closed.
+func (issue Issue) Closed() bool {
+	return issue.State == "closed"
+}
+
+// Milestone is a GitHub milestone.
+type Milestone struct {
+	Number int        `json:"number"`
+	Title  string     `json:"title"`
+	DueOn  *time.Time `json:"due_on"`
+}
+
+// nextLink finds the URL of the next page in a Link header.
+var nextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// githubRequest sends an authenticated request to the GitHub API and decodes
+// the response into out, unless out is nil. It returns the URL of the next
+// page of results, if any.
+func githubRequest(ctx context.Context, options Options, method string, url string, body interface{}, out interface{}) (string, error) {
+	var payload *strings.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return "", err
+		}
+		payload = strings.NewReader(string(encoded))
+	} else {
+		payload = strings.NewReader("")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, payload)
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if options.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+options.Token)
+	}
+
+	resp, err := options.httpClient().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("github: %s %s: %s", method, url, http.StatusText(resp.StatusCode))
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return "", err
+		}
+	}
+
+	next := ""
+	if match := nextLink.FindStringSubmatch(resp.Header.Get("Link")); match != nil {
+		next = match[1]
+	}
+	return next, nil
+}
+
+// ListIssues fetches all issues of the repository, open and closed.
+// Pull requests, which GitHub lists as issues too, are left out.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	options: Repository and credentials
+func ListIssues(ctx context.Context, options Options) ([]Issue, error) {
+	var issues []Issue
+
+	url := fmt.Sprintf("%s/repos/%s/%s/issues?state=all&per_page=100", options.baseURL(), options.Owner, options.Repo)
+	for url != "" {
+		var page []Issue
+		next, err := githubRequest(ctx, options, "GET", url, nil, &page)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, issue := range page {
+			if issue.PullRequest == nil {
+				issues = append(issues, issue)
+			}
+		}
+		url = next
+	}
+
+	return issues, nil
+}
+
+// setIssueState opens or closes an issue.
+func setIssueState(ctx context.Context, options Options, number int, closed bool) error {
+	state := "open"
+	if closed {
+		state = "closed"
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d", options.baseURL(), options.Owner, options.Repo, number)
+	_, err := githubRequest(ctx, options, "PATCH", url, map[string]string{"state": state}, nil)
+	return err
+}
@@ -2,6 +2,9 @@ module togglplanapi
 
 go 1.20
 
-require github.com/hashicorp/go-retryablehttp v0.7.4
+require (
+	github.com/hashicorp/go-retryablehttp v0.7.4
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require github.com/hashicorp/go-cleanhttp v0.5.2 // indirect
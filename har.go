@@ -0,0 +1,457 @@
+package togglplanapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// defaultMaxHARBody is the most of a body kept in a HAR entry by default.
+const defaultMaxHARBody = 64 << 10
+
+// harRedacted replaces the values HARRecorder leaves out.
+const harRedacted = "[redacted]"
+
+// Headers and fields HARRecorder always redacts, in lower case.
+var (
+	harSecretHeaders = []string{"authorization", "proxy-authorization", "cookie", "set-cookie"}
+	harSecretFields  = []string{"password", "username", "client_secret", "access_token", "refresh_token", "token"}
+)
+
+// HAROptions configures a HARRecorder. Zero fields keep the defaults.
+type HAROptions struct {
+	// MaxBodySize caps the bytes of each request and response body kept in
+	// the archive. Longer bodies are cut, and say so in their comment, except
+	// JSON and form bodies, which are left out as they can't be redacted.
+	// Defaults to 64 KB.
+	MaxBodySize int
+	// RedactHeaders lists more headers whose values are left out, such as
+	// the signature of SignRequests. Authorization, Proxy-Authorization,
+	// Cookie and Set-Cookie always are.
+	RedactHeaders []string
+	// RedactFields lists more fields of JSON and form bodies, and parameters
+	// of query strings, whose values are left out. Passwords, usernames,
+	// client secrets and tokens always are.
+	RedactFields []string
+}
+
+// HARRecorder records the requests of a client and their responses as an
+// HTTP Archive (HAR 1.2), which browsers' developer tools and HAR viewers
+// open, e.g. to attach a trace to a support ticket or an issue. Credentials
+// are left out: see HAROptions. Install it with WithHAR, and write it out
+// with WriteTo. A HARRecorder is safe for concurrent use.
+type HARRecorder struct {
+	options       HAROptions
+	secretHeaders map[string]bool
+	secretFields  map[string]bool
+
+	mu      sync.Mutex
+	entries []*harEntry
+}
+
+// NewHARRecorder returns an empty recorder.
+func NewHARRecorder(options HAROptions) *HARRecorder {
+	if options.MaxBodySize <= 0 {
+		options.MaxBodySize = defaultMaxHARBody
+	}
+
+	r := &HARRecorder{options: options, secretHeaders: map[string]bool{}, secretFields: map[string]bool{}}
+	for _, name := range append(harSecretHeaders, options.RedactHeaders...) {
+		r.secretHeaders[strings.ToLower(name)] = true
+	}
+	for _, name := range append(harSecretFields, options.RedactFields...) {
+		r.secretFields[strings.ToLower(name)] = true
+	}
+	return r
+}
+
+// WithHAR records every attempt of the requests of the client in recorder,
+// retries and redirects included, as middleware. Given after other
+// WithMiddleware options, it records requests as they are finally sent.
+func WithHAR(recorder *HARRecorder) Option {
+	return WithMiddleware(recorder.middleware)
+}
+
+// WriteTo writes the archive recorded so far as JSON. Responses whose body
+// is still being read are written with the part read so far.
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	archive := harArchive{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "togglplanapi", Version: Version},
+		Entries: make([]harEntry, len(r.entries)),
+	}}
+	for i, entry := range r.entries {
+		archive.Log.Entries[i] = *entry
+	}
+	r.mu.Unlock()
+
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// Reset drops the entries recorded so far, e.g. to record each session of a
+// long-running process in its own archive.
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// middleware records each request that goes through next.
+func (r *HARRecorder) middleware(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		entry := &harEntry{
+			StartedDateTime: time.Now(),
+			Request:         r.request(req),
+			Cache:           struct{}{},
+		}
+
+		sent := req
+		if req.Body != nil && req.Body != http.NoBody {
+			// Only keep the start of the body, and stream the rest
+			head, err := io.ReadAll(io.LimitReader(req.Body, int64(r.options.MaxBodySize)+1))
+			if err != nil {
+				req.Body.Close()
+				return nil, err
+			}
+			sent = req.Clone(req.Context())
+			sent.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(head), req.Body), req.Body}
+			entry.Request.BodySize = req.ContentLength
+			if entry.Request.BodySize <= 0 {
+				entry.Request.BodySize = -1
+				if len(head) <= r.options.MaxBodySize {
+					entry.Request.BodySize = int64(len(head))
+				}
+			}
+			entry.Request.PostData = &harPostData{MimeType: req.Header.Get("Content-Type")}
+			entry.Request.PostData.Text, entry.Request.PostData.Comment = r.body(head, entry.Request.PostData.MimeType)
+		}
+
+		resp, err := next.RoundTrip(sent)
+		wait := time.Since(entry.StartedDateTime)
+		entry.Time = milliseconds(wait)
+		entry.Timings = harTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: milliseconds(wait)}
+		if err != nil {
+			entry.Error = err.Error()
+			entry.Response = harResponse{HTTPVersion: req.Proto, Headers: []harNameValue{}, Cookies: []harCookie{}, HeadersSize: -1, BodySize: -1}
+			r.add(entry)
+			return nil, err
+		}
+
+		entry.Response = r.response(resp)
+		r.add(entry)
+		resp.Body = &harBody{ReadCloser: resp.Body, recorder: r, entry: entry, mimeType: entry.Response.Content.MimeType, start: time.Now()}
+		return resp, nil
+	})
+}
+
+// add appends entry to the archive.
+func (r *HARRecorder) add(entry *harEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+// request records req without its body.
+func (r *HARRecorder) request(req *http.Request) harRequest {
+	redactedURL := *req.URL
+	query := redactedURL.Query()
+	r.redactValues(query)
+	if redactedURL.RawQuery != "" {
+		redactedURL.RawQuery = query.Encode()
+	}
+	redactedURL.User = nil
+
+	queryString := []harNameValue{}
+	for _, name := range sortedKeys(query) {
+		for _, value := range query[name] {
+			queryString = append(queryString, harNameValue{Name: name, Value: value})
+		}
+	}
+
+	return harRequest{
+		Method:      req.Method,
+		URL:         redactedURL.String(),
+		HTTPVersion: req.Proto,
+		Headers:     r.headers(req.Header),
+		QueryString: queryString,
+		Cookies:     []harCookie{},
+		HeadersSize: -1,
+	}
+}
+
+// response records resp, whose body is filled in as it is read.
+func (r *HARRecorder) response(resp *http.Response) harResponse {
+	redirectURL := ""
+	if location, err := resp.Location(); err == nil {
+		redirectURL = location.String()
+	}
+	return harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode)+" "),
+		HTTPVersion: resp.Proto,
+		Headers:     r.headers(resp.Header),
+		Cookies:     []harCookie{},
+		Content:     harContent{MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: redirectURL,
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+}
+
+// headers records header, sorted by name, with secrets redacted.
+func (r *HARRecorder) headers(header http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for _, name := range sortedKeys(header) {
+		for _, value := range header[name] {
+			if r.secretHeaders[strings.ToLower(name)] {
+				value = harRedacted
+			}
+			headers = append(headers, harNameValue{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+// body returns the text of body to record, with its secret fields redacted,
+// and a comment if it was cut or left out.
+func (r *HARRecorder) body(body []byte, contentType string) (string, string) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	cut := len(body) > r.options.MaxBodySize
+	if cut {
+		end := r.options.MaxBodySize
+		for end > 0 && !utf8.RuneStart(body[end]) {
+			end--
+		}
+		body = body[:end]
+	}
+
+	switch {
+	case strings.Contains(mediaType, "json"):
+		var value interface{}
+		if cut {
+			return "", fmt.Sprintf("body over %d bytes left out, as it can't be redacted", r.options.MaxBodySize)
+		}
+		if json.Unmarshal(body, &value) == nil {
+			redacted, _ := json.Marshal(r.redact(value))
+			return string(redacted), ""
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		if cut {
+			return "", fmt.Sprintf("body over %d bytes left out, as it can't be redacted", r.options.MaxBodySize)
+		}
+		if form, err := url.ParseQuery(string(body)); err == nil {
+			r.redactValues(form)
+			return form.Encode(), ""
+		}
+	}
+
+	if !utf8.Valid(body) {
+		return "", "binary body left out"
+	}
+	if cut {
+		return string(body), fmt.Sprintf("body cut after %d bytes", len(body))
+	}
+	return string(body), ""
+}
+
+// redactValues redacts the secret fields of values, such as a form or a
+// query string.
+func (r *HARRecorder) redactValues(values url.Values) {
+	for name, list := range values {
+		if r.secretFields[strings.ToLower(name)] {
+			for i := range list {
+				list[i] = harRedacted
+			}
+		}
+	}
+}
+
+// redact returns value, decoded from JSON, with its secret fields redacted.
+func (r *HARRecorder) redact(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if r.secretFields[strings.ToLower(key)] {
+				value[key] = harRedacted
+			} else {
+				value[key] = r.redact(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = r.redact(item)
+		}
+	}
+	return value
+}
+
+// harBody records a response body as it is read, up to the size limit of
+// the recorder.
+type harBody struct {
+	io.ReadCloser
+	recorder *HARRecorder
+	entry    *harEntry
+	mimeType string
+	start    time.Time
+	head     bytes.Buffer
+	size     int64
+	once     sync.Once
+}
+
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if room := b.recorder.options.MaxBodySize + 1 - b.head.Len(); room > 0 {
+		if room > n {
+			room = n
+		}
+		b.head.Write(p[:room])
+	}
+	if err == io.EOF {
+		b.record()
+	}
+	return n, err
+}
+
+func (b *harBody) Close() error {
+	b.record()
+	return b.ReadCloser.Close()
+}
+
+// record fills in the content of the response with what was read.
+func (b *harBody) record() {
+	b.once.Do(func() {
+		text, comment := b.recorder.body(b.head.Bytes(), b.mimeType)
+		receive := milliseconds(time.Since(b.start))
+
+		b.recorder.mu.Lock()
+		defer b.recorder.mu.Unlock()
+		b.entry.Response.BodySize = b.size
+		b.entry.Response.Content.Size = b.size
+		b.entry.Response.Content.Text = text
+		b.entry.Response.Content.Comment = comment
+		b.entry.Timings.Receive = receive
+		b.entry.Time += receive
+	})
+}
+
+// milliseconds returns d in milliseconds, as HAR timings are.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// sortedKeys returns the keys of values in order, so that archives of the
+// same requests are the same.
+func sortedKeys(values map[string][]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// The HAR 1.2 format, see http://www.softwareishard.com/blog/har-12-spec/.
+// Fields starting with an underscore are custom ones, which HAR allows.
+type harArchive struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	// Error is the error of a request that got no response.
+	Error string `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harCookie    `json:"cookies"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harCookie    `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harCookie is a cookie, which are always left out.
+type harCookie struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
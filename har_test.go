@@ -0,0 +1,103 @@
+package togglplanapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWithHAR(t *testing.T) {
+	recorder := NewHARRecorder(HAROptions{RedactHeaders: []string{"X-Signature"}})
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v5/authenticate/token":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token":"secret-token","expires_in":3600}`)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":7,"name":"Design"}`)
+		}
+	})
+	WithHAR(recorder)(pa)
+	WithMiddleware(SignRequests(SigningOptions{Key: []byte("key")}))(pa)
+
+	if _, _, err := requestToken(context.Background(), pa, Credentials{Username: "ada@example.com", Password: "hunter2", ClientId: "id", ClientSecret: "shh"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Post(pa, pa.baseURL+"/api/v5/1/tasks?token=abc&since=2024-03-01", []byte(`{"name":"Design","notes":{"password":"p"}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if _, err := recorder.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"hunter2", "ada@example.com", "secret-token", "Basic ", "Bearer ", "abc", `"p"`} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("expected %q to be redacted from the archive", secret)
+		}
+	}
+
+	var archive harArchive
+	if err := json.Unmarshal(buf.Bytes(), &archive); err != nil {
+		t.Fatal(err)
+	}
+	entries := archive.Log.Entries
+	if archive.Log.Version != "1.2" || len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", archive.Log)
+	}
+
+	post := entries[1]
+	if post.Request.Method != "POST" || !strings.HasSuffix(post.Request.URL, "/api/v5/1/tasks?since=2024-03-01&token=%5Bredacted%5D") {
+		t.Errorf("unexpected request %s %s", post.Request.Method, post.Request.URL)
+	}
+	if post.Request.PostData == nil || post.Request.PostData.Text != `{"name":"Design","notes":{"password":"[redacted]"}}` {
+		t.Errorf("unexpected request body %+v", post.Request.PostData)
+	}
+	if post.Response.Status != http.StatusCreated || post.Response.StatusText != "Created" || post.Response.Content.Text != `{"id":7,"name":"Design"}` {
+		t.Errorf("unexpected response %+v", post.Response)
+	}
+	for _, header := range post.Request.Headers {
+		if header.Name == "X-Signature" && header.Value != harRedacted {
+			t.Errorf("expected the signature to be redacted, got %q", header.Value)
+		}
+	}
+
+	if form := entries[0].Request.PostData.Text; form != "grant_type=password&password=%5Bredacted%5D&username=%5Bredacted%5D" {
+		t.Errorf("unexpected token request %q", form)
+	}
+
+	recorder.Reset()
+	buf.Reset()
+	recorder.WriteTo(&buf)
+	if !strings.Contains(buf.String(), `"entries": []`) {
+		t.Errorf("expected no entries after Reset, got %s", buf.String())
+	}
+}
+
+func TestHARBodies(t *testing.T) {
+	recorder := NewHARRecorder(HAROptions{MaxBodySize: 8})
+
+	cases := []struct {
+		body        string
+		contentType string
+		text        string
+		comment     string
+	}{
+		{"hello", "text/plain", "hello", ""},
+		{"hello, world", "text/plain", "hello, w", "body cut after 8 bytes"},
+		{`{"token":"a"}`, "application/json", "", "body over 8 bytes left out, as it can't be redacted"},
+		{"\xff\xfe", "application/octet-stream", "", "binary body left out"},
+	}
+	for _, c := range cases {
+		text, comment := recorder.body([]byte(c.body), c.contentType)
+		if text != c.text || comment != c.comment {
+			t.Errorf("body(%q): expected %q, %q, got %q, %q", c.body, c.text, c.comment, text, comment)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package togglplanapi
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HedgingOptions configures hedged GET requests. Zero fields keep the
+// defaults.
+type HedgingOptions struct {
+	// Percentile of the recent GET latencies after which a second attempt is
+	// sent, between 0 and 1. Defaults to 0.95, so that about one GET in 20 is
+	// sent twice.
+	Percentile float64
+
+	// Delay is used instead of the percentile until 20 GETs have completed.
+	// Defaults to 1 second.
+	Delay time.Duration
+
+	// MinDelay is the least time waited before sending a second attempt, so
+	// that a fast API isn't sent every request twice. Defaults to 50
+	// milliseconds.
+	MinDelay time.Duration
+}
+
+// Number of recent latencies kept by a hedger, and needed before their
+// percentile is used.
+const (
+	hedgeSamples    = 100
+	hedgeMinSamples = 20
+)
+
+// WithHedging turns on hedged requests for GETs, which are safe to send
+// twice: if a GET hasn't been answered once most GETs would have been, a
+// second one is sent, and the first answer is used. This trims the slow
+// tail of interactive tools on flaky networks, at the cost of a few more
+// requests. Each attempt is retried as usual.
+//
+// Use `togglplanapi.HedgingOptions{}` for the defaults.
+func WithHedging(options HedgingOptions) Option {
+	if options.Percentile <= 0 || options.Percentile > 1 {
+		options.Percentile = 0.95
+	}
+	if options.Delay <= 0 {
+		options.Delay = 1 * time.Second
+	}
+	if options.MinDelay <= 0 {
+		options.MinDelay = 50 * time.Millisecond
+	}
+
+	return func(pa *togglPlanApi) {
+		pa.hedger = &hedger{options: options}
+	}
+}
+
+// hedger keeps the recent latencies of GETs, to decide when to hedge them.
+type hedger struct {
+	options HedgingOptions
+
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// record adds the latency of a GET.
+func (h *hedger) record(latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.samples) < hedgeSamples {
+		h.samples = append(h.samples, latency)
+		return
+	}
+	h.samples[h.next] = latency
+	h.next = (h.next + 1) % hedgeSamples
+}
+
+// delay returns how long to wait for a GET before sending a second attempt.
+func (h *hedger) delay() time.Duration {
+	h.mu.Lock()
+	if len(h.samples) < hedgeMinSamples {
+		h.mu.Unlock()
+		return h.options.Delay
+	}
+	samples := append([]time.Duration(nil), h.samples...)
+	h.mu.Unlock()
+
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	delay := samples[int(h.options.Percentile*float64(len(samples)-1))]
+	if delay < h.options.MinDelay {
+		delay = h.options.MinDelay
+	}
+	return delay
+}
+
+// hedgeResult is the outcome of an attempt of a hedged request.
+type hedgeResult struct {
+	attempt int
+	resp    *http.Response
+	message string
+	err     error
+	latency time.Duration
+}
+
+// send sends a GET with send, and sends it again if it takes longer than
+// the delay of h. The first successful response is returned, and the other
+// attempt is canceled. If both fail, the error of the last one is returned.
+// On success, the response body is left open for the caller to consume and
+// close.
+func (h *hedger) send(ctx context.Context, send func(ctx context.Context) (*http.Response, string, error)) (*http.Response, string, error) {
+	results := make(chan hedgeResult, 2)
+	var cancels []context.CancelFunc
+	attempt := func() {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		i := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			start := time.Now()
+			resp, message, err := send(attemptCtx)
+			results <- hedgeResult{attempt: i, resp: resp, message: message, err: err, latency: time.Since(start)}
+		}()
+	}
+
+	attempt()
+	pending := 1
+	timer := time.NewTimer(h.delay())
+	defer timer.Stop()
+	hedge := timer.C
+
+	for {
+		select {
+		case <-hedge:
+			attempt()
+			pending++
+			hedge = nil
+
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				h.record(result.latency)
+				// Cancel the other attempt, and close its response if it
+				// comes anyway
+				for i, cancel := range cancels {
+					if i != result.attempt {
+						cancel()
+					}
+				}
+				go func(pending int) {
+					for ; pending > 0; pending-- {
+						if other := <-results; other.resp != nil {
+							other.resp.Body.Close()
+						}
+					}
+				}(pending)
+				result.resp.Body = cancelOnClose{ReadCloser: result.resp.Body, cancel: cancels[result.attempt]}
+				return result.resp, result.message, nil
+			}
+
+			cancels[result.attempt]()
+			if pending == 0 {
+				// The last attempt failed, or the first one failed before
+				// it was worth hedging
+				return nil, result.message, result.err
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package togglplanapi
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedgingSendsSecondAttempt(t *testing.T) {
+	var calls int32
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// The first attempt hangs until it is canceled
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+			return
+		}
+		fmt.Fprint(w, "second")
+	})
+	WithHedging(HedgingOptions{Delay: 20 * time.Millisecond})(pa)
+
+	start := time.Now()
+	result, err := Get(pa, pa.baseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "second" || atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("unexpected result %q after %d calls", result, calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("hedged request took %s", elapsed)
+	}
+}
+
+func TestHedgingSkipsFastAndWriteRequests(t *testing.T) {
+	var calls int32
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Method == http.MethodPost {
+			time.Sleep(50 * time.Millisecond)
+		}
+		fmt.Fprint(w, "ok")
+	})
+	WithHedging(HedgingOptions{Delay: 20 * time.Millisecond})(pa)
+
+	if _, err := Get(pa, pa.baseURL); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Post(pa, pa.baseURL, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("expected no hedged attempt, got %d calls", calls)
+	}
+}
+
+func TestHedgerDelay(t *testing.T) {
+	h := &hedger{options: HedgingOptions{Percentile: 0.9, Delay: time.Second, MinDelay: 5 * time.Millisecond}}
+	if h.delay() != time.Second {
+		t.Errorf("expected the initial delay before enough samples, got %s", h.delay())
+	}
+
+	for i := 1; i <= hedgeSamples+10; i++ {
+		h.record(time.Duration(i) * time.Millisecond)
+	}
+	// The oldest 10 samples were replaced, leaving 11ms to 110ms
+	if delay := h.delay(); delay != 100*time.Millisecond {
+		t.Errorf("expected the 90th percentile, got %s", delay)
+	}
+}
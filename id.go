@@ -0,0 +1,52 @@
+package togglplanapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ID identifies a resource of the API, such as a workspace, task or member.
+//
+// IDs are 64-bit integers. They decode from JSON numbers without going
+// through float64, which can't hold every 64-bit integer, and from numbers
+// sent as strings.
+type ID int64
+
+// ParseID parses a decimal ID.
+func ParseID(value string) (ID, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID %q", value)
+	}
+	return ID(id), nil
+}
+
+// String formats the ID in decimal.
+func (id ID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// UnmarshalJSON decodes a number, a string holding a number, or null as 0.
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*id = 0
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		value = string(data)
+	} else if value == "" {
+		*id = 0
+		return nil
+	}
+
+	parsed, err := ParseID(value)
+	if err != nil {
+		return err
+	}
+	*id = parsed
+	return nil
+}
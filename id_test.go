@@ -0,0 +1,69 @@
+package togglplanapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIDUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		json string
+		want ID
+	}{
+		{`9007199254740993`, 9007199254740993},
+		{`"12"`, 12},
+		{`null`, 0},
+		{`""`, 0},
+	}
+
+	for _, c := range cases {
+		var id ID
+		if err := json.Unmarshal([]byte(c.json), &id); err != nil {
+			t.Errorf("%s: %v", c.json, err)
+			continue
+		}
+		if id != c.want {
+			t.Errorf("%s: got %d, want %d", c.json, id, c.want)
+		}
+	}
+
+	for _, invalid := range []string{`"abc"`, `1.5`, `true`} {
+		var id ID
+		if err := json.Unmarshal([]byte(invalid), &id); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+
+	var task Task
+	if err := json.Unmarshal([]byte(`{"id": "9007199254740993", "workspace_members": [1, "2"]}`), &task); err != nil {
+		t.Fatal(err)
+	}
+	if task.Id != 9007199254740993 || len(task.Assignees) != 2 || task.Assignees[1] != 2 {
+		t.Errorf("unexpected task %+v", task)
+	}
+
+	if id, err := ParseID("42"); err != nil || id.String() != "42" {
+		t.Errorf("ParseID: got %v, %v", id, err)
+	}
+}
+
+func FuzzIDUnmarshalJSON(f *testing.F) {
+	for _, seed := range []string{`9007199254740993`, `"12"`, `null`, `""`, `-1`, `1.5`, `"9223372036854775808"`} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var id ID
+		if err := json.Unmarshal(data, &id); err != nil {
+			return
+		}
+
+		encoded, err := json.Marshal(id)
+		if err != nil {
+			t.Fatalf("encoding %d: %v", id, err)
+		}
+		var decoded ID
+		if err := json.Unmarshal(encoded, &decoded); err != nil || decoded != id {
+			t.Fatalf("%s decoded as %d, then %s as %d (%v)", data, id, encoded, decoded, err)
+		}
+	})
+}
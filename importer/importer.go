@@ -0,0 +1,74 @@
+/*
+Package importer creates Toggl Plan projects and tasks from the exports of
+other project management tools.
+
+Example Usage:
+
+	import (
+		"context"
+		"fmt"
+		"os"
+
+		"github.com/ricotheque/togglplanapi"
+		"github.com/ricotheque/togglplanapi/importer"
+	)
+
+	func main() {
+		pa := togglplanapi.New(username, password, clientId, clientSecret, "")
+
+		file, _ := os.Open("jira-export.json")
+		issues, err := importer.ReadJiraJSON(file)
+
+		results, err := importer.ImportJira(context.Background(), pa, workspaceId, issues, importer.JiraOptions{})
+
+		for _, result := range results {
+			fmt.Println(result.Source, result.Id, result.Err)
+		}
+	}
+*/
+package importer
+
+import (
+	"context"
+	"strings"
+
+	"togglplanapi"
+)
+
+// ItemResult reports the outcome of importing a single item.
+type ItemResult struct {
+	// Source identifies the item in the export (e.g. a Jira issue key).
+	Source string
+
+	// Id is the ID of the created task, or 0 if it wasn't created.
+	Id togglplanapi.ID
+
+	// Err is the reason the item couldn't be imported, if any.
+	Err error
+}
+
+// membersByEmail fetches the members of a workspace, keyed by lowercase email.
+func membersByEmail(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID) (map[string]togglplanapi.ID, error) {
+	members, err := togglplanapi.GetMembers(ctx, pa, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+
+	byEmail := map[string]togglplanapi.ID{}
+	for _, member := range members {
+		if member.Email != "" {
+			byEmail[strings.ToLower(member.Email)] = member.Id
+		}
+	}
+
+	return byEmail, nil
+}
+
+// lowerKeys returns a copy of m with lowercase keys, for case-insensitive lookups.
+func lowerKeys(m map[string]togglplanapi.ID) map[string]togglplanapi.ID {
+	lowered := make(map[string]togglplanapi.ID, len(m))
+	for key, value := range m {
+		lowered[strings.ToLower(key)] = value
+	}
+	return lowered
+}
@@ -0,0 +1,389 @@
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"togglplanapi"
+)
+
+// JiraIssue is a Jira issue, reduced to the fields that can be carried over to Toggl Plan.
+// Dates are formatted as YYYY-MM-DD, and are empty if not set.
+type JiraIssue struct {
+	Key             string
+	Summary         string
+	Description     string
+	Status          string
+	AssigneeEmail   string
+	ProjectKey      string
+	ProjectName     string
+	Labels          []string
+	StartDate       togglplanapi.Date
+	DueDate         togglplanapi.Date
+	EstimateSeconds int64
+}
+
+// JiraCSVColumns maps JiraIssue fields to the column headers of a Jira CSV export.
+// Columns left empty are not read.
+type JiraCSVColumns struct {
+	Key           string
+	Summary       string
+	Description   string
+	Status        string
+	AssigneeEmail string
+	ProjectKey    string
+	ProjectName   string
+	Labels        string
+	StartDate     string
+	DueDate       string
+	Estimate      string
+}
+
+// DefaultJiraCSVColumns are the column headers used by Jira Cloud's "Export CSV (all fields)".
+// Jira doesn't export assignee emails by default, so AssigneeEmail is left empty.
+var DefaultJiraCSVColumns = JiraCSVColumns{
+	Key:         "Issue key",
+	Summary:     "Summary",
+	Description: "Description",
+	Status:      "Status",
+	ProjectKey:  "Project key",
+	ProjectName: "Project name",
+	Labels:      "Labels",
+	StartDate:   "Custom field (Start date)",
+	DueDate:     "Due Date",
+	Estimate:    "Original Estimate",
+}
+
+// jiraSearchResult is the format of Jira's search API responses and JSON exports.
+type jiraSearchResult struct {
+	Issues []struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string          `json:"summary"`
+			Description json.RawMessage `json:"description"`
+			Status      struct {
+				Name string `json:"name"`
+			} `json:"status"`
+			Assignee *struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"assignee"`
+			Project struct {
+				Key  string `json:"key"`
+				Name string `json:"name"`
+			} `json:"project"`
+			Labels               []string          `json:"labels"`
+			StartDate            togglplanapi.Date `json:"customfield_10015"`
+			DueDate              togglplanapi.Date `json:"duedate"`
+			TimeOriginalEstimate int64             `json:"timeoriginalestimate"`
+		} `json:"fields"`
+	} `json:"issues"`
+}
+
+// ReadJiraJSON reads issues in the format returned by Jira's search API
+// (GET /rest/api/2/search or /rest/api/3/search).
+// Descriptions in Atlassian Document Format (API v3) are converted to plain text.
+// Arguments:
+//
+//	r: Source of the export
+func ReadJiraJSON(r io.Reader) ([]JiraIssue, error) {
+	var result jiraSearchResult
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	issues := make([]JiraIssue, len(result.Issues))
+	for i, raw := range result.Issues {
+		issue := JiraIssue{
+			Key:             raw.Key,
+			Summary:         raw.Fields.Summary,
+			Description:     jiraText(raw.Fields.Description),
+			Status:          raw.Fields.Status.Name,
+			ProjectKey:      raw.Fields.Project.Key,
+			ProjectName:     raw.Fields.Project.Name,
+			Labels:          raw.Fields.Labels,
+			StartDate:       raw.Fields.StartDate,
+			DueDate:         raw.Fields.DueDate,
+			EstimateSeconds: raw.Fields.TimeOriginalEstimate,
+		}
+		if raw.Fields.Assignee != nil {
+			issue.AssigneeEmail = raw.Fields.Assignee.EmailAddress
+		}
+		issues[i] = issue
+	}
+
+	return issues, nil
+}
+
+// jiraText returns the plain text of a description, which is either a string
+// or an Atlassian Document Format node.
+func jiraText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+
+	var node adfNode
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return ""
+	}
+
+	var b strings.Builder
+	node.writeText(&b)
+	return strings.TrimSpace(b.String())
+}
+
+// adfNode is a node of an Atlassian Document Format document.
+type adfNode struct {
+	Type    string    `json:"type"`
+	Text    string    `json:"text"`
+	Content []adfNode `json:"content"`
+}
+
+// writeText writes the text of a node and its children, with a line break after each block.
+func (node adfNode) writeText(b *strings.Builder) {
+	if node.Type == "hardBreak" {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(node.Text)
+
+	for _, child := range node.Content {
+		child.writeText(b)
+	}
+
+	switch node.Type {
+	case "paragraph", "heading", "listItem", "codeBlock", "blockquote":
+		b.WriteString("\n")
+	}
+}
+
+// ReadJiraCSV reads issues from a Jira CSV export.
+// Estimates are expected in seconds, as exported by Jira. Dates are accepted
+// as YYYY-MM-DD, or in Jira's default "02/Jan/06 3:04 PM" format.
+// Jira exports multiple labels as repeated Labels columns, all of which are read.
+// Arguments:
+//
+//	r: Source of the export
+//	columns: Column headers to read (e.g. `importer.DefaultJiraCSVColumns`)
+func ReadJiraCSV(r io.Reader, columns JiraCSVColumns) ([]JiraIssue, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := map[string][]int{}
+	for i, name := range header {
+		indexes[strings.TrimSpace(name)] = append(indexes[strings.TrimSpace(name)], i)
+	}
+
+	var issues []JiraIssue
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		get := func(column string) string {
+			if column == "" || len(indexes[column]) == 0 || indexes[column][0] >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[indexes[column][0]])
+		}
+
+		issue := JiraIssue{
+			Key:           get(columns.Key),
+			Summary:       get(columns.Summary),
+			Description:   get(columns.Description),
+			Status:        get(columns.Status),
+			AssigneeEmail: get(columns.AssigneeEmail),
+			ProjectKey:    get(columns.ProjectKey),
+			ProjectName:   get(columns.ProjectName),
+		}
+
+		for _, i := range indexes[columns.Labels] {
+			if columns.Labels != "" && i < len(record) && strings.TrimSpace(record[i]) != "" {
+				issue.Labels = append(issue.Labels, strings.TrimSpace(record[i]))
+			}
+		}
+
+		if issue.StartDate, err = jiraDate(get(columns.StartDate)); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if issue.DueDate, err = jiraDate(get(columns.DueDate)); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if estimate := get(columns.Estimate); estimate != "" {
+			if issue.EstimateSeconds, err = strconv.ParseInt(estimate, 10, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid estimate %q", line, estimate)
+			}
+		}
+
+		issues = append(issues, issue)
+	}
+
+	return issues, nil
+}
+
+// jiraDate parses a date from a Jira export.
+func jiraDate(value string) (togglplanapi.Date, error) {
+	if value == "" {
+		return togglplanapi.Date{}, nil
+	}
+
+	for _, layout := range []string{"2006-01-02", "02/Jan/06 3:04 PM", "02/Jan/06"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return togglplanapi.DateOf(parsed), nil
+		}
+	}
+
+	return togglplanapi.Date{}, fmt.Errorf("invalid date %q", value)
+}
+
+// JiraOptions configures ImportJira.
+type JiraOptions struct {
+	// Projects maps Jira project keys to existing Toggl Plan project IDs.
+	// A project is created for every other Jira project.
+	Projects map[string]togglplanapi.ID
+
+	// StatusColumns maps Jira status names (case-insensitively) to the IDs of
+	// board columns (see togglplanapi.GetPlanStatuses).
+	StatusColumns map[string]togglplanapi.ID
+
+	// DoneStatuses lists the Jira statuses (case-insensitively) whose issues
+	// are marked as done. Defaults to "Done", "Closed" and "Resolved".
+	DoneStatuses []string
+
+	// Assignees maps Jira assignee emails to workspace member IDs, for people
+	// who use a different email in Toggl Plan. Everyone else is matched by email.
+	Assignees map[string]togglplanapi.ID
+
+	// DryRun resolves projects and assignees without creating anything.
+	DryRun bool
+
+	// Progress, if set, receives the progress of the import, counted in
+	// issues in the "issues" phase.
+	Progress togglplanapi.ProgressFunc
+}
+
+// ImportJira creates a Toggl Plan task for each Jira issue, grouped into
+// projects by Jira project.
+//
+// Issues are imported independently: the returned results report the created
+// task or the error for each issue, in the order of issues. The returned
+// error is only set if the import couldn't start at all.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	issues: Issues read with ReadJiraJSON or ReadJiraCSV
+//	options: Import settings (use `importer.JiraOptions{}` for the defaults)
+func ImportJira(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, issues []JiraIssue, options JiraOptions) ([]ItemResult, error) {
+	members, err := membersByEmail(ctx, pa, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+	for email, id := range options.Assignees {
+		members[strings.ToLower(email)] = id
+	}
+
+	doneStatuses := options.DoneStatuses
+	if doneStatuses == nil {
+		doneStatuses = []string{"Done", "Closed", "Resolved"}
+	}
+	done := map[string]bool{}
+	for _, status := range doneStatuses {
+		done[strings.ToLower(status)] = true
+	}
+
+	statusColumns := lowerKeys(options.StatusColumns)
+
+	projects := map[string]togglplanapi.ID{}
+	for key, id := range options.Projects {
+		projects[key] = id
+	}
+
+	results := make([]ItemResult, len(issues))
+	for i, issue := range issues {
+		options.Progress.Report("issues", i, len(issues))
+		results[i].Source = issue.Key
+
+		params := togglplanapi.TaskParams{
+			Name:             strings.TrimSpace(issue.Key + " " + issue.Summary),
+			Notes:            issue.Description,
+			StartDate:        issue.StartDate,
+			EndDate:          issue.DueDate,
+			EstimatedMinutes: togglplanapi.Estimate(issue.EstimateSeconds / 60),
+			Done:             done[strings.ToLower(issue.Status)],
+			PlanStatusId:     statusColumns[strings.ToLower(issue.Status)],
+			Tags:             issue.Labels,
+		}
+
+		// Toggl Plan tasks need both dates or neither
+		if params.StartDate.IsZero() {
+			params.StartDate = params.EndDate
+		}
+		if params.EndDate.IsZero() {
+			params.EndDate = params.StartDate
+		}
+
+		if issue.AssigneeEmail != "" {
+			id, ok := members[strings.ToLower(issue.AssigneeEmail)]
+			if !ok {
+				results[i].Err = fmt.Errorf("no workspace member with email %s", issue.AssigneeEmail)
+				continue
+			}
+			params.Assignees = []togglplanapi.ID{id}
+		}
+
+		if issue.ProjectKey != "" {
+			projectId, ok := projects[issue.ProjectKey]
+			if !ok && !options.DryRun {
+				name := issue.ProjectName
+				if name == "" {
+					name = issue.ProjectKey
+				}
+
+				project, err := togglplanapi.CreateProject(ctx, pa, workspaceId, togglplanapi.ProjectParams{Name: name})
+				if err != nil {
+					results[i].Err = fmt.Errorf("creating project %s: %w", issue.ProjectKey, err)
+					continue
+				}
+				projectId = project.Id
+				projects[issue.ProjectKey] = projectId
+			}
+			params.ProjectId = projectId
+		}
+
+		if options.DryRun {
+			continue
+		}
+
+		task, err := togglplanapi.CreateTask(ctx, pa, workspaceId, params)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Id = task.Id
+	}
+	options.Progress.Report("issues", len(issues), len(issues))
+
+	return results, nil
+}
@@ -0,0 +1,186 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"togglplanapi"
+	"togglplanapi/internal/testclient"
+)
+
+func TestReadJiraJSON(t *testing.T) {
+	export := `{"issues": [
+		{"key": "WEB-1", "fields": {
+			"summary": "Mockups",
+			"description": {"type": "doc", "content": [
+				{"type": "paragraph", "content": [{"type": "text", "text": "First"}]},
+				{"type": "paragraph", "content": [{"type": "text", "text": "Second"}]}
+			]},
+			"status": {"name": "In Progress"},
+			"assignee": {"emailAddress": "ada@example.com"},
+			"project": {"key": "WEB", "name": "Website"},
+			"labels": ["design"],
+			"duedate": "2024-03-08",
+			"timeoriginalestimate": 7200
+		}},
+		{"key": "WEB-2", "fields": {"summary": "Copy", "description": "Plain text", "assignee": null}}
+	]}`
+
+	issues, err := ReadJiraJSON(strings.NewReader(export))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d", len(issues))
+	}
+
+	first := issues[0]
+	if first.Description != "First\nSecond" || first.AssigneeEmail != "ada@example.com" || first.ProjectKey != "WEB" || first.EstimateSeconds != 7200 {
+		t.Fatalf("unexpected issue %+v", first)
+	}
+
+	if issues[1].Description != "Plain text" || issues[1].AssigneeEmail != "" {
+		t.Fatalf("unexpected issue %+v", issues[1])
+	}
+}
+
+func TestReadJiraCSV(t *testing.T) {
+	export := "Summary,Issue key,Status,Project key,Labels,Labels,Due Date,Original Estimate\n" +
+		"Mockups,WEB-1,Done,WEB,design,ux,08/Mar/24 5:00 PM,3600\n"
+
+	issues, err := ReadJiraCSV(strings.NewReader(export), DefaultJiraCSVColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issue := issues[0]
+	if issue.Key != "WEB-1" || issue.Status != "Done" || issue.DueDate != togglplanapi.NewDate(2024, 3, 8) || issue.EstimateSeconds != 3600 {
+		t.Fatalf("unexpected issue %+v", issue)
+	}
+	if fmt.Sprint(issue.Labels) != "[design ux]" {
+		t.Fatalf("unexpected labels %v", issue.Labels)
+	}
+
+	_, err = ReadJiraCSV(strings.NewReader("Due Date\nsoon\n"), DefaultJiraCSVColumns)
+	if err == nil {
+		t.Fatal("expected an error for an invalid date")
+	}
+}
+
+func TestImportJira(t *testing.T) {
+	var projects []togglplanapi.ProjectParams
+	var tasks []togglplanapi.TaskParams
+	pa := testclient.New(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v5/1/members":
+			fmt.Fprint(w, `[{"id":3,"email":"Ada@example.com"},{"id":4,"email":"grace@example.com"}]`)
+		case "POST /api/v5/1/projects":
+			var params togglplanapi.ProjectParams
+			json.NewDecoder(r.Body).Decode(&params)
+			projects = append(projects, params)
+			fmt.Fprintf(w, `{"id":%d,"name":%q}`, 50+len(projects), params.Name)
+		case "POST /api/v5/1/tasks":
+			var params togglplanapi.TaskParams
+			json.NewDecoder(r.Body).Decode(&params)
+			if strings.Contains(params.Name, "Broken") {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(w, `{"message":"invalid task"}`)
+				return
+			}
+			tasks = append(tasks, params)
+			fmt.Fprintf(w, `{"id":%d,"name":%q}`, 100+len(tasks), params.Name)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+
+	issues := []JiraIssue{
+		{
+			Key: "WEB-1", Summary: "Mockups", Description: "First draft", Status: "done",
+			AssigneeEmail: "ada@example.com", ProjectKey: "WEB", ProjectName: "Website",
+			Labels: []string{"design"}, StartDate: togglplanapi.NewDate(2024, 3, 4), DueDate: togglplanapi.NewDate(2024, 3, 8),
+			EstimateSeconds: 5400,
+		},
+		{Key: "WEB-2", Summary: "Copy", AssigneeEmail: "nobody@example.com", ProjectKey: "WEB"},
+		{Key: "WEB-3", Summary: "Broken", ProjectKey: "WEB", DueDate: togglplanapi.NewDate(2024, 3, 12)},
+		{Key: "OPS-1", Summary: "Backups", Status: "In Review", AssigneeEmail: "grace@example.com", ProjectKey: "OPS"},
+	}
+	options := JiraOptions{
+		Projects:      map[string]togglplanapi.ID{"OPS": 70},
+		StatusColumns: map[string]togglplanapi.ID{"In review": 11},
+	}
+
+	results, err := ImportJira(context.Background(), pa, 1, issues, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 4 {
+		t.Fatalf("expected a result per issue, got %+v", results)
+	}
+	if results[0].Source != "WEB-1" || results[0].Id != 101 || results[0].Err != nil {
+		t.Errorf("unexpected result for WEB-1: %+v", results[0])
+	}
+	if results[1].Id != 0 || results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "nobody@example.com") {
+		t.Errorf("expected WEB-2 to fail on its assignee, got %+v", results[1])
+	}
+	var apiErr *togglplanapi.APIError
+	if results[2].Id != 0 || !errors.As(results[2].Err, &apiErr) || apiErr.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected WEB-3 to fail with the API error, got %+v", results[2])
+	}
+	if results[3].Source != "OPS-1" || results[3].Id != 102 || results[3].Err != nil {
+		t.Errorf("unexpected result for OPS-1: %+v", results[3])
+	}
+
+	// The WEB project is created once, and OPS is mapped to an existing one
+	if len(projects) != 1 || projects[0].Name != "Website" {
+		t.Fatalf("expected only the Website project to be created, got %+v", projects)
+	}
+
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %+v", tasks)
+	}
+	web := tasks[0]
+	if web.Name != "WEB-1 Mockups" || web.Notes != "First draft" || !web.Done || web.ProjectId != 51 ||
+		len(web.Assignees) != 1 || web.Assignees[0] != 3 || web.EstimatedMinutes != 90 ||
+		web.StartDate != togglplanapi.NewDate(2024, 3, 4) || web.EndDate != togglplanapi.NewDate(2024, 3, 8) ||
+		len(web.Tags) != 1 || web.Tags[0] != "design" {
+		t.Errorf("unexpected task for WEB-1: %+v", web)
+	}
+	ops := tasks[1]
+	if ops.Name != "OPS-1 Backups" || ops.Done || ops.ProjectId != 70 || ops.PlanStatusId != 11 ||
+		len(ops.Assignees) != 1 || ops.Assignees[0] != 4 {
+		t.Errorf("unexpected task for OPS-1: %+v", ops)
+	}
+}
+
+func TestImportJiraDryRun(t *testing.T) {
+	pa := testclient.New(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected %s %s in a dry run", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `[{"id":3,"email":"ada@example.com"}]`)
+	})
+
+	issues := []JiraIssue{
+		{Key: "WEB-1", Summary: "Mockups", AssigneeEmail: "ada@example.com", ProjectKey: "WEB"},
+		{Key: "WEB-2", Summary: "Copy", AssigneeEmail: "nobody@example.com"},
+	}
+	results, err := ImportJira(context.Background(), pa, 1, issues, JiraOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Issues are checked but skipped, so a dry run reports the failures of
+	// a real import without creating anything
+	if len(results) != 2 || results[0].Id != 0 || results[0].Err != nil || results[1].Err == nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
@@ -0,0 +1,279 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"togglplanapi"
+)
+
+// TrelloBoard is the subset of a Trello board JSON export read by ImportTrello.
+type TrelloBoard struct {
+	Name       string            `json:"name"`
+	Lists      []TrelloList      `json:"lists"`
+	Cards      []TrelloCard      `json:"cards"`
+	Checklists []TrelloChecklist `json:"checklists"`
+	Labels     []TrelloLabel     `json:"labels"`
+}
+
+// TrelloList is a list (column) of a Trello board.
+type TrelloList struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Closed bool   `json:"closed"`
+}
+
+// TrelloCard is a card of a Trello board.
+type TrelloCard struct {
+	Id           string     `json:"id"`
+	Name         string     `json:"name"`
+	Desc         string     `json:"desc"`
+	Closed       bool       `json:"closed"`
+	ListId       string     `json:"idList"`
+	LabelIds     []string   `json:"idLabels"`
+	MemberIds    []string   `json:"idMembers"`
+	Start        *time.Time `json:"start"`
+	Due          *time.Time `json:"due"`
+	DueComplete  bool       `json:"dueComplete"`
+	ChecklistIds []string   `json:"idChecklists"`
+}
+
+// TrelloChecklist is a checklist attached to a Trello card.
+type TrelloChecklist struct {
+	Id         string `json:"id"`
+	CardId     string `json:"idCard"`
+	Name       string `json:"name"`
+	CheckItems []struct {
+		Name  string `json:"name"`
+		State string `json:"state"`
+	} `json:"checkItems"`
+}
+
+// TrelloLabel is a label of a Trello board. Labels without a name are identified by their color.
+type TrelloLabel struct {
+	Id    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// ReadTrelloJSON reads a board exported from Trello's "Print and export" menu.
+// Arguments:
+//
+//	r: Source of the export
+func ReadTrelloJSON(r io.Reader) (*TrelloBoard, error) {
+	var board TrelloBoard
+	if err := json.NewDecoder(r).Decode(&board); err != nil {
+		return nil, err
+	}
+	return &board, nil
+}
+
+// TrelloOptions configures ImportTrello.
+type TrelloOptions struct {
+	// ProjectId is an existing project to import into. If 0, a project named
+	// after the board is created.
+	ProjectId togglplanapi.ID
+
+	// Columns maps Trello list names (case-insensitively) to existing board
+	// columns. A board column is created for every other list.
+	Columns map[string]togglplanapi.ID
+
+	// Members maps Trello member IDs to workspace member IDs. Trello exports
+	// don't include emails, so members can't be matched automatically.
+	Members map[string]togglplanapi.ID
+
+	// IncludeArchived also imports archived cards and cards of archived lists.
+	IncludeArchived bool
+
+	// Concurrency is the number of cards imported at the same time. Defaults to 4.
+	Concurrency int
+
+	// DryRun works out the tasks to create without creating anything.
+	DryRun bool
+
+	// Progress, if set, receives the progress of the import, counted in
+	// cards in the "cards" phase.
+	Progress togglplanapi.ProgressFunc
+}
+
+// ImportTrello creates a Toggl Plan project from a Trello board. Lists become
+// board columns, cards become tasks, and their checklists, labels (as tags),
+// members and dates are carried over. Cards without dates are imported as
+// tasks without dates. Multiple checklists of a card are merged into one,
+// prefixing items with the checklist's name.
+//
+// Cards are imported independently using togglplanapi.RunBatch: the returned
+// results report the created task or the error for each card, in the order of
+// the export. The returned error is only set if the import couldn't start at
+// all, e.g. because the project couldn't be created.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	board: Board read with ReadTrelloJSON
+//	options: Import settings (use `importer.TrelloOptions{}` for the defaults)
+func ImportTrello(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, board *TrelloBoard, options TrelloOptions) ([]ItemResult, error) {
+	projectId := options.ProjectId
+	if projectId == 0 && !options.DryRun {
+		project, err := togglplanapi.CreateProject(ctx, pa, workspaceId, togglplanapi.ProjectParams{Name: board.Name})
+		if err != nil {
+			return nil, fmt.Errorf("creating project: %w", err)
+		}
+		projectId = project.Id
+	}
+
+	columns, err := trelloColumns(ctx, pa, workspaceId, board, options)
+	if err != nil {
+		return nil, err
+	}
+
+	labels := map[string]string{}
+	for _, label := range board.Labels {
+		name := label.Name
+		if name == "" {
+			name = label.Color
+		}
+		labels[label.Id] = name
+	}
+
+	checklists := map[string]TrelloChecklist{}
+	for _, checklist := range board.Checklists {
+		checklists[checklist.Id] = checklist
+	}
+
+	closedLists := map[string]bool{}
+	for _, list := range board.Lists {
+		closedLists[list.Id] = list.Closed
+	}
+
+	var cards []TrelloCard
+	for _, card := range board.Cards {
+		if options.IncludeArchived || !(card.Closed || closedLists[card.ListId]) {
+			cards = append(cards, card)
+		}
+	}
+
+	results := make([]ItemResult, len(cards))
+	jobs := make([]func(ctx context.Context) error, len(cards))
+	progress := options.Progress.Counter("cards", len(cards))
+
+	for i, card := range cards {
+		i, card := i, card
+		results[i].Source = card.Id
+
+		params := togglplanapi.TaskParams{
+			Name:         card.Name,
+			Notes:        card.Desc,
+			Done:         card.DueComplete,
+			ProjectId:    projectId,
+			PlanStatusId: columns[card.ListId],
+		}
+
+		if card.Due != nil {
+			params.EndDate = togglplanapi.DateOf(*card.Due)
+			params.StartDate = params.EndDate
+		}
+		if card.Start != nil {
+			params.StartDate = togglplanapi.DateOf(*card.Start)
+			if params.EndDate.IsZero() {
+				params.EndDate = params.StartDate
+			}
+		}
+
+		for _, id := range card.LabelIds {
+			if name, ok := labels[id]; ok && name != "" {
+				params.Tags = append(params.Tags, name)
+			}
+		}
+
+		for _, id := range card.MemberIds {
+			if member, ok := options.Members[id]; ok {
+				params.Assignees = append(params.Assignees, member)
+			}
+		}
+
+		params.Checklist = trelloChecklist(card, checklists)
+
+		jobs[i] = func(ctx context.Context) error {
+			defer progress.Add()
+			if options.DryRun {
+				return nil
+			}
+
+			task, err := togglplanapi.CreateTask(ctx, pa, workspaceId, params)
+			if err != nil {
+				return err
+			}
+			results[i].Id = task.Id
+			return nil
+		}
+	}
+
+	concurrency := options.Concurrency
+	if concurrency == 0 {
+		concurrency = 4
+	}
+
+	for i, err := range togglplanapi.RunBatch(ctx, concurrency, jobs) {
+		results[i].Err = err
+	}
+
+	return results, nil
+}
+
+// trelloColumns maps the IDs of Trello lists to board columns, creating the missing ones.
+func trelloColumns(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, board *TrelloBoard, options TrelloOptions) (map[string]togglplanapi.ID, error) {
+	existing := lowerKeys(options.Columns)
+
+	columns := map[string]togglplanapi.ID{}
+	for _, list := range board.Lists {
+		if list.Closed && !options.IncludeArchived {
+			continue
+		}
+
+		if id, ok := existing[strings.ToLower(list.Name)]; ok {
+			columns[list.Id] = id
+			continue
+		}
+
+		if options.DryRun {
+			continue
+		}
+
+		status, err := togglplanapi.CreatePlanStatus(ctx, pa, workspaceId, list.Name)
+		if err != nil {
+			return nil, fmt.Errorf("creating column %s: %w", list.Name, err)
+		}
+		columns[list.Id] = status.Id
+		existing[strings.ToLower(list.Name)] = status.Id
+	}
+
+	return columns, nil
+}
+
+// trelloChecklist merges the checklists of a card into a single task checklist.
+func trelloChecklist(card TrelloCard, checklists map[string]TrelloChecklist) []togglplanapi.ChecklistItem {
+	var items []togglplanapi.ChecklistItem
+
+	for _, id := range card.ChecklistIds {
+		checklist, ok := checklists[id]
+		if !ok {
+			continue
+		}
+
+		for _, checkItem := range checklist.CheckItems {
+			name := checkItem.Name
+			if len(card.ChecklistIds) > 1 {
+				name = checklist.Name + ": " + name
+			}
+			items = append(items, togglplanapi.ChecklistItem{Name: name, Done: checkItem.State == "complete"})
+		}
+	}
+
+	return items
+}
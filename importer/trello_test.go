@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"togglplanapi"
+	"togglplanapi/internal/testclient"
+)
+
+const trelloExport = `{
+	"name": "Launch",
+	"lists": [{"id": "l1", "name": "Doing"}, {"id": "l2", "name": "Old", "closed": true}],
+	"labels": [{"id": "b1", "name": "Design"}, {"id": "b2", "name": "", "color": "red"}],
+	"checklists": [
+		{"id": "c1", "idCard": "k1", "name": "Assets", "checkItems": [{"name": "Logo", "state": "complete"}]},
+		{"id": "c2", "idCard": "k1", "name": "Copy", "checkItems": [{"name": "Headline", "state": "incomplete"}]}
+	],
+	"cards": [
+		{"id": "k1", "name": "Landing page", "idList": "l1", "idLabels": ["b1", "b2"], "idMembers": ["m1", "m2"],
+		 "idChecklists": ["c1", "c2"], "start": "2024-03-04T00:00:00.000Z", "due": "2024-03-08T12:00:00.000Z"},
+		{"id": "k2", "name": "Archived", "idList": "l2"}
+	]
+}`
+
+func TestReadTrelloJSON(t *testing.T) {
+	board, err := ReadTrelloJSON(strings.NewReader(trelloExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if board.Name != "Launch" || len(board.Cards) != 2 || board.Cards[0].Due == nil {
+		t.Fatalf("unexpected board %+v", board)
+	}
+
+	checklists := map[string]TrelloChecklist{}
+	for _, checklist := range board.Checklists {
+		checklists[checklist.Id] = checklist
+	}
+
+	items := trelloChecklist(board.Cards[0], checklists)
+	if len(items) != 2 || items[0].Name != "Assets: Logo" || !items[0].Done || items[1].Done {
+		t.Fatalf("unexpected checklist %+v", items)
+	}
+}
+
+func TestImportTrelloDryRun(t *testing.T) {
+	board, err := ReadTrelloJSON(strings.NewReader(trelloExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A dry run sends no requests, so no client is needed
+	results, err := ImportTrello(context.Background(), nil, 1, board, TrelloOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].Source != "k1" || results[0].Err != nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+}
+
+func TestImportTrello(t *testing.T) {
+	board, err := ReadTrelloJSON(strings.NewReader(trelloExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests []string
+	var column map[string]string
+	var tasks []togglplanapi.TaskParams
+	pa := testclient.New(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v5/1/projects":
+			var params togglplanapi.ProjectParams
+			json.NewDecoder(r.Body).Decode(&params)
+			fmt.Fprintf(w, `{"id":51,"name":%q}`, params.Name)
+		case "POST /api/v5/1/plan_statuses":
+			json.NewDecoder(r.Body).Decode(&column)
+			fmt.Fprintf(w, `{"id":11,"name":%q}`, column["name"])
+		case "POST /api/v5/1/tasks":
+			var params togglplanapi.TaskParams
+			json.NewDecoder(r.Body).Decode(&params)
+			tasks = append(tasks, params)
+			fmt.Fprintf(w, `{"id":%d,"name":%q}`, 100+len(tasks), params.Name)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+		}
+	})
+
+	results, err := ImportTrello(context.Background(), pa, 1, board, TrelloOptions{Members: map[string]togglplanapi.ID{"m1": 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].Source != "k1" || results[0].Id != 101 || results[0].Err != nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+
+	// The project and the column of the open list are created before the cards
+	expected := []string{"POST /api/v5/1/projects", "POST /api/v5/1/plan_statuses", "POST /api/v5/1/tasks"}
+	if strings.Join(requests, ", ") != strings.Join(expected, ", ") {
+		t.Fatalf("unexpected requests %v", requests)
+	}
+	if column["name"] != "Doing" {
+		t.Fatalf("unexpected column %v", column)
+	}
+
+	task := tasks[0]
+	if task.Name != "Landing page" || task.ProjectId != 51 || task.PlanStatusId != 11 ||
+		task.StartDate != togglplanapi.NewDate(2024, 3, 4) || task.EndDate != togglplanapi.NewDate(2024, 3, 8) ||
+		len(task.Assignees) != 1 || task.Assignees[0] != 3 ||
+		strings.Join(task.Tags, ",") != "Design,red" || len(task.Checklist) != 2 {
+		t.Errorf("unexpected task %+v", task)
+	}
+}
@@ -0,0 +1,380 @@
+/*
+Package index keeps a local copy of the tasks and projects of a workspace,
+and answers queries about them without calling the API.
+
+Tasks are fed from a togglplanapi.TaskFeed, so that each refresh only
+applies what changed. Projects are few, and fetched whole by
+RefreshProjects, so that queries can name them:
+
+	feed := togglplanapi.NewTaskFeed(pa, workspaceId, togglplanapi.TaskFeedOptions{})
+	ix := index.New()
+
+	if err := ix.Refresh(ctx, feed); err != nil {
+		log.Fatal(err)
+	}
+	if err := ix.RefreshProjects(ctx, pa, workspaceId); err != nil {
+		log.Fatal(err)
+	}
+
+	query, err := index.ParseQuery(`assignee:12 tag:urgent project:website to:2024-03-31 "release notes"`)
+	tasks := ix.Query(query)
+
+An index can be saved to a file with Save and read back with Load, so that a
+CLI started many times a day can answer queries before, or without, a
+refresh. The first refresh with a new feed still fetches every task in its
+window, and drops the tasks the index holds that are no longer there.
+*/
+package index
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"togglplanapi"
+)
+
+// Index holds tasks and projects in memory, with lookups by assignee,
+// project and tag. It is safe for concurrent use.
+type Index struct {
+	mu        sync.RWMutex
+	tasks     map[togglplanapi.ID]togglplanapi.Task
+	projects  map[togglplanapi.ID]togglplanapi.Project
+	assignees map[togglplanapi.ID]idSet
+	inProject map[togglplanapi.ID]idSet
+	tags      map[string]idSet
+	// feed is the feed of the last refresh. The first poll of another feed
+	// reports every task as created, and none as deleted.
+	feed *togglplanapi.TaskFeed
+}
+
+// idSet is a set of task IDs.
+type idSet map[togglplanapi.ID]struct{}
+
+// New returns an empty index.
+func New() *Index {
+	return &Index{
+		tasks:     map[togglplanapi.ID]togglplanapi.Task{},
+		projects:  map[togglplanapi.ID]togglplanapi.Project{},
+		assignees: map[togglplanapi.ID]idSet{},
+		inProject: map[togglplanapi.ID]idSet{},
+		tags:      map[string]idSet{},
+	}
+}
+
+// Refresh polls feed and applies the changes it reports. On the first
+// refresh with feed, e.g. after Load, the tasks of the index that the feed
+// doesn't report are dropped, since they were deleted or left the window
+// of the feed in the meantime.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	feed: Feed of the tasks to mirror
+func (ix *Index) Refresh(ctx context.Context, feed *togglplanapi.TaskFeed) error {
+	changes, err := feed.Poll(ctx)
+	if err != nil {
+		return err
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if ix.feed != feed {
+		ix.feed = feed
+		reported := make(idSet, len(changes))
+		for _, change := range changes {
+			reported[change.Task.Id] = struct{}{}
+		}
+		for id := range ix.tasks {
+			if _, ok := reported[id]; !ok {
+				ix.remove(id)
+			}
+		}
+	}
+	ix.apply(changes)
+	return nil
+}
+
+// RefreshProjects replaces the projects of the index with those of the
+// workspace.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: Client to fetch the projects with
+//	workspaceId: ID of the workspace
+func (ix *Index) RefreshProjects(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID) error {
+	projects, err := togglplanapi.GetProjects(ctx, pa, workspaceId)
+	if err != nil {
+		return err
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.projects = make(map[togglplanapi.ID]togglplanapi.Project, len(projects))
+	for _, project := range projects {
+		ix.projects[project.Id] = project
+	}
+	return nil
+}
+
+// Apply updates the index with changes reported by a TaskFeed.
+func (ix *Index) Apply(changes []togglplanapi.TaskChange) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.apply(changes)
+}
+
+// apply updates the index with changes. The caller holds the lock.
+func (ix *Index) apply(changes []togglplanapi.TaskChange) {
+	for _, change := range changes {
+		ix.remove(change.Task.Id)
+		if change.Type != togglplanapi.ChangeDeleted {
+			ix.add(change.Task)
+		}
+	}
+}
+
+// PutTasks adds tasks to the index, replacing those with the same IDs.
+func (ix *Index) PutTasks(tasks ...togglplanapi.Task) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for _, task := range tasks {
+		ix.remove(task.Id)
+		ix.add(task)
+	}
+}
+
+// RemoveTask removes a task from the index.
+func (ix *Index) RemoveTask(taskId togglplanapi.ID) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(taskId)
+}
+
+// PutProjects adds projects to the index, replacing those with the same IDs.
+func (ix *Index) PutProjects(projects ...togglplanapi.Project) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for _, project := range projects {
+		ix.projects[project.Id] = project
+	}
+}
+
+// Task returns the task with the given ID, if it is in the index.
+func (ix *Index) Task(taskId togglplanapi.ID) (togglplanapi.Task, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	task, ok := ix.tasks[taskId]
+	return task, ok
+}
+
+// Project returns the project with the given ID, if it is in the index.
+func (ix *Index) Project(projectId togglplanapi.ID) (togglplanapi.Project, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	project, ok := ix.projects[projectId]
+	return project, ok
+}
+
+// Projects returns the projects of the index, sorted by name, then by ID.
+func (ix *Index) Projects() []togglplanapi.Project {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	projects := make([]togglplanapi.Project, 0, len(ix.projects))
+	for _, project := range ix.projects {
+		projects = append(projects, project)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		a, b := strings.ToLower(projects[i].Name), strings.ToLower(projects[j].Name)
+		if a != b {
+			return a < b
+		}
+		return projects[i].Id < projects[j].Id
+	})
+	return projects
+}
+
+// Len returns the number of tasks in the index.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.tasks)
+}
+
+// Query returns the tasks matching query, sorted by start date, then by ID.
+// Undated tasks come last. Projects named in the query but not in the index
+// match no task.
+func (ix *Index) Query(query Query) []togglplanapi.Task {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if len(query.ProjectNames) > 0 {
+		query.ProjectIds = append(ix.projectIds(query.ProjectNames), query.ProjectIds...)
+		query.ProjectNames = nil
+		if len(query.ProjectIds) == 0 {
+			return nil
+		}
+	}
+
+	var matches []togglplanapi.Task
+	for id := range ix.candidates(query) {
+		task := ix.tasks[id]
+		if query.Match(task) {
+			matches = append(matches, task)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.StartDate != b.StartDate {
+			switch {
+			case a.StartDate.IsZero():
+				return false
+			case b.StartDate.IsZero():
+				return true
+			}
+			return a.StartDate.Before(b.StartDate)
+		}
+		return a.Id < b.Id
+	})
+
+	return matches
+}
+
+// projectIds returns the IDs of the projects with the given names. The
+// caller holds the lock.
+func (ix *Index) projectIds(names []string) []togglplanapi.ID {
+	var ids []togglplanapi.ID
+	for _, project := range ix.projects {
+		for _, name := range names {
+			if strings.EqualFold(project.Name, name) {
+				ids = append(ids, project.Id)
+				break
+			}
+		}
+	}
+	return ids
+}
+
+// candidates returns the IDs of the tasks that may match query, using the
+// smallest lookup that applies.
+func (ix *Index) candidates(query Query) idSet {
+	var best idSet
+	narrowed := false
+	consider := func(set idSet) {
+		if !narrowed || len(set) < len(best) {
+			best = set
+			narrowed = true
+		}
+	}
+
+	for _, id := range query.Assignees {
+		consider(ix.assignees[id])
+	}
+	for _, tag := range query.Tags {
+		consider(ix.tags[normalizeTag(tag)])
+	}
+	if len(query.ProjectIds) == 1 {
+		consider(ix.inProject[query.ProjectIds[0]])
+	}
+
+	if narrowed {
+		return best
+	}
+
+	all := make(idSet, len(ix.tasks))
+	for id := range ix.tasks {
+		all[id] = struct{}{}
+	}
+	return all
+}
+
+// snapshot is the saved form of an index.
+type snapshot struct {
+	Tasks    []togglplanapi.Task    `json:"tasks"`
+	Projects []togglplanapi.Project `json:"projects"`
+}
+
+// Save writes the tasks and projects of the index to w as JSON.
+func (ix *Index) Save(w io.Writer) error {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var snap snapshot
+	for _, task := range ix.tasks {
+		snap.Tasks = append(snap.Tasks, task)
+	}
+	for _, project := range ix.projects {
+		snap.Projects = append(snap.Projects, project)
+	}
+
+	return json.NewEncoder(w).Encode(snap)
+}
+
+// Load reads an index written by Save.
+func Load(r io.Reader) (*Index, error) {
+	var snap snapshot
+	if err := json.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, err
+	}
+
+	ix := New()
+	ix.PutTasks(snap.Tasks...)
+	ix.PutProjects(snap.Projects...)
+	return ix, nil
+}
+
+// add indexes a task. The caller holds the lock.
+func (ix *Index) add(task togglplanapi.Task) {
+	ix.tasks[task.Id] = task
+
+	for _, id := range task.Assignees {
+		insert(ix.assignees, id, task.Id)
+	}
+	insert(ix.inProject, task.ProjectId, task.Id)
+	for _, tag := range task.Tags {
+		insert(ix.tags, normalizeTag(tag), task.Id)
+	}
+}
+
+// remove drops a task from the index, if present. The caller holds the lock.
+func (ix *Index) remove(taskId togglplanapi.ID) {
+	task, ok := ix.tasks[taskId]
+	if !ok {
+		return
+	}
+	delete(ix.tasks, taskId)
+
+	for _, id := range task.Assignees {
+		discard(ix.assignees, id, taskId)
+	}
+	discard(ix.inProject, task.ProjectId, taskId)
+	for _, tag := range task.Tags {
+		discard(ix.tags, normalizeTag(tag), taskId)
+	}
+}
+
+// insert adds a task ID to the set under key.
+func insert[K comparable](sets map[K]idSet, key K, taskId togglplanapi.ID) {
+	set, ok := sets[key]
+	if !ok {
+		set = idSet{}
+		sets[key] = set
+	}
+	set[taskId] = struct{}{}
+}
+
+// discard removes a task ID from the set under key, dropping empty sets.
+func discard[K comparable](sets map[K]idSet, key K, taskId togglplanapi.ID) {
+	set := sets[key]
+	delete(set, taskId)
+	if len(set) == 0 {
+		delete(sets, key)
+	}
+}
@@ -0,0 +1,192 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"togglplanapi"
+	"togglplanapi/internal/testclient"
+)
+
+func testIndex() *Index {
+	ix := New()
+	ix.PutTasks(
+		togglplanapi.Task{Id: 1, Name: "Write release notes", Assignees: []togglplanapi.ID{12}, Tags: []string{"Urgent"}, ProjectId: 7, StartDate: togglplanapi.NewDate(2024, 3, 4), EndDate: togglplanapi.NewDate(2024, 3, 8)},
+		togglplanapi.Task{Id: 2, Name: "Review notes", Assignees: []togglplanapi.ID{12, 13}, ProjectId: 7, StartDate: togglplanapi.NewDate(2024, 3, 1), EndDate: togglplanapi.NewDate(2024, 3, 1), Done: true},
+		togglplanapi.Task{Id: 3, Name: "Backlog idea", Tags: []string{"urgent"}},
+	)
+	return ix
+}
+
+func ids(tasks []togglplanapi.Task) []togglplanapi.ID {
+	var ids []togglplanapi.ID
+	for _, task := range tasks {
+		ids = append(ids, task.Id)
+	}
+	return ids
+}
+
+func TestQuery(t *testing.T) {
+	ix := testIndex()
+
+	tests := map[string][]togglplanapi.ID{
+		``:                                  {2, 1, 3},
+		`assignee:12`:                       {2, 1},
+		`assignee:12 assignee:13`:           {2},
+		`assignee:99`:                       nil,
+		`tag:URGENT`:                        {1, 3},
+		`project:7 done:no`:                 {1},
+		`on:2024-03-05`:                     {1},
+		`from:2024-03-02 to:2024-03-31`:     {1},
+		`notes`:                             {2, 1},
+		`"release notes"`:                   {1},
+		`"tag:urgent"`:                      nil,
+		`tag:urgent "backlog idea" done:no`: {3},
+		`FROM:2024-03-02 To:2024-03-31`:     {1},
+		`Assignee:13`:                       {2},
+	}
+
+	for text, want := range tests {
+		query, err := ParseQuery(text)
+		if err != nil {
+			t.Errorf("%s: %v", text, err)
+			continue
+		}
+		if got := ids(ix.Query(query)); !equal(got, want) {
+			t.Errorf("%s: got %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, text := range []string{`assignee:ada`, `due:2024-03-01`, `from:March`, `done:maybe`} {
+		if _, err := ParseQuery(text); err == nil {
+			t.Errorf("%s: expected an error", text)
+		}
+	}
+}
+
+func TestProjects(t *testing.T) {
+	pa := testclient.New(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/1/projects" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `[{"id":8,"name":"Website"},{"id":7,"name":"Launch"}]`)
+	})
+
+	ix := testIndex()
+	ix.PutProjects(togglplanapi.Project{Id: 99, Name: "Gone"})
+	if err := ix.RefreshProjects(context.Background(), pa, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	projects := ix.Projects()
+	if len(projects) != 2 || projects[0].Name != "Launch" || projects[1].Name != "Website" {
+		t.Fatalf("expected the projects of the workspace by name, got %+v", projects)
+	}
+
+	tests := map[string][]togglplanapi.ID{
+		`project:launch`:            {2, 1},
+		`project:LAUNCH done:no`:    {1},
+		`project:website`:           nil,
+		`project:gone`:              nil,
+		`project:website project:7`: {2, 1},
+	}
+	for text, want := range tests {
+		query, err := ParseQuery(text)
+		if err != nil {
+			t.Errorf("%s: %v", text, err)
+			continue
+		}
+		if got := ids(ix.Query(query)); !equal(got, want) {
+			t.Errorf("%s: got %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	ix := testIndex()
+
+	renamed := togglplanapi.Task{Id: 1, Name: "Publish release notes", Assignees: []togglplanapi.ID{13}}
+	ix.Apply([]togglplanapi.TaskChange{
+		{Type: togglplanapi.ChangeUpdated, Task: renamed},
+		{Type: togglplanapi.ChangeDeleted, Task: togglplanapi.Task{Id: 3}},
+	})
+
+	if ix.Len() != 2 {
+		t.Fatalf("expected 2 tasks, got %d", ix.Len())
+	}
+	if got := ids(ix.Query(Query{Assignees: []togglplanapi.ID{13}})); !equal(got, []togglplanapi.ID{2, 1}) {
+		t.Fatalf("unexpected tasks of member 13: %v", got)
+	}
+	if got := ids(ix.Query(Query{Tags: []string{"urgent"}})); len(got) != 0 {
+		t.Fatalf("expected no urgent tasks left, got %v", got)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testIndex().Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	ix, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(ix.Query(Query{Assignees: []togglplanapi.ID{12}})); !equal(got, []togglplanapi.ID{2, 1}) {
+		t.Fatalf("unexpected tasks after loading: %v", got)
+	}
+}
+
+func TestRefreshAfterLoad(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testIndex().Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Task 2 was deleted since the index was saved, and task 3 renamed
+	tasks := `[{"id":1,"name":"Write release notes"},{"id":3,"name":"Shipped idea"}]`
+	pa := testclient.New(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, tasks)
+	})
+	feed := togglplanapi.NewTaskFeed(pa, 1, togglplanapi.TaskFeedOptions{})
+
+	ix, err := Load(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Refresh(context.Background(), feed); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ix.Task(2); ok || ix.Len() != 2 {
+		t.Fatalf("expected the deleted task to be dropped, got %d tasks", ix.Len())
+	}
+	if task, _ := ix.Task(3); task.Name != "Shipped idea" {
+		t.Fatalf("expected the renamed task, got %+v", task)
+	}
+
+	// Later refreshes apply the changes reported by the feed
+	tasks = `[{"id":3,"name":"Shipped idea"}]`
+	if err := ix.Refresh(context.Background(), feed); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ix.Task(1); ok || ix.Len() != 1 {
+		t.Fatalf("expected task 1 to be dropped, got %d tasks", ix.Len())
+	}
+}
+
+func equal(a []togglplanapi.ID, b []togglplanapi.ID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,217 @@
+package index
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"togglplanapi"
+)
+
+// Query selects tasks of an Index. Empty fields don't restrict the result.
+type Query struct {
+	// Assignees lists members who must all be assigned to the task.
+	Assignees []togglplanapi.ID
+	// Tags lists tags the task must all have. Tags are not case-sensitive.
+	Tags []string
+	// ProjectIds lists projects the task must be in one of.
+	ProjectIds []togglplanapi.ID
+	// ProjectNames lists more projects the task may be in, by name, not
+	// case-sensitive. They are looked up among the projects of the index by
+	// Index.Query, and ignored by Match.
+	ProjectNames []string
+	// Since and Until select tasks whose dates overlap that window.
+	// Undated tasks never match a window.
+	Since togglplanapi.Date
+	Until togglplanapi.Date
+	// Text lists words that must all appear in the name or notes of the task.
+	Text []string
+	// Done, if set, selects tasks that are done or not.
+	Done *bool
+}
+
+// ParseQuery parses a query written as space-separated terms:
+//
+//	assignee:12     assigned to the member with ID 12
+//	tag:urgent      tagged "urgent"
+//	project:7       in the project with ID 7, or project:website by name
+//	from:2024-03-01 ending on or after the date
+//	to:2024-03-31   starting on or before the date
+//	on:2024-03-04   scheduled on the date
+//	done:yes        done, or not with done:no
+//	word "a phrase" appearing in the name or notes
+//
+// Filter names are not case-sensitive. Terms are combined with AND, except
+// project terms which are combined with OR.
+func ParseQuery(text string) (Query, error) {
+	var query Query
+
+	for _, term := range splitTerms(text) {
+		key, value, found := strings.Cut(term.text, ":")
+		if !found || term.quoted {
+			query.Text = append(query.Text, term.text)
+			continue
+		}
+
+		key = strings.ToLower(key)
+		switch key {
+		case "assignee":
+			id, err := togglplanapi.ParseID(value)
+			if err != nil {
+				return query, fmt.Errorf("invalid assignee %q", value)
+			}
+			query.Assignees = append(query.Assignees, id)
+		case "tag":
+			query.Tags = append(query.Tags, value)
+		case "project":
+			if value == "" {
+				return query, fmt.Errorf("invalid project %q", value)
+			}
+			if id, err := togglplanapi.ParseID(value); err == nil {
+				query.ProjectIds = append(query.ProjectIds, id)
+			} else {
+				query.ProjectNames = append(query.ProjectNames, value)
+			}
+		case "from", "to", "on":
+			date, err := togglplanapi.ParseDate(value)
+			if err != nil {
+				return query, fmt.Errorf("invalid date %q", value)
+			}
+			if key != "to" {
+				query.Since = date
+			}
+			if key != "from" {
+				query.Until = date
+			}
+		case "done":
+			done, err := parseYesNo(value)
+			if err != nil {
+				return query, err
+			}
+			query.Done = &done
+		default:
+			return query, fmt.Errorf("unknown filter %q", key)
+		}
+	}
+
+	return query, nil
+}
+
+// Match reports whether task matches the query.
+func (query Query) Match(task togglplanapi.Task) bool {
+	if query.Done != nil && task.Done != *query.Done {
+		return false
+	}
+
+	for _, id := range query.Assignees {
+		if !containsId(task.Assignees, id) {
+			return false
+		}
+	}
+
+	if len(query.ProjectIds) > 0 && !containsId(query.ProjectIds, task.ProjectId) {
+		return false
+	}
+
+	for _, tag := range query.Tags {
+		found := false
+		for _, taskTag := range task.Tags {
+			if normalizeTag(taskTag) == normalizeTag(tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if !query.Since.IsZero() || !query.Until.IsZero() {
+		if task.StartDate.IsZero() || task.EndDate.IsZero() {
+			return false
+		}
+		if !query.Since.IsZero() && task.EndDate.Before(query.Since) {
+			return false
+		}
+		if !query.Until.IsZero() && task.StartDate.After(query.Until) {
+			return false
+		}
+	}
+
+	if len(query.Text) > 0 {
+		haystack := strings.ToLower(task.Name + "\n" + task.Notes)
+		for _, word := range query.Text {
+			if !strings.Contains(haystack, strings.ToLower(word)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// term is a word or quoted phrase of a query.
+type term struct {
+	text   string
+	quoted bool
+}
+
+// splitTerms splits a query on spaces, keeping double-quoted phrases together.
+func splitTerms(text string) []term {
+	var terms []term
+	var current strings.Builder
+	quoted, inQuotes := false, false
+
+	flush := func() {
+		if current.Len() > 0 {
+			terms = append(terms, term{text: current.String(), quoted: quoted})
+		}
+		current.Reset()
+		quoted = false
+	}
+
+	for _, r := range text {
+		switch {
+		case r == '"':
+			if inQuotes {
+				flush()
+			} else {
+				quoted = true
+			}
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+
+	return terms
+}
+
+// parseYesNo parses the value of a yes/no filter.
+func parseYesNo(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "true", "1":
+		return true, nil
+	case "no", "false", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid yes/no value %q", value)
+}
+
+// containsId reports whether ids contains id.
+func containsId(ids []togglplanapi.ID, id togglplanapi.ID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeTag returns the form tags are compared in.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
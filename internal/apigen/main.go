@@ -0,0 +1,351 @@
+// Command apigen generates models and typed calls of the togglplanapi
+// package from an OpenAPI description of the Toggl Plan API.
+//
+// It reads the subset of OpenAPI 3 the API needs: object schemas under
+// components, and operations whose parameters are IDs in the path, with an
+// optional JSON body and a JSON response that is an object or an array of
+// objects. Run it through go generate in the package directory:
+//
+//	go generate ./...
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+func main() {
+	specPath := flag.String("spec", "openapi.json", "OpenAPI description to read")
+	outPath := flag.String("out", "generated.go", "Go file to write")
+	pkg := flag.String("package", "togglplanapi", "package of the generated file")
+	flag.Parse()
+
+	data, err := os.ReadFile(*specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		log.Fatalf("reading %s: %v", *specPath, err)
+	}
+
+	source, err := Generate(spec, *pkg, *specPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(*outPath, source, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Spec is the part of an OpenAPI description that apigen reads.
+type Spec struct {
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components struct {
+		Schemas map[string]Schema `json:"schemas"`
+	} `json:"components"`
+}
+
+// Operation is an endpoint and method of the API.
+type Operation struct {
+	OperationId string      `json:"operationId"`
+	Summary     string      `json:"summary"`
+	Parameters  []Parameter `json:"parameters"`
+	RequestBody *struct {
+		Description string             `json:"description"`
+		Content     map[string]Content `json:"content"`
+	} `json:"requestBody"`
+	Responses map[string]struct {
+		Content map[string]Content `json:"content"`
+	} `json:"responses"`
+}
+
+// Parameter is a path parameter of an operation.
+type Parameter struct {
+	Name        string `json:"name"`
+	In          string `json:"in"`
+	Description string `json:"description"`
+}
+
+// Content is the body of a request or response of a media type.
+type Content struct {
+	Schema Schema `json:"schema"`
+}
+
+// Schema describes a model, one of its properties or a body.
+type Schema struct {
+	Ref         string     `json:"$ref"`
+	Type        string     `json:"type"`
+	Format      string     `json:"format"`
+	Description string     `json:"description"`
+	Items       *Schema    `json:"items"`
+	Properties  Properties `json:"properties"`
+	// GoType overrides the Go type of a property, e.g. Color.
+	GoType string `json:"x-go-type"`
+}
+
+// Properties are the properties of an object schema, in the order of the
+// description, so that the fields of models follow it.
+type Properties []Property
+
+// Property is a named property of an object schema.
+type Property struct {
+	Name   string
+	Schema Schema
+}
+
+// UnmarshalJSON decodes a JSON object into properties, keeping their order.
+func (p *Properties) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		property := Property{Name: token.(string)}
+		if err := decoder.Decode(&property.Schema); err != nil {
+			return err
+		}
+		*p = append(*p, property)
+	}
+	return nil
+}
+
+// Generate returns the formatted Go source of the models and calls of spec.
+func Generate(spec Spec, pkg string, specPath string) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by apigen from %s. DO NOT EDIT.\n\n", specPath)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n\t\"context\"\n)\n")
+
+	names := make([]string, 0, len(spec.Components.Schemas))
+	for name := range spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := writeModel(&b, name, spec.Components.Schemas[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		for _, method := range []string{"get", "post", "put", "patch", "delete"} {
+			operation, ok := spec.Paths[path][method]
+			if !ok {
+				continue
+			}
+			if err := writeOperation(&b, path, strings.ToUpper(method), operation); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+		}
+	}
+
+	return format.Source(b.Bytes())
+}
+
+// writeModel writes the struct of an object schema.
+func writeModel(b *bytes.Buffer, name string, schema Schema) error {
+	fmt.Fprintf(b, "\n%s", comment(schema.Description, name+" is a model of the API."))
+	fmt.Fprintf(b, "type %s struct {\n", name)
+	for _, property := range schema.Properties {
+		goType, err := typeOf(property.Schema, property.Name)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", name, property.Name, err)
+		}
+		fmt.Fprintf(b, "\t%s %s `json:\"%s\"`\n", fieldName(property.Name), goType, property.Name)
+	}
+	b.WriteString("}\n")
+	return nil
+}
+
+// writeOperation writes the function calling an operation, in the style of
+// the hand-written calls of the package.
+func writeOperation(b *bytes.Buffer, path string, method string, operation Operation) error {
+	if operation.OperationId == "" {
+		return fmt.Errorf("missing operationId")
+	}
+
+	arguments := []string{"ctx context.Context", "pa *togglPlanApi"}
+	docs := []string{"ctx: Context controlling cancellation of the request", "pa: togglPlanApi instance"}
+
+	// Path parameters become ID arguments, filled into the path in order
+	format := path
+	var values []string
+	for _, parameter := range operation.Parameters {
+		if parameter.In != "path" {
+			return fmt.Errorf("unsupported %s parameter %s", parameter.In, parameter.Name)
+		}
+		name := argumentName(parameter.Name)
+		arguments = append(arguments, name+" ID")
+		docs = append(docs, name+": "+parameter.Description)
+		format = strings.Replace(format, "{"+parameter.Name+"}", "%d", 1)
+		values = append(values, name)
+	}
+	if strings.Contains(format, "{") {
+		return fmt.Errorf("path parameter missing from parameters")
+	}
+
+	pathExpr := fmt.Sprintf("%q", format)
+	if len(values) > 0 {
+		if !strings.HasPrefix(path, "/{workspace_id}") {
+			return fmt.Errorf("paths with IDs must start with the workspace ID")
+		}
+		rest := append([]string{fmt.Sprintf("%q", strings.TrimPrefix(format, "/%d"))}, values[1:]...)
+		pathExpr = fmt.Sprintf("workspacePath(workspaceId, %s)", strings.Join(rest, ", "))
+	}
+
+	in := "nil"
+	if operation.RequestBody != nil {
+		body, ok := operation.RequestBody.Content["application/json"]
+		if !ok || body.Schema.Ref == "" {
+			return fmt.Errorf("request body must reference a schema")
+		}
+		arguments = append(arguments, "params "+refName(body.Schema.Ref))
+		docs = append(docs, "params: "+operation.RequestBody.Description)
+		in = "params"
+	}
+
+	// The response is an object, an array of objects, or nothing
+	result, out := "", ""
+	for _, status := range []string{"200", "201"} {
+		response, ok := operation.Responses[status]
+		if !ok {
+			continue
+		}
+		if content, ok := response.Content["application/json"]; ok {
+			goType, err := typeOf(content.Schema, "")
+			if err != nil {
+				return err
+			}
+			result, out = goType, "result"
+		}
+		break
+	}
+
+	b.WriteString("\n")
+	b.WriteString(comment(operation.Summary, operation.OperationId+" calls "+method+" "+path+"."))
+	b.WriteString("// Arguments:\n//\n")
+	for _, doc := range docs {
+		fmt.Fprintf(b, "//\t%s\n", doc)
+	}
+
+	send := fmt.Sprintf("sendJSON(ctx, pa, %q, %s, nil, %s, &result)", method, pathExpr, in)
+	if method == "GET" {
+		send = fmt.Sprintf("getJSON(ctx, pa, %s, nil, &result)", pathExpr)
+	}
+
+	switch {
+	case out == "":
+		send = strings.Replace(send, "&result", "nil", 1)
+		fmt.Fprintf(b, "func %s(%s) error {\n\treturn %s\n}\n", operation.OperationId, strings.Join(arguments, ", "), send)
+	case strings.HasPrefix(result, "[]"):
+		fmt.Fprintf(b, "func %s(%s) (%s, error) {\n\tvar result %s\n\terr := %s\n\treturn result, err\n}\n",
+			operation.OperationId, strings.Join(arguments, ", "), result, result, send)
+	default:
+		fmt.Fprintf(b, "func %s(%s) (*%s, error) {\n\tvar result %s\n\tif err := %s; err != nil {\n\t\treturn nil, err\n\t}\n\treturn &result, nil\n}\n",
+			operation.OperationId, strings.Join(arguments, ", "), result, result, send)
+	}
+	return nil
+}
+
+// typeOf returns the Go type of a schema. IDs are 64-bit integers, and dates
+// use the Date and DateTime types of the package.
+func typeOf(schema Schema, name string) (string, error) {
+	if schema.GoType != "" {
+		return schema.GoType, nil
+	}
+	if schema.Ref != "" {
+		return refName(schema.Ref), nil
+	}
+
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date":
+			return "Date", nil
+		case "date-time":
+			return "DateTime", nil
+		}
+		return "string", nil
+	case "integer":
+		if schema.Format == "int64" || name == "id" || strings.HasSuffix(name, "_id") {
+			return "ID", nil
+		}
+		return "int", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if schema.Items == nil {
+			return "", fmt.Errorf("array without items")
+		}
+		item, err := typeOf(*schema.Items, strings.TrimSuffix(name, "s"))
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	}
+	return "", fmt.Errorf("unsupported type %q", schema.Type)
+}
+
+// refName returns the name of the schema a $ref points to.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// fieldName converts a snake_case property to a field name, e.g. created_at
+// to CreatedAt. IDs are written Id and URLs URL, as in the rest of the package.
+func fieldName(property string) string {
+	var name strings.Builder
+	for _, part := range strings.Split(property, "_") {
+		switch {
+		case part == "url":
+			name.WriteString("URL")
+		case part != "":
+			name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return name.String()
+}
+
+// argumentName converts a snake_case parameter to an argument name, e.g.
+// workspace_id to workspaceId.
+func argumentName(parameter string) string {
+	name := fieldName(parameter)
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// comment returns text as a Go comment, or fallback if text is empty.
+func comment(text string, fallback string) string {
+	if strings.TrimSpace(text) == "" {
+		text = fallback
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		b.WriteString(strings.TrimRight("// "+line, " ") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	var spec Spec
+	err := json.Unmarshal([]byte(`{
+		"paths": {
+			"/{workspace_id}/labels/{label_id}": {
+				"put": {
+					"operationId": "UpdateLabel",
+					"parameters": [
+						{"name": "workspace_id", "in": "path", "description": "ID of the workspace"},
+						{"name": "label_id", "in": "path", "description": "ID of the label"}
+					],
+					"requestBody": {"description": "Fields to change", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/LabelUpdate"}}}},
+					"responses": {"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Label"}}}}}
+				}
+			}
+		},
+		"components": {
+			"schemas": {
+				"Label": {"properties": {"name": {"type": "string"}, "id": {"type": "integer"}, "color": {"type": "string", "x-go-type": "Color"}, "due_on": {"type": "string", "format": "date"}}},
+				"LabelUpdate": {"properties": {"name": {"type": "string"}}}
+			}
+		}
+	}`), &spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := Generate(spec, "togglplanapi", "openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"type Label struct {\n\tName  string `json:\"name\"`\n\tId    ID     `json:\"id\"`\n\tColor Color  `json:\"color\"`\n\tDueOn Date   `json:\"due_on\"`\n}",
+		"func UpdateLabel(ctx context.Context, pa *togglPlanApi, workspaceId ID, labelId ID, params LabelUpdate) (*Label, error) {",
+		`sendJSON(ctx, pa, "PUT", workspacePath(workspaceId, "/labels/%d", labelId), nil, params, &result)`,
+		"//\tlabelId: ID of the label\n",
+	} {
+		if !strings.Contains(string(source), expected) {
+			t.Errorf("expected %q in:\n%s", expected, source)
+		}
+	}
+}
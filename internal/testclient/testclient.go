@@ -0,0 +1,34 @@
+// Package testclient builds togglplanapi clients whose requests are served by
+// a test handler, for the tests of the packages built on togglplanapi.
+package testclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"togglplanapi"
+)
+
+// New returns a client whose requests are sent to a test server running
+// handler, whatever their URL. The server is closed when the test ends.
+// Paths are those of the API, e.g. /api/v5/1/tasks.
+func New(t *testing.T, handler http.HandlerFunc) *togglplanapi.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return togglplanapi.New("user", "pass", "id", "secret", "token", togglplanapi.WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.URL.Scheme, req.URL.Host = "http", server.Listener.Addr().String()
+			return next.RoundTrip(req)
+		})
+	}))
+}
+
+// roundTripperFunc is an http.RoundTripper calling a function.
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,74 @@
+package togglplanapi
+
+import (
+	"context"
+	"time"
+)
+
+// Invitation is a pending invitation to join a workspace.
+type Invitation struct {
+	Id        ID       `json:"id"`
+	Email     string   `json:"email"`
+	Role      Role     `json:"role"`
+	InvitedBy ID       `json:"inviter_id"`
+	CreatedAt DateTime `json:"created_at"`
+	// SentAt is when the invitation was last sent, which changes when it
+	// is resent.
+	SentAt DateTime `json:"sent_at"`
+}
+
+// GetInvitations fetches the pending invitations of a workspace.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+func GetInvitations(ctx context.Context, pa *togglPlanApi, workspaceId ID) ([]Invitation, error) {
+	var invitations []Invitation
+	err := getJSON(ctx, pa, workspacePath(workspaceId, "/invitations"), nil, &invitations)
+	return invitations, err
+}
+
+// ResendInvitation sends the email of a pending invitation again, and returns
+// the updated invitation.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	invitationId: ID of the invitation
+func ResendInvitation(ctx context.Context, pa *togglPlanApi, workspaceId ID, invitationId ID) (*Invitation, error) {
+	var invitation Invitation
+	if err := sendJSON(ctx, pa, "POST", workspacePath(workspaceId, "/invitations/%d/resend", invitationId), nil, nil, &invitation); err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// RevokeInvitation cancels a pending invitation, so that its link stops working.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	invitationId: ID of the invitation
+func RevokeInvitation(ctx context.Context, pa *togglPlanApi, workspaceId ID, invitationId ID) error {
+	return sendJSON(ctx, pa, "DELETE", workspacePath(workspaceId, "/invitations/%d", invitationId), nil, nil, nil)
+}
+
+// StaleInvitations returns the invitations last sent before cutoff, e.g.
+// time.Now().AddDate(0, 0, -14) for those left unanswered for two weeks.
+// Invitations that were never sent count from their creation.
+func StaleInvitations(invitations []Invitation, cutoff time.Time) []Invitation {
+	var stale []Invitation
+	for _, invitation := range invitations {
+		sent := invitation.SentAt.Time
+		if sent.IsZero() {
+			sent = invitation.CreatedAt.Time
+		}
+		if sent.Before(cutoff) {
+			stale = append(stale, invitation)
+		}
+	}
+	return stale
+}
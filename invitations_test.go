@@ -0,0 +1,24 @@
+package togglplanapi
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestStaleInvitations(t *testing.T) {
+	var invitations []Invitation
+	err := json.Unmarshal([]byte(`[
+		{"id":1,"created_at":"2024-01-01T00:00:00Z","sent_at":"2024-03-01T00:00:00Z"},
+		{"id":2,"created_at":"2024-01-01T00:00:00Z"},
+		{"id":3,"created_at":"2024-03-10T00:00:00Z"}
+	]`), &invitations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale := StaleInvitations(invitations, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	if len(stale) != 1 || stale[0].Id != 2 {
+		t.Fatalf("unexpected stale invitations %+v", stale)
+	}
+}
@@ -0,0 +1,396 @@
+package togglplanapi
+
+import (
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Task notes and comment bodies are HTML. HTMLToText and HTMLToMarkdown turn
+// them into text for reports, chat messages and terminals, and MarkdownToHTML
+// goes the other way for posting comments. They handle the small subset of
+// HTML produced by the Toggl Plan editor: paragraphs, line breaks, headings,
+// lists, quotes, code, links and text styles. Other tags are dropped and
+// their text kept.
+
+// HTMLToText converts an HTML body to plain text. Lists keep their bullets
+// and numbers, and links are followed by their URL. A body without tags is
+// returned as is, with entities decoded.
+func HTMLToText(body string) string {
+	return convertHTML(body, false)
+}
+
+// HTMLToMarkdown converts an HTML body to Markdown. A body without tags is
+// returned as is, with entities decoded.
+func HTMLToMarkdown(body string) string {
+	return convertHTML(body, true)
+}
+
+// htmlTag matches a tag: whether it is a closing tag, its name, and its attributes.
+var htmlTag = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*)>`)
+
+// hrefAttr matches the href attribute of a tag.
+var hrefAttr = regexp.MustCompile(`\bhref\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+
+// htmlConverter holds the state of a conversion from HTML.
+type htmlConverter struct {
+	markdown bool
+	out      strings.Builder
+	// lists holds the number of the next item of each open list, or -1 for
+	// bulleted lists.
+	lists []int
+	// links holds the URL and the output position of each open link.
+	links []link
+	pre   bool
+}
+
+type link struct {
+	href  string
+	start int
+}
+
+// convertHTML converts body to plain text or Markdown.
+func convertHTML(body string, markdown bool) string {
+	if !htmlTag.MatchString(body) {
+		return strings.TrimSpace(html.UnescapeString(body))
+	}
+
+	c := &htmlConverter{markdown: markdown}
+	position := 0
+	for _, match := range htmlTag.FindAllStringSubmatchIndex(body, -1) {
+		c.text(body[position:match[0]])
+		c.tag(body[match[2]:match[3]] == "/", strings.ToLower(body[match[4]:match[5]]), body[match[6]:match[7]])
+		position = match[1]
+	}
+	c.text(body[position:])
+
+	return tidyLines(c.out.String())
+}
+
+// text writes the decoded text between two tags, collapsing whitespace
+// outside preformatted blocks.
+func (c *htmlConverter) text(raw string) {
+	text := html.UnescapeString(raw)
+	if c.pre {
+		c.out.WriteString(text)
+		return
+	}
+
+	text = strings.Join(strings.Fields(text), " ")
+	if raw != "" && strings.TrimSpace(raw) == "" {
+		text = " "
+	} else {
+		if strings.TrimLeft(raw, " \t\r\n") != raw {
+			text = " " + text
+		}
+		if strings.TrimRight(raw, " \t\r\n") != raw {
+			text += " "
+		}
+	}
+
+	// Whitespace at the start of a line is dropped
+	current := c.out.String()
+	if current == "" || strings.HasSuffix(current, "\n") || strings.HasSuffix(current, " ") {
+		text = strings.TrimLeft(text, " ")
+	}
+	c.out.WriteString(text)
+}
+
+// tag writes what an opening or closing tag stands for.
+func (c *htmlConverter) tag(closing bool, name string, attributes string) {
+	switch name {
+	case "br":
+		c.out.WriteString("\n")
+	case "p", "div":
+		c.block()
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		c.block()
+		if !closing && c.markdown {
+			level, _ := strconv.Atoi(name[1:])
+			c.out.WriteString(strings.Repeat("#", level) + " ")
+		}
+	case "blockquote":
+		c.block()
+		if !closing {
+			c.out.WriteString("> ")
+		}
+	case "ul", "ol":
+		if closing {
+			if len(c.lists) > 0 {
+				c.lists = c.lists[:len(c.lists)-1]
+			}
+			if len(c.lists) == 0 {
+				c.block()
+			}
+			return
+		}
+		if len(c.lists) == 0 {
+			c.block()
+		}
+		next := -1
+		if name == "ol" {
+			next = 1
+		}
+		c.lists = append(c.lists, next)
+	case "li":
+		c.newline()
+		if closing || len(c.lists) == 0 {
+			return
+		}
+		depth := len(c.lists) - 1
+		c.out.WriteString(strings.Repeat("  ", depth))
+		if c.lists[depth] < 0 {
+			c.out.WriteString("- ")
+		} else {
+			c.out.WriteString(strconv.Itoa(c.lists[depth]) + ". ")
+			c.lists[depth]++
+		}
+	case "pre":
+		c.pre = !closing
+		if !c.markdown {
+			c.block()
+			return
+		}
+		if closing {
+			c.newline()
+			c.out.WriteString("```")
+			c.block()
+			return
+		}
+		c.block()
+		c.out.WriteString("```\n")
+	case "strong", "b":
+		c.style("**")
+	case "em", "i":
+		c.style("_")
+	case "s", "del", "strike":
+		c.style("~~")
+	case "code":
+		if !c.pre {
+			c.style("`")
+		}
+	case "a":
+		c.link(closing, attributes)
+	}
+}
+
+// style writes the Markdown delimiter of a text style.
+func (c *htmlConverter) style(delimiter string) {
+	if c.markdown {
+		c.out.WriteString(delimiter)
+	}
+}
+
+// link writes the start or the end of a link. In plain text, the URL follows
+// the text of the link unless they are the same.
+func (c *htmlConverter) link(closing bool, attributes string) {
+	if !closing {
+		href := ""
+		if match := hrefAttr.FindStringSubmatch(attributes); match != nil {
+			href = html.UnescapeString(match[1] + match[2])
+		}
+		if c.markdown && href != "" {
+			c.out.WriteString("[")
+		}
+		c.links = append(c.links, link{href: href, start: c.out.Len()})
+		return
+	}
+
+	if len(c.links) == 0 {
+		return
+	}
+	l := c.links[len(c.links)-1]
+	c.links = c.links[:len(c.links)-1]
+	if l.href == "" {
+		return
+	}
+
+	text := c.out.String()[l.start:]
+	switch {
+	case c.markdown:
+		c.out.WriteString("](" + l.href + ")")
+	case strings.TrimSpace(text) != l.href:
+		c.out.WriteString(" (" + l.href + ")")
+	}
+}
+
+// newline starts a new line, unless the output is at the start of one.
+func (c *htmlConverter) newline() {
+	if current := c.out.String(); current != "" && !strings.HasSuffix(current, "\n") {
+		c.out.WriteString("\n")
+	}
+}
+
+// block starts a new paragraph, unless the output is at the start of one.
+func (c *htmlConverter) block() {
+	c.newline()
+	if current := c.out.String(); current != "" && !strings.HasSuffix(current, "\n\n") {
+		c.out.WriteString("\n")
+	}
+}
+
+// tidyLines trims trailing spaces and blank lines, and collapses runs of
+// blank lines into one.
+func tidyLines(text string) string {
+	lines := strings.Split(text, "\n")
+	tidy := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimRight(line, " \t")
+		if line == "" && (len(tidy) == 0 || tidy[len(tidy)-1] == "") {
+			continue
+		}
+		tidy = append(tidy, line)
+	}
+	return strings.TrimSpace(strings.Join(tidy, "\n"))
+}
+
+var (
+	markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	markdownBullet  = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	markdownNumber  = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	markdownQuote   = regexp.MustCompile(`^>\s?(.*)$`)
+
+	markdownLink   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	markdownStrong = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	markdownEm     = regexp.MustCompile(`\*([^*\s](?:[^*]*[^*\s])?)\*|\b_([^_\s](?:[^_]*[^_\s])?)_\b`)
+	markdownStrike = regexp.MustCompile(`~~(.+?)~~`)
+)
+
+// MarkdownToHTML converts Markdown to an HTML body, for posting comments.
+// It supports paragraphs, headings, flat lists, quotes, fenced code blocks,
+// code spans, links, and bold, italic and struck-through text. Line breaks
+// within a paragraph are kept. Only http, https and mailto links are kept as
+// links; others are left as their text.
+//
+// If members are given, their @mentions are turned into mentions, as with
+// FormatMentions.
+func MarkdownToHTML(markdown string, members []Member) string {
+	var b strings.Builder
+	var paragraph []string
+	list := ""
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + strings.Join(paragraph, "<br>") + "</p>")
+			paragraph = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			b.WriteString("</" + list + ">")
+			list = ""
+		}
+	}
+	openList := func(tag string) {
+		if list != tag {
+			closeList()
+			b.WriteString("<" + tag + ">")
+			list = tag
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(markdown, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			flushParagraph()
+			closeList()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			b.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>")
+			continue
+		}
+
+		if match := markdownBullet.FindStringSubmatch(line); match != nil {
+			flushParagraph()
+			openList("ul")
+			b.WriteString("<li>" + markdownInline(match[1], members) + "</li>")
+			continue
+		}
+		if match := markdownNumber.FindStringSubmatch(line); match != nil {
+			flushParagraph()
+			openList("ol")
+			b.WriteString("<li>" + markdownInline(match[1], members) + "</li>")
+			continue
+		}
+		closeList()
+
+		switch match := markdownHeading.FindStringSubmatch(line); {
+		case strings.TrimSpace(line) == "":
+			flushParagraph()
+		case match != nil:
+			flushParagraph()
+			tag := "h" + strconv.Itoa(len(match[1]))
+			b.WriteString("<" + tag + ">" + markdownInline(match[2], members) + "</" + tag + ">")
+		case markdownQuote.MatchString(line):
+			flushParagraph()
+			b.WriteString("<blockquote>" + markdownInline(markdownQuote.FindStringSubmatch(line)[1], members) + "</blockquote>")
+		default:
+			paragraph = append(paragraph, markdownInline(strings.TrimSpace(line), members))
+		}
+	}
+	flushParagraph()
+	closeList()
+
+	return b.String()
+}
+
+// markdownInline converts the inline Markdown of a line to HTML. Code spans
+// are left alone, and the rest is escaped before styles are applied.
+func markdownInline(line string, members []Member) string {
+	var b strings.Builder
+	parts := strings.Split(line, "`")
+	for i, part := range parts {
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			b.WriteString("<code>" + html.EscapeString(part) + "</code>")
+		case i%2 == 1:
+			// An unmatched backtick is kept as text
+			b.WriteString(markdownStyles("`"+part, members))
+		default:
+			b.WriteString(markdownStyles(part, members))
+		}
+	}
+	return b.String()
+}
+
+// markdownStyles escapes text, turns mentions into markup and applies links
+// and text styles. Links to targets that aren't safe are left as their text.
+func markdownStyles(text string, members []Member) string {
+	escaped := html.EscapeString(text)
+	if len(members) > 0 {
+		escaped = FormatMentions(text, members)
+	}
+
+	escaped = markdownLink.ReplaceAllStringFunc(escaped, func(link string) string {
+		match := markdownLink.FindStringSubmatch(link)
+		if !safeLink(html.UnescapeString(match[2])) {
+			return match[1]
+		}
+		return `<a href="` + match[2] + `">` + match[1] + `</a>`
+	})
+	escaped = markdownStrong.ReplaceAllString(escaped, "<strong>$1$2</strong>")
+	escaped = markdownEm.ReplaceAllString(escaped, "<em>$1$2</em>")
+	escaped = markdownStrike.ReplaceAllString(escaped, "<s>$1</s>")
+	return escaped
+}
+
+// safeLink reports whether target is a link that can go in a comment: an
+// http, https or mailto URL. Others, such as javascript: URLs, would run in
+// the browser of whoever opens the link.
+func safeLink(target string) bool {
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "mailto":
+		return true
+	}
+	return false
+}
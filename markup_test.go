@@ -0,0 +1,83 @@
+package togglplanapi
+
+import (
+	"testing"
+)
+
+const richBody = `<p>Hi <span class="mention" data-member-id="1">@Ada</span>, see <a href="https://example.com/spec">the <b>spec</b></a>.</p>
+<ul><li>Fix &amp; test</li><li><em>Then</em> ship</li></ul>
+<ol><li>One</li><li>Two<br>lines</li></ol>
+<pre>go test
+./...</pre>`
+
+func TestHTMLToText(t *testing.T) {
+	expected := "Hi @Ada, see the spec (https://example.com/spec).\n\n" +
+		"- Fix & test\n- Then ship\n\n" +
+		"1. One\n2. Two\nlines\n\n" +
+		"go test\n./..."
+	if text := HTMLToText(richBody); text != expected {
+		t.Fatalf("unexpected text:\n%s", text)
+	}
+
+	if text := HTMLToText("Plain\n\nnotes &lt;3"); text != "Plain\n\nnotes <3" {
+		t.Fatalf("unexpected text for plain notes: %q", text)
+	}
+}
+
+func TestHTMLToMarkdown(t *testing.T) {
+	expected := "Hi @Ada, see [the **spec**](https://example.com/spec).\n\n" +
+		"- Fix & test\n- _Then_ ship\n\n" +
+		"1. One\n2. Two\nlines\n\n" +
+		"```\ngo test\n./...\n```"
+	if markdown := HTMLToMarkdown(richBody); markdown != expected {
+		t.Fatalf("unexpected Markdown:\n%s", markdown)
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	markdown := "## Plan\n\n**Ship** it, @ada & read [docs](https://example.com/a?b=1&c=2).\nUse `a<b` _now_.\n\n- one\n- two\n\n```\nx := 1 < 2\n```"
+	members := []Member{{Id: 1, Name: "Ada", Email: "ada@example.com"}}
+
+	expected := `<h2>Plan</h2>` +
+		`<p><strong>Ship</strong> it, <span class="mention" data-member-id="1">@Ada</span> &amp; read <a href="https://example.com/a?b=1&amp;c=2">docs</a>.<br>` +
+		`Use <code>a&lt;b</code> <em>now</em>.</p>` +
+		`<ul><li>one</li><li>two</li></ul>` +
+		`<pre><code>x := 1 &lt; 2</code></pre>`
+	if body := MarkdownToHTML(markdown, members); body != expected {
+		t.Fatalf("unexpected HTML:\n%s\nexpected:\n%s", body, expected)
+	}
+
+	if markdown := HTMLToMarkdown(MarkdownToHTML("- **a**\n- b", nil)); markdown != "- **a**\n- b" {
+		t.Fatalf("round trip gave %q", markdown)
+	}
+}
+
+func TestMarkdownToHTMLLinks(t *testing.T) {
+	cases := map[string]string{
+		"[x](javascript:alert(1))":         "<p>x)</p>",
+		"[x](JavaScript:alert)":            "<p>x</p>",
+		"[x](data:text/html,hi)":           "<p>x</p>",
+		"[x](&#106;avascript:alert)":       "<p>x</p>",
+		"[x](/relative)":                   "<p>x</p>",
+		"[x](mailto:ada@example.com)":      `<p><a href="mailto:ada@example.com">x</a></p>`,
+		"[x](HTTP://example.com/?a=1&b=2)": `<p><a href="HTTP://example.com/?a=1&amp;b=2">x</a></p>`,
+	}
+	for markdown, expected := range cases {
+		if body := MarkdownToHTML(markdown, nil); body != expected {
+			t.Errorf("MarkdownToHTML(%q): expected %s, got %s", markdown, expected, body)
+		}
+	}
+}
+
+func FuzzHTMLToMarkdown(f *testing.F) {
+	f.Add(richBody)
+	f.Add(`<p><span class="mention" data-member-id="x">@</span><a href=>`)
+	f.Add(`<ol><li><ul><li></ol>&#xffffff;`)
+	f.Fuzz(func(t *testing.T, body string) {
+		// Notes come from the API as written by any client, and must not
+		// panic the conversions
+		HTMLToText(body)
+		HTMLToMarkdown(body)
+		MentionsOf(body)
+	})
+}
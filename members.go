@@ -0,0 +1,203 @@
+package togglplanapi
+
+import (
+	"context"
+	"time"
+)
+
+// Member represents a member of a workspace.
+type Member struct {
+	Id        ID       `json:"id"`
+	UserId    ID       `json:"user_id"`
+	Name      string   `json:"name"`
+	Email     string   `json:"email"`
+	AvatarURL string   `json:"avatar_url"`
+	Role      Role     `json:"role"`
+	Active    bool     `json:"active"`
+	CreatedAt DateTime `json:"created_at"`
+	UpdatedAt DateTime `json:"updated_at"`
+}
+
+// Group represents a group (team) of workspace members.
+type Group struct {
+	Id        ID       `json:"id"`
+	Name      string   `json:"name"`
+	MemberIds []ID     `json:"workspace_members"`
+	CreatedAt DateTime `json:"created_at"`
+	UpdatedAt DateTime `json:"updated_at"`
+}
+
+// GetMembers fetches all members of a workspace.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+func GetMembers(ctx context.Context, pa *togglPlanApi, workspaceId ID) ([]Member, error) {
+	var members []Member
+	err := getJSON(ctx, pa, workspacePath(workspaceId, "/members"), nil, &members)
+	return members, err
+}
+
+// GetGroups fetches all groups of a workspace.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+func GetGroups(ctx context.Context, pa *togglPlanApi, workspaceId ID) ([]Group, error) {
+	var groups []Group
+	err := getJSON(ctx, pa, workspacePath(workspaceId, "/groups"), nil, &groups)
+	return groups, err
+}
+
+// MemberParams holds the fields of a member to invite to a workspace.
+type MemberParams struct {
+	Email string `json:"email"`
+	// Role is the role of the new member, e.g. RoleRegular. The API picks one
+	// if it is empty.
+	Role Role `json:"role,omitempty"`
+}
+
+// InviteMember invites someone to a workspace by email, and returns the new member.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	params: Email and role of the new member
+func InviteMember(ctx context.Context, pa *togglPlanApi, workspaceId ID, params MemberParams) (*Member, error) {
+	var member Member
+	if err := sendJSON(ctx, pa, "POST", workspacePath(workspaceId, "/members"), nil, params, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// RemoveMember removes a member from a workspace.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	memberId: ID of the workspace member
+func RemoveMember(ctx context.Context, pa *togglPlanApi, workspaceId ID, memberId ID) error {
+	return sendJSON(ctx, pa, "DELETE", workspacePath(workspaceId, "/members/%d", memberId), nil, nil, nil)
+}
+
+// MemberBatchOptions configures InviteMembers and RemoveMembers.
+type MemberBatchOptions struct {
+	// ChunkSize is the number of members handled before moving on to the
+	// next chunk. Defaults to 20.
+	ChunkSize int
+	// Concurrency is the number of requests sent at the same time within a
+	// chunk. Defaults to 4.
+	Concurrency int
+	// ChunkDelay is the pause between two chunks, to spread large batches
+	// out under the rate limit of the API. Defaults to 0: chunks are sent
+	// back to back, and rate-limited requests are retried.
+	ChunkDelay time.Duration
+}
+
+// MemberResult reports the outcome of inviting or removing one member.
+type MemberResult struct {
+	// Email is the invited email, for InviteMembers.
+	Email string
+	// MemberId is the ID of the invited or removed member, or 0 if an
+	// invitation failed.
+	MemberId ID
+	// Err is the reason the member couldn't be invited or removed, if any.
+	Err error
+}
+
+// InviteMembers invites several people to a workspace, e.g. to reconcile it
+// with a directory export, and reports the outcome of each invitation in the
+// same order as invites. A failed invitation doesn't stop the others.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	invites: Emails and roles of the new members
+//	options: Batch settings (use `togglplanapi.MemberBatchOptions{}` for the defaults)
+func InviteMembers(ctx context.Context, pa *togglPlanApi, workspaceId ID, invites []MemberParams, options MemberBatchOptions) []MemberResult {
+	results := make([]MemberResult, len(invites))
+	jobs := make([]func(ctx context.Context) error, len(invites))
+	for i, invite := range invites {
+		i, invite := i, invite
+		results[i].Email = invite.Email
+		jobs[i] = func(ctx context.Context) error {
+			member, err := InviteMember(ctx, pa, workspaceId, invite)
+			if err == nil {
+				results[i].MemberId = member.Id
+			}
+			return err
+		}
+	}
+
+	for i, err := range runChunked(ctx, options, jobs) {
+		results[i].Err = err
+	}
+	return results
+}
+
+// RemoveMembers removes several members from a workspace, and reports the
+// outcome of each removal in the same order as memberIds. A failed removal
+// doesn't stop the others.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	memberIds: IDs of the workspace members to remove
+//	options: Batch settings (use `togglplanapi.MemberBatchOptions{}` for the defaults)
+func RemoveMembers(ctx context.Context, pa *togglPlanApi, workspaceId ID, memberIds []ID, options MemberBatchOptions) []MemberResult {
+	results := make([]MemberResult, len(memberIds))
+	jobs := make([]func(ctx context.Context) error, len(memberIds))
+	for i, memberId := range memberIds {
+		memberId := memberId
+		results[i].MemberId = memberId
+		jobs[i] = func(ctx context.Context) error {
+			return RemoveMember(ctx, pa, workspaceId, memberId)
+		}
+	}
+
+	for i, err := range runChunked(ctx, options, jobs) {
+		results[i].Err = err
+	}
+	return results
+}
+
+// runChunked runs jobs with RunBatch, one chunk after another, pausing for
+// ChunkDelay in between. Jobs left when ctx is canceled fail with its error.
+func runChunked(ctx context.Context, options MemberBatchOptions, jobs []func(ctx context.Context) error) []error {
+	if options.ChunkSize <= 0 {
+		options.ChunkSize = 20
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 4
+	}
+
+	errs := make([]error, 0, len(jobs))
+	for start := 0; start < len(jobs); start += options.ChunkSize {
+		if start > 0 && options.ChunkDelay > 0 {
+			timer := time.NewTimer(options.ChunkDelay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				for len(errs) < len(jobs) {
+					errs = append(errs, ctx.Err())
+				}
+				return errs
+			case <-timer.C:
+			}
+		}
+
+		end := start + options.ChunkSize
+		if end > len(jobs) {
+			end = len(jobs)
+		}
+		errs = append(errs, RunBatch(ctx, options.Concurrency, jobs[start:end])...)
+	}
+	return errs
+}
@@ -0,0 +1,88 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInviteMembers(t *testing.T) {
+	var nextId int64
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var params MemberParams
+		json.NewDecoder(r.Body).Decode(&params)
+		if strings.HasSuffix(params.Email, "@invalid") {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		fmt.Fprintf(w, `{"id":%d,"email":%q,"role":%q}`, atomic.AddInt64(&nextId, 1), params.Email, params.Role)
+	})
+
+	invites := []MemberParams{
+		{Email: "ada@example.com", Role: "admin"},
+		{Email: "bob@invalid"},
+		{Email: "cy@example.com"},
+	}
+	results := InviteMembers(context.Background(), pa, 1, invites, MemberBatchOptions{ChunkSize: 2})
+
+	for i, result := range results {
+		if result.Email != invites[i].Email {
+			t.Fatalf("result %d is for %s, expected %s", i, result.Email, invites[i].Email)
+		}
+		if (i == 1) != (result.Err != nil) || (i == 1) != (result.MemberId == 0) {
+			t.Fatalf("unexpected result %d: %+v", i, result)
+		}
+	}
+}
+
+func TestRemoveMembers(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" || r.URL.Path == "/api/v5/1/members/8" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	results := RemoveMembers(context.Background(), pa, 1, []ID{7, 8, 9}, MemberBatchOptions{})
+
+	for i, result := range results {
+		if (result.MemberId == 8) != (result.Err != nil) {
+			t.Fatalf("unexpected result %d: %+v", i, result)
+		}
+	}
+}
+
+func TestMemberBatchChunkDelay(t *testing.T) {
+	var times []time.Time
+	var mu sync.Mutex
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+	})
+
+	delay := 50 * time.Millisecond
+	results := RemoveMembers(context.Background(), pa, 1, []ID{7, 8, 9}, MemberBatchOptions{ChunkSize: 2, ChunkDelay: delay})
+	for i, result := range results {
+		if result.Err != nil {
+			t.Fatalf("unexpected result %d: %+v", i, result)
+		}
+	}
+	if len(times) != 3 || times[2].Sub(times[1]) < delay {
+		t.Fatalf("expected a pause before the second chunk, got requests at %v", times)
+	}
+
+	// Canceling during the pause fails the remaining members
+	ctx, cancel := context.WithCancel(context.Background())
+	pa = newTestClient(t, func(w http.ResponseWriter, r *http.Request) { cancel() })
+	results = RemoveMembers(ctx, pa, 1, []ID{7, 8, 9}, MemberBatchOptions{ChunkSize: 2, ChunkDelay: time.Hour})
+	if len(results) != 3 || !errors.Is(results[2].Err, context.Canceled) {
+		t.Fatalf("expected the last member to fail with the context, got %+v", results)
+	}
+}
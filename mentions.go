@@ -0,0 +1,122 @@
+package togglplanapi
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Comment bodies are HTML, in which a mention of a workspace member is a span
+// holding the ID of the member and the text shown for it:
+//
+//	<span class="mention" data-member-id="42">@Ada Lovelace</span>
+var mentionPattern = regexp.MustCompile(`<span[^>]*\bdata-member-id="(\d+)"[^>]*>(.*?)</span>`)
+
+// Mentions returns the IDs of the members mentioned in the comment.
+func (comment Comment) Mentions() []ID {
+	return MentionsOf(comment.Body)
+}
+
+// MentionsOf returns the IDs of the members mentioned in a comment body, in
+// order of first mention and without duplicates.
+func MentionsOf(body string) []ID {
+	var ids []ID
+	seen := map[ID]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		id, err := ParseID(match[1])
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// ReplaceMentions calls replace for each mention in a comment body, with the
+// ID of the member and the text shown for it, and substitutes the result,
+// e.g. to turn mentions into mentions of a chat tool. The rest of the body is
+// left as is.
+func ReplaceMentions(body string, replace func(memberId ID, text string) string) string {
+	return mentionPattern.ReplaceAllStringFunc(body, func(markup string) string {
+		match := mentionPattern.FindStringSubmatch(markup)
+		id, err := ParseID(match[1])
+		if err != nil {
+			return markup
+		}
+		return replace(id, html.UnescapeString(match[2]))
+	})
+}
+
+// Mention returns the markup mentioning a member in a comment body.
+func Mention(member Member) string {
+	return fmt.Sprintf(`<span class="mention" data-member-id="%d">@%s</span>`, member.Id, html.EscapeString(member.Name))
+}
+
+// FormatMentions turns plain text into a comment body, escaping it and
+// replacing @mentions of members with their markup. A member is mentioned by
+// name, e.g. "@Ada Lovelace", or by the part of their email before the @,
+// e.g. "@ada". Names are matched regardless of case, longest first, and
+// @words matching no member are kept as text.
+func FormatMentions(text string, members []Member) string {
+	type candidate struct {
+		handle string
+		member Member
+	}
+	var candidates []candidate
+	for _, member := range members {
+		if member.Name != "" {
+			candidates = append(candidates, candidate{member.Name, member})
+		}
+		if local, _, ok := strings.Cut(member.Email, "@"); ok && local != "" {
+			candidates = append(candidates, candidate{local, member})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return len(candidates[i].handle) > len(candidates[j].handle)
+	})
+
+	var b strings.Builder
+	for len(text) > 0 {
+		at := strings.IndexByte(text, '@')
+		if at < 0 {
+			break
+		}
+
+		// An @ inside a word, as in an email address, isn't a mention
+		if at > 0 {
+			if r, _ := utf8.DecodeLastRuneInString(text[:at]); unicode.IsLetter(r) || unicode.IsDigit(r) {
+				b.WriteString(html.EscapeString(text[:at+1]))
+				text = text[at+1:]
+				continue
+			}
+		}
+
+		b.WriteString(html.EscapeString(text[:at]))
+		rest := text[at+1:]
+		matched := false
+		for _, c := range candidates {
+			if len(rest) < len(c.handle) || !strings.EqualFold(rest[:len(c.handle)], c.handle) {
+				continue
+			}
+			// The handle must end at a word boundary
+			if r, _ := utf8.DecodeRuneInString(rest[len(c.handle):]); len(rest) > len(c.handle) && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				continue
+			}
+			b.WriteString(Mention(c.member))
+			text = rest[len(c.handle):]
+			matched = true
+			break
+		}
+		if !matched {
+			b.WriteString("@")
+			text = rest
+		}
+	}
+	b.WriteString(html.EscapeString(text))
+	return b.String()
+}
@@ -0,0 +1,38 @@
+package togglplanapi
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestFormatMentions(t *testing.T) {
+	members := []Member{
+		{Id: 1, Name: "Ada Lovelace", Email: "ada@example.com"},
+		{Id: 2, Name: "Ada", Email: "countess@example.com"},
+	}
+
+	body := FormatMentions("@ada lovelace & @countess: mail bob@ada.org, not @nobody", members)
+
+	expected := `<span class="mention" data-member-id="1">@Ada Lovelace</span> &amp; ` +
+		`<span class="mention" data-member-id="2">@Ada</span>: mail bob@ada.org, not @nobody`
+	if body != expected {
+		t.Fatalf("unexpected body:\n%s\nexpected:\n%s", body, expected)
+	}
+
+	if ids := (Comment{Body: body + body}).Mentions(); !reflect.DeepEqual(ids, []ID{1, 2}) {
+		t.Fatalf("unexpected mentions %v", ids)
+	}
+}
+
+func TestReplaceMentions(t *testing.T) {
+	body := `Ping <span class="mention" data-member-id="7">@Bob &amp; Co</span>!`
+
+	text := ReplaceMentions(body, func(memberId ID, text string) string {
+		return "<@" + strconv.Itoa(int(memberId)) + "|" + text + ">"
+	})
+
+	if text != "Ping <@7|@Bob & Co>!" {
+		t.Fatalf("unexpected text %q", text)
+	}
+}
@@ -0,0 +1,262 @@
+package togglplanapi
+
+import (
+	"bufio"
+	"expvar"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointStats counts the requests sent to an endpoint of the API.
+type EndpointStats struct {
+	Method string
+	// Endpoint is the path of the endpoint with its IDs replaced, e.g.
+	// /v5/{workspace}/tasks/{id}, so that requests to all tasks add up.
+	// Requests to other hosts, such as attachment downloads, are counted
+	// under "other".
+	Endpoint string
+
+	// Requests counts the requests, whatever their outcome, and Errors
+	// those that failed with an error status or without a response.
+	// Retries counts the attempts beyond the first.
+	Requests int
+	Errors   int
+	Retries  int
+	// Duration adds up the time taken by the requests, retries included.
+	Duration time.Duration
+}
+
+// ClientStats counts the requests sent by a client since it was created.
+type ClientStats struct {
+	Requests int
+	Errors   int
+	Retries  int
+
+	// Endpoints splits the counts by endpoint, sorted by endpoint and
+	// method.
+	Endpoints []EndpointStats
+
+	// CacheHits counts the typed GET calls answered from the cache without
+	// a request, CacheRevalidated those answered from the cache after the
+	// API confirmed it was current, and CacheMisses those that fetched a new
+	// response. They stay 0 without a cache (see WithCache).
+	CacheHits        int
+	CacheRevalidated int
+	CacheMisses      int
+
+	// TokenAge is the time since the client fetched its bearer token, or 0
+	// if it was given one.
+	TokenAge time.Duration
+}
+
+// CacheHitRate returns the share of cached calls answered from the cache,
+// with or without revalidation, or 0 if there were none.
+func (stats ClientStats) CacheHitRate() float64 {
+	total := stats.CacheHits + stats.CacheRevalidated + stats.CacheMisses
+	if total == 0 {
+		return 0
+	}
+	return float64(stats.CacheHits+stats.CacheRevalidated) / float64(total)
+}
+
+// clientMetrics counts the requests of a client by endpoint, and the calls
+// going through its cache.
+type clientMetrics struct {
+	mu        sync.Mutex
+	endpoints map[[2]string]*EndpointStats
+
+	cacheHits        int
+	cacheRevalidated int
+	cacheMisses      int
+}
+
+// Outcomes of a call going through the cache.
+const (
+	cacheHit = iota
+	cacheRevalidated
+	cacheMiss
+)
+
+// recordCache counts a call going through the cache.
+func (m *clientMetrics) recordCache(outcome int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch outcome {
+	case cacheHit:
+		m.cacheHits++
+	case cacheRevalidated:
+		m.cacheRevalidated++
+	case cacheMiss:
+		m.cacheMisses++
+	}
+}
+
+// record counts a request to u that took attempts and elapsed.
+func (m *clientMetrics) record(pa *togglPlanApi, method string, u *url.URL, attempts int, elapsed time.Duration, failed bool) {
+	endpoint := endpointTemplate(pa, u)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.endpoints == nil {
+		m.endpoints = map[[2]string]*EndpointStats{}
+	}
+	stats, ok := m.endpoints[[2]string{endpoint, method}]
+	if !ok {
+		stats = &EndpointStats{Method: method, Endpoint: endpoint}
+		m.endpoints[[2]string{endpoint, method}] = stats
+	}
+	stats.Requests++
+	if failed {
+		stats.Errors++
+	}
+	if attempts > 1 {
+		stats.Retries += attempts - 1
+	}
+	stats.Duration += elapsed
+}
+
+// endpointTemplate returns the path of u relative to /api with its IDs
+// replaced: the workspace by {workspace} and other IDs by {id}. URLs outside
+// the API return "other", so that the number of endpoints stays bounded.
+func endpointTemplate(pa *togglPlanApi, u *url.URL) string {
+	base, err := url.Parse(pa.baseURL)
+	if err != nil || u.Host != base.Host || !strings.HasPrefix(u.Path, base.Path+"/api/") {
+		return "other"
+	}
+
+	segments := strings.Split(strings.TrimPrefix(u.Path, base.Path+"/api"), "/")
+	for i, segment := range segments {
+		if !isNumeric(segment) {
+			continue
+		}
+		// segments[0] is empty and segments[1] is the version
+		if i == 2 {
+			segments[i] = "{workspace}"
+		} else {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isNumeric reports whether s is made of digits only.
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Stats returns the counts of the requests sent by pa, for dashboards and
+// alerts. See WritePrometheus to export them.
+func Stats(pa *togglPlanApi) ClientStats {
+	pa.metrics.mu.Lock()
+	defer pa.metrics.mu.Unlock()
+
+	stats := ClientStats{
+		CacheHits:        pa.metrics.cacheHits,
+		CacheRevalidated: pa.metrics.cacheRevalidated,
+		CacheMisses:      pa.metrics.cacheMisses,
+	}
+	if _, tokenAt := currentToken(pa); !tokenAt.IsZero() {
+		stats.TokenAge = time.Since(tokenAt)
+	}
+	for _, endpoint := range pa.metrics.endpoints {
+		stats.Requests += endpoint.Requests
+		stats.Errors += endpoint.Errors
+		stats.Retries += endpoint.Retries
+		stats.Endpoints = append(stats.Endpoints, *endpoint)
+	}
+	sort.Slice(stats.Endpoints, func(i, j int) bool {
+		a, b := stats.Endpoints[i], stats.Endpoints[j]
+		if a.Endpoint != b.Endpoint {
+			return a.Endpoint < b.Endpoint
+		}
+		return a.Method < b.Method
+	})
+	return stats
+}
+
+// WritePrometheus writes the request counts of pa to w in the text format of
+// Prometheus, labeled by method and endpoint, e.g. to serve them from a
+// /metrics handler next to those of the application:
+//
+//	togglplanapi_requests_total{method="GET",endpoint="/v5/{workspace}/tasks"} 12
+func WritePrometheus(w io.Writer, pa *togglPlanApi) error {
+	stats := Stats(pa)
+	b := bufio.NewWriter(w)
+
+	metrics := []struct {
+		name  string
+		kind  string
+		help  string
+		value func(EndpointStats) string
+	}{
+		{"togglplanapi_requests_total", "counter", "Requests sent to the Toggl Plan API.",
+			func(e EndpointStats) string { return fmt.Sprint(e.Requests) }},
+		{"togglplanapi_request_errors_total", "counter", "Requests that failed with an error status or without a response.",
+			func(e EndpointStats) string { return fmt.Sprint(e.Errors) }},
+		{"togglplanapi_request_retries_total", "counter", "Attempts beyond the first of each request.",
+			func(e EndpointStats) string { return fmt.Sprint(e.Retries) }},
+		{"togglplanapi_request_duration_seconds_total", "counter", "Time taken by requests, retries included.",
+			func(e EndpointStats) string { return fmt.Sprint(e.Duration.Seconds()) }},
+	}
+
+	for _, metric := range metrics {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+		for _, endpoint := range stats.Endpoints {
+			fmt.Fprintf(b, "%s{method=%s,endpoint=%s} %s\n", metric.name,
+				prometheusLabel(endpoint.Method), prometheusLabel(endpoint.Endpoint), metric.value(endpoint))
+		}
+	}
+	return b.Flush()
+}
+
+// PublishExpvar publishes the Stats of pa under name with the expvar
+// package, so that they show in /debug/vars with the other variables of the
+// process. Like expvar.Publish, it panics if name is already in use.
+//
+// Durations are published in seconds, and endpoints keyed by method and
+// endpoint, e.g. "GET /v5/{workspace}/tasks".
+func PublishExpvar(name string, pa *togglPlanApi) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		stats := Stats(pa)
+		endpoints := map[string]interface{}{}
+		for _, endpoint := range stats.Endpoints {
+			endpoints[endpoint.Method+" "+endpoint.Endpoint] = map[string]interface{}{
+				"requests":         endpoint.Requests,
+				"errors":           endpoint.Errors,
+				"retries":          endpoint.Retries,
+				"duration_seconds": endpoint.Duration.Seconds(),
+			}
+		}
+		return map[string]interface{}{
+			"requests":          stats.Requests,
+			"errors":            stats.Errors,
+			"retries":           stats.Retries,
+			"endpoints":         endpoints,
+			"cache_hits":        stats.CacheHits,
+			"cache_revalidated": stats.CacheRevalidated,
+			"cache_misses":      stats.CacheMisses,
+			"cache_hit_rate":    stats.CacheHitRate(),
+			"token_age_seconds": stats.TokenAge.Seconds(),
+		}
+	}))
+}
+
+// prometheusLabel quotes a label value for the Prometheus text format.
+func prometheusLabel(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
@@ -0,0 +1,100 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEndpointTemplate(t *testing.T) {
+	pa := New(username, password, clientId, clientSecret, "token")
+
+	cases := map[string]string{
+		"https://api.plan.toggl.com/api/v5/me":                        "/v5/me",
+		"https://api.plan.toggl.com/api/v5/42/tasks?since=2024-01-01": "/v5/{workspace}/tasks",
+		"https://api.plan.toggl.com/api/v5/42/tasks/7/comments/9":     "/v5/{workspace}/tasks/{id}/comments/{id}",
+		"https://files.example/attachments/123/report.pdf":            "other",
+	}
+	for raw, want := range cases {
+		u, _ := url.Parse(raw)
+		if got := endpointTemplate(pa, u); got != want {
+			t.Errorf("endpointTemplate(%s) = %s, expected %s", raw, got, want)
+		}
+	}
+}
+
+func TestStatsByEndpoint(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v5/1/tasks/404" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"id":1}`)
+	})
+
+	ctx := context.Background()
+	for _, taskId := range []ID{1, 2, 404} {
+		GetTask(ctx, pa, 1, taskId)
+	}
+	GetProjects(ctx, pa, 1)
+
+	stats := Stats(pa)
+	if stats.Requests != 4 || stats.Errors != 1 || len(stats.Endpoints) != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	tasks := stats.Endpoints[1]
+	if tasks.Endpoint != "/v5/{workspace}/tasks/{id}" || tasks.Method != "GET" || tasks.Requests != 3 || tasks.Errors != 1 {
+		t.Errorf("unexpected task stats %+v", tasks)
+	}
+
+	var b strings.Builder
+	if err := WritePrometheus(&b, pa); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"# TYPE togglplanapi_requests_total counter",
+		`togglplanapi_requests_total{method="GET",endpoint="/v5/{workspace}/tasks/{id}"} 3`,
+		`togglplanapi_request_errors_total{method="GET",endpoint="/v5/{workspace}/projects"} 0`,
+	} {
+		if !strings.Contains(b.String(), line+"\n") {
+			t.Errorf("missing %q in:\n%s", line, b.String())
+		}
+	}
+}
+
+func TestPublishExpvar(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[]`)
+	})
+	WithCache(NewMemoryCache(), CacheOptions{TTL: time.Minute})(pa)
+
+	ctx := context.Background()
+	GetProjects(ctx, pa, 1)
+	GetProjects(ctx, pa, 1)
+	GetProjects(ctx, pa, 1)
+
+	PublishExpvar("togglplanapi_test", pa)
+
+	var vars struct {
+		Requests     int                       `json:"requests"`
+		CacheHits    int                       `json:"cache_hits"`
+		CacheMisses  int                       `json:"cache_misses"`
+		CacheHitRate float64                   `json:"cache_hit_rate"`
+		Endpoints    map[string]map[string]any `json:"endpoints"`
+	}
+	if err := json.Unmarshal([]byte(expvar.Get("togglplanapi_test").String()), &vars); err != nil {
+		t.Fatal(err)
+	}
+	if vars.Requests != 1 || vars.CacheHits != 2 || vars.CacheMisses != 1 || vars.CacheHitRate < 0.66 || vars.CacheHitRate > 0.67 {
+		t.Errorf("unexpected vars %+v", vars)
+	}
+	if vars.Endpoints["GET /v5/{workspace}/projects"]["requests"] != 1.0 {
+		t.Errorf("unexpected endpoints %v", vars.Endpoints)
+	}
+}
@@ -0,0 +1,78 @@
+package togglplanapi
+
+import (
+	"context"
+)
+
+// Milestone represents a milestone on a project's timeline.
+type Milestone struct {
+	Id        ID       `json:"id"`
+	Name      string   `json:"name"`
+	Date      Date     `json:"date"`
+	ProjectId ID       `json:"project_id"`
+	CreatedAt DateTime `json:"created_at"`
+	UpdatedAt DateTime `json:"updated_at"`
+}
+
+// MilestoneParams holds the fields of a milestone to create.
+type MilestoneParams struct {
+	Name      string `json:"name"`
+	Date      Date   `json:"date"`
+	ProjectId ID     `json:"project_id,omitempty"`
+}
+
+// MilestoneUpdate holds the fields of a milestone to change.
+// Fields left nil or unset are not sent, and keep their current value.
+type MilestoneUpdate struct {
+	Name      *string      `json:"name,omitempty"`
+	Date      *Date        `json:"date,omitempty"`
+	ProjectId Optional[ID] `json:"project_id"`
+}
+
+// MarshalJSON encodes the fields set in the update.
+func (update MilestoneUpdate) MarshalJSON() ([]byte, error) {
+	return marshalUpdate(update)
+}
+
+// GetMilestones fetches all milestones of a workspace.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+func GetMilestones(ctx context.Context, pa *togglPlanApi, workspaceId ID) ([]Milestone, error) {
+	var milestones []Milestone
+	err := getJSON(ctx, pa, workspacePath(workspaceId, "/milestones"), nil, &milestones)
+	return milestones, err
+}
+
+// CreateMilestone creates a milestone in a workspace and returns it.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	params: Fields of the new milestone
+func CreateMilestone(ctx context.Context, pa *togglPlanApi, workspaceId ID, params MilestoneParams) (*Milestone, error) {
+	var milestone Milestone
+	if err := sendJSON(ctx, pa, "POST", workspacePath(workspaceId, "/milestones"), nil, params, &milestone); err != nil {
+		return nil, err
+	}
+	return &milestone, nil
+}
+
+// UpdateMilestone changes the fields of a milestone set in update, and returns the updated milestone.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	milestoneId: ID of the milestone
+//	update: Fields to change
+func UpdateMilestone(ctx context.Context, pa *togglPlanApi, workspaceId ID, milestoneId ID, update MilestoneUpdate) (*Milestone, error) {
+	var milestone Milestone
+	if err := sendJSON(ctx, pa, "PUT", workspacePath(workspaceId, "/milestones/%d", milestoneId), nil, update, &milestone); err != nil {
+		return nil, err
+	}
+	return &milestone, nil
+}
@@ -0,0 +1,151 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MyWeekOptions configures MyWeek.
+type MyWeekOptions struct {
+	// SundayFirst starts weeks on Sunday instead of Monday.
+	SundayFirst bool
+
+	// Location is the time zone of the user, which decides what "this week"
+	// is. Defaults to time.Local.
+	Location *time.Location
+}
+
+// AgendaDay is a day of an Agenda.
+type AgendaDay struct {
+	Date Date
+	// Tasks are the tasks scheduled on the day. A task spanning several
+	// days is listed on each of them.
+	Tasks []Task
+	// Milestones are the milestones on the day of the projects of the
+	// member's tasks of the week.
+	Milestones []Milestone
+	// TimeOff is the time off of the member covering the day, if any.
+	TimeOff *TimeOff
+}
+
+// Agenda is the week of a workspace member, one entry per day.
+type Agenda struct {
+	MemberId ID
+	Days     []AgendaDay
+}
+
+// MyWeek returns the agenda of the user the client is authenticated as, for
+// the week weekOffset weeks away from the current one: 0 is this week, -1
+// the previous one and 1 the next one. It is the building block for digests,
+// command line tools and widgets.
+//
+// It fetches the user's membership first, and then their tasks, the
+// milestones and their time off at the same time.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	weekOffset: Number of weeks from the current one
+//	options: Week settings (use `togglplanapi.MyWeekOptions{}` for the defaults)
+func MyWeek(ctx context.Context, pa *togglPlanApi, workspaceId ID, weekOffset int, options MyWeekOptions) (*Agenda, error) {
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+	weekStart := time.Monday
+	if options.SundayFirst {
+		weekStart = time.Sunday
+	}
+
+	today := Today(options.Location)
+	start := StartOfWeek(today, weekStart).AddDays(7 * weekOffset)
+	end := start.AddDays(6)
+
+	var me *Me
+	var members []Member
+	err := errors.Join(RunBatch(ctx, 2, []func(ctx context.Context) error{
+		func(ctx context.Context) (err error) {
+			me, err = GetMe(ctx, pa)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			members, err = GetMembers(ctx, pa, workspaceId)
+			return err
+		},
+	})...)
+	if err != nil {
+		return nil, err
+	}
+
+	var memberId ID
+	for _, member := range members {
+		if member.UserId == me.Id {
+			memberId = member.Id
+		}
+	}
+	if memberId == 0 {
+		return nil, fmt.Errorf("user %d is not a member of workspace %d", me.Id, workspaceId)
+	}
+
+	var tasks []Task
+	var milestones []Milestone
+	var timeOffs []TimeOff
+	err = errors.Join(RunBatch(ctx, 3, []func(ctx context.Context) error{
+		func(ctx context.Context) (err error) {
+			tasks, err = ListAllTasks(ctx, pa, workspaceId, TaskFilter{Since: start, Until: end, MemberIds: []ID{memberId}})
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			milestones, err = GetMilestones(ctx, pa, workspaceId)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			timeOffs, err = GetTimeOffs(ctx, pa, workspaceId, start, end)
+			return err
+		},
+	})...)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildAgenda(memberId, start, tasks, milestones, timeOffs), nil
+}
+
+// buildAgenda sorts the tasks, milestones and time off of a member into the
+// days of the week starting on start.
+func buildAgenda(memberId ID, start Date, tasks []Task, milestones []Milestone, timeOffs []TimeOff) *Agenda {
+	agenda := &Agenda{MemberId: memberId, Days: make([]AgendaDay, 7)}
+	end := start.AddDays(6)
+
+	projects := map[ID]bool{}
+	for i := range agenda.Days {
+		day := &agenda.Days[i]
+		day.Date = start.AddDays(i)
+
+		for _, task := range tasks {
+			if contains(task.Assignees, memberId) && day.Date.Between(task.StartDate, task.EndDate) {
+				day.Tasks = append(day.Tasks, task)
+				if task.ProjectId != 0 {
+					projects[task.ProjectId] = true
+				}
+			}
+		}
+		for _, timeOff := range timeOffs {
+			if timeOff.MemberId == memberId && timeOff.Covers(day.Date) {
+				timeOff := timeOff
+				day.TimeOff = &timeOff
+			}
+		}
+	}
+
+	for _, milestone := range milestones {
+		if projects[milestone.ProjectId] && milestone.Date.Between(start, end) {
+			day := &agenda.Days[start.DaysUntil(milestone.Date)]
+			day.Milestones = append(day.Milestones, milestone)
+		}
+	}
+
+	return agenda
+}
@@ -0,0 +1,47 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMyWeek(t *testing.T) {
+	today := Today(time.UTC)
+	monday := today.AddDays(-(int(today.Weekday()) + 6) % 7).AddDays(7)
+
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v5/me":
+			fmt.Fprint(w, `{"id":5}`)
+		case "/api/v5/1/members":
+			fmt.Fprint(w, `[{"id":50,"user_id":4},{"id":51,"user_id":5}]`)
+		case "/api/v5/1/tasks":
+			if r.URL.Query().Get("since") != monday.String() {
+				t.Errorf("unexpected range %s", r.URL.RawQuery)
+			}
+			fmt.Fprintf(w, `[{"id":10,"project_id":3,"workspace_members":[51],"start_date":%q,"end_date":%q}]`, monday, monday.AddDays(1))
+		case "/api/v5/1/milestones":
+			fmt.Fprintf(w, `[{"id":20,"project_id":3,"date":%q},{"id":21,"project_id":4,"date":%q}]`, monday.AddDays(4), monday)
+		case "/api/v5/1/time_offs":
+			fmt.Fprintf(w, `[{"id":30,"workspace_member_id":51,"start_date":%q,"end_date":%q}]`, monday.AddDays(3), monday.AddDays(9))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	agenda, err := MyWeek(context.Background(), pa, 1, 1, MyWeekOptions{Location: time.UTC})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary := ""
+	for _, day := range agenda.Days {
+		summary += fmt.Sprintf("%d%d%v ", len(day.Tasks), len(day.Milestones), day.TimeOff != nil)
+	}
+	if agenda.MemberId != 51 || agenda.Days[0].Date != monday || summary != "10false 10false 00false 00true 01true 00true 00true " {
+		t.Fatalf("unexpected agenda for member %d from %s: %s", agenda.MemberId, agenda.Days[0].Date, summary)
+	}
+}
@@ -0,0 +1,71 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+)
+
+// WithNotFoundAsNil makes lookup calls, which fetch a single resource by ID
+// such as GetTask, return (nil, nil) rather than an error matching
+// ErrNotFound when the resource doesn't exist. In sync loops, where a
+// deleted resource is an expected outcome, this saves checking the error,
+// and the 404 isn't counted as an error in Stats. It is off by default.
+// NotFoundAsNil overrides it for a single call.
+func WithNotFoundAsNil(enabled bool) Option {
+	return func(pa *togglPlanApi) {
+		pa.notFoundAsNil = enabled
+	}
+}
+
+// notFoundKey is the context key of the setting of NotFoundAsNil.
+type notFoundKey struct{}
+
+// expectedNotFoundKey is the context key marking the requests of a lookup
+// whose 404 response is returned as (nil, nil).
+type expectedNotFoundKey struct{}
+
+// NotFoundAsNil returns a context that makes lookup calls return (nil, nil)
+// on 404 Not Found if enabled, or an error matching ErrNotFound otherwise,
+// whatever the client was set to with WithNotFoundAsNil:
+//
+//	task, err := togglplanapi.GetTask(togglplanapi.NotFoundAsNil(ctx, true), pa, workspaceId, taskId)
+//	if err == nil && task == nil {
+//	    // The task was deleted
+//	}
+func NotFoundAsNil(ctx context.Context, enabled bool) context.Context {
+	return context.WithValue(ctx, notFoundKey{}, enabled)
+}
+
+// notFoundAsNil reports whether a lookup made with ctx returns (nil, nil) on
+// 404 Not Found.
+func notFoundAsNil(ctx context.Context, pa *togglPlanApi) bool {
+	if enabled, ok := ctx.Value(notFoundKey{}).(bool); ok {
+		return enabled
+	}
+	return pa.notFoundAsNil
+}
+
+// expectedNotFound reports whether a 404 response to a request made with ctx
+// is an expected outcome rather than an error.
+func expectedNotFound(ctx context.Context) bool {
+	return ctx.Value(expectedNotFoundKey{}) != nil
+}
+
+// getOne fetches the resource at path into a new T, following the setting
+// of NotFoundAsNil.
+func getOne[T any](ctx context.Context, pa *togglPlanApi, path string) (*T, error) {
+	nilOnNotFound := notFoundAsNil(ctx, pa)
+	if nilOnNotFound {
+		ctx = context.WithValue(ctx, expectedNotFoundKey{}, true)
+	}
+
+	var result T
+	err := getJSON(ctx, pa, path, nil, &result)
+	if nilOnNotFound && errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,49 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestNotFoundAsNil(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v5/1/tasks/1" {
+			fmt.Fprint(w, `{"id":1}`)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	ctx := context.Background()
+	if _, err := GetTask(ctx, pa, 1, 2); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound by default, got %v", err)
+	}
+
+	WithNotFoundAsNil(true)(pa)
+	task, err := GetTask(ctx, pa, 1, 2)
+	if task != nil || err != nil {
+		t.Fatalf("expected (nil, nil), got %+v, %v", task, err)
+	}
+	if task, err := GetTask(ctx, pa, 1, 1); err != nil || task.Id != 1 {
+		t.Fatalf("unexpected result %+v, %v", task, err)
+	}
+	if project, err := GetProject(ctx, pa, 1, 2); project != nil || err != nil {
+		t.Fatalf("expected (nil, nil), got %+v, %v", project, err)
+	}
+
+	// A call can still ask for the error, and calls relying on it do
+	if _, err := GetTask(NotFoundAsNil(ctx, false), pa, 1, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for the call, got %v", err)
+	}
+	if _, errs := GetManyTasks(ctx, pa, 1, []ID{2}, 1); !errors.Is(errs[2], ErrNotFound) {
+		t.Errorf("expected ErrNotFound from GetManyTasks, got %v", errs)
+	}
+
+	// Only the 404s of the first GetTask and the last two calls are errors
+	if stats := Stats(pa); stats.Requests != 6 || stats.Errors != 3 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,128 @@
+package offline
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"togglplanapi"
+)
+
+// Op is the kind of write recorded by an Entry.
+type Op string
+
+const (
+	OpCreateTask      Op = "create_task"
+	OpUpdateTask      Op = "update_task"
+	OpDeleteTask      Op = "delete_task"
+	OpCreateMilestone Op = "create_milestone"
+	OpUpdateMilestone Op = "update_milestone"
+	OpCreateProject   Op = "create_project"
+	OpUpdateProject   Op = "update_project"
+)
+
+// Entry is a write waiting in the journal to be sent.
+type Entry struct {
+	Seq         int64           `json:"seq"`
+	Op          Op              `json:"op"`
+	WorkspaceId togglplanapi.ID `json:"workspace_id"`
+	// Id is the ID of the updated or deleted resource, or 0 for creations.
+	Id togglplanapi.ID `json:"id,omitempty"`
+	// Expected is the UpdatedAt of the task an update is based on, if any.
+	// When set, the update is only applied if the task is still at that version.
+	Expected togglplanapi.DateTime `json:"expected"`
+	// Payload holds the params or update of the write, as sent to the API.
+	Payload  json.RawMessage `json:"payload,omitempty"`
+	QueuedAt time.Time       `json:"queued_at"`
+}
+
+// Journal keeps queued writes in a file, one JSON entry per line, so that
+// they survive the process exiting before connectivity returns.
+// A missing file is an empty journal.
+type Journal struct {
+	Path string
+}
+
+// Entries reads the queued writes, in the order they were made.
+func (j Journal) Entries() ([]Entry, error) {
+	file, err := os.Open(j.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 16<<20)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, scanner.Err()
+}
+
+// Append adds an entry at the end of the journal, and syncs it to disk
+// before returning.
+func (j Journal) Append(entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(j.Path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Replace rewrites the journal with entries, atomically so that a crash
+// never leaves a truncated journal behind.
+func (j Journal) Replace(entries []Entry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(j.Path), filepath.Base(j.Path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), j.Path)
+}
@@ -0,0 +1,297 @@
+/*
+Package offline queues writes to Toggl Plan while the API can't be reached,
+and replays them in order once it can.
+
+Writes go through a Queue instead of the togglplanapi functions. While the
+API is reachable they are sent right away. When a write fails because the
+network is down or the API is unavailable, it is recorded in a journal file
+instead, and the write returns ErrQueued. From then on, writes are queued
+behind it until Flush sends them, so that they are applied in the order they
+were made:
+
+	queue := offline.New(pa, "plan-journal.jsonl")
+
+	_, err := queue.UpdateTask(ctx, workspaceId, taskId, update)
+	if errors.Is(err, offline.ErrQueued) {
+		fmt.Println("offline, the change will be sent later")
+	}
+
+	// Later, for example on the next run of the CLI
+	result, err := queue.Flush(ctx)
+
+Updates made with UpdateTaskIfUnchanged keep the version of the task they are
+based on, and are reported as conflicts by Flush if the task was changed in
+the meantime, rather than overwriting the other change.
+
+The journal stores writes as JSON, so dates cleared by setting them to the
+zero Date in a TaskUpdate are not kept, and are left unchanged on replay.
+*/
+package offline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"togglplanapi"
+)
+
+// ErrQueued is returned by writes that were recorded in the journal instead
+// of being sent. Their results are not available until the queue is flushed.
+var ErrQueued = errors.New("offline: write queued")
+
+// Queue sends writes to Toggl Plan, or journals them while it can't be reached.
+// It is safe for concurrent use within a process, but the journal should not
+// be shared by several processes at once.
+type Queue struct {
+	pa      *togglplanapi.Client
+	journal Journal
+	mu      sync.Mutex
+
+	now func() time.Time
+	// apply sends an entry to the API; it is replaced in tests
+	apply func(ctx context.Context, entry Entry) (*togglplanapi.Task, error)
+}
+
+// New returns a queue sending writes with pa, and keeping the journal in the
+// file at path.
+func New(pa *togglplanapi.Client, path string) *Queue {
+	q := &Queue{pa: pa, journal: Journal{Path: path}, now: time.Now}
+	q.apply = q.send
+	return q
+}
+
+// Rejection is a queued write that the API refused when it was replayed.
+type Rejection struct {
+	Entry Entry
+	Err   error
+	// Current is the task as it is now, for conflicting task updates.
+	Current *togglplanapi.Task
+}
+
+// FlushResult summarizes a Flush.
+type FlushResult struct {
+	Applied int
+	// Conflicts are updates of tasks that were changed since the update was made.
+	Conflicts []Rejection
+	// Failed are writes refused for any other reason, such as a deleted task.
+	Failed []Rejection
+	// Pending is the number of writes still queued, if the API became
+	// unreachable again during the flush.
+	Pending int
+}
+
+// CreateTask creates a task, or queues its creation.
+func (q *Queue) CreateTask(ctx context.Context, workspaceId togglplanapi.ID, params togglplanapi.TaskParams) (*togglplanapi.Task, error) {
+	return q.write(ctx, Entry{Op: OpCreateTask, WorkspaceId: workspaceId}, params)
+}
+
+// UpdateTask changes the fields of a task set in update, or queues the change.
+func (q *Queue) UpdateTask(ctx context.Context, workspaceId togglplanapi.ID, taskId togglplanapi.ID, update togglplanapi.TaskUpdate) (*togglplanapi.Task, error) {
+	return q.write(ctx, Entry{Op: OpUpdateTask, WorkspaceId: workspaceId, Id: taskId}, update)
+}
+
+// UpdateTaskIfUnchanged works like togglplanapi.UpdateTaskIfUnchanged, or
+// queues the change. A queued change is reported as a conflict by Flush if
+// the task no longer has the expected UpdatedAt.
+func (q *Queue) UpdateTaskIfUnchanged(ctx context.Context, workspaceId togglplanapi.ID, taskId togglplanapi.ID, expected togglplanapi.DateTime, update togglplanapi.TaskUpdate) (*togglplanapi.Task, error) {
+	return q.write(ctx, Entry{Op: OpUpdateTask, WorkspaceId: workspaceId, Id: taskId, Expected: expected}, update)
+}
+
+// DeleteTask deletes a task, or queues its deletion.
+func (q *Queue) DeleteTask(ctx context.Context, workspaceId togglplanapi.ID, taskId togglplanapi.ID) error {
+	_, err := q.write(ctx, Entry{Op: OpDeleteTask, WorkspaceId: workspaceId, Id: taskId}, nil)
+	return err
+}
+
+// CreateMilestone creates a milestone, or queues its creation.
+func (q *Queue) CreateMilestone(ctx context.Context, workspaceId togglplanapi.ID, params togglplanapi.MilestoneParams) error {
+	_, err := q.write(ctx, Entry{Op: OpCreateMilestone, WorkspaceId: workspaceId}, params)
+	return err
+}
+
+// UpdateMilestone changes the fields of a milestone set in update, or queues the change.
+func (q *Queue) UpdateMilestone(ctx context.Context, workspaceId togglplanapi.ID, milestoneId togglplanapi.ID, update togglplanapi.MilestoneUpdate) error {
+	_, err := q.write(ctx, Entry{Op: OpUpdateMilestone, WorkspaceId: workspaceId, Id: milestoneId}, update)
+	return err
+}
+
+// CreateProject creates a project, or queues its creation.
+func (q *Queue) CreateProject(ctx context.Context, workspaceId togglplanapi.ID, params togglplanapi.ProjectParams) error {
+	_, err := q.write(ctx, Entry{Op: OpCreateProject, WorkspaceId: workspaceId}, params)
+	return err
+}
+
+// UpdateProject changes the fields of a project set in update, or queues the change.
+func (q *Queue) UpdateProject(ctx context.Context, workspaceId togglplanapi.ID, projectId togglplanapi.ID, update togglplanapi.ProjectUpdate) error {
+	_, err := q.write(ctx, Entry{Op: OpUpdateProject, WorkspaceId: workspaceId, Id: projectId}, update)
+	return err
+}
+
+// Pending returns the writes waiting in the journal, in order.
+func (q *Queue) Pending() ([]Entry, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.journal.Entries()
+}
+
+// Flush replays the queued writes in order. Writes refused by the API are
+// dropped from the journal and reported in the result. If the API can't be
+// reached, Flush stops and returns an error matching ErrQueued, keeping the
+// remaining writes for the next flush.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+func (q *Queue) Flush(ctx context.Context) (*FlushResult, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := q.journal.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FlushResult{}
+	for i, entry := range entries {
+		current, err := q.apply(ctx, entry)
+		switch {
+		case err == nil:
+			result.Applied++
+		case unreachable(err):
+			result.Pending = len(entries) - i
+			return result, fmt.Errorf("%w: %v", ErrQueued, err)
+		case ctx.Err() != nil:
+			result.Pending = len(entries) - i
+			return result, ctx.Err()
+		case errors.Is(err, togglplanapi.ErrConflict):
+			result.Conflicts = append(result.Conflicts, Rejection{Entry: entry, Err: err, Current: current})
+		default:
+			result.Failed = append(result.Failed, Rejection{Entry: entry, Err: err})
+		}
+
+		// Drop each write as soon as it's done, so that a crash doesn't replay it
+		if err := q.journal.Replace(entries[i+1:]); err != nil {
+			result.Pending = len(entries) - i - 1
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// write sends a write, or journals it if the API can't be reached or other
+// writes are already waiting.
+func (q *Queue) write(ctx context.Context, entry Entry, payload interface{}) (*togglplanapi.Task, error) {
+	if validator, ok := payload.(togglplanapi.Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		entry.Payload = data
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	pending, err := q.journal.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pending) == 0 {
+		task, err := q.apply(ctx, entry)
+		if err == nil || !unreachable(err) || ctx.Err() != nil {
+			return task, err
+		}
+	}
+
+	entry.QueuedAt = q.now()
+	entry.Seq = 1
+	if len(pending) > 0 {
+		entry.Seq = pending[len(pending)-1].Seq + 1
+	}
+	if err := q.journal.Append(entry); err != nil {
+		return nil, err
+	}
+	return nil, ErrQueued
+}
+
+// send applies an entry through the API. For task writes it returns the
+// task; for conflicting task updates, it returns the current task.
+func (q *Queue) send(ctx context.Context, entry Entry) (*togglplanapi.Task, error) {
+	switch entry.Op {
+	case OpCreateTask:
+		var params togglplanapi.TaskParams
+		if err := json.Unmarshal(entry.Payload, &params); err != nil {
+			return nil, err
+		}
+		return togglplanapi.CreateTask(ctx, q.pa, entry.WorkspaceId, params)
+	case OpUpdateTask:
+		var update togglplanapi.TaskUpdate
+		if err := json.Unmarshal(entry.Payload, &update); err != nil {
+			return nil, err
+		}
+		if !entry.Expected.IsZero() {
+			return togglplanapi.UpdateTaskIfUnchanged(ctx, q.pa, entry.WorkspaceId, entry.Id, entry.Expected, update)
+		}
+		return togglplanapi.UpdateTask(ctx, q.pa, entry.WorkspaceId, entry.Id, update)
+	case OpDeleteTask:
+		return nil, togglplanapi.DeleteTask(ctx, q.pa, entry.WorkspaceId, entry.Id)
+	case OpCreateMilestone:
+		var params togglplanapi.MilestoneParams
+		if err := json.Unmarshal(entry.Payload, &params); err != nil {
+			return nil, err
+		}
+		_, err := togglplanapi.CreateMilestone(ctx, q.pa, entry.WorkspaceId, params)
+		return nil, err
+	case OpUpdateMilestone:
+		var update togglplanapi.MilestoneUpdate
+		if err := json.Unmarshal(entry.Payload, &update); err != nil {
+			return nil, err
+		}
+		_, err := togglplanapi.UpdateMilestone(ctx, q.pa, entry.WorkspaceId, entry.Id, update)
+		return nil, err
+	case OpCreateProject:
+		var params togglplanapi.ProjectParams
+		if err := json.Unmarshal(entry.Payload, &params); err != nil {
+			return nil, err
+		}
+		_, err := togglplanapi.CreateProject(ctx, q.pa, entry.WorkspaceId, params)
+		return nil, err
+	case OpUpdateProject:
+		var update togglplanapi.ProjectUpdate
+		if err := json.Unmarshal(entry.Payload, &update); err != nil {
+			return nil, err
+		}
+		_, err := togglplanapi.UpdateProject(ctx, q.pa, entry.WorkspaceId, entry.Id, update)
+		return nil, err
+	default:
+		return nil, fmt.Errorf("unknown operation %q", entry.Op)
+	}
+}
+
+// unreachable reports whether err means that the API couldn't be reached,
+// or is temporarily unavailable, so that the write should be retried later.
+func unreachable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var apiErr *togglplanapi.APIError
+	return errors.As(err, &apiErr) && (apiErr.StatusCode >= 500 || apiErr.StatusCode == 429)
+}
@@ -0,0 +1,114 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"togglplanapi"
+)
+
+func TestQueueOfflineAndFlush(t *testing.T) {
+	queue := New(nil, filepath.Join(t.TempDir(), "journal.jsonl"))
+
+	online := false
+	var sent []Entry
+	queue.apply = func(ctx context.Context, entry Entry) (*togglplanapi.Task, error) {
+		if !online {
+			return nil, &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+		}
+		sent = append(sent, entry)
+		switch entry.Id {
+		case 8:
+			return &togglplanapi.Task{Id: 8, Name: "Theirs"}, &togglplanapi.ConflictError{Resource: "task 8"}
+		case 9:
+			return nil, &togglplanapi.APIError{StatusCode: 404}
+		}
+		return &togglplanapi.Task{Id: 1}, nil
+	}
+
+	ctx := context.Background()
+	if _, err := queue.CreateTask(ctx, 42, togglplanapi.TaskParams{Name: "Design"}); !errors.Is(err, ErrQueued) {
+		t.Fatalf("expected the write to be queued, got %v", err)
+	}
+
+	online = true
+
+	// Writes made while others are waiting are queued behind them
+	expected := togglplanapi.DateTime{Time: time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)}
+	if _, err := queue.UpdateTaskIfUnchanged(ctx, 42, 8, expected, togglplanapi.TaskUpdate{Done: togglplanapi.Bool(true)}); !errors.Is(err, ErrQueued) {
+		t.Fatalf("expected the write to be queued, got %v", err)
+	}
+	if err := queue.DeleteTask(ctx, 42, 9); !errors.Is(err, ErrQueued) {
+		t.Fatalf("expected the write to be queued, got %v", err)
+	}
+
+	pending, err := queue.Pending()
+	if err != nil || len(pending) != 3 || pending[2].Seq != 3 || !pending[1].Expected.Equal(expected.Time) {
+		t.Fatalf("unexpected journal %+v, %v", pending, err)
+	}
+
+	result, err := queue.Flush(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 3 || sent[0].Op != OpCreateTask || sent[1].Op != OpUpdateTask || sent[2].Op != OpDeleteTask {
+		t.Fatalf("unexpected replay order %+v", sent)
+	}
+	if result.Applied != 1 || len(result.Conflicts) != 1 || result.Conflicts[0].Current.Name != "Theirs" || len(result.Failed) != 1 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	if pending, _ := queue.Pending(); len(pending) != 0 {
+		t.Fatalf("expected an empty journal, got %+v", pending)
+	}
+
+	// With nothing queued, writes are sent right away
+	task, err := queue.CreateTask(ctx, 42, togglplanapi.TaskParams{Name: "Review"})
+	if err != nil || task.Id != 1 {
+		t.Fatalf("unexpected result %+v, %v", task, err)
+	}
+}
+
+func TestFlushStopsWhenOffline(t *testing.T) {
+	queue := New(nil, filepath.Join(t.TempDir(), "journal.jsonl"))
+	queue.apply = func(ctx context.Context, entry Entry) (*togglplanapi.Task, error) {
+		return nil, &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	}
+
+	ctx := context.Background()
+	queue.DeleteTask(ctx, 42, 1)
+	queue.DeleteTask(ctx, 42, 2)
+
+	queue.apply = func(ctx context.Context, entry Entry) (*togglplanapi.Task, error) {
+		if entry.Seq == 2 {
+			return nil, &togglplanapi.APIError{StatusCode: 503}
+		}
+		return nil, nil
+	}
+
+	result, err := queue.Flush(ctx)
+	if !errors.Is(err, ErrQueued) || result.Applied != 1 || result.Pending != 1 {
+		t.Fatalf("unexpected result %+v, %v", result, err)
+	}
+
+	pending, _ := queue.Pending()
+	if len(pending) != 1 || pending[0].Id != 2 {
+		t.Fatalf("expected the second write to stay queued, got %+v", pending)
+	}
+}
+
+func TestQueueRejectsInvalidWrites(t *testing.T) {
+	queue := New(nil, filepath.Join(t.TempDir(), "journal.jsonl"))
+
+	var invalid *togglplanapi.ValidationError
+	if _, err := queue.CreateTask(context.Background(), 42, togglplanapi.TaskParams{}); !errors.As(err, &invalid) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	if pending, _ := queue.Pending(); len(pending) != 0 {
+		t.Fatal("invalid write was queued")
+	}
+}
@@ -0,0 +1,149 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Default polling intervals of Operation.Wait.
+const (
+	defaultPollInterval    = 1 * time.Second
+	defaultMaxPollInterval = 30 * time.Second
+)
+
+// Operation is a request the API accepted with 202 Accepted, to complete it
+// in the background. The API points to the status of the operation with a
+// Location header.
+//
+// None of the documented Toggl Plan endpoints work this way today; Operation
+// is there for those that will, such as server-side imports and exports.
+type Operation struct {
+	// Location is the URL polled for the status of the operation.
+	Location string
+
+	// Interval is the time waited before the first poll. It doubles after
+	// each poll, up to MaxInterval, unless the API sends a Retry-After
+	// header. Default to 1 and 30 seconds.
+	Interval    time.Duration
+	MaxInterval time.Duration
+
+	pa     *togglPlanApi
+	done   bool
+	result string
+}
+
+// PostAsync works like Post, for endpoints that may complete the request in
+// the background. If the API answers with 202 Accepted, the returned
+// operation can be waited on; otherwise it is already done. An operation
+// whose Location is outside of the API fails with ErrForeignLocation.
+// A WithTimeout option limits the initial request, not the operation.
+func PostAsync(pa *togglPlanApi, url string, body []byte, opts ...RequestOption) (*Operation, error) {
+	resp, _, cancel, err := rawRequest(context.Background(), pa, url, http.MethodPost, body, opts)
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+
+	op := &Operation{pa: pa}
+	if resp.StatusCode != http.StatusAccepted {
+		op.done = true
+		op.result, err = readBody(resp)
+		return op, err
+	}
+	resp.Body.Close()
+
+	location, err := resp.Location()
+	if err != nil {
+		return nil, errors.New("accepted operation has no Location header")
+	}
+	if err := checkLocation(pa, location.String()); err != nil {
+		return nil, err
+	}
+	op.Location = location.String()
+	op.Interval = retryAfter(resp)
+	return op, nil
+}
+
+// Done reports whether the operation has completed.
+func (op *Operation) Done() bool {
+	return op.done
+}
+
+// Wait polls the status of the operation until it completes, and returns the
+// final response body. The status URL answers 202 Accepted while the
+// operation runs; any other successful response ends it. Redirects, such as
+// 303 See Other pointing to the result, are followed.
+//
+// Wait gives up with the error of ctx if it is cancelled, and with an
+// *APIError if the status URL answers with an error. A Location outside of
+// the API isn't polled, and fails with an error matching ErrForeignLocation.
+func (op *Operation) Wait(ctx context.Context) (string, error) {
+	if !op.done {
+		if err := checkLocation(op.pa, op.Location); err != nil {
+			return "", err
+		}
+	}
+
+	interval := op.Interval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	maxInterval := op.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = defaultMaxPollInterval
+	}
+
+	for !op.done {
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", ctx.Err()
+		case <-timer.C:
+		}
+
+		resp, _, err := authenticatedRequest(ctx, op.pa, op.Location, http.MethodGet, nil, map[string]string{})
+		if err != nil {
+			return "", err
+		}
+
+		if resp.StatusCode != http.StatusAccepted {
+			op.result, err = readBody(resp)
+			if err != nil {
+				return "", err
+			}
+			op.done = true
+			break
+		}
+		resp.Body.Close()
+
+		if wait := retryAfter(resp); wait > 0 {
+			interval = wait
+		} else if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+
+	return op.result, nil
+}
+
+// retryAfter returns the delay of the Retry-After header of resp, given in
+// seconds or as an HTTP date, or 0 if it has none.
+func retryAfter(resp *http.Response) time.Duration {
+	header := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOperationWait(t *testing.T) {
+	polls := 0
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v5/1/exports":
+			w.Header().Set("Location", "/api/v5/1/jobs/5")
+			w.WriteHeader(http.StatusAccepted)
+		case "/api/v5/1/jobs/5":
+			polls++
+			if polls < 3 {
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			http.Redirect(w, r, "/api/v5/1/files/5", http.StatusSeeOther)
+		case "/api/v5/1/files/5":
+			fmt.Fprint(w, `{"url":"https://example.com/export.zip"}`)
+		case "/api/v5/1/now":
+			fmt.Fprint(w, `{"ok":true}`)
+		}
+	})
+
+	op, err := PostAsync(pa, pa.baseURL+"/api/v5/1/exports", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Done() || op.Location != pa.baseURL+"/api/v5/1/jobs/5" {
+		t.Fatalf("unexpected operation %+v", op)
+	}
+
+	op.Interval = time.Millisecond
+	result, err := op.Wait(context.Background())
+	if err != nil || result != `{"url":"https://example.com/export.zip"}` || polls != 3 {
+		t.Fatalf("unexpected result %q, %v after %d polls", result, err, polls)
+	}
+
+	op, err = PostAsync(pa, pa.baseURL+"/api/v5/1/now", nil)
+	if err != nil || !op.Done() {
+		t.Fatalf("expected a synchronous answer to be done, got %+v, %v", op, err)
+	}
+	if result, _ := op.Wait(context.Background()); result != `{"ok":true}` {
+		t.Fatalf("unexpected result %q", result)
+	}
+}
+
+func TestOperationWaitCancelled(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/api/v5/1/jobs/5")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	op, err := PostAsync(pa, pa.baseURL+"/api/v5/1/exports", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	op.Interval = time.Millisecond
+	if _, err := op.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to stop polling, got %v", err)
+	}
+}
+
+func TestOperationForeignLocation(t *testing.T) {
+	foreignRequests := 0
+	foreign := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		foreignRequests++
+	}))
+	defer foreign.Close()
+
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", foreign.URL+"/api/v5/1/jobs/5")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	if _, err := PostAsync(pa, pa.baseURL+"/api/v5/1/exports", nil); !errors.Is(err, ErrForeignLocation) {
+		t.Fatalf("expected a foreign Location to be refused, got %v", err)
+	}
+
+	op := &Operation{Location: foreign.URL + "/api/v5/1/jobs/5", Interval: time.Millisecond, pa: pa}
+	if _, err := op.Wait(context.Background()); !errors.Is(err, ErrForeignLocation) {
+		t.Fatalf("expected a foreign Location not to be polled, got %v", err)
+	}
+	if foreignRequests != 0 {
+		t.Fatalf("expected no request to the foreign host, got %d", foreignRequests)
+	}
+}
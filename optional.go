@@ -0,0 +1,136 @@
+package togglplanapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// String returns a pointer to value, for filling in update fields.
+func String(value string) *string {
+	return &value
+}
+
+// Int returns a pointer to value, for filling in update fields.
+func Int(value int) *int {
+	return &value
+}
+
+// Int64 returns a pointer to value, for filling in update fields.
+func Int64(value int64) *int64 {
+	return &value
+}
+
+// Bool returns a pointer to value, for filling in update fields.
+func Bool(value bool) *bool {
+	return &value
+}
+
+// Optional is a field of an update that is either left unset, set to a
+// value, or set to null.
+//
+// Unset fields are left out of the request, so the server keeps their current
+// value. Null clears the field, for example to take a task out of its project.
+// The zero Optional is unset.
+type Optional[T any] struct {
+	value T
+	set   bool
+	null  bool
+}
+
+// Some returns an Optional set to value.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, set: true}
+}
+
+// Null returns an Optional set to null.
+func Null[T any]() Optional[T] {
+	return Optional[T]{set: true, null: true}
+}
+
+// OptionalId returns an Optional set to id, or to null if id is 0.
+// This suits references such as ProjectId, where 0 means none.
+func OptionalId(id ID) Optional[ID] {
+	if id == 0 {
+		return Null[ID]()
+	}
+	return Some(id)
+}
+
+// IsSet reports whether the Optional is set, to a value or to null.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// IsNull reports whether the Optional is set to null.
+func (o Optional[T]) IsNull() bool {
+	return o.null
+}
+
+// Get returns the value of the Optional, and whether it is set to a value.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set && !o.null
+}
+
+// Or returns the value of the Optional, or fallback if it isn't set to a value.
+func (o Optional[T]) Or(fallback T) T {
+	if value, ok := o.Get(); ok {
+		return value
+	}
+	return fallback
+}
+
+// MarshalJSON encodes the value of the Optional, or null.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if value, ok := o.Get(); ok {
+		return json.Marshal(value)
+	}
+	return []byte("null"), nil
+}
+
+// UnmarshalJSON sets the Optional to the decoded value, or to null.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*o = Null[T]()
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*o = Some(value)
+	return nil
+}
+
+// optional is implemented by every Optional, whatever its type.
+type optional interface {
+	IsSet() bool
+}
+
+// marshalUpdate encodes an update struct, leaving out nil pointers and unset
+// Optionals so that the server keeps the current value of those fields.
+func marshalUpdate(update any) ([]byte, error) {
+	payload := map[string]any{}
+
+	value := reflect.ValueOf(update)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil() {
+			continue
+		}
+		if opt, ok := fieldValue.Interface().(optional); ok && !opt.IsSet() {
+			continue
+		}
+
+		payload[name] = fieldValue.Interface()
+	}
+
+	return json.Marshal(payload)
+}
@@ -0,0 +1,50 @@
+package togglplanapi
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTaskUpdateMarshal(t *testing.T) {
+	tests := []struct {
+		update TaskUpdate
+		want   string
+	}{
+		{TaskUpdate{}, `{}`},
+		{TaskUpdate{Name: String("Design"), Done: Bool(false)}, `{"done":false,"name":"Design"}`},
+		{TaskUpdate{ProjectId: Some(ID(5)), MilestoneId: Null[ID]()}, `{"milestone_id":null,"project_id":5}`},
+		{TaskUpdate{StartDate: &Date{}, PlanStatusId: OptionalId(0)}, `{"plan_status_id":null,"start_date":null}`},
+	}
+
+	for _, test := range tests {
+		data, err := json.Marshal(test.update)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != test.want {
+			t.Errorf("got %s, want %s", data, test.want)
+		}
+	}
+}
+
+func TestOptionalUnmarshal(t *testing.T) {
+	var fields struct {
+		Set     Optional[int64] `json:"set"`
+		Null    Optional[int64] `json:"null"`
+		Missing Optional[int64] `json:"missing"`
+	}
+
+	if err := json.Unmarshal([]byte(`{"set":5,"null":null}`), &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	if value, ok := fields.Set.Get(); !ok || value != 5 {
+		t.Errorf("unexpected set field %+v", fields.Set)
+	}
+	if !fields.Null.IsSet() || !fields.Null.IsNull() || fields.Null.Or(7) != 7 {
+		t.Errorf("unexpected null field %+v", fields.Null)
+	}
+	if fields.Missing.IsSet() {
+		t.Errorf("unexpected missing field %+v", fields.Missing)
+	}
+}
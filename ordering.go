@@ -0,0 +1,163 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Tasks are ordered within a list, such as a column of the board or the
+// tasks of a member, by their Position: a number that only matters relative
+// to the positions of the other tasks of the list. Moving a task gives it a
+// position between those of its new neighbours, so that a single task is
+// updated. When there is no room left between the new neighbours, such as in
+// a list never ordered, the whole list is renumbered.
+
+// positionStep is the gap left between positions when a list is renumbered.
+const positionStep = 1024
+
+// minPositionGap is the smallest gap split between two positions before a
+// list is renumbered, well above the precision of float64.
+const minPositionGap = 1e-6
+
+// Reposition is the new position of a task, computed by MoveBefore,
+// MoveAfter, MoveToTop or MoveToBottom.
+type Reposition struct {
+	TaskId   ID
+	Position float64
+}
+
+// SortByPosition sorts tasks by position, keeping the order of tasks with
+// the same position.
+func SortByPosition(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Position < tasks[j].Position
+	})
+}
+
+// MoveBefore returns the positions to send so that a task comes right before
+// another in a list. tasks is the whole list, in any order, and must include
+// both tasks. Usually only the moved task changes; otherwise every task whose
+// position changes is returned.
+func MoveBefore(tasks []Task, taskId ID, beforeId ID) ([]Reposition, error) {
+	return move(tasks, taskId, func(others []Task) (int, error) {
+		for i, task := range others {
+			if task.Id == beforeId {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("task %d is not in the list", beforeId)
+	})
+}
+
+// MoveAfter returns the positions to send so that a task comes right after
+// another in a list, like MoveBefore.
+func MoveAfter(tasks []Task, taskId ID, afterId ID) ([]Reposition, error) {
+	return move(tasks, taskId, func(others []Task) (int, error) {
+		for i, task := range others {
+			if task.Id == afterId {
+				return i + 1, nil
+			}
+		}
+		return 0, fmt.Errorf("task %d is not in the list", afterId)
+	})
+}
+
+// MoveToTop returns the positions to send so that a task comes first in a
+// list, like MoveBefore.
+func MoveToTop(tasks []Task, taskId ID) ([]Reposition, error) {
+	return move(tasks, taskId, func(others []Task) (int, error) {
+		return 0, nil
+	})
+}
+
+// MoveToBottom returns the positions to send so that a task comes last in a
+// list, like MoveBefore.
+func MoveToBottom(tasks []Task, taskId ID) ([]Reposition, error) {
+	return move(tasks, taskId, func(others []Task) (int, error) {
+		return len(others), nil
+	})
+}
+
+// move moves a task to the index returned by target among the other tasks
+// of the list, sorted by position.
+func move(tasks []Task, taskId ID, target func(others []Task) (int, error)) ([]Reposition, error) {
+	sorted := append([]Task(nil), tasks...)
+	SortByPosition(sorted)
+
+	var moved *Task
+	others := make([]Task, 0, len(sorted))
+	for i, task := range sorted {
+		if task.Id == taskId {
+			moved = &sorted[i]
+			continue
+		}
+		others = append(others, task)
+	}
+	if moved == nil {
+		return nil, fmt.Errorf("task %d is not in the list", taskId)
+	}
+
+	index, err := target(others)
+	if err != nil {
+		return nil, err
+	}
+
+	var position float64
+	switch {
+	case len(others) == 0:
+		return []Reposition{{TaskId: taskId, Position: moved.Position}}, nil
+	case index == 0:
+		position = others[0].Position - positionStep
+	case index == len(others):
+		position = others[len(others)-1].Position + positionStep
+	default:
+		before, after := others[index-1].Position, others[index].Position
+		if after-before < minPositionGap {
+			return renumber(others, index, *moved), nil
+		}
+		position = before + (after-before)/2
+	}
+	return []Reposition{{TaskId: taskId, Position: position}}, nil
+}
+
+// renumber spreads the positions of a list evenly, with moved inserted at
+// index among others, and returns those that change.
+func renumber(others []Task, index int, moved Task) []Reposition {
+	list := make([]Task, 0, len(others)+1)
+	list = append(list, others[:index]...)
+	list = append(list, moved)
+	list = append(list, others[index:]...)
+
+	var positions []Reposition
+	for i, task := range list {
+		position := float64(i+1) * positionStep
+		if task.Position != position {
+			positions = append(positions, Reposition{TaskId: task.Id, Position: position})
+		}
+	}
+	return positions
+}
+
+// ApplyPositions updates the positions computed by MoveBefore and the other
+// moves, sending at most concurrency updates at a time with RunBatch. It
+// returns the errors in the same order as positions.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	positions: New positions of tasks
+//	concurrency: Maximum number of updates sent at the same time
+func ApplyPositions(ctx context.Context, pa *togglPlanApi, workspaceId ID, positions []Reposition, concurrency int) []error {
+	jobs := make([]func(ctx context.Context) error, len(positions))
+	for i, reposition := range positions {
+		reposition := reposition
+		jobs[i] = func(ctx context.Context) error {
+			_, err := UpdateTask(ctx, pa, workspaceId, reposition.TaskId, TaskUpdate{Position: &reposition.Position})
+			return err
+		}
+	}
+
+	return RunBatch(ctx, concurrency, jobs)
+}
@@ -0,0 +1,72 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestMoveBefore(t *testing.T) {
+	tasks := []Task{{Id: 3, Position: 3072}, {Id: 1, Position: 1024}, {Id: 2, Position: 2048}}
+
+	positions, err := MoveBefore(tasks, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 1 || positions[0] != (Reposition{TaskId: 3, Position: 1536}) {
+		t.Fatalf("unexpected positions %+v", positions)
+	}
+
+	if positions, _ := MoveAfter(tasks, 1, 3); len(positions) != 1 || positions[0].Position != 4096 {
+		t.Fatalf("expected task 1 to move to the bottom, got %+v", positions)
+	}
+	if positions, _ := MoveToTop(tasks, 2); len(positions) != 1 || positions[0].Position != 0 {
+		t.Fatalf("expected task 2 to move to the top, got %+v", positions)
+	}
+	if positions, _ := MoveToBottom(tasks, 2); len(positions) != 1 || positions[0].Position != 4096 {
+		t.Fatalf("expected task 2 to move to the bottom, got %+v", positions)
+	}
+
+	if _, err := MoveBefore(tasks, 3, 9); err == nil {
+		t.Fatal("expected an error for a task missing from the list")
+	}
+}
+
+func TestMoveBeforeRenumbers(t *testing.T) {
+	// Tasks that were never ordered leave no room between them
+	tasks := []Task{{Id: 1}, {Id: 2}, {Id: 3}}
+
+	positions, err := MoveBefore(tasks, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moved := map[ID]float64{}
+	for _, position := range positions {
+		moved[position.TaskId] = position.Position
+	}
+	if len(moved) != 3 || moved[1] != 1024 || moved[3] != 2048 || moved[2] != 3072 {
+		t.Fatalf("unexpected positions %+v", positions)
+	}
+}
+
+func TestApplyPositions(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/api/v5/1/tasks/3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var update map[string]any
+		json.NewDecoder(r.Body).Decode(&update)
+		if len(update) != 1 || update["position"] != 1536.0 {
+			t.Errorf("unexpected update %v", update)
+		}
+		json.NewEncoder(w).Encode(Task{Id: 3, Position: 1536})
+	})
+
+	errs := ApplyPositions(context.Background(), pa, 1, []Reposition{{TaskId: 3, Position: 1536}}, 2)
+	if len(errs) != 1 || errs[0] != nil {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
@@ -0,0 +1,163 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// OverdueTask is a task past its end date that isn't done.
+type OverdueTask struct {
+	Task Task
+	// DaysOverdue is the number of days since the end date of the task.
+	DaysOverdue int
+}
+
+// OverdueOptions configures FetchOverdue.
+type OverdueOptions struct {
+	// DaysBack bounds how long ago overdue tasks may have ended. Defaults
+	// to 90 days.
+	DaysBack int
+
+	// Today is the reference day. Defaults to today in Location, which
+	// defaults to time.Local.
+	Today    Date
+	Location *time.Location
+
+	// ProjectIds and MemberIds restrict the tasks scanned, like in TaskFilter.
+	ProjectIds []ID
+	MemberIds  []ID
+}
+
+// FetchOverdue fetches the tasks of a workspace that ended recently, and
+// returns those that aren't done. See FindOverdue.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	options: Range and scope of the scan (use `togglplanapi.OverdueOptions{}` for the defaults)
+func FetchOverdue(ctx context.Context, pa *togglPlanApi, workspaceId ID, options OverdueOptions) ([]OverdueTask, error) {
+	if options.DaysBack <= 0 {
+		options.DaysBack = 90
+	}
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+	if options.Today.IsZero() {
+		options.Today = Today(options.Location)
+	}
+
+	tasks, err := ListAllTasks(ctx, pa, workspaceId, TaskFilter{
+		Since:      options.Today.AddDays(-options.DaysBack),
+		Until:      options.Today.AddDays(-1),
+		ProjectIds: options.ProjectIds,
+		MemberIds:  options.MemberIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return FindOverdue(tasks, options.Today), nil
+}
+
+// FindOverdue returns the tasks that ended before today and aren't done,
+// most overdue first.
+func FindOverdue(tasks []Task, today Date) []OverdueTask {
+	var overdue []OverdueTask
+	for _, task := range tasks {
+		if !task.Done && !task.EndDate.IsZero() && task.EndDate.Before(today) {
+			overdue = append(overdue, OverdueTask{Task: task, DaysOverdue: task.EndDate.DaysUntil(today)})
+		}
+	}
+
+	sort.SliceStable(overdue, func(i, j int) bool {
+		return overdue[i].DaysOverdue > overdue[j].DaysOverdue
+	})
+	return overdue
+}
+
+// GroupOverdue groups overdue tasks by assignee or by project. A task with
+// several assignees is listed under each, and tasks without an assignee or
+// a project are listed under 0.
+func GroupOverdue(overdue []OverdueTask, groupBy ReportGrouping) map[ID][]OverdueTask {
+	groups := map[ID][]OverdueTask{}
+	for _, task := range overdue {
+		keys := []ID{task.Task.ProjectId}
+		if groupBy == GroupByMember {
+			keys = task.Task.Assignees
+			if len(keys) == 0 {
+				keys = []ID{0}
+			}
+		}
+		for _, key := range keys {
+			groups[key] = append(groups[key], task)
+		}
+	}
+	return groups
+}
+
+// Escalation is what to do about an overdue task.
+type Escalation struct {
+	// Comment is posted on the task if it isn't empty. It is HTML, such
+	// as built by FormatMentions or MarkdownToHTML.
+	Comment string
+	// Assignees replace the assignees of the task if not nil.
+	Assignees []ID
+}
+
+// EscalationPolicy decides what to do about an overdue task. Returning the
+// zero Escalation leaves the task alone.
+type EscalationPolicy func(task OverdueTask) Escalation
+
+// EscalationResult reports what was done about an overdue task.
+type EscalationResult struct {
+	TaskId     ID
+	Escalation Escalation
+	// Err is the reason the comment couldn't be posted or the task
+	// reassigned, if any.
+	Err error
+}
+
+// Escalate applies a policy to overdue tasks, posting comments and
+// reassigning tasks as it decides, a few tasks at a time. It returns what
+// was done about each task the policy acted on, in the order of overdue. A
+// failure doesn't stop the others.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	overdue: Overdue tasks, as returned by FetchOverdue
+//	policy: Function deciding what to do about each task
+func Escalate(ctx context.Context, pa *togglPlanApi, workspaceId ID, overdue []OverdueTask, policy EscalationPolicy) []EscalationResult {
+	var results []EscalationResult
+	for _, task := range overdue {
+		escalation := policy(task)
+		if escalation.Comment != "" || escalation.Assignees != nil {
+			results = append(results, EscalationResult{TaskId: task.Task.Id, Escalation: escalation})
+		}
+	}
+
+	jobs := make([]func(ctx context.Context) error, len(results))
+	for i, result := range results {
+		result := result
+		jobs[i] = func(ctx context.Context) error {
+			var errs []error
+			if result.Escalation.Comment != "" {
+				_, err := CreateComment(ctx, pa, workspaceId, result.TaskId, CommentParams{Body: result.Escalation.Comment})
+				errs = append(errs, err)
+			}
+			if result.Escalation.Assignees != nil {
+				_, err := UpdateTask(ctx, pa, workspaceId, result.TaskId, TaskUpdate{Assignees: &result.Escalation.Assignees})
+				errs = append(errs, err)
+			}
+			return errors.Join(errs...)
+		}
+	}
+
+	for i, err := range RunBatch(ctx, 4, jobs) {
+		results[i].Err = err
+	}
+	return results
+}
@@ -0,0 +1,72 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFindOverdue(t *testing.T) {
+	today := NewDate(2024, 3, 6)
+	tasks := []Task{
+		{Id: 1, EndDate: today.AddDays(-1), Assignees: []ID{7, 8}, ProjectId: 3},
+		{Id: 2, EndDate: today.AddDays(-5), ProjectId: 3},
+		{Id: 3, EndDate: today.AddDays(-5), Done: true},
+		{Id: 4, EndDate: today},
+	}
+
+	overdue := FindOverdue(tasks, today)
+	if len(overdue) != 2 || overdue[0].Task.Id != 2 || overdue[0].DaysOverdue != 5 || overdue[1].DaysOverdue != 1 {
+		t.Fatalf("unexpected overdue tasks %+v", overdue)
+	}
+
+	byMember := GroupOverdue(overdue, GroupByMember)
+	if len(byMember[7]) != 1 || len(byMember[8]) != 1 || len(byMember[0]) != 1 {
+		t.Fatalf("unexpected groups %+v", byMember)
+	}
+	if byProject := GroupOverdue(overdue, GroupByProject); len(byProject[3]) != 2 {
+		t.Fatalf("unexpected groups %+v", byProject)
+	}
+}
+
+func TestEscalate(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/api/v5/1/tasks/2") {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	overdue := []OverdueTask{
+		{Task: Task{Id: 1}, DaysOverdue: 10},
+		{Task: Task{Id: 2}, DaysOverdue: 3},
+		{Task: Task{Id: 3}, DaysOverdue: 1},
+	}
+	results := Escalate(context.Background(), pa, 1, overdue, func(task OverdueTask) Escalation {
+		switch {
+		case task.DaysOverdue > 7:
+			return Escalation{Assignees: []ID{9}}
+		case task.DaysOverdue > 2:
+			return Escalation{Comment: "Still on it?"}
+		}
+		return Escalation{}
+	})
+
+	if len(results) != 2 || results[0].TaskId != 1 || results[0].Err != nil || results[1].TaskId != 2 || results[1].Err == nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("unexpected requests %v", requests)
+	}
+}
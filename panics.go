@@ -0,0 +1,54 @@
+package togglplanapi
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicError reports that code given to the package, such as middleware, a
+// callback or a job, panicked. The panic is recovered, so that a buggy hook
+// fails the call it ran for rather than the whole process, which is what a
+// panic in one of the goroutines of the package would otherwise do.
+type PanicError struct {
+	// Hook names what panicked, such as "middleware", "OnRefresh" or "job".
+	Hook string
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the goroutine when it panicked.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s panicked: %v", e.Hook, e.Value)
+}
+
+// Unwrap returns the value passed to panic if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// callHook calls f, turning a panic into a *PanicError attributed to hook.
+func callHook(hook string, f func() error) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = &PanicError{Hook: hook, Value: value, Stack: debug.Stack()}
+		}
+	}()
+	return f()
+}
+
+// recoveringTransport sends requests through middleware, failing them with
+// a *PanicError if it panics.
+type recoveringTransport struct {
+	next http.RoundTripper
+}
+
+func (t recoveringTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+	err = callHook("middleware", func() error {
+		resp, err = t.next.RoundTrip(req)
+		return err
+	})
+	return resp, err
+}
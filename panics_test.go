@@ -0,0 +1,51 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPanicsInHooks(t *testing.T) {
+	requests := 0
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, `{"id":7,"priority":1}`)
+	})
+
+	var panicErr *PanicError
+	WithJSONOptions(JSONOptions{OnUnknownFields: func(*UnknownFieldsError) { panic("bad logger") }})(pa)
+	if _, err := GetTask(context.Background(), pa, 1, 7); !errors.As(err, &panicErr) || panicErr.Hook != "OnUnknownFields" {
+		t.Errorf("expected a PanicError from OnUnknownFields, got %v", err)
+	}
+	WithJSONOptions(JSONOptions{})(pa)
+
+	WithMiddleware(func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			panic(errors.New("nil map"))
+		})
+	})(pa)
+	requests = 0
+	start := time.Now()
+	_, err := GetTask(context.Background(), pa, 1, 7)
+	if !errors.As(err, &panicErr) || panicErr.Hook != "middleware" || panicErr.Unwrap().Error() != "nil map" || len(panicErr.Stack) == 0 {
+		t.Errorf("expected a PanicError from the middleware, got %v", err)
+	}
+	if requests != 0 || time.Since(start) > time.Second || IsRetryable(err) {
+		t.Error("expected the request not to be retried")
+	}
+
+	errs := RunBatch(context.Background(), 2, []func(ctx context.Context) error{
+		func(ctx context.Context) error { return nil },
+		func(ctx context.Context) error { panic("job") },
+	})
+	if errs[0] != nil || !errors.As(errs[1], &panicErr) || panicErr.Hook != "job" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	// Progress is dropped rather than failing the operation
+	ProgressFunc(func(ProgressUpdate) { panic("progress bar") }).Report("tasks", 1, 2)
+}
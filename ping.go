@@ -0,0 +1,46 @@
+package togglplanapi
+
+import (
+	"context"
+	"time"
+)
+
+// PingResult reports the outcome of Ping.
+type PingResult struct {
+	// User is the user the client is authenticated as.
+	User Me
+	// Latency is the time taken by the authenticated request to /me.
+	Latency time.Duration
+	// TokenAge is the time since the client fetched its bearer token, or 0
+	// if the token was passed to New.
+	TokenAge time.Duration
+	// Workspaces is the number of workspaces the user is a member of.
+	Workspaces int
+}
+
+// Ping checks that the client can reach the API and authenticate, fetching
+// a token first if necessary, e.g. for the readiness probe of a service.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+func Ping(ctx context.Context, pa *togglPlanApi) (*PingResult, error) {
+	start := time.Now()
+	me, err := GetMe(ctx, pa)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PingResult{User: *me, Latency: time.Since(start)}
+	if _, tokenAt := currentToken(pa); !tokenAt.IsZero() {
+		result.TokenAge = time.Since(tokenAt)
+	}
+
+	workspaces, err := GetWorkspaces(ctx, pa)
+	if err != nil {
+		return nil, err
+	}
+	result.Workspaces = len(workspaces)
+
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestPing(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v5/authenticate/token":
+			fmt.Fprint(w, `{"access_token":"fresh"}`)
+		case "/api/v5/me":
+			fmt.Fprint(w, `{"id":5,"name":"Ada"}`)
+		case "/api/v5/workspaces":
+			fmt.Fprint(w, `[{"id":1},{"id":2}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	pa.bearerToken = ""
+
+	result, err := Ping(context.Background(), pa)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.User.Id != 5 || result.Workspaces != 2 || result.Latency <= 0 || result.TokenAge <= 0 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,41 @@
+package togglplanapi
+
+import (
+	"context"
+)
+
+// PlanStatus represents a column of the workspace's board (e.g. "To do",
+// "In progress", "Done"), which tasks can be placed in.
+type PlanStatus struct {
+	Id       ID     `json:"id"`
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// GetPlanStatuses fetches the board columns of a workspace.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+func GetPlanStatuses(ctx context.Context, pa *togglPlanApi, workspaceId ID) ([]PlanStatus, error) {
+	var statuses []PlanStatus
+	err := getJSON(ctx, pa, workspacePath(workspaceId, "/plan_statuses"), nil, &statuses)
+	return statuses, err
+}
+
+// CreatePlanStatus adds a column to the workspace's board and returns it.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	name: Name of the column
+func CreatePlanStatus(ctx context.Context, pa *togglPlanApi, workspaceId ID, name string) (*PlanStatus, error) {
+	var status PlanStatus
+	params := map[string]string{"name": name}
+	if err := sendJSON(ctx, pa, "POST", workspacePath(workspaceId, "/plan_statuses"), nil, params, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
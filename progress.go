@@ -0,0 +1,170 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// ProgressOptions configures ComputeProgress.
+type ProgressOptions struct {
+	// Since and Until set the first and last day of the data points, both
+	// inclusive. They are required by FetchProgress, which fetches the
+	// tasks scheduled between them.
+	Since Date
+	Until Date
+
+	// ProjectIds restricts the result to some projects. By default every
+	// project with tasks is included.
+	ProjectIds []ID
+
+	// Interval is the number of days between data points. Defaults to 1.
+	// The last point is always on Until.
+	Interval int
+
+	// ByEstimate weighs tasks by their estimate in hours, instead of
+	// counting each task once. Tasks without an estimate then weigh nothing.
+	ByEstimate bool
+
+	// Location is the time zone in which timestamps are turned into days.
+	// Defaults to time.Local.
+	Location *time.Location
+}
+
+// ProgressPoint is the state of a project at the end of a day, in tasks or
+// in hours depending on ProgressOptions.ByEstimate.
+type ProgressPoint struct {
+	Date      Date
+	Done      float64
+	Remaining float64
+}
+
+// Total returns the scope of the project on the day: done and remaining work.
+func (point ProgressPoint) Total() float64 {
+	return point.Done + point.Remaining
+}
+
+// ProjectProgress holds the burn-up data of a project, one point per interval.
+type ProjectProgress struct {
+	ProjectId ID
+	Points    []ProgressPoint
+}
+
+// Latest returns the last point of the project, or the zero point if there is none.
+func (progress ProjectProgress) Latest() ProgressPoint {
+	if len(progress.Points) == 0 {
+		return ProgressPoint{}
+	}
+	return progress.Points[len(progress.Points)-1]
+}
+
+// FetchProgress fetches the tasks of a workspace scheduled between
+// options.Since and options.Until with ListAllTasks, and computes the
+// progress of their projects.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	options: Date range, projects and weighting of the data points
+func FetchProgress(ctx context.Context, pa *togglPlanApi, workspaceId ID, options ProgressOptions) ([]ProjectProgress, error) {
+	if options.Since.IsZero() || options.Until.IsZero() {
+		return nil, errors.New("progress requires a date range")
+	}
+
+	tasks, err := ListAllTasks(ctx, pa, workspaceId, TaskFilter{
+		Since:      options.Since,
+		Until:      options.Until,
+		ProjectIds: options.ProjectIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ComputeProgress(tasks, options), nil
+}
+
+// ComputeProgress aggregates done and remaining work per project at the end
+// of each day between options.Since and options.Until, for burn-up charts.
+// Projects are sorted by ID, and tasks outside of any project are left out.
+//
+// The API doesn't record when tasks were created or completed as such, so a
+// task joins the scope on the day of its CreatedAt, and a task that is done
+// counts as done from the day of its UpdatedAt. Tasks without timestamps
+// count from the first day.
+func ComputeProgress(tasks []Task, options ProgressOptions) []ProjectProgress {
+	if options.Interval <= 0 {
+		options.Interval = 1
+	}
+	if options.Location == nil {
+		options.Location = time.Local
+	}
+
+	var days []Date
+	for day := options.Since; !day.After(options.Until); day = day.AddDays(options.Interval) {
+		days = append(days, day)
+	}
+	if len(days) > 0 && days[len(days)-1] != options.Until {
+		days = append(days, options.Until)
+	}
+
+	dayOf := func(dt DateTime) Date {
+		if dt.IsZero() {
+			return Date{}
+		}
+		return DateOf(dt.In(options.Location))
+	}
+
+	selected := map[ID]bool{}
+	for _, id := range options.ProjectIds {
+		selected[id] = true
+	}
+
+	rows := map[ID]*ProjectProgress{}
+	for _, task := range tasks {
+		if task.ProjectId == 0 {
+			continue
+		}
+		if len(selected) > 0 && !selected[task.ProjectId] {
+			continue
+		}
+
+		row, ok := rows[task.ProjectId]
+		if !ok {
+			row = &ProjectProgress{ProjectId: task.ProjectId, Points: make([]ProgressPoint, len(days))}
+			for i, day := range days {
+				row.Points[i].Date = day
+			}
+			rows[task.ProjectId] = row
+		}
+
+		weight := 1.0
+		if options.ByEstimate {
+			weight = task.EstimatedMinutes.Hours()
+		}
+
+		created := dayOf(task.CreatedAt)
+		completed := dayOf(task.UpdatedAt)
+		for i, day := range days {
+			if day.Before(created) {
+				continue
+			}
+			if task.Done && !day.Before(completed) {
+				row.Points[i].Done += weight
+			} else {
+				row.Points[i].Remaining += weight
+			}
+		}
+	}
+
+	progress := make([]ProjectProgress, 0, len(rows))
+	for _, row := range rows {
+		progress = append(progress, *row)
+	}
+	sort.Slice(progress, func(i, j int) bool {
+		return progress[i].ProjectId < progress[j].ProjectId
+	})
+
+	return progress
+}
@@ -0,0 +1,58 @@
+package togglplanapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestComputeProgress(t *testing.T) {
+	at := func(value string) DateTime {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return DateTime{parsed}
+	}
+
+	tasks := []Task{
+		{Id: 1, ProjectId: 7, EstimatedMinutes: Hours(2), Done: true, CreatedAt: at("2024-03-01T09:00:00Z"), UpdatedAt: at("2024-03-05T17:00:00Z")},
+		{Id: 2, ProjectId: 7, EstimatedMinutes: Hours(4), CreatedAt: at("2024-03-01T09:00:00Z")},
+		{Id: 3, ProjectId: 7, EstimatedMinutes: Hours(1), CreatedAt: at("2024-03-06T09:00:00Z")},
+		{Id: 4, ProjectId: 8, Done: true},
+		{Id: 5},
+	}
+	options := ProgressOptions{
+		Since:    NewDate(2024, 3, 4),
+		Until:    NewDate(2024, 3, 7),
+		Interval: 2,
+		Location: time.UTC,
+	}
+
+	progress := ComputeProgress(tasks, options)
+	if len(progress) != 2 || progress[0].ProjectId != 7 || progress[1].ProjectId != 8 {
+		t.Fatalf("unexpected projects: %+v", progress)
+	}
+
+	expected := []ProgressPoint{
+		{Date: NewDate(2024, 3, 4), Done: 0, Remaining: 2},
+		{Date: NewDate(2024, 3, 6), Done: 1, Remaining: 2},
+		{Date: NewDate(2024, 3, 7), Done: 1, Remaining: 2},
+	}
+	if !reflect.DeepEqual(progress[0].Points, expected) {
+		t.Fatalf("unexpected points: %+v", progress[0].Points)
+	}
+	if latest := progress[1].Latest(); latest.Done != 1 || latest.Total() != 1 {
+		t.Fatalf("expected tasks without timestamps to count from the start, got %+v", latest)
+	}
+
+	options.ByEstimate = true
+	options.ProjectIds = []ID{7}
+	progress = ComputeProgress(tasks, options)
+	if len(progress) != 1 {
+		t.Fatalf("expected only project 7, got %+v", progress)
+	}
+	if latest := progress[0].Latest(); latest.Done != 2 || latest.Remaining != 5 {
+		t.Fatalf("expected hours, got %+v", latest)
+	}
+}
@@ -0,0 +1,58 @@
+package togglplanapi
+
+import "sync"
+
+// ProgressUpdate tells how far a long operation, such as a backup or an
+// import, has gone.
+type ProgressUpdate struct {
+	// Phase names the current step of the operation, such as "tasks" or
+	// "comments".
+	Phase string
+	// Done counts the items of the phase handled so far, and Total is the
+	// expected number of items, or 0 if it isn't known yet.
+	Done  int
+	Total int
+}
+
+// ProgressFunc receives the progress of a long operation, e.g. to draw a
+// progress bar. Calls don't overlap, even when the operation runs requests
+// concurrently.
+type ProgressFunc func(ProgressUpdate)
+
+// Report calls f with the progress of phase, unless f is nil. Progress is
+// only informative, so a panic in f is recovered and dropped rather than
+// failing the operation.
+func (f ProgressFunc) Report(phase string, done int, total int) {
+	if f != nil {
+		callHook("Progress", func() error {
+			f(ProgressUpdate{Phase: phase, Done: done, Total: total})
+			return nil
+		})
+	}
+}
+
+// Counter returns a ProgressCounter reporting the progress of phase to f,
+// out of total items. It reports that the phase started right away.
+func (f ProgressFunc) Counter(phase string, total int) *ProgressCounter {
+	f.Report(phase, 0, total)
+	return &ProgressCounter{report: f, phase: phase, total: total}
+}
+
+// ProgressCounter counts the items of a phase handled by concurrent jobs,
+// and reports each of them. It is safe for concurrent use.
+type ProgressCounter struct {
+	report ProgressFunc
+	phase  string
+	total  int
+
+	mu   sync.Mutex
+	done int
+}
+
+// Add records that an item was handled, and reports the progress.
+func (c *ProgressCounter) Add() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.done++
+	c.report.Report(c.phase, c.done, c.total)
+}
@@ -0,0 +1,99 @@
+package togglplanapi
+
+import (
+	"context"
+)
+
+// Project represents a project in a workspace.
+// Its dates are zero if not set.
+type Project struct {
+	Id        ID       `json:"id"`
+	Name      string   `json:"name"`
+	Notes     string   `json:"notes"`
+	Color     Color    `json:"color"`
+	StartDate Date     `json:"start_date"`
+	EndDate   Date     `json:"end_date"`
+	Archived  bool     `json:"archived"`
+	CreatedAt DateTime `json:"created_at"`
+	UpdatedAt DateTime `json:"updated_at"`
+}
+
+// ProjectParams holds the fields of a project to create.
+type ProjectParams struct {
+	Name      string `json:"name"`
+	Notes     string `json:"notes,omitempty"`
+	Color     Color  `json:"color,omitempty"`
+	StartDate Date   `json:"start_date"`
+	EndDate   Date   `json:"end_date"`
+}
+
+// ProjectUpdate holds the fields of a project to change.
+// Fields left nil are not sent, and keep their current value.
+type ProjectUpdate struct {
+	Name      *string `json:"name,omitempty"`
+	Notes     *string `json:"notes,omitempty"`
+	Color     *Color  `json:"color,omitempty"`
+	StartDate *Date   `json:"start_date,omitempty"`
+	EndDate   *Date   `json:"end_date,omitempty"`
+	Archived  *bool   `json:"archived,omitempty"`
+}
+
+// MarshalJSON encodes the fields set in the update.
+func (update ProjectUpdate) MarshalJSON() ([]byte, error) {
+	return marshalUpdate(update)
+}
+
+// GetProjects fetches all projects of a workspace.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+func GetProjects(ctx context.Context, pa *togglPlanApi, workspaceId ID) ([]Project, error) {
+	var projects []Project
+	err := getJSON(ctx, pa, workspacePath(workspaceId, "/projects"), nil, &projects)
+	return projects, err
+}
+
+// GetProject fetches a single project. If it doesn't exist, the error
+// matches ErrNotFound, or the project is nil, see WithNotFoundAsNil.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	projectId: ID of the project
+func GetProject(ctx context.Context, pa *togglPlanApi, workspaceId ID, projectId ID) (*Project, error) {
+	return getOne[Project](ctx, pa, workspacePath(workspaceId, "/projects/%d", projectId))
+}
+
+// CreateProject creates a project in a workspace and returns it.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	params: Fields of the new project
+func CreateProject(ctx context.Context, pa *togglPlanApi, workspaceId ID, params ProjectParams) (*Project, error) {
+	var project Project
+	if err := sendJSON(ctx, pa, "POST", workspacePath(workspaceId, "/projects"), nil, params, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+// UpdateProject changes the fields of a project set in update, and returns the updated project.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	projectId: ID of the project
+//	update: Fields to change
+func UpdateProject(ctx context.Context, pa *togglPlanApi, workspaceId ID, projectId ID, update ProjectUpdate) (*Project, error) {
+	var project Project
+	if err := sendJSON(ctx, pa, "PUT", workspacePath(workspaceId, "/projects/%d", projectId), nil, update, &project); err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
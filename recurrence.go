@@ -0,0 +1,159 @@
+package togglplanapi
+
+import (
+	"sort"
+	"time"
+)
+
+// Frequency is the period a recurring task repeats on.
+type Frequency string
+
+// Frequencies of a Recurrence.
+const (
+	EveryDay   Frequency = "daily"
+	EveryWeek  Frequency = "weekly"
+	EveryMonth Frequency = "monthly"
+	EveryYear  Frequency = "yearly"
+)
+
+// Recurrence describes how a task repeats, counted from its start date.
+type Recurrence struct {
+	Frequency Frequency
+	// Interval repeats the task every Interval periods, e.g. every 2 weeks.
+	// Defaults to 1.
+	Interval int
+	// Weekdays lists the days of the week a weekly task repeats on. Defaults
+	// to the weekday of its start date. Weeks start on Sunday.
+	Weekdays []time.Weekday
+	// Until is the last day an occurrence can start on, if set.
+	Until Date
+	// Count is the total number of occurrences, including the first, if set.
+	Count int
+}
+
+// Dates returns the start dates of the occurrences of a task starting on
+// start, from since to until, both inclusive. A monthly or yearly task
+// starting on a day missing from a month, such as the 31st, repeats on the
+// last day of that month instead. An unknown frequency doesn't repeat.
+func (r Recurrence) Dates(start Date, since Date, until Date) []Date {
+	if start.IsZero() || until.IsZero() {
+		return nil
+	}
+	if !r.Until.IsZero() && r.Until.Before(until) {
+		until = r.Until
+	}
+
+	interval := r.Interval
+	if interval <= 0 {
+		interval = 1
+	}
+
+	var dates []Date
+	count := 0
+	// add records an occurrence, and reports whether there can be more
+	add := func(date Date) bool {
+		if date.After(until) || (r.Count > 0 && count >= r.Count) {
+			return false
+		}
+		count++
+		if !date.Before(since) {
+			dates = append(dates, date)
+		}
+		return true
+	}
+
+	switch r.Frequency {
+	case EveryDay:
+		for n := 0; add(start.AddDays(n * interval)); n++ {
+		}
+	case EveryWeek:
+		weekdays := r.Weekdays
+		if len(weekdays) == 0 {
+			weekdays = []time.Weekday{start.Weekday()}
+		}
+		weekdays = append([]time.Weekday(nil), weekdays...)
+		sort.Slice(weekdays, func(i, j int) bool { return weekdays[i] < weekdays[j] })
+
+		sunday := start.AddDays(-int(start.Weekday()))
+		for week := 0; ; week += interval {
+			more := true
+			for _, weekday := range weekdays {
+				date := sunday.AddDays(7*week + int(weekday))
+				if date.Before(start) {
+					continue
+				}
+				if more = add(date); !more {
+					break
+				}
+			}
+			if !more {
+				break
+			}
+		}
+	case EveryMonth:
+		for n := 0; add(addMonthsClamped(start, n*interval)); n++ {
+		}
+	case EveryYear:
+		for n := 0; add(addMonthsClamped(start, 12*n*interval)); n++ {
+		}
+	default:
+		add(start)
+	}
+
+	return dates
+}
+
+// addMonthsClamped adds months to date, moving to the last day of the month
+// when the day of date doesn't exist in it.
+func addMonthsClamped(date Date, months int) Date {
+	first := NewDate(date.Year, date.Month+time.Month(months), 1)
+	last := first.AddDate(0, 1, -1)
+	if date.Day > last.Day {
+		return last
+	}
+	return NewDate(first.Year, first.Month, date.Day)
+}
+
+// RecurringTask is a task that repeats. Task holds the first occurrence.
+type RecurringTask struct {
+	Task       Task
+	Recurrence Recurrence
+}
+
+// Expand returns the occurrences of the task overlapping since to until,
+// both inclusive, without calling the API. Each occurrence is a copy of the
+// task, with the same ID, moved to its start date and keeping its length.
+func (rt RecurringTask) Expand(since Date, until Date) []Task {
+	task := rt.Task
+	if task.StartDate.IsZero() || task.EndDate.IsZero() {
+		return nil
+	}
+
+	// Occurrences starting before since can still run into it
+	length := task.StartDate.DaysUntil(task.EndDate)
+
+	var occurrences []Task
+	for _, date := range rt.Recurrence.Dates(task.StartDate, since.AddDays(-length), until) {
+		occurrence := task
+		occurrence.StartDate = date
+		occurrence.EndDate = date.AddDays(length)
+		occurrences = append(occurrences, occurrence)
+	}
+	return occurrences
+}
+
+// ExpandRecurring expands recurring tasks from since to until, and returns
+// their occurrences sorted by start date. The result can be passed to
+// helpers working on fetched tasks, such as export.WriteICS or
+// schedule.ComputeWorkload, together with tasks fetched from the API.
+func ExpandRecurring(tasks []RecurringTask, since Date, until Date) []Task {
+	var occurrences []Task
+	for _, task := range tasks {
+		occurrences = append(occurrences, task.Expand(since, until)...)
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartDate.Before(occurrences[j].StartDate)
+	})
+	return occurrences
+}
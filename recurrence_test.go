@@ -0,0 +1,102 @@
+package togglplanapi
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecurrenceDates(t *testing.T) {
+	tests := []struct {
+		name       string
+		recurrence Recurrence
+		start      Date
+		since      Date
+		until      Date
+		expected   []Date
+	}{
+		{
+			name:       "every other day",
+			recurrence: Recurrence{Frequency: EveryDay, Interval: 2},
+			start:      NewDate(2024, 3, 1),
+			since:      NewDate(2024, 3, 4),
+			until:      NewDate(2024, 3, 8),
+			expected:   []Date{NewDate(2024, 3, 5), NewDate(2024, 3, 7)},
+		},
+		{
+			name:       "weekdays",
+			recurrence: Recurrence{Frequency: EveryWeek, Weekdays: []time.Weekday{time.Friday, time.Monday}},
+			start:      NewDate(2024, 3, 6), // Wednesday
+			since:      NewDate(2024, 3, 1),
+			until:      NewDate(2024, 3, 15),
+			expected:   []Date{NewDate(2024, 3, 8), NewDate(2024, 3, 11), NewDate(2024, 3, 15)},
+		},
+		{
+			name:       "end of month",
+			recurrence: Recurrence{Frequency: EveryMonth},
+			start:      NewDate(2024, 1, 31),
+			since:      NewDate(2024, 1, 1),
+			until:      NewDate(2024, 4, 30),
+			expected:   []Date{NewDate(2024, 1, 31), NewDate(2024, 2, 29), NewDate(2024, 3, 31), NewDate(2024, 4, 30)},
+		},
+		{
+			name:       "count before window",
+			recurrence: Recurrence{Frequency: EveryWeek, Count: 3},
+			start:      NewDate(2024, 3, 4),
+			since:      NewDate(2024, 3, 12),
+			until:      NewDate(2024, 4, 30),
+			expected:   []Date{NewDate(2024, 3, 18)},
+		},
+		{
+			name:       "until",
+			recurrence: Recurrence{Frequency: EveryYear, Until: NewDate(2025, 6, 1)},
+			start:      NewDate(2024, 2, 29),
+			since:      NewDate(2024, 1, 1),
+			until:      NewDate(2030, 1, 1),
+			expected:   []Date{NewDate(2024, 2, 29), NewDate(2025, 2, 28)},
+		},
+		{
+			name:       "no repeat",
+			recurrence: Recurrence{},
+			start:      NewDate(2024, 3, 4),
+			since:      NewDate(2024, 3, 1),
+			until:      NewDate(2024, 3, 31),
+			expected:   []Date{NewDate(2024, 3, 4)},
+		},
+	}
+
+	for _, test := range tests {
+		got := test.recurrence.Dates(test.start, test.since, test.until)
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.expected, got)
+		}
+	}
+}
+
+func TestExpandRecurring(t *testing.T) {
+	weekly := RecurringTask{
+		Task:       Task{Id: 1, Name: "Review", StartDate: NewDate(2024, 3, 4), EndDate: NewDate(2024, 3, 5)},
+		Recurrence: Recurrence{Frequency: EveryWeek},
+	}
+	daily := RecurringTask{
+		Task:       Task{Id: 2, Name: "Standup", StartDate: NewDate(2024, 3, 10), EndDate: NewDate(2024, 3, 10)},
+		Recurrence: Recurrence{Frequency: EveryDay, Count: 2},
+	}
+
+	// The occurrence starting on the 4th runs into the window
+	tasks := ExpandRecurring([]RecurringTask{weekly, daily}, NewDate(2024, 3, 5), NewDate(2024, 3, 11))
+
+	var got []string
+	for _, task := range tasks {
+		got = append(got, task.Name+" "+task.StartDate.String()+" "+task.EndDate.String())
+	}
+	expected := []string{
+		"Review 2024-03-04 2024-03-05",
+		"Standup 2024-03-10 2024-03-10",
+		"Review 2024-03-11 2024-03-12",
+		"Standup 2024-03-11 2024-03-11",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("unexpected occurrences: %v", got)
+	}
+}
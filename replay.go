@@ -0,0 +1,113 @@
+package togglplanapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+)
+
+// maxBufferedBody is the size up to which Do reads a request body that can't
+// be rewound into memory, so that it can be sent again on retries. Larger
+// bodies are streamed, and sent once.
+const maxBufferedBody = 1 << 20
+
+// ErrBodyNotReplayable is matched, using errors.Is, by errors of requests
+// sent with Do that needed a retry, but whose body was streamed and can't be
+// sent again. The error also wraps the failure of the attempt. Set GetBody
+// on the request, or pass a body that implements io.Seeker such as an
+// *os.File, to have such requests retried.
+var ErrBodyNotReplayable = errors.New("request body can't be sent again to retry")
+
+// replayableRequest wraps req, which Do has copied, so that its body can be
+// sent again on retries. Bodies are rewound with GetBody or else Seek, and
+// small bodies are read into memory. Others are sent once, and marked in the
+// context of the request so that checkReplay stops its retries.
+func replayableRequest(req *http.Request) (*retryRequest, error) {
+	body := req.Body
+	if body == nil || body == http.NoBody {
+		return wrapRequest(req, nil, 0)
+	}
+	req.Body = nil
+	contentLength := req.ContentLength
+
+	var bodyReader func() (io.Reader, error)
+	if getBody := req.GetBody; getBody != nil {
+		body.Close()
+		bodyReader = func() (io.Reader, error) {
+			return getBody()
+		}
+	} else if seeker, ok := body.(io.Seeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+		bodyReader = func() (io.Reader, error) {
+			if _, err := seeker.Seek(start, io.SeekStart); err != nil {
+				return nil, err
+			}
+			// Hidden from Close, which would end the next attempt
+			return struct{ io.Reader }{body}, nil
+		}
+	} else {
+		head, err := io.ReadAll(io.LimitReader(body, maxBufferedBody+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(head) <= maxBufferedBody {
+			body.Close()
+			return wrapRequest(req, func() (io.Reader, error) {
+				return bytes.NewReader(head), nil
+			}, int64(len(head)))
+		}
+
+		stream := &oneShotBody{ReadCloser: struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(head), body), body}}
+		req = req.WithContext(context.WithValue(req.Context(), oneShotBodyKey{}, stream))
+		bodyReader = func() (io.Reader, error) {
+			if stream.read.Load() {
+				return nil, ErrBodyNotReplayable
+			}
+			// Hidden from Close, which retryablehttp calls on the body it
+			// probes before the first attempt. Do closes it when done.
+			return struct{ io.Reader }{stream}, nil
+		}
+	}
+
+	return wrapRequest(req, bodyReader, contentLength)
+}
+
+// oneShotBodyKey is the context key of the body of a request that can only
+// be sent once.
+type oneShotBodyKey struct{}
+
+// oneShotBody is a request body that can only be sent once, and remembers
+// whether it was read.
+type oneShotBody struct {
+	io.ReadCloser
+	read atomic.Bool
+}
+
+func (b *oneShotBody) Read(p []byte) (int, error) {
+	b.read.Store(true)
+	return b.ReadCloser.Read(p)
+}
+
+// checkReplay stops the retries of a request made with ctx if its body was
+// streamed, returning an error matching ErrBodyNotReplayable and the failure
+// of the attempt, resp or err.
+func checkReplay(ctx context.Context, resp *http.Response, err error) error {
+	body, ok := ctx.Value(oneShotBodyKey{}).(*oneShotBody)
+	if !ok || !body.read.Load() {
+		return nil
+	}
+	if err == nil {
+		err = newAPIError(resp)
+	}
+	return fmt.Errorf("%w: %w", ErrBodyNotReplayable, err)
+}
@@ -0,0 +1,107 @@
+package togglplanapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDoReplaysBodies(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		attempts[r.URL.Path]++
+		first := attempts[r.URL.Path] == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, "%d %s", len(body), body)
+	})
+	pa.retryClient.RetryWaitMin = time.Millisecond
+	pa.retryClient.RetryWaitMax = time.Millisecond
+
+	file := filepath.Join(t.TempDir(), "upload")
+	if err := os.WriteFile(file, []byte("skip:hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	seekable, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer seekable.Close()
+	seekable.Seek(5, io.SeekStart)
+
+	cases := map[string]struct {
+		body io.Reader
+		want string
+	}{
+		// strings.Reader bodies get a GetBody
+		"/getbody":  {strings.NewReader("hello"), "5 hello"},
+		"/seeker":   {seekable, "5 hello"},
+		"/buffered": {struct{ io.Reader }{strings.NewReader("hello")}, "5 hello"},
+	}
+	for path, c := range cases {
+		req, _ := http.NewRequest(http.MethodPost, pa.baseURL+path, c.body)
+		resp, err := Do(pa, req)
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != c.want {
+			t.Errorf("%s: expected %q, got %q", path, c.want, body)
+		}
+	}
+
+	large := struct{ io.Reader }{bytes.NewReader(make([]byte, maxBufferedBody+1))}
+	req, _ := http.NewRequest(http.MethodPost, pa.baseURL+"/streamed", large)
+	_, err = Do(pa, req)
+	var apiErr *APIError
+	if !errors.Is(err, ErrBodyNotReplayable) || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected ErrBodyNotReplayable, got %v", err)
+	}
+	if attempts["/streamed"] != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts["/streamed"])
+	}
+}
+
+func TestDoStreamsPipes(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprint(w, len(body))
+	})
+
+	// A pipe has a Close of its own, unlike the readers http.NewRequest wraps
+	reader, writer := io.Pipe()
+	go func() {
+		writer.Write(make([]byte, maxBufferedBody+10))
+		writer.Close()
+	}()
+
+	req, _ := http.NewRequest(http.MethodPost, pa.baseURL, reader)
+	resp, err := Do(pa, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != fmt.Sprint(maxBufferedBody+10) {
+		t.Fatalf("expected the whole body to be streamed, got %q", body)
+	}
+	if _, err := reader.Read(make([]byte, 1)); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("expected Do to close the body, got %v", err)
+	}
+}
@@ -0,0 +1,194 @@
+package togglplanapi
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReportGrouping selects how tasks are grouped in a report.
+type ReportGrouping int
+
+const (
+	// GroupByMember lists tasks under each of their assignees.
+	GroupByMember ReportGrouping = iota
+	// GroupByProject lists tasks under their project.
+	GroupByProject
+)
+
+// reportSections are the sections of each group in a weekly report, in order.
+var reportSections = []string{"Completed", "In progress", "Upcoming", "Overdue"}
+
+// WeeklyReport fetches the tasks of a workspace for the week starting on
+// weekStart, and returns a Markdown summary of them grouped by member or
+// project, suitable for pasting into Slack or a wiki.
+// See WriteWeeklyReport for how tasks are categorized.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	weekStart: First day of the week
+//	groupBy: GroupByMember or GroupByProject
+func WeeklyReport(ctx context.Context, pa *togglPlanApi, workspaceId ID, weekStart Date, groupBy ReportGrouping) (string, error) {
+	filter := TaskFilter{Since: weekStart, Until: weekStart.AddDays(6)}
+
+	tasks, err := GetTasks(ctx, pa, workspaceId, filter)
+	if err != nil {
+		return "", err
+	}
+
+	projects, err := GetProjects(ctx, pa, workspaceId)
+	if err != nil {
+		return "", err
+	}
+
+	members, err := GetMembers(ctx, pa, workspaceId)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	err = WriteWeeklyReport(&b, tasks, projects, members, weekStart, Today(time.Local), groupBy)
+	return b.String(), err
+}
+
+// WriteWeeklyReport writes a Markdown summary of the tasks of the week
+// starting on weekStart to w.
+//
+// Within each group, tasks are listed as completed (done), overdue (not done
+// and ended before today), in progress (not done and scheduled for today) or
+// upcoming (not done and starting after today). Tasks outside the week are
+// left out.
+// Arguments:
+//
+//	w: Destination of the report
+//	tasks: Tasks to report on
+//	projects: Projects used to look up project names
+//	members: Members used to look up member names
+//	weekStart: First day of the week
+//	today: Reference day for in progress, upcoming and overdue tasks
+//	groupBy: GroupByMember or GroupByProject
+func WriteWeeklyReport(w io.Writer, tasks []Task, projects []Project, members []Member, weekStart Date, today Date, groupBy ReportGrouping) error {
+	projectNames := map[ID]string{}
+	for _, project := range projects {
+		projectNames[project.Id] = project.Name
+	}
+
+	memberNames := map[ID]string{}
+	for _, member := range members {
+		memberNames[member.Id] = member.Name
+	}
+
+	until := weekStart.AddDays(6)
+
+	// groups[group name][section] lists the report lines of each task
+	groups := map[string]map[string][]string{}
+
+	for _, task := range tasks {
+		if task.StartDate.After(until) || task.EndDate.Before(weekStart) {
+			continue
+		}
+
+		section := reportSection(task, today)
+
+		line := task.Name
+		if groupBy == GroupByMember && projectNames[task.ProjectId] != "" {
+			line += " (" + projectNames[task.ProjectId] + ")"
+		}
+		line += " — " + formatDateRange(task.StartDate, task.EndDate)
+
+		var names []string
+		if groupBy == GroupByProject {
+			names = []string{projectNames[task.ProjectId]}
+			if names[0] == "" {
+				names[0] = "No project"
+			}
+		} else {
+			for _, id := range task.Assignees {
+				name := memberNames[id]
+				if name == "" {
+					name = fmt.Sprintf("Member %d", id)
+				}
+				names = append(names, name)
+			}
+			if len(names) == 0 {
+				names = []string{"Unassigned"}
+			}
+		}
+
+		for _, name := range names {
+			if groups[name] == nil {
+				groups[name] = map[string][]string{}
+			}
+			groups[name][section] = append(groups[name][section], line)
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# Week of %s\n", formatDateRange(weekStart, until))
+	if len(names) == 0 {
+		fmt.Fprint(bw, "\nNo tasks this week.\n")
+	}
+
+	for _, name := range names {
+		fmt.Fprintf(bw, "\n## %s\n", name)
+
+		for _, section := range reportSections {
+			lines := groups[name][section]
+			if len(lines) == 0 {
+				continue
+			}
+
+			sort.Strings(lines)
+			fmt.Fprintf(bw, "\n**%s**\n", section)
+			for _, line := range lines {
+				fmt.Fprintf(bw, "- %s\n", line)
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// reportSection returns the report section a task belongs in.
+func reportSection(task Task, today Date) string {
+	switch {
+	case task.Done:
+		return "Completed"
+	case task.EndDate.Before(today):
+		return "Overdue"
+	case task.StartDate.After(today):
+		return "Upcoming"
+	default:
+		return "In progress"
+	}
+}
+
+// formatDateRange formats two dates for humans, e.g. "Mar 4–8, 2024".
+func formatDateRange(start Date, end Date) string {
+	startDate := start.In(time.UTC)
+	endDate := end.In(time.UTC)
+
+	switch {
+	case start == end:
+		return startDate.Format("Jan 2, 2006")
+	case startDate.Year() != endDate.Year():
+		return startDate.Format("Jan 2, 2006") + "–" + endDate.Format("Jan 2, 2006")
+	case startDate.Month() != endDate.Month():
+		return startDate.Format("Jan 2") + "–" + endDate.Format("Jan 2, 2006")
+	default:
+		return startDate.Format("Jan 2") + "–" + endDate.Format("2, 2006")
+	}
+}
@@ -0,0 +1,54 @@
+package togglplanapi
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteWeeklyReport(t *testing.T) {
+	tasks := []Task{
+		{Name: "Mockups", StartDate: mustParseDate("2024-03-04"), EndDate: mustParseDate("2024-03-05"), Done: true, ProjectId: 1, Assignees: []ID{3}},
+		{Name: "Copy", StartDate: mustParseDate("2024-03-04"), EndDate: mustParseDate("2024-03-05"), ProjectId: 1, Assignees: []ID{3}},
+		{Name: "Build", StartDate: mustParseDate("2024-03-06"), EndDate: mustParseDate("2024-03-08"), Assignees: []ID{3, 4}},
+		{Name: "Launch", StartDate: mustParseDate("2024-03-08"), EndDate: mustParseDate("2024-03-08")},
+		{Name: "Next week", StartDate: mustParseDate("2024-03-11"), EndDate: mustParseDate("2024-03-11")},
+	}
+	projects := []Project{{Id: 1, Name: "Website"}}
+	members := []Member{{Id: 3, Name: "Ada"}, {Id: 4, Name: "Grace"}}
+
+	weekStart := NewDate(2024, 3, 4)
+	today := NewDate(2024, 3, 6)
+
+	var b strings.Builder
+	if err := WriteWeeklyReport(&b, tasks, projects, members, weekStart, today, GroupByMember); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# Week of Mar 4–10, 2024
+
+## Ada
+
+**Completed**
+- Mockups (Website) — Mar 4–5, 2024
+
+**In progress**
+- Build — Mar 6–8, 2024
+
+**Overdue**
+- Copy (Website) — Mar 4–5, 2024
+
+## Grace
+
+**In progress**
+- Build — Mar 6–8, 2024
+
+## Unassigned
+
+**Upcoming**
+- Launch — Mar 8, 2024
+`
+
+	if b.String() != expected {
+		t.Fatalf("unexpected report:\n%s", b.String())
+	}
+}
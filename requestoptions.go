@@ -0,0 +1,106 @@
+package togglplanapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RequestOption configures a single raw call, such as Request or Get.
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the settings of a raw call.
+type requestOptions struct {
+	headers map[string]string
+	query   url.Values
+	timeout time.Duration
+}
+
+// WithHeader sets a header on the request, overriding the default headers
+// of the client.
+func WithHeader(key string, value string) RequestOption {
+	return func(o *requestOptions) {
+		o.headers = mergeMaps(o.headers, map[string]string{key: value})
+	}
+}
+
+// WithHeaders sets several headers on the request, like WithHeader.
+func WithHeaders(headers map[string]string) RequestOption {
+	return func(o *requestOptions) {
+		o.headers = mergeMaps(o.headers, headers)
+	}
+}
+
+// WithQuery adds a query string parameter to the URL of the request. It can
+// be given several times, including for the same key.
+func WithQuery(key string, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.query == nil {
+			o.query = url.Values{}
+		}
+		o.query.Add(key, value)
+	}
+}
+
+// WithTimeout limits the request, including its retries and reading the
+// response, to timeout.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithIdempotencyKey sets the Idempotency-Key header of the request, so that
+// a server supporting it applies a retried write only once.
+func WithIdempotencyKey(key string) RequestOption {
+	return WithHeader("Idempotency-Key", key)
+}
+
+// rawRequest sends an authenticated request for a raw call, with opts
+// applied. cancel releases the timeout of the request, and must be called
+// once the response body has been read. Raw calls without a context pass
+// context.Background().
+// On failure, a short description of the failed step is returned alongside the error.
+func rawRequest(ctx context.Context, pa *togglPlanApi, rawURL string, method string, body []byte, opts []RequestOption) (resp *http.Response, message string, cancel context.CancelFunc, err error) {
+	options := requestOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+
+	cancel = func() {}
+	if options.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, options.timeout)
+	}
+
+	if len(options.query) > 0 {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, "Error building request", cancel, err
+		}
+		query := parsed.Query()
+		for key, values := range options.query {
+			query[key] = append(query[key], values...)
+		}
+		parsed.RawQuery = query.Encode()
+		rawURL = parsed.String()
+	}
+
+	resp, message, err = authenticatedRequest(ctx, pa, rawURL, method, body, options.headers)
+	return resp, message, cancel, err
+}
+
+// cancelOnClose is a response body that releases the timeout of its request
+// when closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
@@ -0,0 +1,34 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRequestOptions(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		fmt.Fprintf(w, "%s %s %s", r.URL.RawQuery, r.Header.Get("Idempotency-Key"), r.Header.Get("X-Trace"))
+	})
+
+	result, err := Post(pa, pa.baseURL+"/tasks?a=1", []byte(`{}`),
+		WithQuery("b", "2"),
+		WithQuery("b", "3"),
+		WithIdempotencyKey("key-1"),
+		WithHeaders(map[string]string{"X-Trace": "abc"}),
+	)
+	if err != nil || result != "a=1&b=2&b=3 key-1 abc" {
+		t.Fatalf("unexpected result %q, %v", result, err)
+	}
+
+	_, err = Get(pa, pa.baseURL+"/slow", WithTimeout(20*time.Millisecond))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request to time out, got %v", err)
+	}
+}
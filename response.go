@@ -0,0 +1,78 @@
+package togglplanapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Response describes the HTTP response of a typed call, for callers that
+// keep their own copy of the data and want to fetch it conditionally, or of
+// a Head or Options call.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	// ContentLength is the size of the body in bytes, or -1 if unknown. For
+	// Head, it is the size a GET would return, if the server tells.
+	ContentLength int64
+	// ETag is the entity tag of the response, if the API sent one.
+	ETag string
+	// LastModified is the time the resource last changed, if the API sent a
+	// Last-Modified header.
+	LastModified time.Time
+}
+
+// responseKey is the context key of the responseTarget filled in by typed
+// calls.
+type responseKey struct{}
+
+// responseTarget is the Response requested with WithResponse, guarded for
+// the calls that share its context.
+type responseTarget struct {
+	mu   sync.Mutex
+	resp *Response
+}
+
+// WithResponse returns a context that makes typed calls record their HTTP
+// response in resp, leaving their return values as they are:
+//
+//	var resp togglplanapi.Response
+//	tasks, err := togglplanapi.GetTasks(togglplanapi.WithResponse(ctx, &resp), pa, workspaceId, filter)
+//	fmt.Println(resp.ETag, resp.LastModified)
+//
+// resp only holds the last response. Calls sending several requests, such
+// as ListAllTasks, record the last one, and so do calls sharing the context,
+// e.g. the jobs of RunBatch: resp is then safe to read once they have all
+// returned, but tells about whichever finished last. Give each call its own
+// context to see the response of each.
+func WithResponse(ctx context.Context, resp *Response) context.Context {
+	return context.WithValue(ctx, responseKey{}, &responseTarget{resp: resp})
+}
+
+// recordResponse fills in the Response requested with WithResponse, if any.
+func recordResponse(ctx context.Context, resp *http.Response) {
+	target, ok := ctx.Value(responseKey{}).(*responseTarget)
+	if !ok || target.resp == nil {
+		return
+	}
+
+	response := newResponse(resp)
+	target.mu.Lock()
+	defer target.mu.Unlock()
+	*target.resp = *response
+}
+
+// newResponse describes resp.
+func newResponse(resp *http.Response) *Response {
+	response := &Response{
+		StatusCode:    resp.StatusCode,
+		Header:        resp.Header,
+		ContentLength: resp.ContentLength,
+		ETag:          resp.Header.Get("ETag"),
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		response.LastModified = modified
+	}
+	return response
+}
@@ -0,0 +1,93 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithResponse(t *testing.T) {
+	modified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v7"`)
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		fmt.Fprint(w, `{"id":1,"name":"Design"}`)
+	})
+
+	var resp Response
+	task, err := GetTask(WithResponse(context.Background(), &resp), pa, 1, 1)
+	if err != nil || task.Name != "Design" {
+		t.Fatalf("unexpected task %+v, %v", task, err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.ETag != `"v7"` || !resp.LastModified.Equal(modified) {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestWithResponseShared(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"`+r.URL.Path+`"`)
+		fmt.Fprint(w, `{"id":1}`)
+	})
+
+	// Concurrent calls sharing the context each record their response
+	var resp Response
+	ctx := WithResponse(context.Background(), &resp)
+	var wg sync.WaitGroup
+	for i := 1; i <= 8; i++ {
+		wg.Add(1)
+		go func(id ID) {
+			defer wg.Done()
+			if _, err := GetTask(ctx, pa, 1, id); err != nil {
+				t.Error(err)
+			}
+		}(ID(i))
+	}
+	wg.Wait()
+
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.ETag, `"/api/v5/1/tasks/`) {
+		t.Fatalf("expected the response of one of the calls, got %+v", resp)
+	}
+}
+
+func TestHeadAndOptions(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files/5" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodOptions {
+			w.Header().Set("Allow", "GET, HEAD, OPTIONS")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Length", "1024")
+		w.Header().Set("Content-Type", "application/pdf")
+		if r.Method == http.MethodGet {
+			w.Write(make([]byte, 1024))
+		}
+	})
+
+	ctx := context.Background()
+	resp, err := Head(ctx, pa, pa.baseURL+"/files/5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || resp.ContentLength != 1024 || resp.Header.Get("Content-Type") != "application/pdf" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	if _, err := Head(ctx, pa, pa.baseURL+"/files/6"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	resp, err = Options(ctx, pa, pa.baseURL+"/files/5")
+	if err != nil || resp.Header.Get("Allow") != "GET, HEAD, OPTIONS" {
+		t.Errorf("unexpected response %+v, %v", resp, err)
+	}
+}
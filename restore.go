@@ -0,0 +1,210 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RestoreOptions configures Restore.
+type RestoreOptions struct {
+	// DryRun reads the target workspace and works out what would be created,
+	// without creating anything. New IDs are reported as 0.
+	DryRun bool
+
+	// MemberIds maps workspace member IDs in the archive to member IDs in the
+	// target workspace. Members missing from the map are matched by email.
+	// Assignees that can't be matched are dropped from their tasks.
+	MemberIds map[ID]ID
+
+	// Progress, if set, receives the progress of the restore, in phases named
+	// after the resources created: "tags", "projects", "milestones" and
+	// "tasks".
+	Progress ProgressFunc
+}
+
+// RestoreResult reports what Restore created.
+// The ID maps go from IDs in the archive to IDs in the target workspace.
+type RestoreResult struct {
+	Projects   map[ID]ID
+	Milestones map[ID]ID
+	Tasks      map[ID]ID
+	Tags       map[ID]ID
+
+	// UnmatchedMembers lists archived member IDs that couldn't be mapped to the target workspace.
+	UnmatchedMembers []ID
+}
+
+// Restore recreates the projects, milestones, tags and tasks of an archive
+// written by Backup in a workspace, which can be the original workspace or
+// another one (to clone it).
+//
+// Since the target workspace assigns new IDs, references between resources
+// (e.g. a task's project) are remapped as they are created. Tags that already
+// exist in the target workspace (by name) are reused. Members, groups and
+// comments are not recreated.
+//
+// If an error occurs part way, the result reports what was created so far.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the target workspace
+//	r: Source of the archive
+//	options: Restore settings (use `togglplanapi.RestoreOptions{}` for the defaults)
+func Restore(ctx context.Context, pa *togglPlanApi, workspaceId ID, r io.Reader, options RestoreOptions) (*RestoreResult, error) {
+	archive, err := ReadArchive(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RestoreResult{
+		Projects:   map[ID]ID{},
+		Milestones: map[ID]ID{},
+		Tasks:      map[ID]ID{},
+		Tags:       map[ID]ID{},
+	}
+
+	members, err := mapMembers(ctx, pa, workspaceId, archive, options, result)
+	if err != nil {
+		return result, err
+	}
+
+	existingTags, err := GetTags(ctx, pa, workspaceId)
+	if err != nil {
+		return result, fmt.Errorf("reading tags: %w", err)
+	}
+
+	tagIds := map[string]ID{}
+	for _, tag := range existingTags {
+		tagIds[strings.ToLower(tag.Name)] = tag.Id
+	}
+
+	for i, tag := range archive.Tags {
+		options.Progress.Report("tags", i, len(archive.Tags))
+		if id, ok := tagIds[strings.ToLower(tag.Name)]; ok {
+			result.Tags[tag.Id] = id
+			continue
+		}
+
+		result.Tags[tag.Id] = 0
+		if options.DryRun {
+			continue
+		}
+
+		created, err := CreateTag(ctx, pa, workspaceId, TagParams{Name: tag.Name, Color: tag.Color})
+		if err != nil {
+			return result, fmt.Errorf("restoring tag %d: %w", tag.Id, err)
+		}
+		result.Tags[tag.Id] = created.Id
+	}
+
+	for i, project := range archive.Projects {
+		options.Progress.Report("projects", i, len(archive.Projects))
+		result.Projects[project.Id] = 0
+		if options.DryRun {
+			continue
+		}
+
+		created, err := CreateProject(ctx, pa, workspaceId, ProjectParams{
+			Name:      project.Name,
+			Notes:     project.Notes,
+			Color:     project.Color,
+			StartDate: project.StartDate,
+			EndDate:   project.EndDate,
+		})
+		if err != nil {
+			return result, fmt.Errorf("restoring project %d: %w", project.Id, err)
+		}
+		result.Projects[project.Id] = created.Id
+	}
+
+	for i, milestone := range archive.Milestones {
+		options.Progress.Report("milestones", i, len(archive.Milestones))
+		result.Milestones[milestone.Id] = 0
+		if options.DryRun {
+			continue
+		}
+
+		created, err := CreateMilestone(ctx, pa, workspaceId, MilestoneParams{
+			Name:      milestone.Name,
+			Date:      milestone.Date,
+			ProjectId: result.Projects[milestone.ProjectId],
+		})
+		if err != nil {
+			return result, fmt.Errorf("restoring milestone %d: %w", milestone.Id, err)
+		}
+		result.Milestones[milestone.Id] = created.Id
+	}
+
+	for i, task := range archive.Tasks {
+		options.Progress.Report("tasks", i, len(archive.Tasks))
+		result.Tasks[task.Id] = 0
+		if options.DryRun {
+			continue
+		}
+
+		var assignees []ID
+		for _, id := range task.Assignees {
+			if mapped, ok := members[id]; ok {
+				assignees = append(assignees, mapped)
+			}
+		}
+
+		created, err := CreateTask(ctx, pa, workspaceId, TaskParams{
+			Name:             task.Name,
+			Notes:            task.Notes,
+			StartDate:        task.StartDate,
+			EndDate:          task.EndDate,
+			StartTime:        task.StartTime,
+			EndTime:          task.EndTime,
+			Color:            task.Color,
+			EstimatedMinutes: task.EstimatedMinutes,
+			Done:             task.Done,
+			ProjectId:        result.Projects[task.ProjectId],
+			MilestoneId:      result.Milestones[task.MilestoneId],
+			Assignees:        assignees,
+			Tags:             task.Tags,
+			Checklist:        task.Checklist,
+			Position:         task.Position,
+		})
+		if err != nil {
+			return result, fmt.Errorf("restoring task %d: %w", task.Id, err)
+		}
+		result.Tasks[task.Id] = created.Id
+	}
+	options.Progress.Report("tasks", len(archive.Tasks), len(archive.Tasks))
+
+	return result, nil
+}
+
+// mapMembers maps the members of an archive to members of the target
+// workspace, using the explicit mapping in options first and emails second.
+// Members that can't be mapped are recorded in result.
+func mapMembers(ctx context.Context, pa *togglPlanApi, workspaceId ID, archive *Archive, options RestoreOptions, result *RestoreResult) (map[ID]ID, error) {
+	targetMembers, err := GetMembers(ctx, pa, workspaceId)
+	if err != nil {
+		return nil, fmt.Errorf("reading members: %w", err)
+	}
+
+	byEmail := map[string]ID{}
+	for _, member := range targetMembers {
+		if member.Email != "" {
+			byEmail[strings.ToLower(member.Email)] = member.Id
+		}
+	}
+
+	members := map[ID]ID{}
+	for _, member := range archive.Members {
+		if id, ok := options.MemberIds[member.Id]; ok {
+			members[member.Id] = id
+		} else if id, ok := byEmail[strings.ToLower(member.Email)]; ok && member.Email != "" {
+			members[member.Id] = id
+		} else {
+			result.UnmatchedMembers = append(result.UnmatchedMembers, member.Id)
+		}
+	}
+
+	return members, nil
+}
@@ -0,0 +1,90 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const testArchive = `{
+	"version": 1,
+	"workspace_id": 1,
+	"projects": [{"id": 10, "name": "Website"}],
+	"milestones": [{"id": 20, "name": "Launch", "date": "2024-03-31", "project_id": 10}],
+	"tags": [{"id": 30, "name": "Urgent"}, {"id": 31, "name": "Design"}],
+	"members": [{"id": 40, "email": "ada@example.com"}, {"id": 41, "email": "gone@example.com"}],
+	"tasks": [{"id": 50, "name": "Mockups", "project_id": 10, "milestone_id": 20, "workspace_members": [40, 41], "tags": ["Design"]}]
+}`
+
+func TestRestore(t *testing.T) {
+	var createdTask TaskParams
+	var created []string
+
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			switch r.URL.Path {
+			case "/api/v5/2/members":
+				fmt.Fprint(w, `[{"id":400,"email":"Ada@example.com"}]`)
+			case "/api/v5/2/tags":
+				fmt.Fprint(w, `[{"id":300,"name":"urgent"}]`)
+			}
+			return
+		}
+
+		created = append(created, r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v5/2/tags":
+			fmt.Fprint(w, `{"id":301}`)
+		case "/api/v5/2/projects":
+			fmt.Fprint(w, `{"id":100}`)
+		case "/api/v5/2/milestones":
+			fmt.Fprint(w, `{"id":200}`)
+		case "/api/v5/2/tasks":
+			json.NewDecoder(r.Body).Decode(&createdTask)
+			fmt.Fprint(w, `{"id":500}`)
+		}
+	})
+
+	result, err := Restore(context.Background(), pa, 2, strings.NewReader(testArchive), RestoreOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[/api/v5/2/tags /api/v5/2/projects /api/v5/2/milestones /api/v5/2/tasks]"
+	if fmt.Sprint(created) != expected {
+		t.Fatalf("created %v, expected %v", created, expected)
+	}
+
+	if result.Tags[30] != 300 || result.Tags[31] != 301 || result.Tasks[50] != 500 {
+		t.Fatalf("unexpected ID maps %+v", result)
+	}
+
+	if createdTask.ProjectId != 100 || createdTask.MilestoneId != 200 || fmt.Sprint(createdTask.Assignees) != "[400]" {
+		t.Fatalf("task references not remapped: %+v", createdTask)
+	}
+
+	if fmt.Sprint(result.UnmatchedMembers) != "[41]" {
+		t.Fatalf("unexpected unmatched members %v", result.UnmatchedMembers)
+	}
+}
+
+func TestRestoreDryRun(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("dry run sent %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `[]`)
+	})
+
+	result, err := Restore(context.Background(), pa, 2, strings.NewReader(testArchive), RestoreOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Projects) != 1 || len(result.Tasks) != 1 || len(result.Tags) != 2 {
+		t.Fatalf("dry run didn't report planned resources: %+v", result)
+	}
+}
@@ -0,0 +1,44 @@
+package togglplanapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Retry policy of the requests of a client, shared by both retry
+// implementations. See retry_default.go and retry_nohashicorp.go.
+const (
+	retryMax     = 5
+	retryWaitMin = 1 * time.Second
+	retryWaitMax = 30 * time.Second
+)
+
+// attemptsKey is the context key of the attempt counter of a request.
+type attemptsKey struct{}
+
+// countAttempt records that attempt, counted from 0, of req is being sent,
+// so that errors can report the attempts of a request.
+func countAttempt(req *http.Request, attempt int) {
+	if attempts, ok := req.Context().Value(attemptsKey{}).(*int); ok {
+		*attempts = attempt + 1
+	}
+}
+
+// checkRetry reports whether an attempt of a request made with ctx, which
+// got resp or failed with err, is retried: on rate limits, server errors and
+// network errors. It returns the error to report if retries stop there.
+func checkRetry(ctx context.Context, resp *http.Response, err error) (bool, error) {
+	var retry bool
+	if err != nil {
+		retry = retryableNetworkError(err)
+	} else {
+		retry = retryableStatus(resp.StatusCode)
+	}
+	if retry {
+		if replayErr := checkReplay(ctx, resp, err); replayErr != nil {
+			return false, replayErr
+		}
+	}
+	return retry, err
+}
@@ -0,0 +1,61 @@
+//go:build !nohashicorp
+
+package togglplanapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// retryClient sends requests with their retries, with go-retryablehttp.
+// Build with the nohashicorp tag to use the built-in retries instead.
+type retryClient = retryablehttp.Client
+
+// retryRequest is a request that retryClient can send again.
+type retryRequest = retryablehttp.Request
+
+// newRetryClient returns the client retrying the requests of pa on rate
+// limits, server errors and network errors. It is built once, and counts the
+// attempts of each request in its context.
+func newRetryClient(pa *togglPlanApi) *retryClient {
+	client := retryablehttp.NewClient()
+	client.HTTPClient = pa.httpClient
+	client.CheckRetry = checkRetry
+
+	// Once retries run out, hand back the last response so that it is
+	// reported as an *APIError like any other error status
+	client.ErrorHandler = retryablehttp.PassthroughErrorHandler
+
+	client.RetryMax = retryMax
+	client.RetryWaitMin = retryWaitMin
+	client.RetryWaitMax = retryWaitMax
+
+	// Keep count of the attempts, so that errors can report them
+	client.RequestLogHook = func(_ retryablehttp.Logger, req *http.Request, attempt int) {
+		countAttempt(req, attempt)
+	}
+	return client
+}
+
+// newRetryRequest builds a request whose body is resent as is by retries.
+func newRetryRequest(ctx context.Context, method string, url string, body []byte) (*retryRequest, error) {
+	return retryablehttp.NewRequestWithContext(ctx, method, url, body)
+}
+
+// wrapRequest wraps req, whose body is left unset, so that retries send the
+// body returned by a new call to body, of contentLength bytes. A nil body
+// sends none.
+func wrapRequest(req *http.Request, body func() (io.Reader, error), contentLength int64) (*retryRequest, error) {
+	retryable, err := retryablehttp.FromRequest(req)
+	if err != nil || body == nil {
+		return retryable, err
+	}
+	if err := retryable.SetBody(retryablehttp.ReaderFunc(body)); err != nil {
+		return nil, err
+	}
+	retryable.ContentLength = contentLength
+	return retryable, nil
+}
@@ -0,0 +1,159 @@
+//go:build nohashicorp
+
+package togglplanapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+)
+
+// retryClient sends requests with their retries, without go-retryablehttp.
+// It follows the same policy: up to RetryMax retries, waiting the delay of
+// Retry-After on 429 and 503 responses, or else a delay doubling from
+// RetryWaitMin up to RetryWaitMax.
+type retryClient struct {
+	HTTPClient   *http.Client
+	RetryMax     int
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+}
+
+// retryRequest is a request that retryClient can send again. body returns
+// the body of each attempt, or is nil for requests without one.
+type retryRequest struct {
+	*http.Request
+	body func() (io.Reader, error)
+}
+
+// WithContext returns a shallow copy of r with its context changed to ctx.
+func (r *retryRequest) WithContext(ctx context.Context) *retryRequest {
+	return &retryRequest{Request: r.Request.WithContext(ctx), body: r.body}
+}
+
+// newRetryClient returns the client retrying the requests of pa on rate
+// limits, server errors and network errors. It is built once, and counts the
+// attempts of each request in its context.
+func newRetryClient(pa *togglPlanApi) *retryClient {
+	return &retryClient{
+		HTTPClient:   pa.httpClient,
+		RetryMax:     retryMax,
+		RetryWaitMin: retryWaitMin,
+		RetryWaitMax: retryWaitMax,
+	}
+}
+
+// newRetryRequest builds a request whose body is resent as is by retries.
+func newRetryRequest(ctx context.Context, method string, url string, body []byte) (*retryRequest, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return wrapRequest(req, func() (io.Reader, error) {
+		return bytes.NewReader(body), nil
+	}, int64(len(body)))
+}
+
+// wrapRequest wraps req, whose body is left unset, so that retries send the
+// body returned by a new call to body, of contentLength bytes. A nil body
+// sends none.
+func wrapRequest(req *http.Request, body func() (io.Reader, error), contentLength int64) (*retryRequest, error) {
+	if body == nil {
+		return &retryRequest{Request: req}, nil
+	}
+	req.ContentLength = contentLength
+	req.GetBody = func() (io.ReadCloser, error) {
+		reader, err := body()
+		if err != nil {
+			return nil, err
+		}
+		return readCloser(reader), nil
+	}
+	return &retryRequest{Request: req, body: body}, nil
+}
+
+// readCloser returns reader, or reader with a Close method that does nothing
+// if it has none.
+func readCloser(reader io.Reader) io.ReadCloser {
+	if rc, ok := reader.(io.ReadCloser); ok {
+		return rc
+	}
+	return io.NopCloser(reader)
+}
+
+// Do sends req until it succeeds, fails in a way that isn't retried, or runs
+// out of retries. Once retries run out, it returns the last response, so
+// that it is reported as an *APIError like any other error status.
+func (c *retryClient) Do(req *retryRequest) (*http.Response, error) {
+	var resp *http.Response
+	var retry bool
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		// Rewind the body for each attempt
+		if req.body != nil {
+			body, bodyErr := req.body()
+			if bodyErr != nil {
+				c.HTTPClient.CloseIdleConnections()
+				return resp, bodyErr
+			}
+			req.Body = readCloser(body)
+		}
+		countAttempt(req.Request, attempt)
+
+		var doErr error
+		resp, doErr = c.HTTPClient.Do(req.Request)
+		retry, err = checkRetry(req.Context(), resp, doErr)
+		if !retry || attempt >= c.RetryMax {
+			break
+		}
+
+		// Consume the response, so that its connection is reused
+		if doErr == nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(c.backoff(attempt, resp))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			c.HTTPClient.CloseIdleConnections()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		// Copy the request, as the transport may still be closing the body
+		// of the last attempt
+		copied := *req.Request
+		req.Request = &copied
+	}
+
+	if err != nil || retry {
+		c.HTTPClient.CloseIdleConnections()
+	}
+	return resp, err
+}
+
+// backoff returns the delay before retrying attempt, counted from 0, which
+// got resp: the delay of its Retry-After header on 429 Too Many Requests and
+// 503 Service Unavailable, or else RetryWaitMin doubled for each attempt, up
+// to RetryWaitMax.
+func (c *retryClient) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if wait := retryAfter(resp); wait > 0 {
+			return wait
+		}
+	}
+
+	wait := c.RetryWaitMin
+	for i := 0; i < attempt && wait < c.RetryWaitMax; i++ {
+		wait *= 2
+	}
+	if wait > c.RetryWaitMax {
+		wait = c.RetryWaitMax
+	}
+	return wait
+}
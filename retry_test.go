@@ -0,0 +1,73 @@
+package togglplanapi
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRetryPolicy runs against both retry implementations:
+//
+//	go test -run TestRetryPolicy . && go test -tags nohashicorp -run TestRetryPolicy .
+func TestRetryPolicy(t *testing.T) {
+	var bodies []string
+	status := http.StatusServiceUnavailable
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(status)
+	})
+	pa.retryClient.RetryWaitMin = time.Millisecond
+	pa.retryClient.RetryWaitMax = 2 * time.Millisecond
+
+	_, err := Post(pa, pa.baseURL, []byte("payload"))
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Attempts != 6 {
+		t.Fatalf("expected the last 503 after 6 attempts, got %v", err)
+	}
+	if len(bodies) != 6 || strings.Join(bodies, ",") != strings.Repeat("payload,", 5)+"payload" {
+		t.Fatalf("expected the body on every attempt, got %q", bodies)
+	}
+
+	bodies = nil
+	status = http.StatusUnprocessableEntity
+	if _, err := Post(pa, pa.baseURL, []byte("payload")); err == nil || len(bodies) != 1 {
+		t.Fatalf("expected a 422 to fail without retries, got %v after %d attempts", err, len(bodies))
+	}
+}
+
+// TestRetriesExhausted checks that the last response is reported once the
+// retries run out, so that callers such as the offline queue can tell a rate
+// limit or an outage from other failures.
+func TestRetriesExhausted(t *testing.T) {
+	attempts := 0
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, `{"message":"slow down %d"}`, attempts)
+	})
+	pa.retryClient.RetryWaitMin = time.Millisecond
+	pa.retryClient.RetryWaitMax = 2 * time.Millisecond
+
+	_, err := Get(pa, pa.baseURL)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected an *APIError with the last status, got %v", err)
+	}
+	if !strings.Contains(apiErr.Body, "slow down 6") || apiErr.Attempts != 6 {
+		t.Fatalf("expected the body of the 6th and last attempt, got %q after %d attempts", apiErr.Body, apiErr.Attempts)
+	}
+}
+
+func TestRetryDefaults(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+	client := newRetryClient(pa)
+	if client.RetryMax != 5 || client.RetryWaitMin != time.Second || client.RetryWaitMax != 30*time.Second {
+		t.Fatalf("unexpected policy %d retries from %s to %s", client.RetryMax, client.RetryWaitMin, client.RetryWaitMax)
+	}
+}
@@ -0,0 +1,119 @@
+package togglplanapi
+
+import (
+	"context"
+)
+
+// Role is the access level of a member in a workspace.
+type Role string
+
+// Workspace roles. Owners and admins manage the workspace and its members,
+// regular members plan their own work, and viewers can only look.
+const (
+	RoleOwner   Role = "owner"
+	RoleAdmin   Role = "admin"
+	RoleRegular Role = "regular"
+	RoleViewer  Role = "viewer"
+)
+
+// Valid reports whether r is a known workspace role.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleOwner, RoleAdmin, RoleRegular, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// ProjectRole is the access level of a member in a project.
+type ProjectRole string
+
+// Project roles. Managers change the project and its members, editors plan
+// its tasks and milestones, and viewers can only look.
+const (
+	ProjectRoleManager ProjectRole = "manager"
+	ProjectRoleEditor  ProjectRole = "editor"
+	ProjectRoleViewer  ProjectRole = "viewer"
+)
+
+// Valid reports whether r is a known project role.
+func (r ProjectRole) Valid() bool {
+	switch r {
+	case ProjectRoleManager, ProjectRoleEditor, ProjectRoleViewer:
+		return true
+	}
+	return false
+}
+
+// ProjectMember is the access of a workspace member to a project.
+type ProjectMember struct {
+	MemberId ID          `json:"workspace_member_id"`
+	Role     ProjectRole `json:"role"`
+}
+
+// roleUpdate is the body of a role change.
+type roleUpdate struct {
+	Role Role `json:"role"`
+}
+
+// SetMemberRole changes the role of a member in a workspace, and returns the
+// updated member.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	memberId: ID of the workspace member
+//	role: New role of the member
+func SetMemberRole(ctx context.Context, pa *togglPlanApi, workspaceId ID, memberId ID, role Role) (*Member, error) {
+	var member Member
+	if err := sendJSON(ctx, pa, "PUT", workspacePath(workspaceId, "/members/%d", memberId), nil, roleUpdate{Role: role}, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// GetProjectMembers fetches the members with access to a project, and their roles.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	projectId: ID of the project
+func GetProjectMembers(ctx context.Context, pa *togglPlanApi, workspaceId ID, projectId ID) ([]ProjectMember, error) {
+	var members []ProjectMember
+	err := getJSON(ctx, pa, workspacePath(workspaceId, "/projects/%d/members", projectId), nil, &members)
+	return members, err
+}
+
+// SetProjectMemberRole gives a workspace member access to a project with the
+// given role, or changes their role if they already have access.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	projectId: ID of the project
+//	memberId: ID of the workspace member
+//	role: Role of the member in the project
+func SetProjectMemberRole(ctx context.Context, pa *togglPlanApi, workspaceId ID, projectId ID, memberId ID, role ProjectRole) (*ProjectMember, error) {
+	var member ProjectMember
+	params := ProjectMember{MemberId: memberId, Role: role}
+	if err := sendJSON(ctx, pa, "PUT", workspacePath(workspaceId, "/projects/%d/members/%d", projectId, memberId), nil, params, &member); err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// RemoveProjectMember takes away the access of a member to a project. The
+// member stays in the workspace.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	projectId: ID of the project
+//	memberId: ID of the workspace member
+func RemoveProjectMember(ctx context.Context, pa *togglPlanApi, workspaceId ID, projectId ID, memberId ID) error {
+	return sendJSON(ctx, pa, "DELETE", workspacePath(workspaceId, "/projects/%d/members/%d", projectId, memberId), nil, nil, nil)
+}
@@ -0,0 +1,40 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestSetProjectMemberRole(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/api/v5/1/projects/2/members/3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var member ProjectMember
+		json.NewDecoder(r.Body).Decode(&member)
+		json.NewEncoder(w).Encode(member)
+	})
+
+	member, err := SetProjectMemberRole(context.Background(), pa, 1, 2, 3, ProjectRoleEditor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if member.MemberId != 3 || member.Role != ProjectRoleEditor {
+		t.Fatalf("unexpected member %+v", member)
+	}
+}
+
+func TestSetMemberRoleInvalid(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("invalid role was sent")
+	})
+
+	var invalid *ValidationError
+	if _, err := SetMemberRole(context.Background(), pa, 1, 2, Role("superuser")); !errors.As(err, &invalid) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package schedule
+
+import (
+	"sort"
+	"time"
+
+	"togglplanapi"
+)
+
+// ConflictKind tells what a Conflict is about.
+type ConflictKind string
+
+const (
+	// OverlappingTasks is reported for two timed tasks of the same assignee
+	// whose times overlap.
+	OverlappingTasks ConflictKind = "overlapping_tasks"
+	// DoubleBookedMilestones is reported for milestones of the same project
+	// falling on the same day.
+	DoubleBookedMilestones ConflictKind = "double_booked_milestones"
+)
+
+// Conflict is a problem found in a schedule by FindConflicts.
+type Conflict struct {
+	Kind ConflictKind
+	// Date is the day the conflict starts on.
+	Date togglplanapi.Date
+
+	// MemberId and TaskIds are set for OverlappingTasks. From and To are
+	// the period both tasks take. Toggl Plan times have no time zone, so
+	// they are expressed in UTC.
+	MemberId togglplanapi.ID
+	TaskIds  []togglplanapi.ID
+	From     time.Time
+	To       time.Time
+
+	// ProjectId and MilestoneIds are set for DoubleBookedMilestones.
+	// ProjectId is 0 for milestones outside of any project.
+	ProjectId    togglplanapi.ID
+	MilestoneIds []togglplanapi.ID
+}
+
+// FindConflicts returns the overlapping timed tasks of each assignee, and
+// the milestones of a project falling on the same day, sorted by date.
+// Tasks that are done, all-day tasks and unassigned tasks never conflict.
+// A timed task runs from its start time on its start date to its end time
+// on its end date.
+func FindConflicts(tasks []togglplanapi.Task, milestones []togglplanapi.Milestone) []Conflict {
+	var conflicts []Conflict
+
+	type slot struct {
+		taskId   togglplanapi.ID
+		from, to time.Time
+	}
+	slots := map[togglplanapi.ID][]slot{}
+	for _, task := range tasks {
+		from, to, ok := taskPeriod(task)
+		if !ok || task.Done {
+			continue
+		}
+		for _, memberId := range task.Assignees {
+			slots[memberId] = append(slots[memberId], slot{taskId: task.Id, from: from, to: to})
+		}
+	}
+
+	for memberId, memberSlots := range slots {
+		sort.Slice(memberSlots, func(i, j int) bool {
+			return memberSlots[i].from.Before(memberSlots[j].from)
+		})
+
+		for i, a := range memberSlots {
+			for _, b := range memberSlots[i+1:] {
+				if !b.from.Before(a.to) {
+					// Later slots start even later
+					break
+				}
+
+				to := a.to
+				if b.to.Before(to) {
+					to = b.to
+				}
+				conflicts = append(conflicts, Conflict{
+					Kind:     OverlappingTasks,
+					Date:     togglplanapi.DateOf(b.from),
+					MemberId: memberId,
+					TaskIds:  []togglplanapi.ID{a.taskId, b.taskId},
+					From:     b.from,
+					To:       to,
+				})
+			}
+		}
+	}
+
+	type booking struct {
+		projectId togglplanapi.ID
+		date      togglplanapi.Date
+	}
+	bookings := map[booking][]togglplanapi.ID{}
+	var order []booking
+	for _, milestone := range milestones {
+		if milestone.Date.IsZero() {
+			continue
+		}
+		key := booking{projectId: milestone.ProjectId, date: milestone.Date}
+		if _, ok := bookings[key]; !ok {
+			order = append(order, key)
+		}
+		bookings[key] = append(bookings[key], milestone.Id)
+	}
+
+	for _, key := range order {
+		if ids := bookings[key]; len(ids) > 1 {
+			conflicts = append(conflicts, Conflict{
+				Kind:         DoubleBookedMilestones,
+				Date:         key.date,
+				ProjectId:    key.projectId,
+				MilestoneIds: ids,
+			})
+		}
+	}
+
+	sort.SliceStable(conflicts, func(i, j int) bool {
+		a, b := conflicts[i], conflicts[j]
+		if a.Date != b.Date {
+			return a.Date.Before(b.Date)
+		}
+		if a.MemberId != b.MemberId {
+			return a.MemberId < b.MemberId
+		}
+		return a.From.Before(b.From)
+	})
+
+	return conflicts
+}
+
+// taskPeriod returns when a timed task starts and ends.
+func taskPeriod(task togglplanapi.Task) (time.Time, time.Time, bool) {
+	if task.StartDate.IsZero() || task.EndDate.IsZero() || task.StartTime == "" || task.EndTime == "" {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start, err := time.Parse("15:04", task.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	end, err := time.Parse("15:04", task.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	from := task.StartDate.At(start.Hour(), start.Minute(), time.UTC)
+	to := task.EndDate.At(end.Hour(), end.Minute(), time.UTC)
+	if !to.After(from) {
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
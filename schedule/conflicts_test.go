@@ -0,0 +1,46 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"togglplanapi"
+)
+
+func TestFindConflicts(t *testing.T) {
+	monday := togglplanapi.NewDate(2024, 3, 4)
+
+	tasks := []togglplanapi.Task{
+		{Id: 1, Assignees: []togglplanapi.ID{3}, StartDate: monday, EndDate: monday, StartTime: "09:00", EndTime: "10:30"},
+		{Id: 2, Assignees: []togglplanapi.ID{3, 4}, StartDate: monday, EndDate: monday, StartTime: "10:00", EndTime: "11:00"},
+		// Back to back with task 2, not overlapping
+		{Id: 3, Assignees: []togglplanapi.ID{3}, StartDate: monday, EndDate: monday, StartTime: "11:00", EndTime: "12:00"},
+		// All-day and done tasks never conflict
+		{Id: 4, Assignees: []togglplanapi.ID{4}, StartDate: monday, EndDate: monday},
+		{Id: 5, Assignees: []togglplanapi.ID{4}, StartDate: monday, EndDate: monday, StartTime: "10:15", EndTime: "10:45", Done: true},
+	}
+
+	milestones := []togglplanapi.Milestone{
+		{Id: 10, ProjectId: 7, Date: monday.AddDays(1)},
+		{Id: 11, ProjectId: 7, Date: monday.AddDays(1)},
+		{Id: 12, ProjectId: 8, Date: monday.AddDays(1)},
+	}
+
+	conflicts := FindConflicts(tasks, milestones)
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", conflicts)
+	}
+
+	overlap := conflicts[0]
+	if overlap.Kind != OverlappingTasks || overlap.MemberId != 3 || overlap.TaskIds[0] != 1 || overlap.TaskIds[1] != 2 {
+		t.Fatalf("unexpected overlap %+v", overlap)
+	}
+	if !overlap.From.Equal(time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)) || !overlap.To.Equal(time.Date(2024, 3, 4, 10, 30, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected overlap period %s - %s", overlap.From, overlap.To)
+	}
+
+	booking := conflicts[1]
+	if booking.Kind != DoubleBookedMilestones || booking.ProjectId != 7 || len(booking.MilestoneIds) != 2 {
+		t.Fatalf("unexpected booking %+v", booking)
+	}
+}
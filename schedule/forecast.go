@@ -0,0 +1,240 @@
+package schedule
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"togglplanapi"
+)
+
+// ForecastOptions configures ComputeForecast. The embedded WorkloadOptions
+// set the date range, the capacity of members and the calendar.
+type ForecastOptions struct {
+	WorkloadOptions
+
+	// TimeOff removes the capacity of members on the days they are away.
+	TimeOff []togglplanapi.TimeOff
+
+	// Members are included even if nothing is planned for them, so that their
+	// free capacity shows. Assigned members are always included.
+	Members []togglplanapi.ID
+
+	// Threshold is the utilization above which a week is overallocated.
+	// Defaults to 1, i.e. more hours planned than can be worked.
+	Threshold float64
+
+	// SundayFirst starts weeks on Sunday instead of Monday.
+	SundayFirst bool
+}
+
+// WeekForecast is the capacity and the demand of a member over a week.
+type WeekForecast struct {
+	// Start is the first day of the week, and End its last, both within the
+	// range of the forecast: the first and last weeks may be partial.
+	Start togglplanapi.Date
+	End   togglplanapi.Date
+
+	// Workdays is the number of working days in the week, and TimeOffDays
+	// those the member is away.
+	Workdays    int
+	TimeOffDays int
+
+	// CapacityHours is the number of hours the member can work, and
+	// DemandHours the number of hours planned, time off included.
+	CapacityHours float64
+	DemandHours   float64
+
+	// TaskIds lists the tasks planned in the week.
+	TaskIds []togglplanapi.ID
+
+	Overallocated bool
+}
+
+// AvailableHours returns the capacity left once demand is met, negative if
+// the member is overallocated.
+func (week WeekForecast) AvailableHours() float64 {
+	return week.CapacityHours - week.DemandHours
+}
+
+// Utilization returns the demand as a fraction of the capacity. It is 0 if
+// nothing is planned, and +Inf if work is planned without any capacity.
+func (week WeekForecast) Utilization() float64 {
+	switch {
+	case week.DemandHours == 0:
+		return 0
+	case week.CapacityHours == 0:
+		return math.Inf(1)
+	}
+	return week.DemandHours / week.CapacityHours
+}
+
+// MemberForecast is the forecast of a member, week by week.
+type MemberForecast struct {
+	MemberId togglplanapi.ID
+	Weeks    []WeekForecast
+}
+
+// Overallocated returns the weeks the member is overallocated.
+func (member MemberForecast) Overallocated() []WeekForecast {
+	var weeks []WeekForecast
+	for _, week := range member.Weeks {
+		if week.Overallocated {
+			weeks = append(weeks, week)
+		}
+	}
+	return weeks
+}
+
+// Utilization returns the demand of the member over the whole forecast as a
+// fraction of their capacity, like WeekForecast.Utilization.
+func (member MemberForecast) Utilization() float64 {
+	total := WeekForecast{}
+	for _, week := range member.Weeks {
+		total.CapacityHours += week.CapacityHours
+		total.DemandHours += week.DemandHours
+	}
+	return total.Utilization()
+}
+
+// Forecast is the capacity and the demand of members per week.
+type Forecast struct {
+	// Weeks holds the first day of each week of the forecast.
+	Weeks []togglplanapi.Date
+	// Members holds a row per member, sorted by ID. Each row has a
+	// WeekForecast for each of Weeks.
+	Members []MemberForecast
+}
+
+// Member returns the row of a member.
+func (forecast Forecast) Member(memberId togglplanapi.ID) (MemberForecast, bool) {
+	for _, member := range forecast.Members {
+		if member.MemberId == memberId {
+			return member, true
+		}
+	}
+	return MemberForecast{}, false
+}
+
+// Overallocated returns the rows of the members overallocated in at least
+// one week.
+func (forecast Forecast) Overallocated() []MemberForecast {
+	var members []MemberForecast
+	for _, member := range forecast.Members {
+		if len(member.Overallocated()) > 0 {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// FetchForecast fetches the tasks and the time off of a workspace between
+// options.Since and options.Until, and computes their forecast. Time off
+// already in options is kept.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	options: Date range, capacity and allocation settings
+func FetchForecast(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, options ForecastOptions) (Forecast, error) {
+	tasks, err := togglplanapi.ListAllTasks(ctx, pa, workspaceId, togglplanapi.TaskFilter{Since: options.Since, Until: options.Until})
+	if err != nil {
+		return Forecast{}, err
+	}
+
+	timeOffs, err := togglplanapi.GetTimeOffs(ctx, pa, workspaceId, options.Since, options.Until)
+	if err != nil {
+		return Forecast{}, err
+	}
+	options.TimeOff = append(timeOffs, options.TimeOff...)
+
+	return ComputeForecast(tasks, options), nil
+}
+
+// ComputeForecast sums the workload of tasks per member and per week, as
+// computed by ComputeWorkload, and compares it to the capacity of members on
+// the working days they aren't away.
+//
+// Work planned during time off still counts as demand, so that it shows as
+// overallocation to be moved.
+func ComputeForecast(tasks []togglplanapi.Task, options ForecastOptions) Forecast {
+	if options.Capacity == 0 {
+		options.Capacity = defaultCapacity
+	}
+	if options.Threshold == 0 {
+		options.Threshold = 1
+	}
+
+	workload := ComputeWorkload(tasks, options.WorkloadOptions)
+	for _, memberId := range options.Members {
+		if _, ok := workload.Member(memberId); !ok {
+			workload.Members = append(workload.Members, emptyLoad(memberId, workload.Days, options.WorkloadOptions))
+		}
+	}
+	sort.Slice(workload.Members, func(i, j int) bool {
+		return workload.Members[i].MemberId < workload.Members[j].MemberId
+	})
+
+	weekStart := time.Monday
+	if options.SundayFirst {
+		weekStart = time.Sunday
+	}
+
+	var forecast Forecast
+	for _, day := range workload.Days {
+		if len(forecast.Weeks) == 0 || day.Weekday() == weekStart {
+			forecast.Weeks = append(forecast.Weeks, day)
+		}
+	}
+
+	for _, load := range workload.Members {
+		member := MemberForecast{MemberId: load.MemberId, Weeks: make([]WeekForecast, 0, len(forecast.Weeks))}
+
+		var week *WeekForecast
+		seen := map[togglplanapi.ID]bool{}
+		for _, day := range load.Days {
+			if week == nil || day.Date.Weekday() == weekStart {
+				member.Weeks = append(member.Weeks, WeekForecast{Start: day.Date})
+				week = &member.Weeks[len(member.Weeks)-1]
+				seen = map[togglplanapi.ID]bool{}
+			}
+			week.End = day.Date
+
+			if options.Calendar.IsWorkday(day.Date) {
+				week.Workdays++
+				if away(options.TimeOff, load.MemberId, day.Date) {
+					week.TimeOffDays++
+				} else {
+					week.CapacityHours += day.Capacity
+				}
+			}
+
+			week.DemandHours += day.Hours
+			for _, taskId := range day.TaskIds {
+				if !seen[taskId] {
+					seen[taskId] = true
+					week.TaskIds = append(week.TaskIds, taskId)
+				}
+			}
+		}
+
+		for i := range member.Weeks {
+			member.Weeks[i].Overallocated = member.Weeks[i].Utilization() > options.Threshold
+		}
+		forecast.Members = append(forecast.Members, member)
+	}
+
+	return forecast
+}
+
+// away reports whether a member has time off on date.
+func away(timeOffs []togglplanapi.TimeOff, memberId togglplanapi.ID, date togglplanapi.Date) bool {
+	for _, timeOff := range timeOffs {
+		if timeOff.MemberId == memberId && timeOff.Covers(date) {
+			return true
+		}
+	}
+	return false
+}
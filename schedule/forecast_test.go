@@ -0,0 +1,65 @@
+package schedule
+
+import (
+	"math"
+	"testing"
+
+	"togglplanapi"
+)
+
+func TestComputeForecast(t *testing.T) {
+	monday := togglplanapi.NewDate(2024, 3, 4)
+
+	tasks := []togglplanapi.Task{
+		// 8 hours a day over the first week, including the day off
+		{Id: 1, Assignees: []togglplanapi.ID{3}, StartDate: monday, EndDate: monday.AddDays(4), EstimatedMinutes: 40 * togglplanapi.EstimateHour},
+		{Id: 2, Assignees: []togglplanapi.ID{3}, StartDate: monday.AddDays(7), EndDate: monday.AddDays(8), EstimatedMinutes: 4 * togglplanapi.EstimateHour},
+	}
+
+	forecast := ComputeForecast(tasks, ForecastOptions{
+		WorkloadOptions: WorkloadOptions{Since: monday, Until: monday.AddDays(9)},
+		TimeOff:         []togglplanapi.TimeOff{{MemberId: 3, StartDate: monday.AddDays(4), EndDate: monday.AddDays(4)}},
+		Members:         []togglplanapi.ID{5, 3},
+	})
+
+	if len(forecast.Weeks) != 2 || forecast.Weeks[1] != monday.AddDays(7) {
+		t.Fatalf("unexpected weeks %v", forecast.Weeks)
+	}
+	if len(forecast.Members) != 2 || forecast.Members[0].MemberId != 3 {
+		t.Fatalf("unexpected members %+v", forecast.Members)
+	}
+
+	member, _ := forecast.Member(3)
+	first := member.Weeks[0]
+	if first.Workdays != 5 || first.TimeOffDays != 1 || first.CapacityHours != 32 || first.DemandHours != 40 {
+		t.Fatalf("unexpected first week %+v", first)
+	}
+	if !first.Overallocated || first.AvailableHours() != -8 || first.Utilization() != 1.25 {
+		t.Fatalf("expected the first week to be overallocated, got %+v", first)
+	}
+
+	second := member.Weeks[1]
+	if second.End != monday.AddDays(9) || second.Workdays != 3 || second.CapacityHours != 24 || second.DemandHours != 4 || second.Overallocated {
+		t.Fatalf("unexpected second week %+v", second)
+	}
+	if len(second.TaskIds) != 1 || second.TaskIds[0] != 2 {
+		t.Fatalf("expected task 2 once in the second week, got %v", second.TaskIds)
+	}
+
+	idle, _ := forecast.Member(5)
+	if idle.Utilization() != 0 || idle.Weeks[0].CapacityHours != 40 {
+		t.Fatalf("unexpected idle member %+v", idle)
+	}
+	if got := forecast.Overallocated(); len(got) != 1 || got[0].MemberId != 3 {
+		t.Fatalf("expected member 3 to be overallocated, got %+v", got)
+	}
+}
+
+func TestWeekForecastUtilization(t *testing.T) {
+	if got := (WeekForecast{DemandHours: 2}).Utilization(); !math.IsInf(got, 1) {
+		t.Fatalf("expected work without capacity to be infinite, got %v", got)
+	}
+	if got := (WeekForecast{CapacityHours: 40, DemandHours: 30}).Utilization(); got != 0.75 {
+		t.Fatalf("expected 0.75, got %v", got)
+	}
+}
@@ -0,0 +1,93 @@
+package schedule
+
+import (
+	"context"
+	"sort"
+
+	"togglplanapi"
+)
+
+// Move is a planned change of the dates of a task.
+type Move struct {
+	Task      togglplanapi.Task
+	StartDate togglplanapi.Date
+	EndDate   togglplanapi.Date
+}
+
+// Downstream returns the tasks of the same project as task that start after
+// its original end date, sorted by start date. These are the tasks that
+// would be pushed back if task slips.
+func Downstream(original togglplanapi.Task, tasks []togglplanapi.Task) []togglplanapi.Task {
+	var downstream []togglplanapi.Task
+	for _, task := range tasks {
+		if task.Id == original.Id || task.ProjectId != original.ProjectId || task.StartDate.IsZero() {
+			continue
+		}
+		if task.StartDate.After(original.EndDate) {
+			downstream = append(downstream, task)
+		}
+	}
+
+	sort.SliceStable(downstream, func(i, j int) bool {
+		return downstream[i].StartDate.Before(downstream[j].StartDate)
+	})
+	return downstream
+}
+
+// Slip plans moving downstream tasks by as many workdays of cal as a task
+// slipped, from the end date of original to that of slipped. Tasks keep
+// their length in workdays. The moves are only a preview until they are
+// passed to ApplyMoves.
+// A nil cal works Monday to Friday.
+func Slip(original togglplanapi.Task, slipped togglplanapi.Task, downstream []togglplanapi.Task, cal *togglplanapi.Calendar) []Move {
+	return Shift(downstream, cal.WorkdaysBetween(original.EndDate, slipped.EndDate), cal)
+}
+
+// Shift plans moving tasks by delta workdays of cal, or back if delta is
+// negative. Tasks keep their length in workdays, and undated tasks are left
+// out. A nil cal works Monday to Friday.
+func Shift(tasks []togglplanapi.Task, delta int, cal *togglplanapi.Calendar) []Move {
+	if delta == 0 {
+		return nil
+	}
+
+	var moves []Move
+	for _, task := range tasks {
+		if task.StartDate.IsZero() || task.EndDate.IsZero() {
+			continue
+		}
+
+		length := cal.WorkdaysBetween(task.StartDate, task.EndDate)
+		start := cal.AddWorkdays(task.StartDate, delta)
+		moves = append(moves, Move{
+			Task:      task,
+			StartDate: start,
+			EndDate:   cal.AddWorkdays(start, length),
+		})
+	}
+	return moves
+}
+
+// ApplyMoves updates the dates of the tasks planned by Shift or Slip, sending
+// at most concurrency updates at a time with togglplanapi.RunBatch. It returns
+// the errors in the same order as moves.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	moves: Planned moves
+//	concurrency: Maximum number of updates sent at the same time
+func ApplyMoves(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, moves []Move, concurrency int) []error {
+	jobs := make([]func(ctx context.Context) error, len(moves))
+	for i, move := range moves {
+		move := move
+		jobs[i] = func(ctx context.Context) error {
+			update := togglplanapi.TaskUpdate{StartDate: &move.StartDate, EndDate: &move.EndDate}
+			_, err := togglplanapi.UpdateTask(ctx, pa, workspaceId, move.Task.Id, update)
+			return err
+		}
+	}
+
+	return togglplanapi.RunBatch(ctx, concurrency, jobs)
+}
@@ -0,0 +1,42 @@
+package schedule
+
+import (
+	"testing"
+
+	"togglplanapi"
+)
+
+func TestSlip(t *testing.T) {
+	monday := togglplanapi.NewDate(2024, 3, 4)
+
+	original := togglplanapi.Task{Id: 1, ProjectId: 7, StartDate: monday, EndDate: monday.AddDays(1)}
+	slipped := original
+	slipped.EndDate = monday.AddDays(3)
+
+	tasks := []togglplanapi.Task{
+		original,
+		{Id: 3, ProjectId: 7, StartDate: monday.AddDays(4), EndDate: monday.AddDays(7)},
+		{Id: 2, ProjectId: 7, StartDate: monday.AddDays(2), EndDate: monday.AddDays(2)},
+		{Id: 4, ProjectId: 8, StartDate: monday.AddDays(2), EndDate: monday.AddDays(2)},
+		{Id: 5, ProjectId: 7, StartDate: monday, EndDate: monday},
+	}
+
+	downstream := Downstream(original, tasks)
+	if len(downstream) != 2 || downstream[0].Id != 2 || downstream[1].Id != 3 {
+		t.Fatalf("unexpected downstream tasks %+v", downstream)
+	}
+
+	moves := Slip(original, slipped, downstream, nil)
+	if len(moves) != 2 {
+		t.Fatalf("unexpected moves %+v", moves)
+	}
+
+	// Wednesday moves two working days to Friday
+	if moves[0].StartDate != monday.AddDays(4) || moves[0].EndDate != monday.AddDays(4) {
+		t.Fatalf("unexpected move %s - %s", moves[0].StartDate, moves[0].EndDate)
+	}
+	// Friday to Monday, two working days long, moves to Tuesday and Wednesday
+	if moves[1].StartDate != monday.AddDays(8) || moves[1].EndDate != monday.AddDays(9) {
+		t.Fatalf("unexpected move %s - %s", moves[1].StartDate, moves[1].EndDate)
+	}
+}
@@ -0,0 +1,331 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+
+	"togglplanapi"
+)
+
+// Dependency is a finish-to-start link between two tasks: To can't start
+// before From ends. Toggl Plan has no task dependencies, so they come from
+// elsewhere, such as a naming convention or the tool tasks were imported from.
+type Dependency struct {
+	From togglplanapi.ID
+	To   togglplanapi.ID
+}
+
+// TimelineOptions configures ProjectTimeline and MemberTimeline.
+type TimelineOptions struct {
+	// Since and Until clip the timeline, both inclusive. They default to the
+	// first and last day of the tasks and milestones.
+	Since togglplanapi.Date
+	Until togglplanapi.Date
+
+	// Dependencies link tasks of the timeline. Dependencies on tasks that
+	// aren't in the timeline are ignored.
+	Dependencies []Dependency
+
+	// Members gives member names to MemberTimeline rows. Unknown members
+	// are called "Member <id>".
+	Members []togglplanapi.Member
+}
+
+// Timeline is a normalized Gantt chart, ready to be drawn: rows of bars on
+// a range of days, milestones, and links between bars.
+type Timeline struct {
+	Since togglplanapi.Date
+	Until togglplanapi.Date
+
+	Rows       []Row
+	Milestones []Marker
+	Links      []Link
+
+	// CriticalPath lists the tasks that would delay the end of the linked
+	// tasks if they slipped, in order. It is empty without dependencies.
+	CriticalPath []togglplanapi.ID
+}
+
+// Row is a line of a timeline: a task, or a member and their tasks.
+type Row struct {
+	Label string
+	Bars  []Bar
+}
+
+// Bar is a task drawn on a timeline.
+type Bar struct {
+	TaskId togglplanapi.ID
+	Label  string
+	Start  togglplanapi.Date
+	End    togglplanapi.Date
+	Color  togglplanapi.Color
+	Done   bool
+	// Critical is set for tasks on the critical path.
+	Critical bool
+	// Slack is the number of days the task can slip without delaying the
+	// end of the linked tasks. It is only set for linked tasks.
+	Slack int
+}
+
+// Marker is a milestone drawn on a timeline.
+type Marker struct {
+	MilestoneId togglplanapi.ID
+	Label       string
+	Date        togglplanapi.Date
+}
+
+// Link is a dependency drawn between two bars.
+type Link struct {
+	From     togglplanapi.ID
+	To       togglplanapi.ID
+	Critical bool
+}
+
+// ProjectTimeline lays out the tasks of a project with one row per task,
+// sorted by start date, along with its milestones and dependencies.
+// Undated tasks are left out. An error is returned if the dependencies form
+// a cycle.
+func ProjectTimeline(tasks []togglplanapi.Task, milestones []togglplanapi.Milestone, options TimelineOptions) (Timeline, error) {
+	bars := timelineBars(tasks)
+
+	timeline := Timeline{}
+	for _, bar := range bars {
+		timeline.Rows = append(timeline.Rows, Row{Label: bar.Label, Bars: []Bar{bar}})
+	}
+	for _, milestone := range milestones {
+		timeline.Milestones = append(timeline.Milestones, Marker{MilestoneId: milestone.Id, Label: milestone.Name, Date: milestone.Date})
+	}
+	sort.SliceStable(timeline.Milestones, func(i, j int) bool {
+		return timeline.Milestones[i].Date.Before(timeline.Milestones[j].Date)
+	})
+
+	return finishTimeline(timeline, bars, options)
+}
+
+// MemberTimeline lays out tasks with one row per assignee, sorted by name,
+// holding their tasks sorted by start date. A task with several assignees
+// is drawn on each of their rows, and unassigned tasks are left out. An
+// error is returned if the dependencies form a cycle.
+func MemberTimeline(tasks []togglplanapi.Task, options TimelineOptions) (Timeline, error) {
+	names := map[togglplanapi.ID]string{}
+	for _, member := range options.Members {
+		names[member.Id] = member.Name
+	}
+
+	bars := timelineBars(tasks)
+	rows := map[togglplanapi.ID]*Row{}
+	var memberIds []togglplanapi.ID
+	for _, bar := range bars {
+		for _, assignee := range taskById(tasks, bar.TaskId).Assignees {
+			row, ok := rows[assignee]
+			if !ok {
+				name := names[assignee]
+				if name == "" {
+					name = fmt.Sprintf("Member %d", assignee)
+				}
+				row = &Row{Label: name}
+				rows[assignee] = row
+				memberIds = append(memberIds, assignee)
+			}
+			row.Bars = append(row.Bars, bar)
+		}
+	}
+
+	sort.SliceStable(memberIds, func(i, j int) bool {
+		return rows[memberIds[i]].Label < rows[memberIds[j]].Label
+	})
+	timeline := Timeline{}
+	for _, memberId := range memberIds {
+		timeline.Rows = append(timeline.Rows, *rows[memberId])
+	}
+
+	return finishTimeline(timeline, bars, options)
+}
+
+// timelineBars returns the bars of the dated tasks, sorted by start date and name.
+func timelineBars(tasks []togglplanapi.Task) []Bar {
+	var bars []Bar
+	for _, task := range tasks {
+		if task.StartDate.IsZero() || task.EndDate.IsZero() {
+			continue
+		}
+		bars = append(bars, Bar{
+			TaskId: task.Id,
+			Label:  task.Name,
+			Start:  task.StartDate,
+			End:    task.EndDate,
+			Color:  task.Color,
+			Done:   task.Done,
+		})
+	}
+
+	sort.SliceStable(bars, func(i, j int) bool {
+		if bars[i].Start != bars[j].Start {
+			return bars[i].Start.Before(bars[j].Start)
+		}
+		return bars[i].Label < bars[j].Label
+	})
+	return bars
+}
+
+// taskById returns the task with the given ID, or the zero task.
+func taskById(tasks []togglplanapi.Task, id togglplanapi.ID) togglplanapi.Task {
+	for _, task := range tasks {
+		if task.Id == id {
+			return task
+		}
+	}
+	return togglplanapi.Task{}
+}
+
+// finishTimeline sets the range, links and critical path of a timeline, and
+// marks the critical bars of its rows.
+func finishTimeline(timeline Timeline, bars []Bar, options TimelineOptions) (Timeline, error) {
+	timeline.Since, timeline.Until = options.Since, options.Until
+	for _, bar := range bars {
+		if options.Since.IsZero() && (timeline.Since.IsZero() || bar.Start.Before(timeline.Since)) {
+			timeline.Since = bar.Start
+		}
+		if options.Until.IsZero() && bar.End.After(timeline.Until) {
+			timeline.Until = bar.End
+		}
+	}
+	for _, marker := range timeline.Milestones {
+		if options.Since.IsZero() && (timeline.Since.IsZero() || marker.Date.Before(timeline.Since)) {
+			timeline.Since = marker.Date
+		}
+		if options.Until.IsZero() && marker.Date.After(timeline.Until) {
+			timeline.Until = marker.Date
+		}
+	}
+
+	slack, path, err := criticalPath(bars, options.Dependencies)
+	if err != nil {
+		return Timeline{}, err
+	}
+	timeline.CriticalPath = path
+
+	critical := map[togglplanapi.ID]bool{}
+	for _, id := range path {
+		critical[id] = true
+	}
+	for _, dependency := range options.Dependencies {
+		if _, ok := slack[dependency.From]; !ok {
+			continue
+		}
+		if _, ok := slack[dependency.To]; !ok {
+			continue
+		}
+		timeline.Links = append(timeline.Links, Link{
+			From:     dependency.From,
+			To:       dependency.To,
+			Critical: critical[dependency.From] && critical[dependency.To],
+		})
+	}
+
+	for i := range timeline.Rows {
+		for j := range timeline.Rows[i].Bars {
+			bar := &timeline.Rows[i].Bars[j]
+			bar.Critical = critical[bar.TaskId]
+			bar.Slack = slack[bar.TaskId]
+		}
+	}
+	return timeline, nil
+}
+
+// criticalPath runs the critical path method on the linked bars, with each
+// task lasting its number of days. It returns the slack of each linked task,
+// and the tasks without slack in order.
+func criticalPath(bars []Bar, dependencies []Dependency) (map[togglplanapi.ID]int, []togglplanapi.ID, error) {
+	durations := map[togglplanapi.ID]int{}
+	for _, bar := range bars {
+		durations[bar.TaskId] = bar.Start.DaysUntil(bar.End) + 1
+	}
+
+	// Keep the dependencies between bars, and the tasks they link
+	successors := map[togglplanapi.ID][]togglplanapi.ID{}
+	predecessors := map[togglplanapi.ID][]togglplanapi.ID{}
+	var linked []togglplanapi.ID
+	seen := map[togglplanapi.ID]bool{}
+	for _, dependency := range dependencies {
+		_, fromOk := durations[dependency.From]
+		_, toOk := durations[dependency.To]
+		if !fromOk || !toOk {
+			continue
+		}
+		successors[dependency.From] = append(successors[dependency.From], dependency.To)
+		predecessors[dependency.To] = append(predecessors[dependency.To], dependency.From)
+		for _, id := range []togglplanapi.ID{dependency.From, dependency.To} {
+			if !seen[id] {
+				seen[id] = true
+				linked = append(linked, id)
+			}
+		}
+	}
+	if len(linked) == 0 {
+		return map[togglplanapi.ID]int{}, nil, nil
+	}
+
+	// Order the tasks so that each comes after its predecessors
+	remaining := map[togglplanapi.ID]int{}
+	var order, ready []togglplanapi.ID
+	for _, id := range linked {
+		remaining[id] = len(predecessors[id])
+		if remaining[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+		for _, next := range successors[id] {
+			remaining[next]--
+			if remaining[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+	if len(order) != len(linked) {
+		return nil, nil, fmt.Errorf("task dependencies form a cycle")
+	}
+
+	// Forward pass: earliest finish of each task, and of the whole
+	earliestFinish := map[togglplanapi.ID]int{}
+	end := 0
+	for _, id := range order {
+		start := 0
+		for _, previous := range predecessors[id] {
+			if earliestFinish[previous] > start {
+				start = earliestFinish[previous]
+			}
+		}
+		earliestFinish[id] = start + durations[id]
+		if earliestFinish[id] > end {
+			end = earliestFinish[id]
+		}
+	}
+
+	// Backward pass: latest finish of each task without delaying the end
+	latestFinish := map[togglplanapi.ID]int{}
+	slack := map[togglplanapi.ID]int{}
+	for i := len(order) - 1; i >= 0; i-- {
+		id := order[i]
+		finish := end
+		for _, next := range successors[id] {
+			if start := latestFinish[next] - durations[next]; start < finish {
+				finish = start
+			}
+		}
+		latestFinish[id] = finish
+		slack[id] = finish - earliestFinish[id]
+	}
+
+	var path []togglplanapi.ID
+	for _, id := range order {
+		if slack[id] == 0 {
+			path = append(path, id)
+		}
+	}
+	return slack, path, nil
+}
@@ -0,0 +1,60 @@
+package schedule
+
+import (
+	"reflect"
+	"testing"
+
+	"togglplanapi"
+)
+
+func TestProjectTimeline(t *testing.T) {
+	day := togglplanapi.NewDate(2024, 3, 4)
+	tasks := []togglplanapi.Task{
+		{Id: 1, Name: "Design", StartDate: day, EndDate: day.AddDays(2), Assignees: []togglplanapi.ID{7}},
+		{Id: 2, Name: "Build", StartDate: day.AddDays(3), EndDate: day.AddDays(7), Assignees: []togglplanapi.ID{7, 8}},
+		{Id: 3, Name: "Copy", StartDate: day.AddDays(3), EndDate: day.AddDays(4), Assignees: []togglplanapi.ID{8}},
+		{Id: 4, Name: "Launch", StartDate: day.AddDays(8), EndDate: day.AddDays(8)},
+		{Id: 5, Name: "Someday"},
+	}
+	milestones := []togglplanapi.Milestone{{Id: 9, Name: "Go live", Date: day.AddDays(10)}}
+	dependencies := []Dependency{{1, 2}, {1, 3}, {2, 4}, {3, 4}, {4, 99}}
+
+	timeline, err := ProjectTimeline(tasks, milestones, TimelineOptions{Dependencies: dependencies})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if timeline.Since != day || timeline.Until != day.AddDays(10) || len(timeline.Rows) != 4 {
+		t.Fatalf("unexpected timeline from %s to %s with %d rows", timeline.Since, timeline.Until, len(timeline.Rows))
+	}
+	if !reflect.DeepEqual(timeline.CriticalPath, []togglplanapi.ID{1, 2, 4}) {
+		t.Fatalf("unexpected critical path %v", timeline.CriticalPath)
+	}
+	if bar := timeline.Rows[2].Bars[0]; bar.TaskId != 3 || bar.Critical || bar.Slack != 3 {
+		t.Fatalf("unexpected bar %+v", bar)
+	}
+	if len(timeline.Links) != 4 || !timeline.Links[0].Critical || timeline.Links[1].Critical {
+		t.Fatalf("unexpected links %+v", timeline.Links)
+	}
+
+	if _, err := ProjectTimeline(tasks, nil, TimelineOptions{Dependencies: []Dependency{{1, 2}, {2, 1}}}); err == nil {
+		t.Fatal("expected an error for a cycle")
+	}
+}
+
+func TestMemberTimeline(t *testing.T) {
+	day := togglplanapi.NewDate(2024, 3, 4)
+	tasks := []togglplanapi.Task{
+		{Id: 1, Name: "Build", StartDate: day, EndDate: day, Assignees: []togglplanapi.ID{7, 8}},
+		{Id: 2, Name: "Copy", StartDate: day, EndDate: day.AddDays(1), Assignees: []togglplanapi.ID{8}},
+	}
+
+	timeline, err := MemberTimeline(tasks, TimelineOptions{Members: []togglplanapi.Member{{Id: 8, Name: "Ada"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(timeline.Rows) != 2 || timeline.Rows[0].Label != "Ada" || len(timeline.Rows[0].Bars) != 2 || timeline.Rows[1].Label != "Member 7" {
+		t.Fatalf("unexpected rows %+v", timeline.Rows)
+	}
+}
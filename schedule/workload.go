@@ -0,0 +1,236 @@
+/*
+Package schedule provides planning helpers working on Toggl Plan tasks:
+workload and capacity forecasts per member, schedule conflicts, rescheduling,
+and timelines.
+
+The helpers work on tasks already fetched, for example with
+togglplanapi.ListAllTasks, so that they can be combined freely and tested
+without the API.
+*/
+package schedule
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"togglplanapi"
+)
+
+// defaultCapacity is the number of hours a member can work on a working day,
+// unless configured otherwise.
+const defaultCapacity = 8
+
+// WorkloadOptions configures ComputeWorkload.
+type WorkloadOptions struct {
+	// Since and Until set the days of the workload, both inclusive.
+	Since togglplanapi.Date
+	Until togglplanapi.Date
+
+	// Capacity is the number of hours a member can work on a working day.
+	// Defaults to 8. Members can be given their own capacity in MemberCapacity.
+	Capacity       float64
+	MemberCapacity map[togglplanapi.ID]float64
+
+	// Calendar tells which days are worked. Defaults to Monday to Friday.
+	Calendar *togglplanapi.Calendar
+
+	// SplitAmongAssignees divides the hours of tasks with several assignees
+	// between them. By default each assignee carries the whole task.
+	SplitAmongAssignees bool
+
+	// SkipDone leaves out tasks that are done.
+	SkipDone bool
+}
+
+// DayLoad is the planned work of a member on a day.
+type DayLoad struct {
+	Date     togglplanapi.Date
+	Hours    float64
+	Capacity float64
+	// TaskIds lists the tasks planned on the day.
+	TaskIds []togglplanapi.ID
+}
+
+// Over reports whether more hours are planned than the member can work.
+func (day DayLoad) Over() bool {
+	return day.Hours > day.Capacity
+}
+
+// MemberLoad is the planned work of a member over the days of a Workload.
+type MemberLoad struct {
+	MemberId togglplanapi.ID
+	Days     []DayLoad
+}
+
+// Hours returns the total hours planned for the member.
+func (member MemberLoad) Hours() float64 {
+	total := 0.0
+	for _, day := range member.Days {
+		total += day.Hours
+	}
+	return total
+}
+
+// Overloaded returns the days with more hours planned than the member can work.
+func (member MemberLoad) Overloaded() []DayLoad {
+	var days []DayLoad
+	for _, day := range member.Days {
+		if day.Over() {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// Workload is a matrix of the hours planned per member and per day.
+type Workload struct {
+	Days []togglplanapi.Date
+	// Members holds a row per assigned member, sorted by ID. Each row has a
+	// DayLoad for each of Days.
+	Members []MemberLoad
+}
+
+// Member returns the row of a member.
+func (workload Workload) Member(memberId togglplanapi.ID) (MemberLoad, bool) {
+	for _, member := range workload.Members {
+		if member.MemberId == memberId {
+			return member, true
+		}
+	}
+	return MemberLoad{}, false
+}
+
+// Overloaded returns the rows of the members with at least one day over capacity.
+func (workload Workload) Overloaded() []MemberLoad {
+	var members []MemberLoad
+	for _, member := range workload.Members {
+		if len(member.Overloaded()) > 0 {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
+// FetchWorkload fetches the tasks of a workspace between options.Since and
+// options.Until, and computes their workload.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	options: Date range and capacity settings
+func FetchWorkload(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, options WorkloadOptions) (Workload, error) {
+	tasks, err := togglplanapi.ListAllTasks(ctx, pa, workspaceId, togglplanapi.TaskFilter{Since: options.Since, Until: options.Until})
+	if err != nil {
+		return Workload{}, err
+	}
+	return ComputeWorkload(tasks, options), nil
+}
+
+// ComputeWorkload spreads the planned hours of tasks over the working days
+// between options.Since and options.Until, for each assignee.
+//
+// Tasks with an estimate spread it evenly over their working days, including
+// those outside the range. Timed tasks without an estimate count the time
+// between their start and end times on each working day. Other tasks count
+// no hours, but are still listed in the days they cover. Unassigned and
+// undated tasks are left out.
+func ComputeWorkload(tasks []togglplanapi.Task, options WorkloadOptions) Workload {
+	if options.Capacity == 0 {
+		options.Capacity = defaultCapacity
+	}
+
+	var workload Workload
+	for day := options.Since; !day.After(options.Until); day = day.AddDays(1) {
+		workload.Days = append(workload.Days, day)
+	}
+
+	rows := map[togglplanapi.ID]*MemberLoad{}
+	row := func(memberId togglplanapi.ID) *MemberLoad {
+		if member, ok := rows[memberId]; ok {
+			return member
+		}
+		member := emptyLoad(memberId, workload.Days, options)
+		rows[memberId] = &member
+		return &member
+	}
+
+	for _, task := range tasks {
+		if len(task.Assignees) == 0 || task.StartDate.IsZero() || task.EndDate.IsZero() {
+			continue
+		}
+		if options.SkipDone && task.Done {
+			continue
+		}
+
+		hours := hoursPerDay(task, options.Calendar)
+		if options.SplitAmongAssignees {
+			hours /= float64(len(task.Assignees))
+		}
+
+		for _, memberId := range task.Assignees {
+			member := row(memberId)
+			for i, day := range workload.Days {
+				if !day.Between(task.StartDate, task.EndDate) || !options.Calendar.IsWorkday(day) {
+					continue
+				}
+				member.Days[i].Hours += hours
+				member.Days[i].TaskIds = append(member.Days[i].TaskIds, task.Id)
+			}
+		}
+	}
+
+	for _, member := range rows {
+		workload.Members = append(workload.Members, *member)
+	}
+	sort.Slice(workload.Members, func(i, j int) bool {
+		return workload.Members[i].MemberId < workload.Members[j].MemberId
+	})
+
+	return workload
+}
+
+// emptyLoad returns the row of a member with nothing planned on days, with
+// the capacity of the member on each working day. options.Capacity must be set.
+func emptyLoad(memberId togglplanapi.ID, days []togglplanapi.Date, options WorkloadOptions) MemberLoad {
+	capacity, ok := options.MemberCapacity[memberId]
+	if !ok {
+		capacity = options.Capacity
+	}
+
+	member := MemberLoad{MemberId: memberId, Days: make([]DayLoad, len(days))}
+	for i, day := range days {
+		member.Days[i] = DayLoad{Date: day}
+		if options.Calendar.IsWorkday(day) {
+			member.Days[i].Capacity = capacity
+		}
+	}
+	return member
+}
+
+// hoursPerDay returns the hours a task takes on each of its workdays.
+func hoursPerDay(task togglplanapi.Task, cal *togglplanapi.Calendar) float64 {
+	if task.EstimatedMinutes > 0 {
+		days := 0
+		for day := task.StartDate; !day.After(task.EndDate); day = day.AddDays(1) {
+			if cal.IsWorkday(day) {
+				days++
+			}
+		}
+		if days == 0 {
+			return 0
+		}
+		return task.EstimatedMinutes.Hours() / float64(days)
+	}
+
+	if task.StartTime != "" && task.EndTime != "" {
+		start, errStart := time.Parse("15:04", task.StartTime)
+		end, errEnd := time.Parse("15:04", task.EndTime)
+		if errStart == nil && errEnd == nil && end.After(start) {
+			return end.Sub(start).Hours()
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,65 @@
+package schedule
+
+import (
+	"testing"
+
+	"togglplanapi"
+)
+
+func TestComputeWorkload(t *testing.T) {
+	monday := togglplanapi.NewDate(2024, 3, 4)
+
+	tasks := []togglplanapi.Task{
+		// 20 hours over Monday to Friday, 4 hours a day
+		{Id: 1, Assignees: []togglplanapi.ID{3}, StartDate: monday, EndDate: monday.AddDays(4), EstimatedMinutes: 20 * togglplanapi.EstimateHour},
+		// Spans the weekend, which doesn't count
+		{Id: 2, Assignees: []togglplanapi.ID{3, 4}, StartDate: monday.AddDays(4), EndDate: monday.AddDays(7), EstimatedMinutes: 12 * togglplanapi.EstimateHour},
+		{Id: 3, Assignees: []togglplanapi.ID{4}, StartDate: monday, EndDate: monday, StartTime: "09:00", EndTime: "10:30"},
+		{Id: 4, StartDate: monday, EndDate: monday, EstimatedMinutes: togglplanapi.EstimateDay},
+	}
+
+	workload := ComputeWorkload(tasks, WorkloadOptions{
+		Since:          monday,
+		Until:          monday.AddDays(6),
+		MemberCapacity: map[togglplanapi.ID]float64{4: 4},
+	})
+
+	if len(workload.Days) != 7 || len(workload.Members) != 2 {
+		t.Fatalf("unexpected workload size %d days, %d members", len(workload.Days), len(workload.Members))
+	}
+
+	member, _ := workload.Member(3)
+	friday := member.Days[4]
+	if friday.Hours != 10 || !friday.Over() || len(friday.TaskIds) != 2 {
+		t.Fatalf("unexpected friday %+v", friday)
+	}
+	if member.Days[5].Hours != 0 || member.Days[5].Capacity != 0 {
+		t.Fatalf("expected saturday to be off, got %+v", member.Days[5])
+	}
+	if member.Hours() != 26 {
+		t.Fatalf("expected 26 hours in the range, got %v", member.Hours())
+	}
+
+	other, _ := workload.Member(4)
+	if other.Days[0].Hours != 1.5 || other.Days[0].Capacity != 4 || other.Days[0].Over() {
+		t.Fatalf("unexpected monday %+v", other.Days[0])
+	}
+	if got := workload.Overloaded(); len(got) != 2 {
+		t.Fatalf("expected both members to be overloaded on friday, got %+v", got)
+	}
+}
+
+func TestComputeWorkloadSplit(t *testing.T) {
+	monday := togglplanapi.NewDate(2024, 3, 4)
+	tasks := []togglplanapi.Task{
+		{Id: 1, Assignees: []togglplanapi.ID{3, 4}, StartDate: monday, EndDate: monday, EstimatedMinutes: togglplanapi.EstimateDay},
+	}
+
+	workload := ComputeWorkload(tasks, WorkloadOptions{Since: monday, Until: monday, SplitAmongAssignees: true})
+
+	for _, member := range workload.Members {
+		if member.Days[0].Hours != 4 {
+			t.Fatalf("expected the day to be split, got %+v", member)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package togglplanapi
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Priority orders the jobs waiting in a Scheduler. Jobs of higher priority
+// run first, and jobs of the same priority in the order they were submitted.
+type Priority int
+
+// Priorities of jobs. Other values can be used for finer ordering.
+const (
+	PriorityLow    Priority = -1
+	PriorityNormal Priority = 0
+	PriorityHigh   Priority = 1
+)
+
+// SchedulerOptions configures a Scheduler. Zero fields keep the defaults.
+type SchedulerOptions struct {
+	// Interval is the least time between the start of two jobs. Defaults to
+	// 200 milliseconds, i.e. 5 jobs a second.
+	Interval time.Duration
+
+	// Concurrency is the number of jobs running at the same time.
+	// Defaults to 2.
+	Concurrency int
+
+	// Backoff is how long the scheduler pauses after a job fails with
+	// 429 Too Many Requests. It doubles with each failure in a row, up to
+	// MaxBackoff, and is reset by a job that doesn't hit the limit. The pause
+	// is longer if the API asks for it (see APIError.Wait).
+	// Defaults to 10 seconds, and MaxBackoff to 5 minutes.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// SchedulerStats is a snapshot of the state of a Scheduler, for metrics.
+type SchedulerStats struct {
+	// Queued counts the jobs waiting to run, and QueuedByPriority splits
+	// them by priority.
+	Queued           int
+	QueuedByPriority map[Priority]int
+	Running          int
+
+	// Succeeded, Failed and RateLimited count the jobs that finished since
+	// the scheduler was created. RateLimited jobs also count as failed.
+	Succeeded   int
+	Failed      int
+	RateLimited int
+
+	// PausedUntil is the end of the pause following a rate-limited job, or
+	// the zero time if the scheduler isn't paused.
+	PausedUntil time.Time
+}
+
+// Scheduler runs queued jobs using a client, such as the syncs of several
+// integrations in one process, so that they share the rate limit of the API
+// instead of competing for it. Jobs are spaced by an interval, and all of
+// them pause when one fails with 429 Too Many Requests, after the retries of
+// the client.
+//
+// A Scheduler is safe for concurrent use. Jobs can be submitted at any time,
+// and run while Run is running.
+type Scheduler struct {
+	options SchedulerOptions
+	wake    chan struct{}
+
+	mu          sync.Mutex
+	queue       jobQueue
+	seq         int
+	running     int
+	backoff     time.Duration
+	nextStart   time.Time
+	pausedUntil time.Time
+	succeeded   int
+	failed      int
+	rateLimited int
+}
+
+// NewScheduler returns a scheduler with options (use
+// `togglplanapi.SchedulerOptions{}` for the defaults). Call Run to process
+// its jobs.
+func NewScheduler(options SchedulerOptions) *Scheduler {
+	if options.Interval <= 0 {
+		options.Interval = 200 * time.Millisecond
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 2
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = 10 * time.Second
+	}
+	if options.MaxBackoff <= 0 {
+		options.MaxBackoff = 5 * time.Minute
+	}
+	return &Scheduler{options: options, wake: make(chan struct{}, 1)}
+}
+
+// Submit queues a job, and returns a channel receiving its error once it has
+// run. The context passed to the job is the one given to Run. A job that
+// panics gets a *PanicError, and the scheduler keeps running.
+func (s *Scheduler) Submit(priority Priority, job func(ctx context.Context) error) <-chan error {
+	done := make(chan error, 1)
+
+	s.mu.Lock()
+	heap.Push(&s.queue, &queuedJob{priority: priority, seq: s.seq, run: job, done: done})
+	s.seq++
+	s.mu.Unlock()
+
+	s.signal()
+	return done
+}
+
+// Stats returns the current state of the scheduler.
+func (s *Scheduler) Stats() SchedulerStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := SchedulerStats{
+		Queued:           len(s.queue),
+		QueuedByPriority: map[Priority]int{},
+		Running:          s.running,
+		Succeeded:        s.succeeded,
+		Failed:           s.failed,
+		RateLimited:      s.rateLimited,
+	}
+	for _, job := range s.queue {
+		stats.QueuedByPriority[job.priority]++
+	}
+	if time.Now().Before(s.pausedUntil) {
+		stats.PausedUntil = s.pausedUntil
+	}
+	return stats
+}
+
+// Run processes jobs until ctx is cancelled, then waits for the running jobs
+// and returns the context's error. Jobs still queued stay in the queue, for a
+// later call to Run.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		job, wait := s.next(time.Now())
+		if job != nil {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.finish(job, callHook("job", func() error { return job.run(ctx) }))
+			}()
+			continue
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		var tick <-chan time.Time
+		if wait > 0 {
+			timer.Reset(wait)
+			tick = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.wake:
+		case <-tick:
+		}
+	}
+}
+
+// next takes the next job to start at now, if one can start. Otherwise it
+// returns how long until one might, or 0 to wait for a job to be submitted
+// or to finish.
+func (s *Scheduler) next(now time.Time) (*queuedJob, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queue) == 0 || s.running >= s.options.Concurrency {
+		return nil, 0
+	}
+
+	start := s.nextStart
+	if s.pausedUntil.After(start) {
+		start = s.pausedUntil
+	}
+	if wait := start.Sub(now); wait > 0 {
+		return nil, wait
+	}
+
+	s.running++
+	s.nextStart = now.Add(s.options.Interval)
+	return heap.Pop(&s.queue).(*queuedJob), 0
+}
+
+// finish records the outcome of a job, pausing the scheduler if it hit the
+// rate limit, and passes its error on.
+func (s *Scheduler) finish(job *queuedJob, err error) {
+	s.mu.Lock()
+	s.running--
+	var apiErr *APIError
+	switch {
+	case errors.As(err, &apiErr) && errors.Is(apiErr, ErrRateLimited):
+		s.failed++
+		s.rateLimited++
+		s.backoff *= 2
+		if s.backoff == 0 {
+			s.backoff = s.options.Backoff
+		}
+		if s.backoff > s.options.MaxBackoff {
+			s.backoff = s.options.MaxBackoff
+		}
+		pause := s.backoff
+		if wait := apiErr.Wait(); wait > pause {
+			pause = wait
+		}
+		s.pausedUntil = time.Now().Add(pause)
+	case err != nil:
+		s.failed++
+		s.backoff = 0
+	default:
+		s.succeeded++
+		s.backoff = 0
+	}
+	s.mu.Unlock()
+
+	job.done <- err
+	s.signal()
+}
+
+// signal wakes Run up, unless it is already due to wake up.
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// queuedJob is a job waiting in a Scheduler.
+type queuedJob struct {
+	priority Priority
+	seq      int
+	run      func(ctx context.Context) error
+	done     chan error
+}
+
+// jobQueue is a heap of jobs, by priority and then in order of submission.
+type jobQueue []*queuedJob
+
+func (q jobQueue) Len() int { return len(q) }
+
+func (q jobQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q jobQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *jobQueue) Push(x any) { *q = append(*q, x.(*queuedJob)) }
+
+func (q *jobQueue) Pop() any {
+	old := *q
+	job := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return job
+}
@@ -0,0 +1,84 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSchedulerPriorities(t *testing.T) {
+	s := NewScheduler(SchedulerOptions{Interval: time.Millisecond, Concurrency: 1})
+
+	var mu sync.Mutex
+	var order []string
+	job := func(name string) func(ctx context.Context) error {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	// Queued before Run starts, so that priorities decide the order
+	low := s.Submit(PriorityLow, job("low"))
+	first := s.Submit(PriorityNormal, job("first"))
+	second := s.Submit(PriorityNormal, job("second"))
+	high := s.Submit(PriorityHigh, job("high"))
+
+	if stats := s.Stats(); stats.Queued != 4 || stats.QueuedByPriority[PriorityNormal] != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	for _, result := range []<-chan error{low, first, second, high} {
+		if err := <-result; err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected Run to return the context's error, got %v", err)
+	}
+
+	if len(order) != 4 || order[0] != "high" || order[1] != "first" || order[2] != "second" || order[3] != "low" {
+		t.Fatalf("unexpected order %v", order)
+	}
+	if stats := s.Stats(); stats.Succeeded != 4 || stats.Queued != 0 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
+
+func TestSchedulerRateLimited(t *testing.T) {
+	s := NewScheduler(SchedulerOptions{Interval: time.Millisecond, Backoff: 50 * time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	limited := &APIError{StatusCode: http.StatusTooManyRequests}
+	if err := <-s.Submit(PriorityNormal, func(ctx context.Context) error { return limited }); err != limited {
+		t.Fatalf("expected the job's error, got %v", err)
+	}
+
+	stats := s.Stats()
+	if stats.RateLimited != 1 || stats.Failed != 1 || stats.PausedUntil.IsZero() {
+		t.Fatalf("expected the scheduler to pause, got %+v", stats)
+	}
+
+	start := time.Now()
+	var ranAt time.Time
+	<-s.Submit(PriorityNormal, func(ctx context.Context) error {
+		ranAt = time.Now()
+		return nil
+	})
+	if ranAt.Sub(start) < 30*time.Millisecond {
+		t.Fatalf("expected the next job to wait for the pause, ran after %v", ranAt.Sub(start))
+	}
+}
@@ -0,0 +1,60 @@
+package togglplanapi
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSignRequests(t *testing.T) {
+	key := []byte("secret")
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodyHash := sha256.Sum256(body)
+		mac := hmac.New(sha256.New, key)
+		fmt.Fprintf(mac, "%s\n%s\n%x\n%s", r.Method, r.URL.RequestURI(), bodyHash, r.Header.Get("X-Signature-Timestamp"))
+		if r.Header.Get("X-Signature") != hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"id":7,"name":%q}`, r.Header.Get("X-Order"))
+	})
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				req = req.Clone(req.Context())
+				req.Header.Set("X-Order", strings.Join(order, ","))
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	WithMiddleware(trace("first"), SignRequests(SigningOptions{Key: key}))(pa)
+	WithMiddleware(trace("second"))(pa)
+	// Transport options still reach the transport under the middleware
+	WithTransportOptions(TransportOptions{MaxIdleConns: 5})(pa)
+	if transport(pa).MaxIdleConns != 5 {
+		t.Error("expected the transport to be configured")
+	}
+
+	name := "Signed"
+	task, err := UpdateTask(context.Background(), pa, 1, 7, TaskUpdate{Name: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Name != "first,second" {
+		t.Errorf("unexpected middleware order %q", task.Name)
+	}
+
+	if _, err := GetTask(context.Background(), pa, 1, 7); err != nil {
+		t.Errorf("expected a signed GET to pass, got %v", err)
+	}
+}
@@ -0,0 +1,247 @@
+/*
+Package slack formats Toggl Plan tasks and milestones as Slack Block Kit
+messages, for bots posting plans and digests to Slack.
+
+Example Usage:
+
+	import (
+		"bytes"
+		"encoding/json"
+		"net/http"
+
+		"github.com/ricotheque/togglplanapi/slack"
+	)
+
+	func main() {
+		formatter := slack.Formatter{
+			Projects: map[togglplanapi.ID]string{projectId: "Website"},
+			Members:  map[togglplanapi.ID]string{memberId: "Ada"},
+		}
+
+		message := formatter.TaskList("Due today", tasks)
+
+		payload, _ := json.Marshal(message)
+		http.Post(webhookURL, "application/json", bytes.NewReader(payload))
+	}
+*/
+package slack
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"togglplanapi"
+)
+
+// Message is a Slack message, as posted to chat.postMessage or an incoming webhook.
+// Text is shown in notifications and by clients that can't display blocks.
+type Message struct {
+	Text   string  `json:"text"`
+	Blocks []Block `json:"blocks"`
+}
+
+// Block is a Block Kit layout block.
+type Block struct {
+	Type     string `json:"type"`
+	Text     *Text  `json:"text,omitempty"`
+	Fields   []Text `json:"fields,omitempty"`
+	Elements []Text `json:"elements,omitempty"`
+	BlockId  string `json:"block_id,omitempty"`
+}
+
+// Text is a Block Kit text object.
+type Text struct {
+	Type  string `json:"type"`
+	Text  string `json:"text"`
+	Emoji bool   `json:"emoji,omitempty"`
+}
+
+// maxSectionText is the most characters Slack accepts in a section's text.
+const maxSectionText = 3000
+
+// maxBlocks is the most blocks Slack accepts in a message.
+const maxBlocks = 50
+
+// Formatter converts tasks and milestones into blocks.
+// The name maps are used to show project and member names instead of IDs.
+type Formatter struct {
+	Projects map[togglplanapi.ID]string
+	Members  map[togglplanapi.ID]string
+
+	// TaskURL returns a link to a task, which its title is linked to. Optional.
+	TaskURL func(task togglplanapi.Task) string
+}
+
+// Task returns a section block describing a task: its title, dates,
+// project, assignees and tags.
+func (f Formatter) Task(task togglplanapi.Task) Block {
+	title := Escape(task.Name)
+	if f.TaskURL != nil {
+		if url := f.TaskURL(task); url != "" {
+			title = "<" + url + "|" + title + ">"
+		}
+	}
+	if task.Done {
+		title = "~" + title + "~ :white_check_mark:"
+	}
+
+	lines := []string{"*" + title + "*"}
+
+	details := []string{":calendar: " + formatDates(task)}
+	if name, ok := f.Projects[task.ProjectId]; ok {
+		details = append(details, ":file_folder: "+Escape(name))
+	}
+	if len(task.Assignees) > 0 {
+		details = append(details, ":bust_in_silhouette: "+Escape(f.memberNames(task.Assignees)))
+	}
+	if len(task.Tags) > 0 {
+		details = append(details, ":label: "+Escape(strings.Join(task.Tags, ", ")))
+	}
+	lines = append(lines, strings.Join(details, "   "))
+
+	return section(strings.Join(lines, "\n"))
+}
+
+// Milestone returns a section block describing a milestone.
+func (f Formatter) Milestone(milestone togglplanapi.Milestone) Block {
+	text := ":triangular_flag_on_post: *" + Escape(milestone.Name) + "* — " + formatDate(milestone.Date)
+	if name, ok := f.Projects[milestone.ProjectId]; ok {
+		text += "   :file_folder: " + Escape(name)
+	}
+	return section(text)
+}
+
+// TaskList returns a message with a header followed by one block per task.
+// Slack limits messages to 50 blocks, so long lists are cut short with a
+// note saying how many tasks were left out.
+func (f Formatter) TaskList(title string, tasks []togglplanapi.Task) Message {
+	message := Message{
+		Text:   fmt.Sprintf("%s: %d task(s)", title, len(tasks)),
+		Blocks: []Block{Header(title)},
+	}
+
+	if len(tasks) == 0 {
+		message.Blocks = append(message.Blocks, Context("Nothing here :tada:"))
+		return message
+	}
+
+	for i, task := range tasks {
+		// Keep room for the note about left out tasks
+		if len(message.Blocks) == maxBlocks-1 && i < len(tasks)-1 {
+			message.Blocks = append(message.Blocks, Context(fmt.Sprintf("…and %d more", len(tasks)-i)))
+			break
+		}
+		message.Blocks = append(message.Blocks, f.Task(task))
+	}
+
+	return message
+}
+
+// Digest returns a message with a header and one titled section of tasks per
+// entry of sections, skipping empty ones. Sections are shown in the order of titles.
+func (f Formatter) Digest(title string, titles []string, sections map[string][]togglplanapi.Task) Message {
+	message := Message{Blocks: []Block{Header(title)}}
+
+	var summary []string
+	for _, sectionTitle := range titles {
+		tasks := sections[sectionTitle]
+		if len(tasks) == 0 {
+			continue
+		}
+		summary = append(summary, fmt.Sprintf("%d %s", len(tasks), strings.ToLower(sectionTitle)))
+
+		message.Blocks = append(message.Blocks, Divider(), section("*"+Escape(sectionTitle)+"*"))
+		for _, task := range tasks {
+			if len(message.Blocks) >= maxBlocks {
+				break
+			}
+			message.Blocks = append(message.Blocks, f.Task(task))
+		}
+	}
+
+	if len(summary) == 0 {
+		message.Blocks = append(message.Blocks, Context("Nothing here :tada:"))
+		summary = []string{"nothing to report"}
+	}
+	message.Text = title + ": " + strings.Join(summary, ", ")
+	if len(message.Blocks) > maxBlocks {
+		message.Blocks = message.Blocks[:maxBlocks]
+	}
+
+	return message
+}
+
+// memberNames returns the names of members, falling back to their IDs.
+func (f Formatter) memberNames(ids []togglplanapi.ID) string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		if name, ok := f.Members[id]; ok {
+			names[i] = name
+		} else {
+			names[i] = fmt.Sprintf("Member %d", id)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// Header returns a header block.
+func Header(text string) Block {
+	return Block{Type: "header", Text: &Text{Type: "plain_text", Text: truncate(text, 150), Emoji: true}}
+}
+
+// Divider returns a divider block.
+func Divider() Block {
+	return Block{Type: "divider"}
+}
+
+// Context returns a context block showing mrkdwn text in small print.
+func Context(text string) Block {
+	return Block{Type: "context", Elements: []Text{{Type: "mrkdwn", Text: truncate(text, maxSectionText)}}}
+}
+
+// section returns a section block with mrkdwn text.
+func section(text string) Block {
+	return Block{Type: "section", Text: &Text{Type: "mrkdwn", Text: truncate(text, maxSectionText)}}
+}
+
+// Escape escapes the characters that have a special meaning in Slack's mrkdwn.
+func Escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// truncate shortens text to at most limit characters, ending it with an ellipsis if needed.
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// formatDates formats the dates and times of a task.
+func formatDates(task togglplanapi.Task) string {
+	text := formatDate(task.StartDate)
+	if task.StartTime != "" {
+		text += " " + task.StartTime
+	}
+
+	if task.EndDate != task.StartDate {
+		text += " – " + formatDate(task.EndDate)
+		if task.EndTime != "" {
+			text += " " + task.EndTime
+		}
+	} else if task.EndTime != "" {
+		text += "–" + task.EndTime
+	}
+
+	return text
+}
+
+// formatDate formats a date for humans.
+func formatDate(date togglplanapi.Date) string {
+	if date.IsZero() {
+		return "no date"
+	}
+	return date.In(time.UTC).Format("Mon, Jan 2")
+}
@@ -0,0 +1,55 @@
+package slack
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"togglplanapi"
+)
+
+func TestTask(t *testing.T) {
+	f := Formatter{
+		Projects: map[togglplanapi.ID]string{1: "Web & Apps"},
+		Members:  map[togglplanapi.ID]string{3: "Ada"},
+		TaskURL:  func(task togglplanapi.Task) string { return "https://example.com/tasks/7" },
+	}
+
+	block := f.Task(togglplanapi.Task{
+		Id:        7,
+		Name:      "Fix <script> bug",
+		StartDate: togglplanapi.NewDate(2024, 3, 4),
+		EndDate:   togglplanapi.NewDate(2024, 3, 4),
+		StartTime: "09:00",
+		EndTime:   "10:30",
+		ProjectId: 1,
+		Assignees: []togglplanapi.ID{3, 4},
+	})
+
+	expected := "*<https://example.com/tasks/7|Fix &lt;script&gt; bug>*\n" +
+		":calendar: Mon, Mar 4 09:00–10:30   :file_folder: Web &amp; Apps   :bust_in_silhouette: Ada, Member 4"
+
+	if block.Type != "section" || block.Text.Text != expected {
+		t.Fatalf("unexpected block text:\n%s", block.Text.Text)
+	}
+}
+
+func TestTaskListIsCappedAt50Blocks(t *testing.T) {
+	tasks := make([]togglplanapi.Task, 80)
+
+	message := Formatter{}.TaskList("Due today", tasks)
+
+	if len(message.Blocks) != maxBlocks {
+		t.Fatalf("expected %d blocks, got %d", maxBlocks, len(message.Blocks))
+	}
+
+	last := message.Blocks[len(message.Blocks)-1]
+	if last.Type != "context" || last.Elements[0].Text != "…and 32 more" {
+		t.Fatalf("unexpected last block %+v", last)
+	}
+
+	payload, err := json.Marshal(message)
+	if err != nil || !strings.Contains(string(payload), `"type":"header"`) {
+		t.Fatalf("unexpected payload %s, %v", payload, err)
+	}
+}
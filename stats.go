@@ -0,0 +1,250 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// StatsOptions configures ComputeStats.
+type StatsOptions struct {
+	// Since and Until set the range of tasks counted. Since defaults to 12
+	// weeks before Today, and Until to 4 weeks after it.
+	Since Date
+	Until Date
+
+	// Today is the current day. Defaults to the current day in time.Local.
+	Today Date
+
+	// SundayFirst starts weeks on Sunday instead of Monday.
+	SundayFirst bool
+
+	// TopMembers is the number of members listed in BusiestMembers.
+	// Defaults to 5.
+	TopMembers int
+}
+
+// defaults returns the options with their defaults filled in.
+func (options StatsOptions) defaults() StatsOptions {
+	if options.Today.IsZero() {
+		options.Today = Today(time.Local)
+	}
+	if options.Since.IsZero() {
+		options.Since = options.Today.AddDays(-12 * 7)
+	}
+	if options.Until.IsZero() {
+		options.Until = options.Today.AddDays(4 * 7)
+	}
+	if options.TopMembers <= 0 {
+		options.TopMembers = 5
+	}
+	return options
+}
+
+// WorkspaceStats summarizes the use of a workspace, for admin dashboards.
+type WorkspaceStats struct {
+	Since Date
+	Until Date
+
+	// ActiveProjects counts the projects that aren't archived.
+	ActiveProjects   int
+	ArchivedProjects int
+	// ActiveMembers counts the members that are active.
+	ActiveMembers int
+
+	// Tasks counts the tasks of the range, and TasksByState splits them
+	// between scheduled and done.
+	Tasks        int
+	TasksByState map[TaskState]int
+	// TasksByStatus counts the tasks in each column of the board, in the
+	// order of the board, followed by tasks in no column if there are any.
+	TasksByStatus []StatusCount
+
+	// Weeks counts the tasks created and completed each week of the range.
+	Weeks []WeekStats
+
+	// BusiestMembers lists the members with the most hours planned on tasks
+	// not done, most loaded first.
+	BusiestMembers []MemberStats
+}
+
+// StatusCount is the number of tasks in a column of the board.
+type StatusCount struct {
+	// StatusId is the ID of the column, or 0 for tasks in no column.
+	StatusId ID
+	Name     string
+	Tasks    int
+}
+
+// WeekStats counts the tasks created and completed during a week.
+//
+// The API doesn't tell when a task was completed, so done tasks count as
+// completed in the week they were last updated.
+type WeekStats struct {
+	Start     Date
+	Created   int
+	Completed int
+}
+
+// MemberStats is the load of a member.
+type MemberStats struct {
+	MemberId ID
+	Name     string
+	// OpenTasks counts the tasks assigned to the member that aren't done,
+	// and PlannedHours adds up their estimates.
+	OpenTasks    int
+	PlannedHours float64
+}
+
+// FetchStats fetches the projects, members, board columns and tasks of a
+// workspace at the same time, and summarizes them with ComputeStats. Tasks
+// are listed with ListAllTasks, so that long ranges are fetched in windows.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	options: Date range and report settings (use `togglplanapi.StatsOptions{}` for the defaults)
+func FetchStats(ctx context.Context, pa *togglPlanApi, workspaceId ID, options StatsOptions) (*WorkspaceStats, error) {
+	options = options.defaults()
+
+	var tasks []Task
+	var projects []Project
+	var members []Member
+	var statuses []PlanStatus
+	err := errors.Join(RunBatch(ctx, 4, []func(ctx context.Context) error{
+		func(ctx context.Context) (err error) {
+			tasks, err = ListAllTasks(ctx, pa, workspaceId, TaskFilter{Since: options.Since, Until: options.Until})
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			projects, err = GetProjects(ctx, pa, workspaceId)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			members, err = GetMembers(ctx, pa, workspaceId)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			statuses, err = GetPlanStatuses(ctx, pa, workspaceId)
+			return err
+		},
+	})...)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := ComputeStats(tasks, projects, members, statuses, options)
+	return &stats, nil
+}
+
+// ComputeStats summarizes the tasks, projects and members of a workspace.
+func ComputeStats(tasks []Task, projects []Project, members []Member, statuses []PlanStatus, options StatsOptions) WorkspaceStats {
+	options = options.defaults()
+	stats := WorkspaceStats{
+		Since:        options.Since,
+		Until:        options.Until,
+		Tasks:        len(tasks),
+		TasksByState: map[TaskState]int{},
+	}
+
+	for _, project := range projects {
+		if project.Archived {
+			stats.ArchivedProjects++
+		} else {
+			stats.ActiveProjects++
+		}
+	}
+
+	names := map[ID]string{}
+	for _, member := range members {
+		names[member.Id] = member.Name
+		if member.Active {
+			stats.ActiveMembers++
+		}
+	}
+
+	// Board columns, in the order of the board
+	columns := append([]PlanStatus(nil), statuses...)
+	sort.SliceStable(columns, func(i, j int) bool { return columns[i].Position < columns[j].Position })
+	byStatus := map[ID]int{}
+	for _, task := range tasks {
+		stats.TasksByState[task.State()]++
+		byStatus[task.PlanStatusId]++
+	}
+	for _, column := range columns {
+		stats.TasksByStatus = append(stats.TasksByStatus, StatusCount{StatusId: column.Id, Name: column.Name, Tasks: byStatus[column.Id]})
+	}
+	// Tasks in no column, or in columns missing from statuses
+	if unknown := len(tasks) - countTasks(stats.TasksByStatus); unknown > 0 {
+		stats.TasksByStatus = append(stats.TasksByStatus, StatusCount{Name: "No status", Tasks: unknown})
+	}
+
+	weekStart := time.Monday
+	if options.SundayFirst {
+		weekStart = time.Sunday
+	}
+	weeks := map[Date]*WeekStats{}
+	for start := StartOfWeek(options.Since, weekStart); !start.After(options.Until); start = start.AddDays(7) {
+		stats.Weeks = append(stats.Weeks, WeekStats{Start: start})
+	}
+	for i := range stats.Weeks {
+		weeks[stats.Weeks[i].Start] = &stats.Weeks[i]
+	}
+	for _, task := range tasks {
+		if !task.CreatedAt.IsZero() {
+			if week, ok := weeks[StartOfWeek(DateOf(task.CreatedAt.In(time.Local)), weekStart)]; ok {
+				week.Created++
+			}
+		}
+		if task.Done && !task.UpdatedAt.IsZero() {
+			if week, ok := weeks[StartOfWeek(DateOf(task.UpdatedAt.In(time.Local)), weekStart)]; ok {
+				week.Completed++
+			}
+		}
+	}
+
+	loads := map[ID]*MemberStats{}
+	for _, task := range tasks {
+		if task.Done {
+			continue
+		}
+		for _, memberId := range task.Assignees {
+			load, ok := loads[memberId]
+			if !ok {
+				load = &MemberStats{MemberId: memberId, Name: names[memberId]}
+				loads[memberId] = load
+			}
+			load.OpenTasks++
+			load.PlannedHours += task.EstimatedMinutes.Hours()
+		}
+	}
+	for _, load := range loads {
+		stats.BusiestMembers = append(stats.BusiestMembers, *load)
+	}
+	sort.Slice(stats.BusiestMembers, func(i, j int) bool {
+		a, b := stats.BusiestMembers[i], stats.BusiestMembers[j]
+		if a.PlannedHours != b.PlannedHours {
+			return a.PlannedHours > b.PlannedHours
+		}
+		if a.OpenTasks != b.OpenTasks {
+			return a.OpenTasks > b.OpenTasks
+		}
+		return a.MemberId < b.MemberId
+	})
+	if len(stats.BusiestMembers) > options.TopMembers {
+		stats.BusiestMembers = stats.BusiestMembers[:options.TopMembers]
+	}
+
+	return stats
+}
+
+// countTasks adds up the tasks of counts.
+func countTasks(counts []StatusCount) int {
+	total := 0
+	for _, count := range counts {
+		total += count.Tasks
+	}
+	return total
+}
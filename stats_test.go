@@ -0,0 +1,53 @@
+package togglplanapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestComputeStats(t *testing.T) {
+	monday := NewDate(2024, 3, 4)
+	at := func(date Date) DateTime { return DateTime{Time: date.At(12, 0, time.Local)} }
+
+	tasks := []Task{
+		{Id: 1, StartDate: monday, EndDate: monday, Assignees: []ID{3}, EstimatedMinutes: 6 * EstimateHour, PlanStatusId: 20, CreatedAt: at(monday)},
+		{Id: 2, StartDate: monday, EndDate: monday, Assignees: []ID{3, 4}, EstimatedMinutes: 2 * EstimateHour, PlanStatusId: 21, CreatedAt: at(monday)},
+		{Id: 3, StartDate: monday, EndDate: monday, Assignees: []ID{4}, EstimatedMinutes: 10 * EstimateHour, Done: true, PlanStatusId: 21, CreatedAt: at(monday.AddDays(-7)), UpdatedAt: at(monday.AddDays(2))},
+		{Id: 4, StartDate: monday.AddDays(7), EndDate: monday.AddDays(7), Assignees: []ID{5}, EstimatedMinutes: EstimateHour},
+	}
+	projects := []Project{{Id: 1}, {Id: 2, Archived: true}, {Id: 3}}
+	members := []Member{{Id: 3, Name: "Ada", Active: true}, {Id: 4, Name: "Linus", Active: true}, {Id: 5, Name: "Grace"}}
+	statuses := []PlanStatus{{Id: 21, Name: "Done", Position: 2}, {Id: 20, Name: "To do", Position: 1}}
+
+	stats := ComputeStats(tasks, projects, members, statuses, StatsOptions{
+		Since:      monday.AddDays(-7),
+		Until:      monday.AddDays(13),
+		TopMembers: 2,
+	})
+
+	if stats.ActiveProjects != 2 || stats.ArchivedProjects != 1 || stats.ActiveMembers != 2 {
+		t.Fatalf("unexpected counts %+v", stats)
+	}
+	if stats.Tasks != 4 || stats.TasksByState[StateDone] != 1 || stats.TasksByState[StateScheduled] != 3 {
+		t.Fatalf("unexpected states %v", stats.TasksByState)
+	}
+
+	want := []StatusCount{{20, "To do", 1}, {21, "Done", 2}, {0, "No status", 1}}
+	if len(stats.TasksByStatus) != len(want) {
+		t.Fatalf("unexpected statuses %+v", stats.TasksByStatus)
+	}
+	for i := range want {
+		if stats.TasksByStatus[i] != want[i] {
+			t.Fatalf("unexpected status %+v, want %+v", stats.TasksByStatus[i], want[i])
+		}
+	}
+
+	if len(stats.Weeks) != 3 || stats.Weeks[0].Created != 1 || stats.Weeks[1].Created != 2 || stats.Weeks[1].Completed != 1 {
+		t.Fatalf("unexpected weeks %+v", stats.Weeks)
+	}
+
+	busiest := stats.BusiestMembers
+	if len(busiest) != 2 || busiest[0].MemberId != 3 || busiest[0].PlannedHours != 8 || busiest[0].OpenTasks != 2 || busiest[1].Name != "Linus" {
+		t.Fatalf("unexpected busiest members %+v", busiest)
+	}
+}
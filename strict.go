@@ -0,0 +1,155 @@
+package togglplanapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UnknownFieldsError reports the fields of a response that the model it was
+// decoded into doesn't have, e.g. because Toggl added or renamed fields.
+type UnknownFieldsError struct {
+	// Type is the Go type the response was decoded into.
+	Type string
+	// Fields are the paths of the unknown fields, sorted, such as "priority"
+	// or "checklist[].weight". Fields of unknown objects aren't listed.
+	Fields []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return "response has fields unknown to " + e.Type + ": " + strings.Join(e.Fields, ", ")
+}
+
+// decodeChecked decodes a JSON value from r into v with decode, then returns
+// the fields of the value that v doesn't have as an *UnknownFieldsError, or
+// nil if there are none.
+func decodeChecked(r io.Reader, v interface{}, decode func(r io.Reader, v interface{}) error) (*UnknownFieldsError, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if err := decode(bytes.NewReader(data), v); err != nil {
+		return nil, err
+	}
+
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil
+	}
+	t := reflect.TypeOf(v)
+	var fields []string
+	findUnknownFields(raw, t, "", &fields)
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	sort.Strings(fields)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return &UnknownFieldsError{Type: t.String(), Fields: fields}, nil
+}
+
+// unmarshalerType is the type of json.Unmarshaler.
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// findUnknownFields appends to fields the paths of the fields of value,
+// decoded from JSON, that type t doesn't have. Types that decode themselves,
+// such as Date, are trusted to handle their whole value.
+func findUnknownFields(value interface{}, t reflect.Type, path string, fields *[]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		switch t.Kind() {
+		case reflect.Struct:
+			known := decodedFields(t)
+			for key, field := range value {
+				fieldType, ok := known[key]
+				if !ok {
+					// encoding/json matches names case-insensitively too
+					for name, candidate := range known {
+						if strings.EqualFold(name, key) {
+							fieldType, ok = candidate, true
+							break
+						}
+					}
+				}
+				if !ok {
+					*fields = append(*fields, path+key)
+					continue
+				}
+				findUnknownFields(field, fieldType, path+key+".", fields)
+			}
+		case reflect.Map:
+			for key, field := range value {
+				findUnknownFields(field, t.Elem(), path+key+".", fields)
+			}
+		}
+
+	case []interface{}:
+		if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+			return
+		}
+		// Report each field once, whichever items have it. Fields of the
+		// items of a list response are reported without a prefix.
+		itemPath := ""
+		if path != "" {
+			itemPath = strings.TrimSuffix(path, ".") + "[]."
+		}
+		seen := map[string]bool{}
+		var itemFields []string
+		for _, item := range value {
+			findUnknownFields(item, t.Elem(), itemPath, &itemFields)
+		}
+		for _, field := range itemFields {
+			if !seen[field] {
+				seen[field] = true
+				*fields = append(*fields, field)
+			}
+		}
+	}
+}
+
+// decodedFields returns the types of the fields of struct type t by JSON
+// name, following the rules of encoding/json for tags and embedded structs.
+func decodedFields(t reflect.Type) map[string]reflect.Type {
+	fields := map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for embeddedName, embeddedType := range decodedFields(fieldType) {
+				if _, ok := fields[embeddedName]; !ok {
+					fields[embeddedName] = embeddedType
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
@@ -0,0 +1,189 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Tag represents a tag that can be applied to tasks.
+type Tag struct {
+	Id    ID     `json:"id"`
+	Name  string `json:"name"`
+	Color Color  `json:"color"`
+}
+
+// TagParams holds the fields of a tag to create.
+type TagParams struct {
+	Name  string `json:"name"`
+	Color Color  `json:"color,omitempty"`
+}
+
+// GetTags fetches all tags of a workspace.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+func GetTags(ctx context.Context, pa *togglPlanApi, workspaceId ID) ([]Tag, error) {
+	var tags []Tag
+	err := getJSON(ctx, pa, workspacePath(workspaceId, "/tags"), nil, &tags)
+	return tags, err
+}
+
+// CreateTag creates a tag in a workspace and returns it.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	params: Fields of the new tag
+func CreateTag(ctx context.Context, pa *togglPlanApi, workspaceId ID, params TagParams) (*Tag, error) {
+	var tag Tag
+	if err := sendJSON(ctx, pa, "POST", workspacePath(workspaceId, "/tags"), nil, params, &tag); err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// RetagOptions configures MergeTags and RenameTag.
+type RetagOptions struct {
+	// Since and Until set the range of tasks retagged, since tasks can only
+	// be listed by date. Default to two years before and after the current day.
+	Since Date
+	Until Date
+
+	// Concurrency is the number of tasks updated at the same time.
+	// Defaults to 4.
+	Concurrency int
+
+	// Progress, if set, receives the progress of the updates, counted in
+	// tasks in the "tasks" phase.
+	Progress ProgressFunc
+}
+
+// RetagResult reports the outcome of retagging one task.
+type RetagResult struct {
+	TaskId ID
+	// Tags holds the new tags of the task.
+	Tags []string
+	// Err is the reason the task couldn't be updated, if any.
+	Err error
+}
+
+// MergeTags replaces the tags fromTags with toTag on every task of a
+// workspace, and reports the outcome for each task that had one of them. The
+// tag toTag is created first if it doesn't exist, with the color of the first
+// of fromTags found. A failed update doesn't stop the others, so the merge
+// can be run again to retry them. The old tags are left in the workspace.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	fromTags: Names of the tags to replace
+//	toTag: Name of the tag replacing them
+//	options: Task range, concurrency and progress reporting (use `togglplanapi.RetagOptions{}` for the defaults)
+func MergeTags(ctx context.Context, pa *togglPlanApi, workspaceId ID, fromTags []string, toTag string, options RetagOptions) ([]RetagResult, error) {
+	now := time.Now()
+	if options.Since.IsZero() {
+		options.Since = DateOf(now).AddDate(-2, 0, 0)
+	}
+	if options.Until.IsZero() {
+		options.Until = DateOf(now).AddDate(2, 0, 0)
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 4
+	}
+
+	if strings.TrimSpace(toTag) == "" {
+		return nil, fmt.Errorf("missing name of the new tag")
+	}
+	if err := ensureTag(ctx, pa, workspaceId, fromTags, toTag); err != nil {
+		return nil, err
+	}
+
+	tasks, err := ListAllTasks(ctx, pa, workspaceId, TaskFilter{Since: options.Since, Until: options.Until})
+	if err != nil {
+		return nil, fmt.Errorf("reading tasks: %w", err)
+	}
+
+	var results []RetagResult
+	for _, task := range tasks {
+		if tags, changed := retag(task.Tags, fromTags, toTag); changed {
+			results = append(results, RetagResult{TaskId: task.Id, Tags: tags})
+		}
+	}
+
+	progress := options.Progress.Counter("tasks", len(results))
+	jobs := make([]func(ctx context.Context) error, len(results))
+	for i := range results {
+		result := &results[i]
+		jobs[i] = func(ctx context.Context) error {
+			defer progress.Add()
+			_, err := UpdateTask(ctx, pa, workspaceId, result.TaskId, TaskUpdate{Tags: &result.Tags})
+			return err
+		}
+	}
+
+	for i, err := range RunBatch(ctx, options.Concurrency, jobs) {
+		results[i].Err = err
+	}
+	return results, nil
+}
+
+// RenameTag renames a tag on every task of a workspace, like MergeTags with
+// a single tag.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	from: Current name of the tag
+//	to: New name of the tag
+//	options: Task range, concurrency and progress reporting (use `togglplanapi.RetagOptions{}` for the defaults)
+func RenameTag(ctx context.Context, pa *togglPlanApi, workspaceId ID, from string, to string, options RetagOptions) ([]RetagResult, error) {
+	return MergeTags(ctx, pa, workspaceId, []string{from}, to, options)
+}
+
+// ensureTag creates the tag toTag unless it exists, with the color of the
+// first of fromTags found.
+func ensureTag(ctx context.Context, pa *togglPlanApi, workspaceId ID, fromTags []string, toTag string) error {
+	tags, err := GetTags(ctx, pa, workspaceId)
+	if err != nil {
+		return fmt.Errorf("reading tags: %w", err)
+	}
+
+	params := TagParams{Name: toTag}
+	for _, tag := range tags {
+		if tag.Name == toTag {
+			return nil
+		}
+		if params.Color == ColorNone && contains(fromTags, tag.Name) {
+			params.Color = tag.Color
+		}
+	}
+
+	if _, err := CreateTag(ctx, pa, workspaceId, params); err != nil {
+		return fmt.Errorf("creating tag %q: %w", toTag, err)
+	}
+	return nil
+}
+
+// retag returns tags with those in fromTags replaced by toTag, keeping their
+// order, and whether any was replaced.
+func retag(tags []string, fromTags []string, toTag string) ([]string, bool) {
+	result := make([]string, 0, len(tags))
+	changed := false
+	for _, tag := range tags {
+		if tag != toTag && contains(fromTags, tag) {
+			changed = true
+			tag = toTag
+		}
+		if !contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	return result, changed
+}
@@ -0,0 +1,76 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestMergeTags(t *testing.T) {
+	var mu sync.Mutex
+	var created TagParams
+	updates := map[string][]string{}
+
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v5/1/tags":
+			fmt.Fprint(w, `[{"id":1,"name":"bug","color":1},{"id":2,"name":"Bug","color":2}]`)
+		case r.Method == "POST" && r.URL.Path == "/api/v5/1/tags":
+			json.NewDecoder(r.Body).Decode(&created)
+			fmt.Fprint(w, `{"id":3}`)
+		case r.Method == "GET" && r.URL.Path == "/api/v5/1/tasks":
+			fmt.Fprint(w, `[{"id":10,"tags":["bug","urgent","Bug"]},{"id":11,"tags":["urgent"]},{"id":12,"tags":["Bug"]}]`)
+		case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/api/v5/1/tasks/"):
+			if r.URL.Path == "/api/v5/1/tasks/12" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				return
+			}
+			var update struct{ Tags []string }
+			json.NewDecoder(r.Body).Decode(&update)
+			updates[r.URL.Path] = update.Tags
+			fmt.Fprint(w, `{"id":10}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	var progress []int
+	results, err := MergeTags(context.Background(), pa, 1, []string{"bug", "Bug"}, "defect", RetagOptions{
+		Since:    NewDate(2024, 3, 1),
+		Until:    NewDate(2024, 3, 31),
+		Progress: func(update ProgressUpdate) { progress = append(progress, update.Done, update.Total) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created.Name != "defect" || created.Color != ColorRed {
+		t.Fatalf("unexpected new tag %+v", created)
+	}
+	if len(results) != 2 || results[0].TaskId != 10 || results[0].Err != nil || results[1].TaskId != 12 || results[1].Err == nil {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if tags := updates["/api/v5/1/tasks/10"]; strings.Join(tags, ",") != "defect,urgent" {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+	if len(progress) != 6 || progress[0] != 0 || progress[4] != 2 || progress[5] != 2 {
+		t.Fatalf("unexpected progress %v", progress)
+	}
+}
+
+func TestRenameTagMissingName(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("no request should be sent")
+	})
+
+	if _, err := RenameTag(context.Background(), pa, 1, "bug", " ", RetagOptions{}); err == nil {
+		t.Fatal("expected an error for a missing tag name")
+	}
+}
@@ -0,0 +1,363 @@
+package togglplanapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Task represents a task on the Toggl Plan timeline.
+//
+// Tasks without a StartTime and EndTime are all-day tasks. Times are
+// formatted as HH:MM, and EndDate is inclusive. Position orders the task
+// among the others of its list, such as a column of the board, lowest first:
+// see MoveBefore.
+type Task struct {
+	Id               ID              `json:"id"`
+	Name             string          `json:"name"`
+	Notes            string          `json:"notes"`
+	StartDate        Date            `json:"start_date"`
+	EndDate          Date            `json:"end_date"`
+	StartTime        string          `json:"start_time"`
+	EndTime          string          `json:"end_time"`
+	Color            Color           `json:"color"`
+	EstimatedMinutes Estimate        `json:"estimated_minutes"`
+	Done             bool            `json:"done"`
+	ProjectId        ID              `json:"project_id"`
+	MilestoneId      ID              `json:"milestone_id"`
+	PlanStatusId     ID              `json:"plan_status_id"`
+	Assignees        []ID            `json:"workspace_members"`
+	Tags             []string        `json:"tags"`
+	Checklist        []ChecklistItem `json:"checklist"`
+	Position         float64         `json:"position"`
+	CreatedAt        DateTime        `json:"created_at"`
+	UpdatedAt        DateTime        `json:"updated_at"`
+}
+
+// TaskFilter narrows down the tasks returned by GetTasks.
+// Since and Until are required by the API, and are inclusive.
+//
+// The API doesn't document field selection or sorting for tasks. Fields and
+// Sort are sent as the fields and sort parameters, and applied to the
+// response as well, so that tasks come back the same whether the API honors
+// them or not.
+type TaskFilter struct {
+	Since      Date
+	Until      Date
+	ProjectIds []ID
+	MemberIds  []ID
+
+	// Fields lists the fields to return by their JSON name, such as "name"
+	// or "start_date", to shrink responses. Other fields are left unset,
+	// except the ID. All fields are returned by default.
+	Fields []string
+
+	// Sort orders the tasks by a field, given by its JSON name, or in
+	// descending order if it starts with "-", e.g. "-start_date".
+	Sort string
+
+	// ServerOrder keeps the tasks of ListAllTasks in the order the API
+	// returned them, when Sort is empty, instead of sorting them by ID.
+	ServerOrder bool
+
+	// Progress, if set, receives the progress of ListAllTasks, counted in
+	// date windows fetched. GetTasks ignores it.
+	Progress ProgressFunc
+}
+
+// TaskParams holds the fields of a task to create.
+// Times are formatted as HH:MM; leave them empty for an all-day task.
+// Dates left unset are sent as null, creating a task without dates.
+type TaskParams struct {
+	Name             string          `json:"name"`
+	Notes            string          `json:"notes,omitempty"`
+	StartDate        Date            `json:"start_date"`
+	EndDate          Date            `json:"end_date"`
+	StartTime        string          `json:"start_time,omitempty"`
+	EndTime          string          `json:"end_time,omitempty"`
+	Color            Color           `json:"color,omitempty"`
+	EstimatedMinutes Estimate        `json:"estimated_minutes,omitempty"`
+	Done             bool            `json:"done,omitempty"`
+	ProjectId        ID              `json:"project_id,omitempty"`
+	MilestoneId      ID              `json:"milestone_id,omitempty"`
+	PlanStatusId     ID              `json:"plan_status_id,omitempty"`
+	Assignees        []ID            `json:"workspace_members,omitempty"`
+	Tags             []string        `json:"tags,omitempty"`
+	Checklist        []ChecklistItem `json:"checklist,omitempty"`
+	Position         float64         `json:"position,omitempty"`
+}
+
+// TaskUpdate holds the fields of a task to change.
+// Fields left nil or unset are not sent, and keep their current value.
+// Set ProjectId, MilestoneId or PlanStatusId to null to clear them.
+type TaskUpdate struct {
+	Name             *string          `json:"name,omitempty"`
+	Notes            *string          `json:"notes,omitempty"`
+	StartDate        *Date            `json:"start_date,omitempty"`
+	EndDate          *Date            `json:"end_date,omitempty"`
+	StartTime        *string          `json:"start_time,omitempty"`
+	EndTime          *string          `json:"end_time,omitempty"`
+	Color            *Color           `json:"color,omitempty"`
+	EstimatedMinutes *Estimate        `json:"estimated_minutes,omitempty"`
+	Done             *bool            `json:"done,omitempty"`
+	ProjectId        Optional[ID]     `json:"project_id"`
+	MilestoneId      Optional[ID]     `json:"milestone_id"`
+	PlanStatusId     Optional[ID]     `json:"plan_status_id"`
+	Assignees        *[]ID            `json:"workspace_members,omitempty"`
+	Tags             *[]string        `json:"tags,omitempty"`
+	Checklist        *[]ChecklistItem `json:"checklist,omitempty"`
+	Position         *float64         `json:"position,omitempty"`
+}
+
+// MarshalJSON encodes the fields set in the update.
+func (update TaskUpdate) MarshalJSON() ([]byte, error) {
+	return marshalUpdate(update)
+}
+
+// ChecklistItem is an entry of a task's checklist.
+type ChecklistItem struct {
+	Name string `json:"name"`
+	Done bool   `json:"done"`
+}
+
+// query converts the filter into query string parameters.
+func (filter TaskFilter) query() url.Values {
+	query := url.Values{}
+
+	if !filter.Since.IsZero() {
+		query.Set("since", filter.Since.String())
+	}
+	if !filter.Until.IsZero() {
+		query.Set("until", filter.Until.String())
+	}
+	if len(filter.ProjectIds) > 0 {
+		query.Set("project_ids", joinIds(filter.ProjectIds))
+	}
+	if len(filter.MemberIds) > 0 {
+		query.Set("member_ids", joinIds(filter.MemberIds))
+	}
+	if len(filter.Fields) > 0 {
+		query.Set("fields", strings.Join(filter.requestedFields(), ","))
+	}
+	if filter.Sort != "" {
+		query.Set("sort", filter.Sort)
+	}
+
+	return query
+}
+
+// requestedFields returns the fields to request: Fields, and the sort field
+// which is needed to sort the response.
+func (filter TaskFilter) requestedFields() []string {
+	key := strings.TrimPrefix(filter.Sort, "-")
+	if len(filter.Fields) == 0 || key == "" {
+		return filter.Fields
+	}
+	for _, field := range filter.Fields {
+		if field == key {
+			return filter.Fields
+		}
+	}
+	return append(append([]string(nil), filter.Fields...), key)
+}
+
+// GetTasks fetches the tasks of a workspace matching filter.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	filter: Date range and optional project/member restrictions
+func GetTasks(ctx context.Context, pa *togglPlanApi, workspaceId ID, filter TaskFilter) ([]Task, error) {
+	if err := checkFields[Task](filter.Fields, filter.Sort); err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+	if err := getJSON(ctx, pa, workspacePath(workspaceId, "/tasks"), filter.query(), &tasks); err != nil {
+		return tasks, err
+	}
+
+	sortByField(tasks, filter.Sort)
+	selectFields(tasks, filter.Fields)
+	return tasks, nil
+}
+
+// GetTask fetches a single task. If it doesn't exist, the error matches
+// ErrNotFound, or the task is nil, see WithNotFoundAsNil.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	taskId: ID of the task
+func GetTask(ctx context.Context, pa *togglPlanApi, workspaceId ID, taskId ID) (*Task, error) {
+	return getOne[Task](ctx, pa, workspacePath(workspaceId, "/tasks/%d", taskId))
+}
+
+// CreateTask creates a task in a workspace and returns it.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	params: Fields of the new task
+func CreateTask(ctx context.Context, pa *togglPlanApi, workspaceId ID, params TaskParams) (*Task, error) {
+	var task Task
+	if err := sendJSON(ctx, pa, "POST", workspacePath(workspaceId, "/tasks"), nil, params, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdateTask changes the fields of a task set in update, and returns the updated task.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	taskId: ID of the task
+//	update: Fields to change
+func UpdateTask(ctx context.Context, pa *togglPlanApi, workspaceId ID, taskId ID, update TaskUpdate) (*Task, error) {
+	var task Task
+	if err := sendJSON(ctx, pa, "PUT", workspacePath(workspaceId, "/tasks/%d", taskId), nil, update, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// UpdateTaskIfUnchanged works like UpdateTask, but only applies update if
+// the task wasn't changed since expected, the UpdatedAt of the copy the
+// update is based on. Otherwise it fails with a *ConflictError matching
+// ErrConflict, and returns the current task so that the caller can resolve
+// the conflict.
+//
+// The task is checked just before the update, and the update carries an
+// If-Unmodified-Since header, so a change made in between is only caught if
+// the server honors the header.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	taskId: ID of the task
+//	expected: UpdatedAt of the task the update is based on
+//	update: Fields to change
+func UpdateTaskIfUnchanged(ctx context.Context, pa *togglPlanApi, workspaceId ID, taskId ID, expected DateTime, update TaskUpdate) (*Task, error) {
+	current, err := GetTask(NotFoundAsNil(ctx, false), pa, workspaceId, taskId)
+	if err != nil {
+		return nil, err
+	}
+	if !current.UpdatedAt.Equal(expected.Time) {
+		return current, &ConflictError{Resource: fmt.Sprintf("task %d", taskId), Expected: expected, Actual: current.UpdatedAt}
+	}
+
+	headers := map[string]string{"If-Unmodified-Since": expected.UTC().Format(http.TimeFormat)}
+
+	var task Task
+	if err := sendJSONWithHeaders(ctx, pa, "PUT", workspacePath(workspaceId, "/tasks/%d", taskId), nil, headers, update, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// DeleteTask deletes a task.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	taskId: ID of the task
+func DeleteTask(ctx context.Context, pa *togglPlanApi, workspaceId ID, taskId ID) error {
+	return sendJSON(ctx, pa, "DELETE", workspacePath(workspaceId, "/tasks/%d", taskId), nil, nil, nil)
+}
+
+// taskWindowDays is the length of the date windows ListAllTasks requests at a time.
+const taskWindowDays = 31
+
+// ListAllTasks fetches the tasks of a workspace matching filter, like GetTasks,
+// but splits long date ranges into smaller windows that are requested one
+// after the other. This keeps each response small, no matter how many months
+// the filter spans.
+// Tasks spanning several windows are returned only once.
+//
+// The order of the API isn't documented, and may change between releases
+// or windows, so tasks are sorted by ID, or by Sort and then by ID if it is
+// set. Results are the same from one run to the next as long as the tasks
+// are, which keeps diffs and golden files stable. Set ServerOrder to keep
+// the order in which tasks were first seen instead.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	filter: Date range and optional project/member restrictions
+func ListAllTasks(ctx context.Context, pa *togglPlanApi, workspaceId ID, filter TaskFilter) ([]Task, error) {
+	if filter.Since.IsZero() || filter.Until.IsZero() {
+		all := filter
+		all.Fields = filter.requestedFields()
+		tasks, err := GetTasks(ctx, pa, workspaceId, all)
+		if err != nil {
+			return tasks, err
+		}
+		sortTasks(tasks, filter)
+		selectFields(tasks, filter.Fields)
+		return tasks, nil
+	}
+
+	var tasks []Task
+	seen := map[ID]bool{}
+
+	windows := (filter.Since.DaysUntil(filter.Until) + taskWindowDays) / taskWindowDays
+	progress := filter.Progress.Counter("tasks", windows)
+
+	for since := filter.Since; !since.After(filter.Until); since = since.AddDays(taskWindowDays) {
+		// Keep the sort field until the windows are merged and sorted
+		window := filter
+		window.Fields = filter.requestedFields()
+		window.Since = since
+		window.Until = since.AddDays(taskWindowDays - 1)
+		if window.Until.After(filter.Until) {
+			window.Until = filter.Until
+		}
+
+		page, err := GetTasks(ctx, pa, workspaceId, window)
+		if err != nil {
+			return tasks, err
+		}
+
+		for _, task := range page {
+			if !seen[task.Id] {
+				seen[task.Id] = true
+				tasks = append(tasks, task)
+			}
+		}
+		progress.Add()
+	}
+
+	sortTasks(tasks, filter)
+	selectFields(tasks, filter.Fields)
+	return tasks, nil
+}
+
+// sortTasks puts the tasks of ListAllTasks in the order of filter: by Sort
+// then by ID, or by ID alone, unless ServerOrder keeps them as they are.
+func sortTasks(tasks []Task, filter TaskFilter) {
+	if filter.ServerOrder && filter.Sort == "" {
+		return
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].Id < tasks[j].Id })
+	sortByField(tasks, filter.Sort)
+}
+
+// joinIds formats ids as a comma-separated list.
+func joinIds(ids []ID) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = id.String()
+	}
+	return strings.Join(parts, ",")
+}
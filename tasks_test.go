@@ -0,0 +1,227 @@
+package togglplanapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetTasks(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/42/tasks" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("since") != "2024-03-01" || r.URL.Query().Get("project_ids") != "1,2" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `[{"id":7,"name":"Design","start_date":"2024-03-04","end_date":"2024-03-05","workspace_members":[3]}]`)
+	})
+
+	filter := TaskFilter{
+		Since:      NewDate(2024, 3, 1),
+		Until:      NewDate(2024, 3, 31),
+		ProjectIds: []ID{1, 2},
+	}
+
+	tasks, err := GetTasks(context.Background(), pa, 42, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tasks) != 1 || tasks[0].Id != 7 || tasks[0].Assignees[0] != 3 {
+		t.Fatalf("unexpected tasks %+v", tasks)
+	}
+}
+
+func TestListAllTasks(t *testing.T) {
+	var windows []string
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		windows = append(windows, r.URL.Query().Get("since")+"/"+r.URL.Query().Get("until"))
+		// Task 1 spans every window and must only be returned once
+		fmt.Fprintf(w, `[{"id":1},{"id":%d}]`, len(windows)+1)
+	})
+
+	filter := TaskFilter{
+		Since: NewDate(2024, 1, 1),
+		Until: NewDate(2024, 3, 1),
+	}
+
+	tasks, err := ListAllTasks(context.Background(), pa, 42, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedWindows := []string{"2024-01-01/2024-01-31", "2024-02-01/2024-03-01"}
+	if fmt.Sprint(windows) != fmt.Sprint(expectedWindows) {
+		t.Fatalf("requested windows %v, expected %v", windows, expectedWindows)
+	}
+
+	if len(tasks) != 3 || tasks[0].Id != 1 || tasks[1].Id != 2 || tasks[2].Id != 3 {
+		t.Fatalf("unexpected tasks %+v", tasks)
+	}
+}
+
+func TestListAllTasksFieldsAndSort(t *testing.T) {
+	requests := 0
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if fields := r.URL.Query().Get("fields"); fields != "name,start_date" {
+			t.Errorf("unexpected fields %q", fields)
+		}
+		if r.URL.Query().Get("sort") != "-start_date" {
+			t.Errorf("unexpected sort %q", r.URL.Query().Get("sort"))
+		}
+		// The API ignores both parameters
+		if requests == 1 {
+			fmt.Fprint(w, `[{"id":1,"name":"B","notes":"x","start_date":"2024-01-05"},{"id":2,"name":"A","notes":"y","start_date":"2024-01-20"}]`)
+		} else {
+			fmt.Fprint(w, `[{"id":3,"name":"C","notes":"z","start_date":"2024-02-10"}]`)
+		}
+	})
+
+	filter := TaskFilter{
+		Since:  NewDate(2024, 1, 1),
+		Until:  NewDate(2024, 3, 1),
+		Fields: []string{"name"},
+		Sort:   "-start_date",
+	}
+
+	tasks, err := ListAllTasks(context.Background(), pa, 42, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tasks) != 3 || tasks[0].Id != 3 || tasks[1].Id != 2 || tasks[2].Id != 1 {
+		t.Fatalf("expected tasks sorted by descending start date, got %+v", tasks)
+	}
+	if tasks[0].Name != "C" || tasks[0].Notes != "" || !tasks[0].StartDate.IsZero() {
+		t.Fatalf("expected only the name to be kept, got %+v", tasks[0])
+	}
+
+	if _, err := GetTasks(context.Background(), pa, 42, TaskFilter{Sort: "priority"}); err == nil || requests != 2 {
+		t.Fatalf("expected an unknown sort field to be rejected without a request, got %v", err)
+	}
+}
+
+func TestListAllTasksOrder(t *testing.T) {
+	requests := 0
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		// Each window comes back in its own order, and task 5 in both
+		if requests == 1 {
+			fmt.Fprint(w, `[{"id":9,"start_date":"2024-01-10"},{"id":5,"start_date":"2024-01-20"},{"id":2,"start_date":"2024-01-10"}]`)
+		} else {
+			fmt.Fprint(w, `[{"id":7,"start_date":"2024-02-01"},{"id":5,"start_date":"2024-01-20"},{"id":3,"start_date":"2024-01-10"}]`)
+		}
+	})
+
+	ids := func(filter TaskFilter) string {
+		requests = 0
+		filter.Since = NewDate(2024, 1, 1)
+		filter.Until = NewDate(2024, 3, 1)
+		tasks, err := ListAllTasks(context.Background(), pa, 42, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []ID
+		for _, task := range tasks {
+			ids = append(ids, task.Id)
+		}
+		return fmt.Sprint(ids)
+	}
+
+	if order := ids(TaskFilter{}); order != "[2 3 5 7 9]" {
+		t.Fatalf("expected tasks sorted by ID, got %s", order)
+	}
+	if order := ids(TaskFilter{Sort: "start_date"}); order != "[2 3 9 5 7]" {
+		t.Fatalf("expected tasks sorted by start date then ID, got %s", order)
+	}
+	if order := ids(TaskFilter{ServerOrder: true}); order != "[9 5 2 7 3]" {
+		t.Fatalf("expected tasks in the order first seen, got %s", order)
+	}
+
+	positions := []Task{{Id: 1, Position: 3}, {Id: 2, Position: 1.5}, {Id: 3, Position: 2}, {Id: 4, Position: 1.25}}
+	sortTasks(positions, TaskFilter{Sort: "position"})
+	if order := fmt.Sprint(positions[0].Id, positions[1].Id, positions[2].Id, positions[3].Id); order != "4 2 3 1" {
+		t.Fatalf("expected tasks sorted by position, got %s", order)
+	}
+
+	if _, err := ListAllTasks(context.Background(), pa, 42, TaskFilter{Sort: "tags"}); err == nil {
+		t.Fatal("expected sorting by a list to be rejected")
+	}
+}
+
+func TestUpdateTaskIfUnchanged(t *testing.T) {
+	updated := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	puts := 0
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			fmt.Fprintf(w, `{"id":7,"name":"Design","updated_at":%q}`, updated.Format(time.RFC3339))
+		case "PUT":
+			puts++
+			if r.Header.Get("If-Unmodified-Since") != "Mon, 04 Mar 2024 10:00:00 GMT" {
+				t.Errorf("unexpected precondition %q", r.Header.Get("If-Unmodified-Since"))
+			}
+			fmt.Fprint(w, `{"id":7,"name":"Review"}`)
+		}
+	})
+
+	update := TaskUpdate{Name: String("Review")}
+
+	task, err := UpdateTaskIfUnchanged(context.Background(), pa, 42, 7, DateTime{Time: updated}, update)
+	if err != nil || task.Name != "Review" {
+		t.Fatalf("unexpected result %+v, %v", task, err)
+	}
+
+	stale := DateTime{Time: updated.Add(-time.Hour)}
+	current, err := UpdateTaskIfUnchanged(context.Background(), pa, 42, 7, stale, update)
+	if !errors.Is(err, ErrConflict) || current == nil || current.Name != "Design" {
+		t.Fatalf("expected a conflict with the current task, got %+v, %v", current, err)
+	}
+	if puts != 1 {
+		t.Fatalf("expected the stale update not to be sent")
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		fmt.Fprint(w, `{"message":"task was modified"}`)
+	})
+
+	_, err := UpdateTask(context.Background(), pa, 42, 7, TaskUpdate{Done: Bool(true)})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict || apiErr.Body != `{"message":"task was modified"}` {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if !errors.Is(err, ErrConflict) {
+		t.Fatal("expected a 409 to match ErrConflict")
+	}
+}
+
+func FuzzTaskUnmarshalJSON(f *testing.F) {
+	f.Add([]byte(`{"id":7,"name":"Design","project_id":"3","start_date":"2024-03-01","end_date":null,"assignees":[1,"2"],"checklist":[{"name":"Review","done":true}],"created_at":"2024-02-01 10:00:00"}`))
+	f.Add([]byte(`{"id":null,"estimated_minutes":"90","tags":null}`))
+	f.Add([]byte(`[]`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Malformed tasks must fail to decode rather than panic, and decoded
+		// tasks must work with the helpers reading them
+		var task Task
+		if err := json.Unmarshal(data, &task); err != nil {
+			return
+		}
+		task.State()
+		if _, err := json.Marshal(task); err != nil {
+			var marshalErr *json.MarshalerError
+			if !errors.As(err, &marshalErr) {
+				t.Fatalf("encoding %+v: %v", task, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"togglplanapi"
+)
+
+// defaultConcurrency is the number of requests sent at the same time, unless
+// configured otherwise.
+const defaultConcurrency = 4
+
+// Options configures Template.Instantiate.
+type Options struct {
+	// Name replaces the name of the project of the template, e.g. to number
+	// sprints.
+	Name string
+
+	// Members gives a member to each role used by the tasks of the template.
+	Members map[string]togglplanapi.ID
+
+	// Calendar, if set, counts the days of the template in workdays, so
+	// that nothing lands on a weekend or a holiday.
+	Calendar *togglplanapi.Calendar
+
+	// Concurrency is the maximum number of requests sent at the same time.
+	// Defaults to 4.
+	Concurrency int
+}
+
+// Instance holds what was created from a template.
+type Instance struct {
+	// Project is nil if the template has no project.
+	Project    *togglplanapi.Project
+	Milestones []togglplanapi.Milestone
+	Tasks      []togglplanapi.Task
+}
+
+// Instantiate creates the project, milestones and tasks of the template in a
+// workspace, with dates counted from anchor. Milestones and tasks are created
+// in batches with togglplanapi.RunBatch.
+//
+// The template is checked before anything is created. If some milestones or
+// tasks can't be created, the others still are: the instance then holds what
+// was created, and the error lists the failures.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	anchor: Date the days of the template are counted from
+//	options: Project name, members and calendar
+func (t Template) Instantiate(ctx context.Context, pa *togglplanapi.Client, workspaceId togglplanapi.ID, anchor togglplanapi.Date, options Options) (*Instance, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+
+	plan, err := t.plan(anchor, options)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := options.Concurrency
+	if concurrency == 0 {
+		concurrency = defaultConcurrency
+	}
+
+	instance := &Instance{}
+
+	if plan.project != nil {
+		project, err := togglplanapi.CreateProject(ctx, pa, workspaceId, *plan.project)
+		if err != nil {
+			return nil, fmt.Errorf("creating project: %w", err)
+		}
+		instance.Project = project
+
+		for i := range plan.milestones {
+			plan.milestones[i].ProjectId = project.Id
+		}
+		for i := range plan.tasks {
+			plan.tasks[i].ProjectId = project.Id
+		}
+	}
+
+	milestones := make([]*togglplanapi.Milestone, len(plan.milestones))
+	jobs := make([]func(ctx context.Context) error, len(plan.milestones))
+	for i, params := range plan.milestones {
+		i, params := i, params
+		jobs[i] = func(ctx context.Context) (err error) {
+			milestones[i], err = togglplanapi.CreateMilestone(ctx, pa, workspaceId, params)
+			return err
+		}
+	}
+
+	var failures []error
+	milestoneIds := map[string]togglplanapi.ID{}
+	for i, err := range togglplanapi.RunBatch(ctx, concurrency, jobs) {
+		name := plan.milestones[i].Name
+		if err != nil {
+			failures = append(failures, fmt.Errorf("creating milestone %q: %w", name, err))
+			continue
+		}
+		instance.Milestones = append(instance.Milestones, *milestones[i])
+		milestoneIds[name] = milestones[i].Id
+	}
+
+	tasks := make([]*togglplanapi.Task, len(plan.tasks))
+	jobs = make([]func(ctx context.Context) error, len(plan.tasks))
+	for i, params := range plan.tasks {
+		i, params := i, params
+		if milestone := t.Tasks[i].Milestone; milestone != "" {
+			params.MilestoneId = milestoneIds[milestone]
+		}
+		jobs[i] = func(ctx context.Context) (err error) {
+			tasks[i], err = togglplanapi.CreateTask(ctx, pa, workspaceId, params)
+			return err
+		}
+	}
+
+	for i, err := range togglplanapi.RunBatch(ctx, concurrency, jobs) {
+		if err != nil {
+			failures = append(failures, fmt.Errorf("creating task %q: %w", plan.tasks[i].Name, err))
+			continue
+		}
+		instance.Tasks = append(instance.Tasks, *tasks[i])
+	}
+
+	return instance, errors.Join(failures...)
+}
+
+// plan holds the inputs of the requests creating a template.
+type plan struct {
+	project    *togglplanapi.ProjectParams
+	milestones []togglplanapi.MilestoneParams
+	tasks      []togglplanapi.TaskParams
+}
+
+// plan resolves the dates, colors and members of the template.
+func (t Template) plan(anchor togglplanapi.Date, options Options) (plan, error) {
+	var p plan
+
+	day := func(offset int) togglplanapi.Date {
+		if options.Calendar != nil {
+			return options.Calendar.AddWorkdays(anchor, offset)
+		}
+		return anchor.AddDays(offset)
+	}
+
+	var dates []togglplanapi.Date
+
+	for _, milestone := range t.Milestones {
+		date := day(milestone.Day)
+		dates = append(dates, date)
+		p.milestones = append(p.milestones, togglplanapi.MilestoneParams{Name: milestone.Name, Date: date})
+	}
+
+	var unknown []error
+	for _, task := range t.Tasks {
+		length := task.Days
+		if length == 0 {
+			length = 1
+		}
+
+		start := day(task.Start)
+		end := start.AddDays(length - 1)
+		if options.Calendar != nil {
+			end = options.Calendar.AddWorkdays(start, length-1)
+		}
+		dates = append(dates, start, end)
+
+		color, _ := parseColor(task.Color)
+		params := togglplanapi.TaskParams{
+			Name:             task.Name,
+			Notes:            task.Notes,
+			StartDate:        start,
+			EndDate:          end,
+			StartTime:        task.StartTime,
+			EndTime:          task.EndTime,
+			Color:            color,
+			EstimatedMinutes: togglplanapi.Hours(task.Hours),
+			Tags:             task.Tags,
+		}
+
+		for _, item := range task.Checklist {
+			params.Checklist = append(params.Checklist, togglplanapi.ChecklistItem{Name: item})
+		}
+
+		for _, role := range task.Assignees {
+			memberId, ok := options.Members[role]
+			if !ok {
+				unknown = append(unknown, fmt.Errorf("task %q: no member for role %q", task.Name, role))
+				continue
+			}
+			params.Assignees = append(params.Assignees, memberId)
+		}
+
+		p.tasks = append(p.tasks, params)
+	}
+
+	if len(unknown) > 0 {
+		return p, &togglplanapi.ValidationError{Kind: "template", Problems: unknown}
+	}
+
+	if t.Project != nil {
+		color, _ := parseColor(t.Project.Color)
+		p.project = &togglplanapi.ProjectParams{Name: t.Project.Name, Notes: t.Project.Notes, Color: color}
+		if options.Name != "" {
+			p.project.Name = options.Name
+		}
+
+		if len(dates) > 0 {
+			sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+			p.project.StartDate = dates[0]
+			p.project.EndDate = dates[len(dates)-1]
+		}
+	}
+
+	return p, nil
+}
@@ -0,0 +1,184 @@
+/*
+Package templates creates projects, milestones and tasks from reusable
+templates, such as the setup of a sprint or the onboarding of a new hire.
+
+Templates are written in Go or loaded from YAML, with dates relative to an
+anchor date given when instantiating them:
+
+	project:
+	  name: Onboarding
+	  color: teal
+	milestones:
+	  - name: First week done
+	    day: 4
+	tasks:
+	  - name: Set up laptop
+	    assignees: [it]
+	    checklist: [Email, VPN, Password manager]
+	  - name: Meet the team
+	    start: 1
+	    days: 2
+	    assignees: [newcomer]
+	    milestone: First week done
+
+Example Usage:
+
+	import (
+		"context"
+		"os"
+
+		"github.com/ricotheque/togglplanapi"
+		"github.com/ricotheque/togglplanapi/templates"
+	)
+
+	func main() {
+		pa := togglplanapi.New(username, password, clientId, clientSecret, "")
+
+		file, _ := os.Open("onboarding.yaml")
+		template, err := templates.Load(file)
+
+		instance, err := template.Instantiate(context.Background(), pa, workspaceId, togglplanapi.NewDate(2024, 3, 4), templates.Options{
+			Name:    "Onboarding Ada",
+			Members: map[string]togglplanapi.ID{"it": 12, "newcomer": 34},
+		})
+	}
+*/
+package templates
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"togglplanapi"
+)
+
+// Template describes a project, its milestones and its tasks.
+type Template struct {
+	// Project, if set, is created and holds the milestones and tasks.
+	Project    *ProjectTemplate    `yaml:"project"`
+	Milestones []MilestoneTemplate `yaml:"milestones"`
+	Tasks      []TaskTemplate      `yaml:"tasks"`
+}
+
+// ProjectTemplate describes the project of a template. The project is
+// scheduled from its earliest to its latest task or milestone.
+type ProjectTemplate struct {
+	Name  string `yaml:"name"`
+	Notes string `yaml:"notes"`
+	// Color is the name of a color, such as "red".
+	Color string `yaml:"color"`
+}
+
+// MilestoneTemplate describes a milestone of a template.
+type MilestoneTemplate struct {
+	// Name identifies the milestone within the template.
+	Name string `yaml:"name"`
+	// Day is the number of days from the anchor date to the milestone.
+	Day int `yaml:"day"`
+}
+
+// TaskTemplate describes a task of a template.
+type TaskTemplate struct {
+	Name  string `yaml:"name"`
+	Notes string `yaml:"notes"`
+	// Start is the number of days from the anchor date to the start of the task.
+	Start int `yaml:"start"`
+	// Days is the length of the task in days. Defaults to 1.
+	Days      int    `yaml:"days"`
+	StartTime string `yaml:"start_time"`
+	EndTime   string `yaml:"end_time"`
+	// Hours is the estimate of the task.
+	Hours float64 `yaml:"hours"`
+	// Color is the name of a color, such as "red".
+	Color     string   `yaml:"color"`
+	Tags      []string `yaml:"tags"`
+	Checklist []string `yaml:"checklist"`
+	// Assignees lists roles, such as "designer", which are given members
+	// when the template is instantiated.
+	Assignees []string `yaml:"assignees"`
+	// Milestone is the name of a milestone of the template.
+	Milestone string `yaml:"milestone"`
+}
+
+// Load reads a template written in YAML. Unknown fields are rejected, to
+// catch typos.
+func Load(r io.Reader) (*Template, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+
+	var template Template
+	if err := decoder.Decode(&template); err != nil {
+		return nil, fmt.Errorf("reading template: %w", err)
+	}
+
+	if err := template.Validate(); err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// Validate checks the names, colors, lengths and milestone references of the
+// template. Fields sent to the API are validated again when instantiating it.
+func (t Template) Validate() error {
+	var problems []error
+	fail := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Errorf(format, args...))
+	}
+
+	if t.Project != nil {
+		if strings.TrimSpace(t.Project.Name) == "" {
+			fail("project name is required")
+		}
+		if _, err := parseColor(t.Project.Color); err != nil {
+			fail("project: %v", err)
+		}
+	}
+
+	milestones := map[string]bool{}
+	for i, milestone := range t.Milestones {
+		if strings.TrimSpace(milestone.Name) == "" {
+			fail("milestone %d: name is required", i+1)
+		} else if milestones[milestone.Name] {
+			fail("milestone %q: name is used twice", milestone.Name)
+		}
+		milestones[milestone.Name] = true
+	}
+
+	for i, task := range t.Tasks {
+		label := fmt.Sprintf("task %d", i+1)
+		if strings.TrimSpace(task.Name) == "" {
+			fail("%s: name is required", label)
+		} else {
+			label = fmt.Sprintf("task %q", task.Name)
+		}
+
+		if task.Days < 0 {
+			fail("%s: days is negative", label)
+		}
+		if task.Hours < 0 {
+			fail("%s: hours is negative", label)
+		}
+		if _, err := parseColor(task.Color); err != nil {
+			fail("%s: %v", label, err)
+		}
+		if task.Milestone != "" && !milestones[task.Milestone] {
+			fail("%s: unknown milestone %q", label, task.Milestone)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &togglplanapi.ValidationError{Kind: "template", Problems: problems}
+	}
+	return nil
+}
+
+// parseColor parses an optional color name.
+func parseColor(name string) (togglplanapi.Color, error) {
+	if name == "" {
+		return togglplanapi.ColorNone, nil
+	}
+	return togglplanapi.ParseColor(name)
+}
@@ -0,0 +1,165 @@
+package templates
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"togglplanapi"
+	"togglplanapi/internal/testclient"
+)
+
+const onboarding = `
+project:
+  name: Onboarding
+  color: teal
+milestones:
+  - name: First week done
+    day: 4
+tasks:
+  - name: Set up laptop
+    assignees: [it]
+    checklist: [Email, VPN]
+    hours: 2
+  - name: Meet the team
+    start: 1
+    days: 3
+    assignees: [newcomer]
+    milestone: First week done
+`
+
+func TestLoadAndPlan(t *testing.T) {
+	template, err := Load(strings.NewReader(onboarding))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Thursday, so that workdays skip the weekend
+	anchor := togglplanapi.NewDate(2024, 3, 7)
+	cal := togglplanapi.NewCalendar(nil)
+
+	plan, err := template.plan(anchor, Options{Name: "Onboarding Ada", Members: map[string]togglplanapi.ID{"it": 12, "newcomer": 34}, Calendar: cal})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if plan.project.Name != "Onboarding Ada" || plan.project.Color != togglplanapi.ColorTeal {
+		t.Fatalf("unexpected project %+v", plan.project)
+	}
+	if plan.project.StartDate != anchor || plan.project.EndDate != togglplanapi.NewDate(2024, 3, 13) {
+		t.Fatalf("expected the project to span its tasks and milestones, got %s to %s", plan.project.StartDate, plan.project.EndDate)
+	}
+
+	if date := plan.milestones[0].Date; date != togglplanapi.NewDate(2024, 3, 13) {
+		t.Fatalf("expected the milestone 4 workdays after the anchor, got %s", date)
+	}
+
+	laptop := plan.tasks[0]
+	if laptop.StartDate != anchor || laptop.EndDate != anchor || laptop.EstimatedMinutes != togglplanapi.Hours(2) {
+		t.Fatalf("unexpected task %+v", laptop)
+	}
+	if len(laptop.Checklist) != 2 || laptop.Checklist[1].Name != "VPN" || laptop.Assignees[0] != 12 {
+		t.Fatalf("unexpected task %+v", laptop)
+	}
+
+	team := plan.tasks[1]
+	if team.StartDate != togglplanapi.NewDate(2024, 3, 8) || team.EndDate != togglplanapi.NewDate(2024, 3, 12) {
+		t.Fatalf("expected the task to take 3 workdays, got %s to %s", team.StartDate, team.EndDate)
+	}
+
+	if _, err := template.plan(anchor, Options{Members: map[string]togglplanapi.ID{"it": 12}}); err == nil || !strings.Contains(err.Error(), `no member for role "newcomer"`) {
+		t.Fatalf("expected a missing role, got %v", err)
+	}
+
+	plan, _ = template.plan(anchor, Options{Members: map[string]togglplanapi.ID{"it": 12, "newcomer": 34}})
+	if team := plan.tasks[1]; team.StartDate != togglplanapi.NewDate(2024, 3, 8) || team.EndDate != togglplanapi.NewDate(2024, 3, 10) {
+		t.Fatalf("expected calendar days without a calendar, got %s to %s", team.StartDate, team.EndDate)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := Load(strings.NewReader("tasks:\n  - name: Design\n    colour: red\n"))
+	if err == nil {
+		t.Fatal("expected unknown fields to be rejected")
+	}
+
+	_, err = Load(strings.NewReader("tasks:\n  - name: Design\n    milestone: Launch\n  - days: -1\n"))
+	var invalid *togglplanapi.ValidationError
+	if !errors.As(err, &invalid) || len(invalid.Problems) != 3 {
+		t.Fatalf("expected 3 problems, got %v", err)
+	}
+}
+
+func TestInstantiate(t *testing.T) {
+	template, err := Load(strings.NewReader(onboarding))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var project togglplanapi.ProjectParams
+	var milestones []togglplanapi.MilestoneParams
+	tasks := map[string]togglplanapi.TaskParams{}
+
+	pa := testclient.New(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method != "POST" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		switch r.URL.Path {
+		case "/api/v5/1/projects":
+			json.NewDecoder(r.Body).Decode(&project)
+			fmt.Fprintf(w, `{"id":5,"name":%q}`, project.Name)
+		case "/api/v5/1/milestones":
+			var params togglplanapi.MilestoneParams
+			json.NewDecoder(r.Body).Decode(&params)
+			milestones = append(milestones, params)
+			fmt.Fprintf(w, `{"id":9,"name":%q}`, params.Name)
+		case "/api/v5/1/tasks":
+			var params togglplanapi.TaskParams
+			json.NewDecoder(r.Body).Decode(&params)
+			tasks[params.Name] = params
+			if params.Name == "Set up laptop" {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(w, `{"message":"invalid checklist"}`)
+				return
+			}
+			fmt.Fprintf(w, `{"id":%d,"name":%q}`, 20+len(tasks), params.Name)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	anchor := togglplanapi.NewDate(2024, 3, 7)
+	instance, err := template.Instantiate(context.Background(), pa, 1, anchor, Options{Name: "Onboarding Ada", Members: map[string]togglplanapi.ID{"it": 12, "newcomer": 34}})
+	if err == nil || !strings.Contains(err.Error(), `creating task "Set up laptop"`) {
+		t.Fatalf("expected the failed task to be reported, got %v", err)
+	}
+
+	if project.Name != "Onboarding Ada" || project.StartDate != anchor || project.EndDate != togglplanapi.NewDate(2024, 3, 11) {
+		t.Fatalf("unexpected project %+v", project)
+	}
+	if len(milestones) != 1 || milestones[0].ProjectId != 5 || milestones[0].Date != togglplanapi.NewDate(2024, 3, 11) {
+		t.Fatalf("unexpected milestones %+v", milestones)
+	}
+
+	team := tasks["Meet the team"]
+	if team.ProjectId != 5 || team.MilestoneId != 9 || len(team.Assignees) != 1 || team.Assignees[0] != 34 || team.StartDate != togglplanapi.NewDate(2024, 3, 8) {
+		t.Fatalf("unexpected task %+v", team)
+	}
+	if laptop := tasks["Set up laptop"]; laptop.ProjectId != 5 || len(laptop.Checklist) != 2 {
+		t.Fatalf("unexpected task %+v", laptop)
+	}
+
+	if instance.Project == nil || instance.Project.Id != 5 || len(instance.Milestones) != 1 || len(instance.Tasks) != 1 || instance.Tasks[0].Name != "Meet the team" {
+		t.Fatalf("expected the instance to hold what was created, got %+v", instance)
+	}
+}
@@ -0,0 +1,37 @@
+package togglplanapi
+
+import (
+	"context"
+	"net/url"
+)
+
+// TimeOff is a period a workspace member is away, such as a vacation.
+type TimeOff struct {
+	Id        ID     `json:"id"`
+	MemberId  ID     `json:"workspace_member_id"`
+	StartDate Date   `json:"start_date"`
+	EndDate   Date   `json:"end_date"`
+	Reason    string `json:"reason"`
+}
+
+// Covers reports whether the time off includes date.
+func (timeOff TimeOff) Covers(date Date) bool {
+	return date.Between(timeOff.StartDate, timeOff.EndDate)
+}
+
+// GetTimeOffs fetches the time off of the members of a workspace that
+// overlaps a date range.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	pa: togglPlanApi instance
+//	workspaceId: ID of the workspace
+//	since: First day of the range
+//	until: Last day of the range, inclusive
+func GetTimeOffs(ctx context.Context, pa *togglPlanApi, workspaceId ID, since Date, until Date) ([]TimeOff, error) {
+	query := url.Values{"since": {since.String()}, "until": {until.String()}}
+
+	var timeOffs []TimeOff
+	err := getJSON(ctx, pa, workspacePath(workspaceId, "/time_offs"), query, &timeOffs)
+	return timeOffs, err
+}
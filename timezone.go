@@ -0,0 +1,95 @@
+package togglplanapi
+
+import (
+	"fmt"
+	"time"
+)
+
+// Task dates and times are wall-clock values: a task from 09:00 to 10:00
+// stays at 09:00 whatever the time zone of the viewer. The helpers below
+// place them in a time zone, such as the workspace's (see Workspace.Location),
+// to convert them to instants and back, and to shift them without daylight
+// saving changes moving them by an hour.
+
+// Location returns the time zone of the workspace, or UTC if it has none.
+func (workspace Workspace) Location() (*time.Location, error) {
+	if workspace.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(workspace.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("workspace %d: %w", workspace.Id, err)
+	}
+	return loc, nil
+}
+
+// FirstWeekday returns the day weeks start on in the workspace. WeekStart
+// counts from Sunday, as time.Weekday does.
+func (workspace Workspace) FirstWeekday() time.Weekday {
+	return time.Weekday((workspace.WeekStart%7 + 7) % 7)
+}
+
+// StartOfWeek returns the first day of the week date falls in, for weeks
+// starting on weekStart.
+func StartOfWeek(date Date, weekStart time.Weekday) Date {
+	return date.AddDays(-((int(date.Weekday()) - int(weekStart) + 7) % 7))
+}
+
+// AtClock returns date at the HH:MM time of day clock, in loc. A time of day
+// skipped when clocks go forward, such as 02:30 on the night daylight saving
+// starts in most of Europe and America, comes out an hour later.
+func AtClock(date Date, clock string, loc *time.Location) (time.Time, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", clock)
+	}
+	return date.At(parsed.Hour(), parsed.Minute(), loc), nil
+}
+
+// ClockOf returns the date and the HH:MM time of day of t in loc, as set on
+// timed tasks. Seconds are dropped.
+func ClockOf(t time.Time, loc *time.Location) (Date, string) {
+	t = t.In(loc)
+	return DateOf(t), t.Format("15:04")
+}
+
+// ConvertClock converts a date and HH:MM time of day from one time zone to
+// another, e.g. to show the times of a task to a user in another time zone
+// than the workspace.
+func ConvertClock(date Date, clock string, from *time.Location, to *time.Location) (Date, string, error) {
+	t, err := AtClock(date, clock, from)
+	if err != nil {
+		return Date{}, "", err
+	}
+	convertedDate, convertedClock := ClockOf(t, to)
+	return convertedDate, convertedClock, nil
+}
+
+// TaskTimes returns the instants a task starts and ends at in loc. Timed
+// tasks start at StartTime on StartDate and end at EndTime on EndDate, and
+// all-day tasks run from midnight at the start of StartDate to midnight at
+// the end of EndDate. The task must have dates.
+func TaskTimes(task Task, loc *time.Location) (start time.Time, end time.Time, err error) {
+	if task.StartDate.IsZero() || task.EndDate.IsZero() {
+		return start, end, fmt.Errorf("task %d has no dates", task.Id)
+	}
+
+	if task.StartTime == "" || task.EndTime == "" {
+		return task.StartDate.In(loc), task.EndDate.AddDays(1).In(loc), nil
+	}
+
+	if start, err = AtClock(task.StartDate, task.StartTime, loc); err != nil {
+		return start, end, fmt.Errorf("task %d: %w", task.Id, err)
+	}
+	if end, err = AtClock(task.EndDate, task.EndTime, loc); err != nil {
+		return start, end, fmt.Errorf("task %d: %w", task.Id, err)
+	}
+	return start, end, nil
+}
+
+// ShiftDays moves t by days, keeping its time of day in loc. Unlike adding
+// multiples of 24 hours, a meeting at 09:00 stays at 09:00 when a daylight
+// saving change falls in between.
+func ShiftDays(t time.Time, days int, loc *time.Location) time.Time {
+	return t.In(loc).AddDate(0, 0, days)
+}
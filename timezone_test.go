@@ -0,0 +1,83 @@
+package togglplanapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStartOfWeek(t *testing.T) {
+	wednesday := NewDate(2024, 3, 6)
+	if got := StartOfWeek(wednesday, time.Monday); got != NewDate(2024, 3, 4) {
+		t.Fatalf("expected Monday March 4, got %v", got)
+	}
+	if got := StartOfWeek(wednesday, time.Sunday); got != NewDate(2024, 3, 3) {
+		t.Fatalf("expected Sunday March 3, got %v", got)
+	}
+	if got := StartOfWeek(NewDate(2024, 3, 4), time.Monday); got != NewDate(2024, 3, 4) {
+		t.Fatalf("expected the first day of the week to stay, got %v", got)
+	}
+
+	workspace := Workspace{Timezone: "Europe/Berlin", WeekStart: 6}
+	if workspace.FirstWeekday() != time.Saturday {
+		t.Fatalf("expected weeks to start on Saturday, got %v", workspace.FirstWeekday())
+	}
+	if loc, err := workspace.Location(); err != nil || loc.String() != "Europe/Berlin" {
+		t.Fatalf("unexpected location %v, %v", loc, err)
+	}
+	if _, err := (Workspace{Timezone: "Mars/Olympus"}).Location(); err == nil {
+		t.Fatal("expected an error for an unknown time zone")
+	}
+}
+
+func TestClockDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// Clocks go forward on March 31, 2024 in Berlin
+	saturday := NewDate(2024, 3, 30)
+	meeting, err := AtClock(saturday, "09:00", berlin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shifted := ShiftDays(meeting, 2, berlin)
+	if date, clock := ClockOf(shifted, berlin); date != NewDate(2024, 4, 1) || clock != "09:00" {
+		t.Fatalf("expected 09:00 on April 1, got %s %s", date, clock)
+	}
+	if shifted.Sub(meeting) != 47*time.Hour {
+		t.Fatalf("expected the shift to be an hour short of 2 days, got %v", shifted.Sub(meeting))
+	}
+
+	skipped, _ := AtClock(NewDate(2024, 3, 31), "02:30", berlin)
+	if _, clock := ClockOf(skipped, berlin); clock != "03:30" {
+		t.Fatalf("expected a skipped time to come out an hour later, got %s", clock)
+	}
+
+	date, clock, err := ConvertClock(saturday, "23:30", berlin, time.UTC)
+	if err != nil || date != saturday || clock != "22:30" {
+		t.Fatalf("unexpected conversion %s %s, %v", date, clock, err)
+	}
+	if _, _, err := ConvertClock(saturday, "9am", berlin, time.UTC); err == nil {
+		t.Fatal("expected an error for an invalid time")
+	}
+}
+
+func TestTaskTimes(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	monday := NewDate(2024, 3, 4)
+
+	start, end, err := TaskTimes(Task{StartDate: monday, EndDate: monday, StartTime: "09:00", EndTime: "10:30"}, tokyo)
+	if err != nil || start.UTC() != time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC) || end.Sub(start) != 90*time.Minute {
+		t.Fatalf("unexpected times %v - %v, %v", start, end, err)
+	}
+
+	start, end, err = TaskTimes(Task{StartDate: monday, EndDate: monday.AddDays(1)}, tokyo)
+	if err != nil || !start.Equal(monday.In(tokyo)) || end.Sub(start) != 48*time.Hour {
+		t.Fatalf("unexpected all-day times %v - %v, %v", start, end, err)
+	}
+
+	if _, _, err := TaskTimes(Task{Id: 1}, tokyo); err == nil {
+		t.Fatal("expected an error for a task without dates")
+	}
+}
@@ -15,12 +15,12 @@ Example Usage:
 		pa := togglplanapi.New(username, password, clientId, clientSecret, "")
 
 		// Send a GET request to the Toggl Plan API
-		result, err := togglplanapi.Request(pa, "https://api.plan.toggl.com/api/v5/me", "GET", []byte{}, map[string]string{})
+		result, err := togglplanapi.Request(pa, "https://api.plan.toggl.com/api/v5/me", "GET", []byte{})
 
 		fmt.Println(result, err)
 
 		// You can reuse the same client instance for another request
-		result2, err2 := togglplanapi.Request(pa, "https://api.plan.toggl.com/api/v5/me", "GET", []byte{}, map[string]string{})
+		result2, err2 := togglplanapi.Request(pa, "https://api.plan.toggl.com/api/v5/me", "GET", []byte{})
 
 		fmt.Println(result2, err2)
 	}
@@ -28,7 +28,6 @@ Example Usage:
 package togglplanapi
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -36,9 +35,9 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
-
-	"github.com/hashicorp/go-retryablehttp"
 )
 
 // togglPlanApi represents the client structure for Toggl Plan API.
@@ -49,23 +48,141 @@ type togglPlanApi struct {
 	clientId     string
 	clientSecret string
 	bearerToken  string
+	baseURL      string
+	validate     bool
+	decodeJSON   func(r io.Reader, v interface{}) error
+	headers      map[string]string
+	userAgent    string
+	httpClient   *http.Client
+	retryClient  *retryClient
+	// userAgentHeader is the User-Agent of requests, built once by New
+	userAgentHeader string
+	version         APIVersion
+	tokenAt         time.Time
+	hedger          *hedger
+	cache           *responseCache
+	metrics         *clientMetrics
+	// notFoundAsNil makes lookups return (nil, nil) on 404, see WithNotFoundAsNil
+	notFoundAsNil bool
+	// tokenMu guards bearerToken and tokenAt. It is held while a token is
+	// fetched, so that concurrent calls wait for that one request.
+	tokenMu sync.Mutex
 }
 
+// Client is an exported name for togglPlanApi, so that it can be
+// referenced in function signatures outside this package.
+type Client = togglPlanApi
+
+// defaultBaseURL is the scheme and host of the Toggl Plan API.
+const defaultBaseURL = "https://api.plan.toggl.com"
+
 // authDetails represents authentication details required for making API requests.
 type authDetails struct {
 	Type       string // "Basic", "Bearer", etc.
 	Credential string
 }
 
+// Option configures a togglPlanApi instance created with New.
+type Option func(*togglPlanApi)
+
+// WithValidation turns the validation of write inputs on or off. It is on by
+// default, so that invalid inputs are rejected without sending a request.
+// See Validator.
+func WithValidation(enabled bool) Option {
+	return func(pa *togglPlanApi) {
+		pa.validate = enabled
+	}
+}
+
+// JSONOptions sets how typed calls decode responses with encoding/json.
+type JSONOptions struct {
+	// UseNumber decodes numbers into interface{} values as json.Number
+	// rather than float64, which can't hold every 64-bit ID.
+	UseNumber bool
+	// DisallowUnknownFields rejects responses with fields that the target
+	// struct doesn't have, to notice API changes early. Calls then fail with
+	// an *UnknownFieldsError listing all of them.
+	DisallowUnknownFields bool
+	// OnUnknownFields, if set, is called with the fields of each response
+	// that the target struct doesn't have, e.g. to log them. Unless
+	// DisallowUnknownFields is set, the call then succeeds. If it panics,
+	// the call fails with a *PanicError.
+	OnUnknownFields func(*UnknownFieldsError)
+}
+
+// WithJSONOptions configures the json.Decoder of typed calls.
+func WithJSONOptions(options JSONOptions) Option {
+	decode := func(r io.Reader, v interface{}) error {
+		decoder := json.NewDecoder(r)
+		if options.UseNumber {
+			decoder.UseNumber()
+		}
+		return decoder.Decode(v)
+	}
+	if !options.DisallowUnknownFields && options.OnUnknownFields == nil {
+		return WithJSONDecoder(decode)
+	}
+
+	return WithJSONDecoder(func(r io.Reader, v interface{}) error {
+		unknown, err := decodeChecked(r, v, decode)
+		if err != nil || unknown == nil {
+			return err
+		}
+		if options.OnUnknownFields != nil {
+			if err := callHook("OnUnknownFields", func() error {
+				options.OnUnknownFields(unknown)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		if options.DisallowUnknownFields {
+			return unknown
+		}
+		return nil
+	})
+}
+
+// WithJSONDecoder replaces encoding/json for decoding the responses of typed
+// calls, e.g. with a faster library for high volumes. decode reads a single
+// JSON value from r into v, and must return io.EOF if r is empty.
+func WithJSONDecoder(decode func(r io.Reader, v interface{}) error) Option {
+	return func(pa *togglPlanApi) {
+		pa.decodeJSON = decode
+	}
+}
+
+// WithDefaultHeader sets a header on every request of the client, such as
+// Accept-Language or a tracing header. Headers passed to a call override it.
+func WithDefaultHeader(key string, value string) Option {
+	return func(pa *togglPlanApi) {
+		pa.headers = mergeMaps(pa.headers, map[string]string{key: value})
+	}
+}
+
 // New initializes and returns a new togglPlanApi instance.
-func New(username string, password string, clientId string, clientSecret string, bearerToken string) *togglPlanApi {
-	return &togglPlanApi{
+// Options, if any, are applied in order.
+func New(username string, password string, clientId string, clientSecret string, bearerToken string, opts ...Option) *togglPlanApi {
+	pa := &togglPlanApi{
 		username:     username,
 		password:     password,
 		clientId:     clientId,
 		clientSecret: clientSecret,
 		bearerToken:  bearerToken,
+		baseURL:      defaultBaseURL,
+		validate:     true,
+		httpClient:   newHTTPClient(),
+		version:      DefaultAPIVersion,
+		metrics:      &clientMetrics{},
+	}
+
+	for _, opt := range opts {
+		opt(pa)
 	}
+	pa.userAgentHeader = userAgent(pa)
+	pa.retryClient = newRetryClient(pa)
+
+	return pa
 }
 
 // Request sends an authenticated request to the Toggl Plan API.
@@ -76,71 +193,260 @@ func New(username string, password string, clientId string, clientSecret string,
 //	url: The API endpoint
 //	method: HTTP method (GET, POST, etc.)
 //	body: Request body, if any (use `[]byte{}` if you're not passing a body)
-//	headers: Additional request headers (use `map[string]string{}` if you don't have an additional headers)
-func Request(pa *togglPlanApi, url string, method string, body []byte, headers map[string]string) (string, error) {
-	if pa.bearerToken == "" {
-		result, err := getToken(pa)
-		if err == nil {
-			pa.bearerToken = result
-		} else {
-			return "Couldn't authenticate", err
-		}
+//	opts: Settings of the request, such as WithHeader or WithTimeout, if any
+func Request(pa *togglPlanApi, url string, method string, body []byte, opts ...RequestOption) (string, error) {
+	resp, message, cancel, err := rawRequest(context.Background(), pa, url, method, body, opts)
+	defer cancel()
+	if err != nil {
+		return message, err
+	}
+
+	return readBody(resp)
+}
+
+// RequestBytes works like Request, but returns the response body as a byte
+// slice. This avoids the extra copy made when converting the body to a string,
+// which adds up for consumers handling many or large responses.
+// On failure the returned slice is nil.
+func RequestBytes(pa *togglPlanApi, url string, method string, body []byte, opts ...RequestOption) ([]byte, error) {
+	resp, _, cancel, err := rawRequest(context.Background(), pa, url, method, body, opts)
+	defer cancel()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
+
+// RequestStream works like Request, but returns the response body unread, so
+// that it can be decoded or copied as it arrives from the network.
+// The caller is responsible for closing the returned io.ReadCloser.
+// On failure the returned reader is nil.
+func RequestStream(pa *togglPlanApi, url string, method string, body []byte, opts ...RequestOption) (io.ReadCloser, error) {
+	resp, _, cancel, err := rawRequest(context.Background(), pa, url, method, body, opts)
+	if err != nil {
+		cancel()
+		return nil, err
 	}
 
-	defaultHeaders := map[string]string{
-		"Content-Type": "application/json",
+	return cancelOnClose{ReadCloser: resp.Body, cancel: cancel}, nil
+}
+
+// Get sends an authenticated GET request without a body, and returns the
+// response body. See Request.
+func Get(pa *togglPlanApi, url string, opts ...RequestOption) (string, error) {
+	return Request(pa, url, http.MethodGet, nil, opts...)
+}
+
+// Post sends an authenticated POST request, and returns the response body.
+// See Request.
+func Post(pa *togglPlanApi, url string, body []byte, opts ...RequestOption) (string, error) {
+	return Request(pa, url, http.MethodPost, body, opts...)
+}
+
+// Put sends an authenticated PUT request, and returns the response body.
+// See Request.
+func Put(pa *togglPlanApi, url string, body []byte, opts ...RequestOption) (string, error) {
+	return Request(pa, url, http.MethodPut, body, opts...)
+}
+
+// Patch sends an authenticated PATCH request, and returns the response body.
+// See Request.
+func Patch(pa *togglPlanApi, url string, body []byte, opts ...RequestOption) (string, error) {
+	return Request(pa, url, http.MethodPatch, body, opts...)
+}
+
+// Delete sends an authenticated DELETE request without a body, and returns
+// the response body, which is usually empty. See Request.
+func Delete(pa *togglPlanApi, url string, opts ...RequestOption) (string, error) {
+	return Request(pa, url, http.MethodDelete, nil, opts...)
+}
+
+// Head sends an authenticated HEAD request, and returns the status and
+// headers of the response without a body, e.g. to check that a resource
+// exists or to learn the size of an attachment before downloading it.
+// As with other calls, an error status is returned as an *APIError, so a
+// missing resource matches ErrNotFound.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request and its retries
+//	pa: togglPlanApi instance
+//	url: The API endpoint
+//	opts: Settings of the request, such as WithHeader or WithTimeout, if any
+func Head(ctx context.Context, pa *togglPlanApi, url string, opts ...RequestOption) (*Response, error) {
+	return headerRequest(ctx, pa, url, http.MethodHead, opts)
+}
+
+// Options sends an authenticated OPTIONS request, and returns the status and
+// headers of the response, such as Allow, which lists the methods an
+// endpoint supports. See Head.
+func Options(ctx context.Context, pa *togglPlanApi, url string, opts ...RequestOption) (*Response, error) {
+	return headerRequest(ctx, pa, url, http.MethodOptions, opts)
+}
+
+// headerRequest sends a request whose response body is of no interest, and
+// describes its response.
+func headerRequest(ctx context.Context, pa *togglPlanApi, url string, method string, opts []RequestOption) (*Response, error) {
+	resp, _, cancel, err := rawRequest(ctx, pa, url, method, nil, opts)
+	defer cancel()
+	if err != nil {
+		return nil, err
 	}
+	resp.Body.Close()
 
-	finalHeaders := mergeMaps(defaultHeaders, headers)
+	return newResponse(resp), nil
+}
+
+// authenticatedRequest sends a request using the bearer token of pa, fetching
+// a new token first if necessary.
+// On success, the response body is left open for the caller to consume and close.
+// On failure, a short description of the failed step is returned alongside the error.
+func authenticatedRequest(ctx context.Context, pa *togglPlanApi, url string, method string, body []byte, headers map[string]string) (*http.Response, string, error) {
+	token, err := bearerToken(ctx, pa)
+	if err != nil {
+		return nil, "Couldn't authenticate", err
+	}
 
 	auth := &authDetails{
 		Type:       "Bearer",
-		Credential: pa.bearerToken,
+		Credential: token,
 	}
 
-	result, err := doRequest(pa, url, method, body, finalHeaders, auth)
+	return sendRequest(ctx, pa, url, method, body, "application/json", headers, auth)
+}
+
+// ensureToken returns the bearer token of pa, fetching one first unless it
+// already has one. Concurrent calls share a single fetch.
+func ensureToken(ctx context.Context, pa *togglPlanApi) (string, error) {
+	pa.tokenMu.Lock()
+	defer pa.tokenMu.Unlock()
+
+	if pa.bearerToken != "" {
+		return pa.bearerToken, nil
+	}
 
-	return result, err
+	result, err := getToken(ctx, pa)
+	if err != nil {
+		return "", err
+	}
+	pa.bearerToken = result
+	pa.tokenAt = time.Now()
+	return result, nil
 }
 
-// doRequest is a helper function to send an API request.
-// It includes retry logic for certain HTTP status codes.
+// currentToken returns the bearer token of pa, empty if none was fetched
+// yet, and the time it was fetched.
+func currentToken(pa *togglPlanApi) (string, time.Time) {
+	pa.tokenMu.Lock()
+	defer pa.tokenMu.Unlock()
+	return pa.bearerToken, pa.tokenAt
+}
+
+// Do sends a request built by the caller, e.g. for an endpoint or content
+// type the other calls don't cover. It sets the bearer token of pa, fetching
+// one first if necessary, and the default headers of pa unless req already
+// has them, then retries the request like other calls.
+//
+// As with other calls, a response with an error status is returned as an
+// *APIError. Otherwise, the caller is responsible for closing the response
+// body.
+//
+// The body of req, if any, is sent again on retries. It is rewound with
+// req.GetBody if set, or with Seek if it implements io.Seeker, such as an
+// *os.File. Other bodies up to 1 MB are read up front, and larger ones
+// streamed: if such a request needs a retry after its body was sent, it
+// fails with an error matching ErrBodyNotReplayable instead.
+func Do(pa *togglPlanApi, req *http.Request) (*http.Response, error) {
+	token, err := bearerToken(req.Context(), pa)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retries may hide the body from the transport, so it is closed here,
+	// as http.Client.Do would
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+
+	// Headers are set on a copy, leaving the caller's request as it was
+	retryable, err := replayableRequest(req.Clone(req.Context()))
+	if err != nil {
+		return nil, err
+	}
+
+	if retryable.Header.Get("User-Agent") == "" {
+		retryable.Header.Set("User-Agent", pa.userAgentHeader)
+	}
+	for headerKey, headerValue := range pa.headers {
+		if retryable.Header.Get(headerKey) == "" {
+			retryable.Header.Set(headerKey, headerValue)
+		}
+	}
+	retryable.Header.Set("Authorization", "Bearer "+token)
+
+	resp, _, err := sendRetryable(pa, retryable)
+	return resp, err
+}
+
+// doRequest is a helper function to send an API request and read its response body.
 // Arguments:
 //
+//	ctx: Context controlling cancellation of the request and its retries
 //	pa: togglPlanApi instance
 //	url: The API endpoint
 //	method: HTTP method (GET, POST, etc.)
 //	body: Request body, if any
-//	headers: Additional request headers
+//	contentType: Content type of the body
+//	headers: Additional request headers, overriding the default headers of pa
 //	auth: Authentication details
-func doRequest(pa *togglPlanApi, url string, method string, body []byte, headers map[string]string, auth *authDetails) (string, error) {
-	client := retryablehttp.NewClient()
-
-	client.CheckRetry = func(ctx context.Context, resp *http.Response, err error) (bool, error) {
-		if err != nil {
-			return true, err
-		}
-		if resp.StatusCode == http.StatusTooManyRequests {
-			return true, nil
-		}
-		if resp.StatusCode == 401 {
-			return false, nil
-		}
-		if resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented {
-			return true, nil
-		}
-		return false, nil
+func doRequest(ctx context.Context, pa *togglPlanApi, url string, method string, body []byte, contentType string, headers map[string]string, auth *authDetails) (string, error) {
+	resp, message, err := sendRequest(ctx, pa, url, method, body, contentType, headers, auth)
+	if err != nil {
+		return message, err
 	}
 
-	client.RetryMax = 5
-	client.RetryWaitMin = 1 * time.Second
-	client.RetryWaitMax = 30 * time.Second
+	return readBody(resp)
+}
 
-	req, err := retryablehttp.NewRequest(method, url, bytes.NewReader(body))
+// sendRequest is a helper function to send an API request.
+// It includes retry logic for certain HTTP status codes.
+// On success, the response body is left open for the caller to consume and close.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request and its retries
+//	pa: togglPlanApi instance
+//	url: The API endpoint
+//	method: HTTP method (GET, POST, etc.)
+//	body: Request body, if any
+//	contentType: Content type of the body
+//	headers: Additional request headers, overriding the default headers of pa
+//	auth: Authentication details
+func sendRequest(ctx context.Context, pa *togglPlanApi, url string, method string, body []byte, contentType string, headers map[string]string, auth *authDetails) (*http.Response, string, error) {
+	if pa.hedger != nil && method == http.MethodGet {
+		return pa.hedger.send(ctx, func(ctx context.Context) (*http.Response, string, error) {
+			return sendAttempt(ctx, pa, url, method, body, contentType, headers, auth)
+		})
+	}
+	return sendAttempt(ctx, pa, url, method, body, contentType, headers, auth)
+}
+
+// sendAttempt builds a request with the arguments of sendRequest, and sends
+// it with its retries.
+func sendAttempt(ctx context.Context, pa *togglPlanApi, url string, method string, body []byte, contentType string, headers map[string]string, auth *authDetails) (*http.Response, string, error) {
+	// A []byte body is resent as is by retries, where a reader would be
+	// copied first
+	req, err := newRetryRequest(ctx, method, url, body)
 	if err != nil {
-		return "Error building request", err
+		return nil, "Error building request", err
 	}
 
+	// Headers are set in order of precedence, straight into the request
+	req.Header.Set("User-Agent", pa.userAgentHeader)
+	for headerKey, headerValue := range pa.headers {
+		req.Header.Set(headerKey, headerValue)
+	}
+	req.Header.Set("Content-Type", contentType)
 	for headerKey, headerValue := range headers {
 		req.Header.Set(headerKey, headerValue)
 	}
@@ -149,18 +455,51 @@ func doRequest(pa *togglPlanApi, url string, method string, body []byte, headers
 		req.Header.Set("Authorization", auth.Type+" "+auth.Credential)
 	}
 
-	resp, err := client.Do(req)
+	return sendRetryable(pa, req)
+}
+
+// sendRetryable sends a request with the retry client of pa.
+// On success, the response body is left open for the caller to consume and close.
+func sendRetryable(pa *togglPlanApi, req *retryRequest) (*http.Response, string, error) {
+	attempts := 0
+	req = req.WithContext(context.WithValue(req.Context(), attemptsKey{}, &attempts))
+	start := time.Now()
+
+	resp, err := pa.retryClient.Do(req)
+	failed := err != nil || (resp.StatusCode >= 400 && !(resp.StatusCode == http.StatusNotFound && expectedNotFound(req.Context())))
+	pa.metrics.record(pa, req.Method, req.URL, attempts, time.Since(start), failed)
 	if err != nil {
-		return "Error running request", err
+		// Retries may stop with an error and the response that caused it
+		if resp != nil {
+			resp.Body.Close()
+		}
+		if attempts > 1 {
+			err = &RetryError{Attempts: attempts, Elapsed: time.Since(start), Err: err}
+		}
+		return nil, "Error running request", err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == 401 {
-		return "Unauthorized", errors.New("401")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := newAPIError(resp)
+		apiErr.Attempts = attempts
+		apiErr.Elapsed = time.Since(start)
+
+		if resp.StatusCode == 401 {
+			return nil, "Unauthorized", apiErr
+		}
+		return nil, fmt.Sprint(resp.StatusCode), apiErr
 	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Sprint(resp.StatusCode), errors.New(http.StatusText(resp.StatusCode))
+	return resp, "", nil
+}
+
+// readBody reads and closes the body of resp, returning it as a string.
+// A 204 No Content response has no body to read, and returns an empty string.
+func readBody(resp *http.Response) (string, error) {
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNoContent {
+		return "", nil
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
@@ -175,47 +514,63 @@ func doRequest(pa *togglPlanApi, url string, method string, body []byte, headers
 // It uses the client ID, client secret, username, and password to fetch the token.
 // Arguments:
 //
+//	ctx: Context controlling cancellation of the request
 //	pa: togglPlanApi instance
-func getToken(pa *togglPlanApi) (string, error) {
-	type TokenResponse struct {
-		AccessToken string `json:"access_token"`
+func getToken(ctx context.Context, pa *togglPlanApi) (string, error) {
+	token, message, err := requestToken(ctx, pa, Credentials{
+		Username:     pa.username,
+		Password:     pa.password,
+		ClientId:     pa.clientId,
+		ClientSecret: pa.clientSecret,
+	})
+	if err != nil {
+		return message, err
 	}
+	return token.AccessToken, nil
+}
 
-	headers := map[string]string{
-		"Content-Type": "application/x-www-form-urlencoded",
-	}
+// tokenResponse is the response of the token endpoint.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	// ExpiresIn is the lifetime of the token in seconds, if the API tells.
+	ExpiresIn int64 `json:"expires_in"`
+}
 
+// requestToken fetches a bearer token for credentials with the password
+// grant, sending the request with pa.
+// On failure, a short description of the failed step is returned alongside the error.
+func requestToken(ctx context.Context, pa *togglPlanApi, credentials Credentials) (tokenResponse, string, error) {
 	auth := &authDetails{
 		Type:       "Basic",
-		Credential: base64.StdEncoding.EncodeToString([]byte(pa.clientId + ":" + pa.clientSecret)),
+		Credential: base64.StdEncoding.EncodeToString([]byte(credentials.ClientId + ":" + credentials.ClientSecret)),
 	}
 
-	body := []byte("grant_type=password&username=" + pa.username + "&password=" + pa.password)
-
-	result, err := doRequest(pa, "https://api.plan.toggl.com/api/v5/authenticate/token", "POST", body, headers, auth)
-
-	if err == nil {
-		var tokenResponse TokenResponse
-		err = json.Unmarshal([]byte(result), &tokenResponse)
-
-		if err != nil {
-			return "Couldn't parse authentication attempt response", err
-		}
+	form := url.Values{
+		"grant_type": {"password"},
+		"username":   {credentials.Username},
+		"password":   {credentials.Password},
+	}
 
-		if tokenResponse.AccessToken == "" {
-			return "", errors.New("access_token not found in response")
-		}
+	result, err := doRequest(ctx, pa, APIURL(pa, "/authenticate/token"), "POST", []byte(form.Encode()), "application/x-www-form-urlencoded", nil, auth)
+	if err != nil {
+		return tokenResponse{}, "Couldn't request for a new bearer token", err
+	}
 
-		return tokenResponse.AccessToken, nil
-	} else {
-		return "Couldn't request for a new bearer token", err
+	var token tokenResponse
+	if err := json.Unmarshal([]byte(result), &token); err != nil {
+		return tokenResponse{}, "Couldn't parse authentication attempt response", err
+	}
+	if token.AccessToken == "" {
+		return tokenResponse{}, "", errors.New("access_token not found in response")
 	}
+	return token, "", nil
 }
 
 // GetToken retrieves the bearerToken of the specified togglPlanApi instance,
 // so that it can be stored for later user as needed.
 func GetToken(pa *togglPlanApi) string {
-	return pa.bearerToken
+	token, _ := currentToken(pa)
+	return token
 }
 
 // mergeMaps takes two map[string]string instances as input and returns a new map
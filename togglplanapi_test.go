@@ -1,7 +1,14 @@
 package togglplanapi
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -13,11 +20,203 @@ const clientSecret = "[Toggl Plan Secret]"
 func TestNormalRequest(t *testing.T) {
 	pa := New(username, password, clientId, clientSecret, "")
 
-	result, err := Request(pa, "https://api.plan.toggl.com/api/v5/me", "GET", []byte{}, map[string]string{})
+	result, err := Request(pa, "https://api.plan.toggl.com/api/v5/me", "GET", []byte{})
 
 	fmt.Println(result, err)
 
-	result2, err2 := Request(pa, "https://api.plan.toggl.com/api/v5/me", "GET", []byte{}, map[string]string{})
+	result2, err2 := Request(pa, "https://api.plan.toggl.com/api/v5/me", "GET", []byte{})
 
 	fmt.Println(result2, err2)
 }
+
+func TestRequestBytesAndStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"id":1}`)
+	}))
+	defer server.Close()
+
+	pa := New(username, password, clientId, clientSecret, "token")
+
+	result, err := RequestBytes(pa, server.URL, "GET", []byte{})
+	if err != nil || string(result) != `{"id":1}` {
+		t.Fatalf("RequestBytes() = %q, %v", result, err)
+	}
+
+	stream, err := RequestStream(pa, server.URL, "GET", []byte{})
+	if err != nil {
+		t.Fatalf("RequestStream() error = %v", err)
+	}
+	defer stream.Close()
+
+	streamed, err := io.ReadAll(stream)
+	if err != nil || string(streamed) != `{"id":1}` {
+		t.Fatalf("RequestStream() body = %q, %v", streamed, err)
+	}
+
+	unauthorized := New(username, password, clientId, clientSecret, "expired")
+
+	result, err = RequestBytes(unauthorized, server.URL, "GET", []byte{})
+	if err == nil || result != nil {
+		t.Fatalf("RequestBytes() with a bad token = %q, %v", result, err)
+	}
+}
+
+func TestMethodWrappers(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		fmt.Fprintf(w, "%s %s", r.Method, body)
+	})
+
+	calls := []struct {
+		call     func() (string, error)
+		expected string
+	}{
+		{func() (string, error) { return Get(pa, pa.baseURL) }, "GET "},
+		{func() (string, error) { return Post(pa, pa.baseURL, []byte("a")) }, "POST a"},
+		{func() (string, error) { return Put(pa, pa.baseURL, []byte("b")) }, "PUT b"},
+		{func() (string, error) { return Patch(pa, pa.baseURL, []byte("c")) }, "PATCH c"},
+		{func() (string, error) { return Delete(pa, pa.baseURL) }, ""},
+	}
+	for _, c := range calls {
+		if result, err := c.call(); err != nil || result != c.expected {
+			t.Errorf("expected %q, got %q, %v", c.expected, result, err)
+		}
+	}
+
+	// Typed calls leave their output alone on 204 No Content
+	task := Task{Id: 7}
+	if err := sendJSON(context.Background(), pa, http.MethodDelete, "/tasks/7", nil, nil, &task); err != nil || task.Id != 7 {
+		t.Fatalf("unexpected result %+v, %v", task, err)
+	}
+}
+
+func TestJSONOptions(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":9007199254740993,"name":"Design","priority":1}`)
+	}
+
+	pa := newTestClient(t, handler)
+	WithJSONOptions(JSONOptions{UseNumber: true})(pa)
+
+	var raw map[string]interface{}
+	if err := getJSON(context.Background(), pa, "/1/tasks/1", nil, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["id"] != json.Number("9007199254740993") {
+		t.Fatalf("expected the ID to be kept exactly, got %v", raw["id"])
+	}
+
+	WithJSONOptions(JSONOptions{DisallowUnknownFields: true})(pa)
+	if _, err := GetTask(context.Background(), pa, 1, 1); err == nil || !strings.Contains(err.Error(), "priority") {
+		t.Fatalf("expected the unknown field to be rejected, got %v", err)
+	}
+
+	decoded := 0
+	WithJSONDecoder(func(r io.Reader, v interface{}) error {
+		decoded++
+		return json.NewDecoder(r).Decode(v)
+	})(pa)
+	if task, err := GetTask(context.Background(), pa, 1, 1); err != nil || task.Name != "Design" || decoded != 1 {
+		t.Fatalf("expected the custom decoder to be used, got %+v, %v", task, err)
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id":1,"Name":"Design","start_date":"2024-03-04","priority":1,
+			"checklist":[{"name":"Draft","weight":2},{"name":"Review","weight":1,"owner":{"id":3}}]}]`)
+	})
+
+	var reported *UnknownFieldsError
+	WithJSONOptions(JSONOptions{OnUnknownFields: func(err *UnknownFieldsError) { reported = err }})(pa)
+	tasks, err := GetTasks(context.Background(), pa, 1, TaskFilter{})
+	if err != nil || len(tasks) != 1 || tasks[0].Name != "Design" {
+		t.Fatalf("expected the tasks to decode, got %+v, %v", tasks, err)
+	}
+	if reported == nil || reported.Type != "[]togglplanapi.Task" ||
+		strings.Join(reported.Fields, ",") != "checklist[].owner,checklist[].weight,priority" {
+		t.Fatalf("unexpected report %+v", reported)
+	}
+
+	WithJSONOptions(JSONOptions{DisallowUnknownFields: true})(pa)
+	var unknownErr *UnknownFieldsError
+	if _, err := GetTasks(context.Background(), pa, 1, TaskFilter{}); !errors.As(err, &unknownErr) || len(unknownErr.Fields) != 3 {
+		t.Fatalf("expected an UnknownFieldsError, got %v", err)
+	}
+}
+
+// newTestClient returns a client with a bearer token that sends its requests to handler.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *togglPlanApi {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	pa := New(username, password, clientId, clientSecret, "token")
+	pa.baseURL = server.URL
+
+	return pa
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.Header.Get("Accept-Language"), r.Header.Get("X-Trace"))
+	})
+	WithDefaultHeader("Accept-Language", "de")(pa)
+	WithDefaultHeader("X-Trace", "abc")(pa)
+
+	if result, err := Get(pa, pa.baseURL); err != nil || result != "de abc" {
+		t.Fatalf("expected the default headers, got %q, %v", result, err)
+	}
+	if result, err := Get(pa, pa.baseURL, WithHeader("X-Trace", "def")); err != nil || result != "de def" {
+		t.Fatalf("expected the call's header to win, got %q, %v", result, err)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.UserAgent())
+	})
+	WithUserAgent("plan-sync/2.1")(pa)
+
+	result, err := Get(pa, pa.baseURL)
+	if err != nil || !strings.HasPrefix(result, "togglplanapi/"+Version+" go/") || !strings.HasSuffix(result, " plan-sync/2.1") {
+		t.Fatalf("unexpected User-Agent %q, %v", result, err)
+	}
+}
+
+func TestDo(t *testing.T) {
+	calls := 0
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s %s", r.Header.Get("Authorization"), r.Header.Get("Content-Type"), body)
+	})
+
+	req, _ := http.NewRequest(http.MethodPut, pa.baseURL+"/attachments/1", strings.NewReader("raw bytes"))
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := Do(pa, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "Bearer token application/octet-stream raw bytes" || calls != 2 {
+		t.Fatalf("unexpected response %q after %d calls", body, calls)
+	}
+	if req.Header.Get("Authorization") != "" {
+		t.Error("expected the caller's request to be left alone")
+	}
+}
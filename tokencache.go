@@ -0,0 +1,154 @@
+package togglplanapi
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Credentials are what a TokenCache needs to fetch the bearer token of an
+// account.
+type Credentials struct {
+	Username string
+	Password string
+	// ClientId and ClientSecret are the credentials of the application.
+	// They default to those of the client of the cache.
+	ClientId     string
+	ClientSecret string
+}
+
+// TokenCacheOptions configures a TokenCache.
+type TokenCacheOptions struct {
+	// TTL is how long a token is used before a new one is fetched, when the
+	// API doesn't tell its lifetime. Defaults to 1 hour.
+	TTL time.Duration
+	// RefreshBefore is how long before it expires a token is replaced, so
+	// that requests don't go out with a token about to expire. Defaults to
+	// 1 minute.
+	RefreshBefore time.Duration
+}
+
+// TokenCache holds the bearer tokens of many accounts, for servers acting
+// on behalf of many Toggl Plan users. Tokens are fetched when first needed
+// and again when they expire, and concurrent calls for the same account
+// share a single request. It is safe for concurrent use.
+//
+// Accounts are identified by a key of the caller's choosing, such as a
+// username or a client ID, and their credentials are looked up when a token
+// is needed, so that they aren't kept in memory. Pass tokens to calls with
+// Context, or with WithAuth.
+type TokenCache struct {
+	pa          *togglPlanApi
+	credentials func(ctx context.Context, key string) (Credentials, error)
+	options     TokenCacheOptions
+
+	mu      sync.Mutex
+	entries map[string]*tokenEntry
+}
+
+// tokenEntry is the token of an account, or the request fetching it.
+type tokenEntry struct {
+	// done is closed once the request has completed, and token, expires
+	// and err are set.
+	done    chan struct{}
+	token   string
+	expires time.Time
+	err     error
+}
+
+// NewTokenCache returns an empty TokenCache fetching tokens with pa, whose
+// settings, such as its base URL and retries, apply to the token requests.
+// Arguments:
+//
+//	pa: togglPlanApi instance sending the token requests
+//	credentials: Function returning the credentials of the account identified by key
+//	options: Settings of the cache
+func NewTokenCache(pa *togglPlanApi, credentials func(ctx context.Context, key string) (Credentials, error), options TokenCacheOptions) *TokenCache {
+	if options.TTL <= 0 {
+		options.TTL = time.Hour
+	}
+	if options.RefreshBefore <= 0 {
+		options.RefreshBefore = time.Minute
+	}
+	return &TokenCache{pa: pa, credentials: credentials, options: options, entries: map[string]*tokenEntry{}}
+}
+
+// Token returns the bearer token of the account identified by key, fetching
+// it if the cache has none or it is about to expire. A failed fetch isn't
+// cached, so the next call tries again.
+func (c *TokenCache) Token(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok {
+		select {
+		case <-entry.done:
+			if entry.err != nil || time.Now().After(entry.expires.Add(-c.options.RefreshBefore)) {
+				ok = false
+			}
+		default:
+			// Being fetched by another call
+		}
+	}
+	if !ok {
+		entry = &tokenEntry{done: make(chan struct{})}
+		c.entries[key] = entry
+		// A call giving up mustn't fail the others waiting for the token
+		go c.fetch(context.Background(), key, entry)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-entry.done:
+		return entry.token, entry.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// fetch fetches the token of the account identified by key into entry.
+func (c *TokenCache) fetch(ctx context.Context, key string, entry *tokenEntry) {
+	defer close(entry.done)
+
+	var credentials Credentials
+	err := callHook("credentials", func() (err error) {
+		credentials, err = c.credentials(ctx, key)
+		return err
+	})
+	if err != nil {
+		entry.err = err
+		return
+	}
+	if credentials.ClientId == "" {
+		credentials.ClientId, credentials.ClientSecret = c.pa.clientId, c.pa.clientSecret
+	}
+
+	token, _, err := requestToken(ctx, c.pa, credentials)
+	if err != nil {
+		entry.err = err
+		return
+	}
+	entry.token = token.AccessToken
+	entry.expires = time.Now().Add(c.options.TTL)
+	if token.ExpiresIn > 0 {
+		entry.expires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+}
+
+// Context returns ctx with the token of the account identified by key, see
+// WithAuth.
+func (c *TokenCache) Context(ctx context.Context, key string) (context.Context, error) {
+	token, err := c.Token(ctx, key)
+	if err != nil {
+		return ctx, err
+	}
+	return WithAuth(ctx, token), nil
+}
+
+// Invalidate drops the token of the account identified by key, e.g. after
+// the API rejected it or the user changed their password, so that the next
+// call fetches a new one.
+func (c *TokenCache) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
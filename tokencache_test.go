@@ -0,0 +1,79 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenCache(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/authenticate/token" {
+			fmt.Fprintf(w, `{"id":1,"name":%q}`, r.Header.Get("Authorization"))
+			return
+		}
+		requests.Add(1)
+		<-release
+		r.ParseForm()
+		fmt.Fprintf(w, `{"access_token":"token-%s","expires_in":3600}`, r.Form.Get("username"))
+	})
+
+	lookups := 0
+	cache := NewTokenCache(pa, func(ctx context.Context, key string) (Credentials, error) {
+		lookups++
+		if key == "unknown" {
+			return Credentials{}, errors.New("no such account")
+		}
+		return Credentials{Username: key, Password: "p&ss=word"}, nil
+	}, TokenCacheOptions{})
+
+	// Concurrent calls for an account share a request
+	var wg sync.WaitGroup
+	tokens := make([]string, 5)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = cache.Token(context.Background(), "ada@example.com")
+		}(i)
+	}
+	for requests.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	for _, token := range tokens {
+		if token != "token-ada@example.com" {
+			t.Fatalf("unexpected tokens %v", tokens)
+		}
+	}
+	if requests.Load() != 1 || lookups != 1 {
+		t.Errorf("expected a single token request, got %d", requests.Load())
+	}
+
+	ctx, err := cache.Context(context.Background(), "bob@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := GetTask(ctx, pa, 1, 1)
+	if err != nil || task.Name != "Bearer token-bob@example.com" {
+		t.Errorf("unexpected task %+v, %v", task, err)
+	}
+
+	if _, err := cache.Token(context.Background(), "unknown"); err == nil {
+		t.Error("expected the lookup error")
+	}
+
+	cache.Invalidate("ada@example.com")
+	if _, err := cache.Token(context.Background(), "ada@example.com"); err != nil || requests.Load() != 3 {
+		t.Errorf("expected a new token request, got %d requests, %v", requests.Load(), err)
+	}
+}
@@ -0,0 +1,131 @@
+package track
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"togglplanapi"
+)
+
+// taskReference finds "#<task id>" in time entry descriptions.
+var taskReference = regexp.MustCompile(`#(\d+)\b`)
+
+// TaskActual compares a task's planned effort with the time tracked against it.
+type TaskActual struct {
+	Task           togglplanapi.Task
+	PlannedMinutes int
+	ActualMinutes  int
+	Entries        []TimeEntry
+}
+
+// VarianceMinutes returns how much more time was tracked than planned.
+// It is negative if the task took less time than planned.
+func (actual TaskActual) VarianceMinutes() int {
+	return actual.ActualMinutes - actual.PlannedMinutes
+}
+
+// Match assigns time entries to tasks, by "#<task id>" references in their
+// descriptions first and by task name second.
+// Entries that match no task are returned separately.
+// Arguments:
+//
+//	tasks: Tasks to match against
+//	entries: Time entries to assign
+func Match(tasks []togglplanapi.Task, entries []TimeEntry) (map[togglplanapi.ID][]TimeEntry, []TimeEntry) {
+	byId := map[togglplanapi.ID]bool{}
+	byName := map[string]togglplanapi.ID{}
+	for _, task := range tasks {
+		byId[task.Id] = true
+		byName[normalizeName(task.Name)] = task.Id
+	}
+
+	matched := map[togglplanapi.ID][]TimeEntry{}
+	var unmatched []TimeEntry
+
+	for _, entry := range entries {
+		taskId, ok := matchEntry(entry, byId, byName)
+		if ok {
+			matched[taskId] = append(matched[taskId], entry)
+		} else {
+			unmatched = append(unmatched, entry)
+		}
+	}
+
+	return matched, unmatched
+}
+
+// matchEntry returns the ID of the task an entry belongs to.
+func matchEntry(entry TimeEntry, byId map[togglplanapi.ID]bool, byName map[string]togglplanapi.ID) (togglplanapi.ID, bool) {
+	for _, reference := range taskReference.FindAllStringSubmatch(entry.Description, -1) {
+		id, err := togglplanapi.ParseID(reference[1])
+		if err == nil && byId[id] {
+			return id, true
+		}
+	}
+
+	id, ok := byName[normalizeName(entry.Description)]
+	return id, ok
+}
+
+// normalizeName prepares a task name or description for comparison.
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// PlannedVsActual compares the estimate of every task with the time tracked against it.
+// Running entries are counted up to now.
+// Arguments:
+//
+//	tasks: Tasks to report on
+//	entries: Time entries to match against the tasks
+func PlannedVsActual(tasks []togglplanapi.Task, entries []TimeEntry) []TaskActual {
+	matched, _ := Match(tasks, entries)
+	now := time.Now()
+
+	report := make([]TaskActual, len(tasks))
+	for i, task := range tasks {
+		var seconds int64
+		for _, entry := range matched[task.Id] {
+			if entry.Running() {
+				seconds += int64(now.Sub(entry.Start) / time.Second)
+			} else {
+				seconds += entry.Duration
+			}
+		}
+
+		report[i] = TaskActual{
+			Task:           task,
+			PlannedMinutes: int(task.EstimatedMinutes),
+			ActualMinutes:  int(seconds / 60),
+			Entries:        matched[task.Id],
+		}
+	}
+
+	return report
+}
+
+// Compare fetches the tasks of a workspace matching filter and the time
+// tracked during the same period, and compares them with PlannedVsActual.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the requests
+//	pa: togglPlanApi instance
+//	tc: Toggl Track client
+//	workspaceId: ID of the Toggl Plan workspace
+//	filter: Date range and optional project/member restrictions
+func Compare(ctx context.Context, pa *togglplanapi.Client, tc *Client, workspaceId togglplanapi.ID, filter togglplanapi.TaskFilter) ([]TaskActual, error) {
+	tasks, err := togglplanapi.ListAllTasks(ctx, pa, workspaceId, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Until is a date, so include the whole of its day
+	entries, err := GetTimeEntries(ctx, tc, filter.Since.In(time.Local), filter.Until.AddDays(1).In(time.Local))
+	if err != nil {
+		return nil, err
+	}
+
+	return PlannedVsActual(tasks, entries), nil
+}
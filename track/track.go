@@ -0,0 +1,129 @@
+/*
+Package track connects Toggl Plan tasks with the time tracked against them
+in Toggl Track, to compare planned and actual effort.
+
+Time entries are matched to tasks by convention: an entry whose description
+contains "#<task id>" (e.g. "Mockups #1234") belongs to that task. Otherwise,
+an entry whose description equals a task's name (ignoring case and
+surrounding spaces) belongs to that task.
+
+Example Usage:
+
+	import (
+		"context"
+		"fmt"
+
+		"github.com/ricotheque/togglplanapi"
+		"github.com/ricotheque/togglplanapi/track"
+	)
+
+	func main() {
+		credentials := togglplanapi.Credentials{Username: username, Password: password}
+		pa := togglplanapi.New(username, password, clientId, clientSecret, "")
+
+		// Toggl Track accepts the same account credentials
+		tc := track.FromCredentials(credentials)
+
+		report, err := track.Compare(context.Background(), pa, tc, workspaceId, filter)
+
+		for _, row := range report {
+			fmt.Println(row.Task.Name, row.PlannedMinutes, row.ActualMinutes)
+		}
+	}
+*/
+package track
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"togglplanapi"
+)
+
+// defaultBaseURL is the scheme and host of the Toggl Track API.
+const defaultBaseURL = "https://api.track.toggl.com"
+
+// Client holds the credentials used to access the Toggl Track API.
+type Client struct {
+	username   string
+	password   string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// New returns a Toggl Track client authenticating with an account's email
+// and password, or with an API token as username and "api_token" as password.
+func New(username string, password string) *Client {
+	return &Client{
+		username:   username,
+		password:   password,
+		baseURL:    defaultBaseURL,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// FromCredentials returns a Toggl Track client using the account
+// credentials of Toggl Plan. The client credentials are not used.
+func FromCredentials(credentials togglplanapi.Credentials) *Client {
+	return New(credentials.Username, credentials.Password)
+}
+
+// TimeEntry represents a time entry in Toggl Track.
+// Duration is in seconds, and is negative while the entry is running.
+type TimeEntry struct {
+	Id          int64      `json:"id"`
+	WorkspaceId int64      `json:"workspace_id"`
+	ProjectId   int64      `json:"project_id"`
+	Description string     `json:"description"`
+	Start       time.Time  `json:"start"`
+	Stop        *time.Time `json:"stop"`
+	Duration    int64      `json:"duration"`
+	Tags        []string   `json:"tags"`
+}
+
+// Running reports whether the entry is still being tracked.
+func (entry TimeEntry) Running() bool {
+	return entry.Duration < 0
+}
+
+// GetTimeEntries fetches the authenticated user's time entries that started
+// between since and until.
+// Arguments:
+//
+//	ctx: Context controlling cancellation of the request
+//	tc: Toggl Track client
+//	since: Start of the range
+//	until: End of the range
+func GetTimeEntries(ctx context.Context, tc *Client, since time.Time, until time.Time) ([]TimeEntry, error) {
+	query := url.Values{}
+	query.Set("start_date", since.Format(time.RFC3339))
+	query.Set("end_date", until.Format(time.RFC3339))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", tc.baseURL+"/api/v9/me/time_entries?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(tc.username, tc.password)
+
+	resp, err := tc.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 401 || resp.StatusCode == 403 {
+		return nil, errors.New("401")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("toggl track: %s", http.StatusText(resp.StatusCode))
+	}
+
+	var entries []TimeEntry
+	err = json.NewDecoder(resp.Body).Decode(&entries)
+	return entries, err
+}
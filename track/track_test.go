@@ -0,0 +1,99 @@
+package track
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"togglplanapi"
+)
+
+func TestGetTimeEntries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		if username != "ada@example.com" || password != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `[{"id":1,"description":"Mockups","duration":3600,"start":"2024-03-04T09:00:00Z"}]`)
+	}))
+	defer server.Close()
+
+	tc := New("ada@example.com", "secret")
+	tc.baseURL = server.URL
+
+	entries, err := GetTimeEntries(context.Background(), tc, time.Now().AddDate(0, 0, -7), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Duration != 3600 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+
+	tc.password = "wrong"
+	if _, err := GetTimeEntries(context.Background(), tc, time.Now(), time.Now()); err == nil {
+		t.Fatal("expected an error for bad credentials")
+	}
+}
+
+func TestPlannedVsActual(t *testing.T) {
+	tasks := []togglplanapi.Task{
+		{Id: 12, Name: "Mockups", EstimatedMinutes: 120},
+		{Id: 34, Name: "Review", EstimatedMinutes: 60},
+	}
+
+	entries := []TimeEntry{
+		{Description: "Mockups #12", Duration: 3600},
+		{Description: " mockups ", Duration: 5400},
+		{Description: "Follow-up for #34 and #99", Duration: 1800},
+		{Description: "Lunch", Duration: 1800},
+	}
+
+	matched, unmatched := Match(tasks, entries)
+	if len(matched[12]) != 2 || len(matched[34]) != 1 || len(unmatched) != 1 {
+		t.Fatalf("unexpected matches %v, unmatched %v", matched, unmatched)
+	}
+
+	report := PlannedVsActual(tasks, entries)
+	if report[0].ActualMinutes != 150 || report[0].VarianceMinutes() != 30 {
+		t.Fatalf("unexpected report for task 12: %+v", report[0])
+	}
+	if report[1].ActualMinutes != 30 || report[1].VarianceMinutes() != -30 {
+		t.Fatalf("unexpected report for task 34: %+v", report[1])
+	}
+}
+
+func TestVariance(t *testing.T) {
+	actuals := []TaskActual{
+		{Task: togglplanapi.Task{Id: 1, Name: "Mockups", ProjectId: 3, Assignees: []togglplanapi.ID{7}}, PlannedMinutes: 120, ActualMinutes: 180},
+		{Task: togglplanapi.Task{Id: 2, Name: "Copy", ProjectId: 3, Assignees: []togglplanapi.ID{7, 8}}, PlannedMinutes: 60, ActualMinutes: 30},
+		{Task: togglplanapi.Task{Id: 3, Name: "Admin"}, ActualMinutes: 15},
+	}
+	projects := []togglplanapi.Project{{Id: 3, Name: "Website"}}
+
+	rows := Variance(actuals, ByProject, projects, nil)
+	if len(rows) != 2 || rows[0].Name != "Website" || rows[0].Tasks != 2 || rows[0].VarianceMinutes() != 30 || rows[1].Name != "No project" {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+
+	rows = Variance(actuals, ByMember, nil, []togglplanapi.Member{{Id: 7, Name: "Ada"}})
+	if len(rows) != 3 || rows[0].Name != "Ada" || rows[0].PlannedMinutes != 180 || rows[2].Name != "#8" {
+		t.Fatalf("unexpected rows %+v", rows)
+	}
+
+	var b strings.Builder
+	if err := WriteVarianceCSV(&b, Variance(actuals, ByTask, nil, nil)); err != nil {
+		t.Fatal(err)
+	}
+	expected := "id,name,tasks,planned_hours,actual_hours,variance_hours,variance_percent\n" +
+		"1,Mockups,1,2.00,3.00,1.00,50.0\n" +
+		"3,Admin,1,0.00,0.25,0.25,\n" +
+		"2,Copy,1,1.00,0.50,-0.50,-50.0\n"
+	if b.String() != expected {
+		t.Fatalf("unexpected CSV:\n%s", b.String())
+	}
+}
@@ -0,0 +1,165 @@
+package track
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"togglplanapi"
+)
+
+// VarianceGrouping selects what the rows of a variance report are.
+type VarianceGrouping int
+
+const (
+	// ByTask has a row per task.
+	ByTask VarianceGrouping = iota
+	// ByProject has a row per project, and one for tasks outside projects.
+	ByProject
+	// ByMember has a row per assignee, and one for unassigned tasks.
+	ByMember
+)
+
+// VarianceRow is the planned and tracked effort of a task, project or member.
+type VarianceRow struct {
+	// Id is the ID of the task, project or member, or 0 for tasks outside
+	// projects or without assignees.
+	Id             togglplanapi.ID
+	Name           string
+	Tasks          int
+	PlannedMinutes int
+	ActualMinutes  int
+}
+
+// VarianceMinutes returns how much more time was tracked than planned.
+// It is negative if less time was tracked than planned.
+func (row VarianceRow) VarianceMinutes() int {
+	return row.ActualMinutes - row.PlannedMinutes
+}
+
+// VariancePercent returns the variance as a percentage of the planned
+// time, or 0 if no time was planned.
+func (row VarianceRow) VariancePercent() float64 {
+	if row.PlannedMinutes == 0 {
+		return 0
+	}
+	return float64(row.VarianceMinutes()) / float64(row.PlannedMinutes) * 100
+}
+
+// Variance sums the planned and tracked effort of tasks per task, project or
+// member, largest overrun first. Tasks with several assignees count in full
+// for each of them.
+// Arguments:
+//
+//	actuals: Comparison of tasks with their tracked time, as returned by Compare
+//	groupBy: ByTask, ByProject or ByMember
+//	projects: Projects used to look up project names (may be nil)
+//	members: Members used to look up member names (may be nil)
+func Variance(actuals []TaskActual, groupBy VarianceGrouping, projects []togglplanapi.Project, members []togglplanapi.Member) []VarianceRow {
+	names := map[togglplanapi.ID]string{}
+	switch groupBy {
+	case ByProject:
+		for _, project := range projects {
+			names[project.Id] = project.Name
+		}
+		names[0] = "No project"
+	case ByMember:
+		for _, member := range members {
+			names[member.Id] = member.Name
+		}
+		names[0] = "Unassigned"
+	}
+
+	rows := map[togglplanapi.ID]*VarianceRow{}
+	var ids []togglplanapi.ID
+	for _, actual := range actuals {
+		var keys []togglplanapi.ID
+		switch groupBy {
+		case ByTask:
+			keys = []togglplanapi.ID{actual.Task.Id}
+			names[actual.Task.Id] = actual.Task.Name
+		case ByProject:
+			keys = []togglplanapi.ID{actual.Task.ProjectId}
+		case ByMember:
+			keys = actual.Task.Assignees
+			if len(keys) == 0 {
+				keys = []togglplanapi.ID{0}
+			}
+		}
+
+		for _, key := range keys {
+			row, ok := rows[key]
+			if !ok {
+				name, known := names[key]
+				if !known {
+					name = fmt.Sprintf("#%d", key)
+				}
+				row = &VarianceRow{Id: key, Name: name}
+				rows[key] = row
+				ids = append(ids, key)
+			}
+			row.Tasks++
+			row.PlannedMinutes += actual.PlannedMinutes
+			row.ActualMinutes += actual.ActualMinutes
+		}
+	}
+
+	report := make([]VarianceRow, len(ids))
+	for i, id := range ids {
+		report[i] = *rows[id]
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].VarianceMinutes() != report[j].VarianceMinutes() {
+			return report[i].VarianceMinutes() > report[j].VarianceMinutes()
+		}
+		return report[i].Name < report[j].Name
+	})
+	return report
+}
+
+// varianceColumns is the header row written by WriteVarianceCSV.
+var varianceColumns = []string{"id", "name", "tasks", "planned_hours", "actual_hours", "variance_hours", "variance_percent"}
+
+// WriteVarianceCSV writes a variance report to w as CSV, starting with a
+// header row. The columns are id, name, tasks, planned_hours, actual_hours,
+// variance_hours and variance_percent. Hours have two decimals and
+// percentages one, and the percentage is empty if no time was planned.
+// Arguments:
+//
+//	w: Destination of the CSV
+//	rows: Rows returned by Variance
+func WriteVarianceCSV(w io.Writer, rows []VarianceRow) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(varianceColumns); err != nil {
+		return err
+	}
+
+	hours := func(minutes int) string {
+		return strconv.FormatFloat(float64(minutes)/60, 'f', 2, 64)
+	}
+	for _, row := range rows {
+		percent := ""
+		if row.PlannedMinutes > 0 {
+			percent = strconv.FormatFloat(row.VariancePercent(), 'f', 1, 64)
+		}
+
+		record := []string{
+			row.Id.String(),
+			row.Name,
+			strconv.Itoa(row.Tasks),
+			hours(row.PlannedMinutes),
+			hours(row.ActualMinutes),
+			hours(row.VarianceMinutes()),
+			percent,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
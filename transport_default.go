@@ -0,0 +1,36 @@
+//go:build !js
+
+package togglplanapi
+
+import (
+	"net"
+	"net/http"
+	"runtime"
+	"time"
+)
+
+// newTransport returns a pooled transport, which takes its proxy from the
+// environment (HTTPS_PROXY, NO_PROXY) unless WithProxy is given. Its
+// settings are those of go-cleanhttp, which the package no longer needs.
+func newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		MaxIdleConnsPerHost:   runtime.GOMAXPROCS(0) + 1,
+		// Negotiate HTTP/2, so that concurrent requests share a connection,
+		// even when the TLS configuration is replaced
+		ForceAttemptHTTP2: true,
+	}
+}
+
+// setDialer makes t open its connections with dialer.
+func setDialer(t *http.Transport, dialer *net.Dialer) {
+	t.DialContext = dialer.DialContext
+}
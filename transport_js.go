@@ -0,0 +1,19 @@
+//go:build js
+
+package togglplanapi
+
+import (
+	"net"
+	"net/http"
+)
+
+// newTransport returns a transport without a dialer, so that net/http sends
+// requests with the fetch API of the browser. The browser picks the protocol,
+// proxy and certificates on its own, so WithProxy, WithTLSConfig and the
+// connection settings of WithTransportOptions have no effect.
+func newTransport() *http.Transport {
+	return &http.Transport{}
+}
+
+// setDialer does nothing, as setting a dialer would turn off the fetch API.
+func setDialer(t *http.Transport, dialer *net.Dialer) {}
@@ -0,0 +1,210 @@
+package togglplanapi
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestWithProxy(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.URL.Host, r.Header.Get("Proxy-Authorization"))
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	proxyURL.User = url.UserPassword("user", "pass")
+
+	pa := New(username, password, clientId, clientSecret, "token", WithProxy(proxyURL))
+
+	result, err := Get(pa, "http://plan.example/api/v5/me")
+	expected := "plan.example Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+	if err != nil || result != expected {
+		t.Fatalf("expected the request to go through the proxy, got %q, %v", result, err)
+	}
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	hash := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(hash[:])
+
+	pa := New(username, password, clientId, clientSecret, "token", WithTLSConfig(&tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}, pin))
+	if result, err := Get(pa, server.URL); err != nil || result != "ok" {
+		t.Fatalf("expected the pinned server to be trusted, got %q, %v", result, err)
+	}
+
+	pa = New(username, password, clientId, clientSecret, "token", WithTLSConfig(&tls.Config{RootCAs: roots}, "bm90IHRoZSBrZXk="))
+	if _, err := Get(pa, server.URL); !errors.Is(err, errPinMismatch) {
+		t.Fatalf("expected a pin mismatch, got %v", err)
+	}
+}
+
+func TestWithTLSConfigUnverifiedPin(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	// The server adds a certificate outside of its chain, whose key is pinned
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotBefore: time.Now(), NotAfter: time.Now().Add(time.Hour)}
+	extra, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate := &server.TLS.Certificates[0]
+	certificate.Certificate = append(certificate.Certificate, extra)
+
+	parsed, _ := x509.ParseCertificate(extra)
+	hash := sha256.Sum256(parsed.RawSubjectPublicKeyInfo)
+	pin := base64.StdEncoding.EncodeToString(hash[:])
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	pa := New(username, password, clientId, clientSecret, "token", WithTLSConfig(&tls.Config{RootCAs: roots}, pin))
+	if _, err := Get(pa, server.URL); !errors.Is(err, errPinMismatch) {
+		t.Fatalf("expected a certificate outside of the verified chain not to match, got %v", err)
+	}
+
+	pa = New(username, password, clientId, clientSecret, "token", WithTLSConfig(&tls.Config{InsecureSkipVerify: true}, pin))
+	if _, err := Get(pa, server.URL); !errors.Is(err, errPinMismatch) {
+		t.Fatalf("expected only the server certificate to be checked without verification, got %v", err)
+	}
+}
+
+func TestWithTransportOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	defer server.Close()
+
+	pa := New(username, password, clientId, clientSecret, "token", WithTransportOptions(TransportOptions{
+		RequestTimeout: 50 * time.Millisecond,
+		MaxIdleConns:   5,
+	}))
+	if transport(pa).MaxIdleConns != 5 {
+		t.Errorf("expected the pool size to be set, got %d", transport(pa).MaxIdleConns)
+	}
+
+	if result, err := Get(pa, server.URL); err != nil || result != "ok" {
+		t.Fatalf("unexpected result %q, %v", result, err)
+	}
+	CloseIdleConnections(pa)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, _, err := authenticatedRequest(ctx, pa, server.URL+"/slow", http.MethodGet, nil, nil); err == nil {
+		t.Fatal("expected the request to time out")
+	}
+}
+
+func TestHTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	pa := New(username, password, clientId, clientSecret, "token",
+		WithTLSConfig(&tls.Config{RootCAs: roots}),
+		WithTransportOptions(TransportOptions{MaxIdleConnsPerHost: 32, KeepAlive: time.Minute}),
+	)
+	if transport(pa).MaxIdleConnsPerHost != 32 {
+		t.Errorf("expected the per-host pool size to be set, got %d", transport(pa).MaxIdleConnsPerHost)
+	}
+
+	if result, err := Get(pa, server.URL); err != nil || result != "HTTP/2.0" {
+		t.Fatalf("expected HTTP/2, got %q, %v", result, err)
+	}
+}
+
+func TestWithRedirects(t *testing.T) {
+	var otherAuth string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		otherAuth = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"id":1}`)
+	}))
+	t.Cleanup(other.Close)
+
+	var sameAuth string
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v5/1/tasks/1":
+			http.Redirect(w, r, other.URL+"/tasks/1", http.StatusFound)
+		case "/api/v5/1/tasks/2":
+			http.Redirect(w, r, "/api/v5/1/tasks/3", http.StatusFound)
+		case "/api/v5/1/tasks/3":
+			sameAuth = r.Header.Get("Authorization")
+			fmt.Fprint(w, `{"id":3}`)
+		default:
+			http.Redirect(w, r, r.URL.Path, http.StatusFound)
+		}
+	})
+
+	ctx := context.Background()
+	if _, err := GetTask(ctx, pa, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetTask(ctx, pa, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if otherAuth != "" || sameAuth != "Bearer token" {
+		t.Errorf("expected the token on the same host only, got %q and %q", otherAuth, sameAuth)
+	}
+
+	// Same host, but in cleartext or on another port
+	redirect := checkRedirect(RedirectOptions{})
+	sent := httptest.NewRequest("GET", "https://api.example.com/api/v5/me", nil)
+	for _, target := range []string{"http://api.example.com/api/v5/me", "https://api.example.com:8443/api/v5/me"} {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set("Authorization", "Bearer token")
+		if err := redirect(req, []*http.Request{sent}); err != nil || req.Header.Get("Authorization") != "" {
+			t.Errorf("expected the token to be dropped on the redirect to %s, got %v", target, err)
+		}
+	}
+
+	WithRedirects(RedirectOptions{MaxRedirects: 2})(pa)
+	start := time.Now()
+	if _, err := GetTask(ctx, pa, 1, 4); !errors.Is(err, ErrTooManyRedirects) || IsRetryable(err) {
+		t.Errorf("expected too many redirects, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("expected the redirect loop not to be retried")
+	}
+
+	WithRedirects(RedirectOptions{Disabled: true})(pa)
+	var apiErr *APIError
+	if _, err := GetTask(ctx, pa, 1, 2); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusFound {
+		t.Errorf("expected the redirect to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package togglplanapi
+
+import (
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Version is the version of this package, as sent in the User-Agent header.
+// It is read from the build info of the program, and is "devel" when the
+// package isn't built as a versioned dependency.
+var Version = moduleVersion()
+
+// moduleVersion looks up the version of this package's module in the build
+// info of the program.
+func moduleVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+
+	path := reflect.TypeOf(Date{}).PkgPath()
+	for _, dep := range info.Deps {
+		if dep.Path == path && dep.Version != "" {
+			return strings.TrimPrefix(dep.Version, "v")
+		}
+	}
+	return "devel"
+}
+
+// WithUserAgent appends a product token of the application, such as
+// "plan-sync/2.1", to the default User-Agent of the client, so that its
+// traffic can be told apart from other users of this package.
+// It can be given several times to append several tokens.
+func WithUserAgent(product string) Option {
+	return func(pa *togglPlanApi) {
+		pa.userAgent = strings.TrimSpace(pa.userAgent + " " + product)
+		pa.userAgentHeader = userAgent(pa)
+	}
+}
+
+// userAgent returns the User-Agent header of pa's requests, e.g.
+// "togglplanapi/1.4.0 go/1.21.5 plan-sync/2.1".
+func userAgent(pa *togglPlanApi) string {
+	agent := "togglplanapi/" + Version + " go/" + strings.TrimPrefix(runtime.Version(), "go")
+	if pa.userAgent != "" {
+		agent += " " + pa.userAgent
+	}
+	return agent
+}
@@ -0,0 +1,264 @@
+package togglplanapi
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxNameLength is the longest name accepted for tasks, milestones, projects and tags.
+const maxNameLength = 255
+
+// Validator is implemented by the inputs of write requests, such as
+// TaskParams and TaskUpdate. Unless validation is turned off with
+// WithValidation(false), inputs are validated before they are sent, and
+// invalid ones are rejected without a request.
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError lists the problems found in the input of a write request.
+type ValidationError struct {
+	// Kind is what the input describes, such as "task".
+	Kind     string
+	Problems []error
+}
+
+// Error lists all the problems on one line.
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Problems))
+	for i, problem := range e.Problems {
+		problems[i] = problem.Error()
+	}
+	return "invalid " + e.Kind + ": " + strings.Join(problems, "; ")
+}
+
+// Unwrap returns the problems, for errors.Is and errors.As.
+func (e *ValidationError) Unwrap() []error {
+	return e.Problems
+}
+
+// validation collects the problems found in an input.
+type validation struct {
+	kind     string
+	problems []error
+}
+
+// fail records a problem.
+func (v *validation) fail(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Errorf(format, args...))
+}
+
+// name checks a required name.
+func (v *validation) name(label string, name string) {
+	switch {
+	case strings.TrimSpace(name) == "":
+		v.fail("%s is required", label)
+	case utf8.RuneCountInString(name) > maxNameLength:
+		v.fail("%s is longer than %d characters", label, maxNameLength)
+	}
+}
+
+// dates checks that end, if set, is not before start.
+func (v *validation) dates(start Date, end Date) {
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		v.fail("end date %s is before start date %s", end, start)
+	}
+}
+
+// clock checks a HH:MM time of day, returning it as minutes since midnight.
+func (v *validation) clock(label string, clock string) (int, bool) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		v.fail("invalid %s %q", label, clock)
+		return 0, false
+	}
+	return parsed.Hour()*60 + parsed.Minute(), true
+}
+
+// color checks a color index.
+func (v *validation) color(color Color) {
+	if !color.Valid() {
+		v.fail("color %d is not in the palette", int(color))
+	}
+}
+
+// ids checks that a list of references has no zero IDs.
+func (v *validation) ids(label string, ids []ID) {
+	for _, id := range ids {
+		if id == 0 {
+			v.fail("%s contain an empty ID", label)
+			return
+		}
+	}
+}
+
+// err returns the problems found as a *ValidationError, or nil.
+func (v *validation) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Kind: v.kind, Problems: v.problems}
+}
+
+// Validate checks the fields of a task to create.
+func (params TaskParams) Validate() error {
+	v := &validation{kind: "task"}
+	v.name("title", params.Name)
+	if params.StartDate.IsZero() != params.EndDate.IsZero() {
+		v.fail("start and end dates must be set together")
+	}
+	v.dates(params.StartDate, params.EndDate)
+	v.color(params.Color)
+	if !params.EstimatedMinutes.Valid() {
+		v.fail("estimate %s is negative", params.EstimatedMinutes)
+	}
+	v.ids("assignees", params.Assignees)
+	for _, item := range params.Checklist {
+		if strings.TrimSpace(item.Name) == "" {
+			v.fail("checklist items need a name")
+			break
+		}
+	}
+
+	if params.StartTime != "" || params.EndTime != "" {
+		switch {
+		case params.StartTime == "" || params.EndTime == "":
+			v.fail("start and end times must be set together")
+		case params.StartDate.IsZero():
+			v.fail("times need start and end dates")
+		default:
+			start, okStart := v.clock("start time", params.StartTime)
+			end, okEnd := v.clock("end time", params.EndTime)
+			if okStart && okEnd && params.StartDate == params.EndDate && end <= start {
+				v.fail("end time %s is not after start time %s", params.EndTime, params.StartTime)
+			}
+		}
+	}
+
+	return v.err()
+}
+
+// Validate checks the fields set in a task update. Fields that depend on
+// each other, such as the dates, are only compared when both are set.
+func (update TaskUpdate) Validate() error {
+	v := &validation{kind: "task update"}
+	if update.Name != nil {
+		v.name("title", *update.Name)
+	}
+	if update.StartDate != nil && update.EndDate != nil {
+		v.dates(*update.StartDate, *update.EndDate)
+	}
+	if update.StartTime != nil && *update.StartTime != "" {
+		v.clock("start time", *update.StartTime)
+	}
+	if update.EndTime != nil && *update.EndTime != "" {
+		v.clock("end time", *update.EndTime)
+	}
+	if update.Color != nil {
+		v.color(*update.Color)
+	}
+	if update.EstimatedMinutes != nil && !update.EstimatedMinutes.Valid() {
+		v.fail("estimate %s is negative", *update.EstimatedMinutes)
+	}
+	if update.Assignees != nil {
+		v.ids("assignees", *update.Assignees)
+	}
+	return v.err()
+}
+
+// Validate checks the fields of a milestone to create.
+func (params MilestoneParams) Validate() error {
+	v := &validation{kind: "milestone"}
+	v.name("name", params.Name)
+	if params.Date.IsZero() {
+		v.fail("date is required")
+	}
+	return v.err()
+}
+
+// Validate checks the fields set in a milestone update.
+func (update MilestoneUpdate) Validate() error {
+	v := &validation{kind: "milestone update"}
+	if update.Name != nil {
+		v.name("name", *update.Name)
+	}
+	if update.Date != nil && update.Date.IsZero() {
+		v.fail("date can't be removed")
+	}
+	return v.err()
+}
+
+// Validate checks the fields of a project to create.
+func (params ProjectParams) Validate() error {
+	v := &validation{kind: "project"}
+	v.name("name", params.Name)
+	v.dates(params.StartDate, params.EndDate)
+	v.color(params.Color)
+	return v.err()
+}
+
+// Validate checks the fields set in a project update.
+func (update ProjectUpdate) Validate() error {
+	v := &validation{kind: "project update"}
+	if update.Name != nil {
+		v.name("name", *update.Name)
+	}
+	if update.StartDate != nil && update.EndDate != nil {
+		v.dates(*update.StartDate, *update.EndDate)
+	}
+	if update.Color != nil {
+		v.color(*update.Color)
+	}
+	return v.err()
+}
+
+// Validate checks the fields of a tag to create.
+func (params TagParams) Validate() error {
+	v := &validation{kind: "tag"}
+	v.name("name", params.Name)
+	v.color(params.Color)
+	return v.err()
+}
+
+// Validate checks the fields of a member to invite.
+func (params MemberParams) Validate() error {
+	v := &validation{kind: "member"}
+	if !strings.Contains(params.Email, "@") {
+		v.fail("invalid email %q", params.Email)
+	}
+	if params.Role != "" && !params.Role.Valid() {
+		v.fail("unknown role %q", params.Role)
+	}
+	return v.err()
+}
+
+// Validate checks the new role.
+func (update roleUpdate) Validate() error {
+	v := &validation{kind: "role update"}
+	if !update.Role.Valid() {
+		v.fail("unknown role %q", update.Role)
+	}
+	return v.err()
+}
+
+// Validate checks the role of a project member.
+func (member ProjectMember) Validate() error {
+	v := &validation{kind: "project member"}
+	v.ids("members", []ID{member.MemberId})
+	if !member.Role.Valid() {
+		v.fail("unknown project role %q", member.Role)
+	}
+	return v.err()
+}
+
+// validateInput validates in before it is sent, if the client validates
+// inputs and in implements Validator.
+func validateInput(pa *togglPlanApi, in interface{}) error {
+	validator, ok := in.(Validator)
+	if !ok || !pa.validate {
+		return nil
+	}
+	return validator.Validate()
+}
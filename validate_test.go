@@ -0,0 +1,62 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		input Validator
+		valid bool
+	}{
+		{TaskParams{Name: "Design", StartDate: NewDate(2024, 3, 4), EndDate: NewDate(2024, 3, 4)}, true},
+		{TaskParams{Name: "Design", Color: 42}, false},
+		{TaskParams{Name: "Design", Assignees: []ID{3, 0}}, false},
+		{TaskUpdate{}, true},
+		{TaskUpdate{Name: String("")}, false},
+		{TaskUpdate{StartDate: &Date{}, EndDate: &Date{}}, true},
+		{MilestoneParams{Name: "Launch"}, false},
+		{MilestoneUpdate{Date: &Date{}}, false},
+		{ProjectParams{Name: "Web", StartDate: NewDate(2024, 3, 4)}, true},
+		{TagParams{Name: " "}, false},
+	}
+
+	for _, test := range tests {
+		err := test.input.Validate()
+		if (err == nil) != test.valid {
+			t.Errorf("%+v: unexpected result %v", test.input, err)
+		}
+
+		var invalid *ValidationError
+		if err != nil && !errors.As(err, &invalid) {
+			t.Errorf("%+v: expected a *ValidationError, got %T", test.input, err)
+		}
+	}
+}
+
+func TestValidationBeforeWrite(t *testing.T) {
+	requests := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"id":1}`))
+	}
+
+	pa := newTestClient(t, handler)
+	if _, err := CreateTask(context.Background(), pa, 42, TaskParams{}); err == nil {
+		t.Fatal("expected an error for a task without a title")
+	}
+	if requests != 0 {
+		t.Fatalf("invalid task was sent")
+	}
+
+	WithValidation(false)(pa)
+	if _, err := CreateTask(context.Background(), pa, 42, TaskParams{}); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected the task to be sent with validation off")
+	}
+}
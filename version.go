@@ -0,0 +1,52 @@
+package togglplanapi
+
+import (
+	"errors"
+	"fmt"
+)
+
+// APIVersion is a version of the Toggl Plan API, such as "v5".
+type APIVersion string
+
+// Versions of the Toggl Plan API.
+const (
+	APIv5 APIVersion = "v5"
+)
+
+// DefaultAPIVersion is the version of the API clients talk to unless
+// WithAPIVersion is given.
+const DefaultAPIVersion = APIv5
+
+// typedVersions lists the API versions the typed calls and their models are
+// written for. Raw calls such as Request work with any version.
+var typedVersions = []APIVersion{APIv5}
+
+// ErrUnsupportedVersion is matched, using errors.Is, by errors of typed calls
+// made by a client set to an API version they don't support yet.
+var ErrUnsupportedVersion = errors.New("unsupported API version")
+
+// WithAPIVersion sets the version of the API the client talks to, e.g. to try
+// out a new version with raw calls before the typed calls support it.
+func WithAPIVersion(version APIVersion) Option {
+	return func(pa *togglPlanApi) {
+		pa.version = version
+	}
+}
+
+// APIURL returns the full URL of path in the API version of pa, e.g.
+// https://api.plan.toggl.com/api/v5/me for "/me". Use it to build the URLs
+// passed to Request, rather than writing out the version.
+func APIURL(pa *togglPlanApi, path string) string {
+	return pa.baseURL + "/api/" + string(pa.version) + path
+}
+
+// checkTypedVersion returns an error if the typed calls don't support the API
+// version of pa.
+func checkTypedVersion(pa *togglPlanApi) error {
+	for _, version := range typedVersions {
+		if pa.version == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q: typed calls support %v", ErrUnsupportedVersion, pa.version, typedVersions)
+}
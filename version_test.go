@@ -0,0 +1,27 @@
+package togglplanapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestAPIVersion(t *testing.T) {
+	pa := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	})
+
+	if url := APIURL(pa, "/me"); url != pa.baseURL+"/api/v5/me" {
+		t.Errorf("unexpected default URL %q", url)
+	}
+
+	WithAPIVersion("v6")(pa)
+	if result, err := Get(pa, APIURL(pa, "/me")); err != nil || result != "/api/v6/me" {
+		t.Errorf("expected raw calls to use v6, got %q, %v", result, err)
+	}
+	if _, err := GetTask(context.Background(), pa, 1, 2); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("expected typed calls to reject v6, got %v", err)
+	}
+}